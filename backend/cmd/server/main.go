@@ -42,6 +42,12 @@ func main() {
 
 	svc := service.New(repo, cfg)
 
+	schedCtx, stopSchedulers := context.WithCancel(context.Background())
+	defer stopSchedulers()
+	svc.StartStaleBranchScheduler(schedCtx)
+	svc.StartApprovalScheduler(schedCtx)
+	svc.StartReplicationScheduler(schedCtx)
+
 	r := chi.NewRouter()
 	r.Use(middleware.Recovery)
 	r.Use(middleware.CORS(cfg.Server.CORSOrigin))
@@ -52,7 +58,11 @@ func main() {
 	// r.Use() is called after any route has been defined on the same mux.
 	r.Use(func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			r.Body = http.MaxBytesReader(w, r.Body, int64(cfg.Server.BodyLimitMB)*1024*1024)
+			limitMB := cfg.Server.BodyLimitMB
+			if r.URL.Path == handler.CSVUploadPath {
+				limitMB = cfg.Server.Upload.LimitMB
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, int64(limitMB)*1024*1024)
 			next.ServeHTTP(w, r)
 		})
 	})
@@ -109,6 +119,7 @@ func main() {
 		signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 		<-sigCh
 		log.Println("shutting down server gracefully (30s timeout)...")
+		stopSchedulers()
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
 		if err := srv.Shutdown(ctx); err != nil {
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	_ "time/tzdata" // change_control.timezone must resolve on hosts without zoneinfo
+)
+
+// ChangeControl restricts when approvals may merge into main. Without windows,
+// merges are allowed at any time outside freeze periods.
+type ChangeControl struct {
+	Timezone string         `yaml:"timezone"` // IANA name for windows (default UTC)
+	Windows  []ChangeWindow `yaml:"windows"`
+	Freezes  []FreezePeriod `yaml:"freezes"`
+}
+
+// ChangeWindow allows merges on Days between Start and End ("HH:MM", End exclusive).
+// An End earlier than Start spans midnight into the next day.
+type ChangeWindow struct {
+	Days  []string `yaml:"days"` // mon..sun; empty = every day
+	Start string   `yaml:"start"`
+	End   string   `yaml:"end"`
+}
+
+// FreezePeriod blocks merges from Start up to End (RFC3339).
+type FreezePeriod struct {
+	Start  string `yaml:"start"`
+	End    string `yaml:"end"`
+	Reason string `yaml:"reason"`
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// Blocked reports whether a merge at t is outside the allowed windows or inside a
+// freeze, with a human-readable explanation.
+func (c ChangeControl) Blocked(t time.Time) (bool, string) {
+	for _, freeze := range c.Freezes {
+		start, errStart := time.Parse(time.RFC3339, freeze.Start)
+		end, errEnd := time.Parse(time.RFC3339, freeze.End)
+		if errStart != nil || errEnd != nil {
+			continue
+		}
+		if !t.Before(start) && t.Before(end) {
+			detail := fmt.Sprintf("freeze period %s - %s", freeze.Start, freeze.End)
+			if freeze.Reason != "" {
+				detail += " (" + freeze.Reason + ")"
+			}
+			return true, detail
+		}
+	}
+	if len(c.Windows) == 0 {
+		return false, ""
+	}
+
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	local := t.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	for _, window := range c.Windows {
+		start, errStart := parseClock(window.Start)
+		end, errEnd := parseClock(window.End)
+		if errStart != nil || errEnd != nil {
+			continue
+		}
+		if start <= end {
+			if window.allowsDay(local.Weekday()) && minute >= start && minute < end {
+				return false, ""
+			}
+			continue
+		}
+		if window.allowsDay(local.Weekday()) && minute >= start {
+			return false, ""
+		}
+		if window.allowsDay((local.Weekday()+6)%7) && minute < end {
+			return false, ""
+		}
+	}
+	return true, "outside change windows"
+}
+
+func (w ChangeWindow) allowsDay(day time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, name := range w.Days {
+		if weekdayNames[strings.ToLower(name)] == day {
+			return true
+		}
+	}
+	return false
+}
+
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q (want HH:MM)", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func (c ChangeControl) validate() error {
+	if c.Timezone != "" {
+		if _, err := time.LoadLocation(c.Timezone); err != nil {
+			return fmt.Errorf("invalid timezone %q: %w", c.Timezone, err)
+		}
+	}
+	for _, window := range c.Windows {
+		for _, name := range window.Days {
+			if _, ok := weekdayNames[strings.ToLower(name)]; !ok {
+				return fmt.Errorf("invalid day %q (want mon..sun)", name)
+			}
+		}
+		if _, err := parseClock(window.Start); err != nil {
+			return err
+		}
+		if _, err := parseClock(window.End); err != nil {
+			return err
+		}
+	}
+	for _, freeze := range c.Freezes {
+		start, err := time.Parse(time.RFC3339, freeze.Start)
+		if err != nil {
+			return fmt.Errorf("invalid freeze start %q: %w", freeze.Start, err)
+		}
+		end, err := time.Parse(time.RFC3339, freeze.End)
+		if err != nil {
+			return fmt.Errorf("invalid freeze end %q: %w", freeze.End, err)
+		}
+		if !end.After(start) {
+			return fmt.Errorf("freeze end %q must be after start %q", freeze.End, freeze.Start)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestChangeControlBlocked(t *testing.T) {
+	cc := ChangeControl{
+		Timezone: "Asia/Tokyo",
+		Windows: []ChangeWindow{
+			{Days: []string{"mon", "tue", "wed", "thu", "fri"}, Start: "09:00", End: "18:00"},
+			{Days: []string{"sat"}, Start: "22:00", End: "02:00"},
+		},
+		Freezes: []FreezePeriod{
+			{Start: "2026-12-28T00:00:00+09:00", End: "2027-01-04T00:00:00+09:00", Reason: "year end"},
+		},
+	}
+	jst := time.FixedZone("JST", 9*60*60)
+
+	cases := []struct {
+		name    string
+		at      time.Time
+		blocked bool
+	}{
+		{"weekday inside window", time.Date(2026, 3, 11, 10, 0, 0, 0, jst), false},
+		{"weekday window end is exclusive", time.Date(2026, 3, 11, 18, 0, 0, 0, jst), true},
+		{"sunday", time.Date(2026, 3, 15, 10, 0, 0, 0, jst), true},
+		{"saturday night", time.Date(2026, 3, 14, 23, 0, 0, 0, jst), false},
+		{"overnight into sunday", time.Date(2026, 3, 15, 1, 30, 0, 0, jst), false},
+		{"window evaluated in configured timezone", time.Date(2026, 3, 11, 1, 0, 0, 0, time.UTC), false},
+		{"freeze overrides window", time.Date(2026, 12, 28, 10, 0, 0, 0, jst), true},
+	}
+	for _, tc := range cases {
+		blocked, detail := cc.Blocked(tc.at)
+		if blocked != tc.blocked {
+			t.Errorf("%s: blocked=%v (%s), want %v", tc.name, blocked, detail, tc.blocked)
+		}
+	}
+
+	if blocked, _ := (ChangeControl{}).Blocked(time.Now()); blocked {
+		t.Fatal("empty change control must allow merges")
+	}
+}
+
+func TestLoadRejectsInvalidChangeControl(t *testing.T) {
+	for name, body := range map[string]string{
+		"bad day":      "windows: [{days: [funday], start: \"09:00\", end: \"18:00\"}]",
+		"bad clock":    "windows: [{start: \"9am\", end: \"18:00\"}]",
+		"bad timezone": "timezone: Mars/Base",
+		"empty freeze": "freezes: [{start: \"2026-01-02T00:00:00Z\", end: \"2026-01-01T00:00:00Z\"}]",
+	} {
+		_, err := Load(writeConfigFile(t, `
+databases:
+  - target_id: local
+    name: test_db
+    change_control:
+      `+body+`
+`))
+		if err == nil {
+			t.Errorf("%s: expected load error", name)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"gopkg.in/yaml.v3"
 )
@@ -22,9 +23,33 @@ type Target struct {
 }
 
 type Database struct {
-	TargetID        string   `yaml:"target_id"`
-	Name            string   `yaml:"name"`
-	AllowedBranches []string `yaml:"allowed_branches"`
+	TargetID        string            `yaml:"target_id"`
+	Name            string            `yaml:"name"`
+	AllowedBranches []string          `yaml:"allowed_branches"`
+	StaleBranches   StaleBranchPolicy `yaml:"stale_branches"`
+	ChangeControl   ChangeControl     `yaml:"change_control"`
+	Replication     Replication       `yaml:"replication"`
+	AuditImportDir  string            `yaml:"audit_import_dir"` // directory of snapshot subdirectories for the audit import lane
+}
+
+// Stale branch actions.
+const (
+	StaleActionReport  = "report"
+	StaleActionDelete  = "delete"
+	StaleActionArchive = "archive"
+)
+
+// StaleBranchPolicy identifies abandoned wi/* branches: no commits for IdleDays,
+// no pending req/* tag, and no changes since the merge base with main.
+type StaleBranchPolicy struct {
+	IdleDays        int    `yaml:"idle_days"`        // 0 disables the policy
+	Action          string `yaml:"action"`           // report (default) | delete | archive
+	IntervalMinutes int    `yaml:"interval_minutes"` // >0 applies Action on a schedule
+}
+
+// Enabled reports whether stale detection is configured.
+func (p StaleBranchPolicy) Enabled() bool {
+	return p.IdleDays > 0
 }
 
 type Server struct {
@@ -36,6 +61,7 @@ type Server struct {
 	Retries     Retries  `yaml:"retries"`
 	Search      Search   `yaml:"search"`
 	Pool        Pool     `yaml:"pool"`
+	Upload      Upload   `yaml:"upload"`
 }
 
 type Timeouts struct {
@@ -58,6 +84,11 @@ type Search struct {
 	TimeoutSec int `yaml:"timeout_sec"`
 }
 
+type Upload struct {
+	LimitMB    int `yaml:"limit_mb"`    // max multipart upload size, separate from body_limit_mb (default 100)
+	TTLMinutes int `yaml:"ttl_minutes"` // how long a parsed upload stays available (default 30)
+}
+
 type Pool struct {
 	MaxOpen         int `yaml:"max_open"`          // max open DB connections (default 5)
 	MaxIdle         int `yaml:"max_idle"`          // max idle DB connections (default 5)
@@ -119,6 +150,35 @@ func Load(path string) (*Config, error) {
 	if cfg.Server.Pool.ConnLifetimeSec == 0 {
 		cfg.Server.Pool.ConnLifetimeSec = 3600
 	}
+	if cfg.Server.Upload.LimitMB == 0 {
+		cfg.Server.Upload.LimitMB = 100
+	}
+	if cfg.Server.Upload.TTLMinutes == 0 {
+		cfg.Server.Upload.TTLMinutes = 30
+	}
+	for i := range cfg.Databases {
+		policy := &cfg.Databases[i].StaleBranches
+		if policy.Action == "" {
+			policy.Action = StaleActionReport
+		}
+		switch policy.Action {
+		case StaleActionReport, StaleActionDelete, StaleActionArchive:
+		default:
+			return nil, fmt.Errorf("database %q: unknown stale_branches.action %q", cfg.Databases[i].Name, policy.Action)
+		}
+		if policy.IdleDays < 0 || policy.IntervalMinutes < 0 {
+			return nil, fmt.Errorf("database %q: stale_branches values must not be negative", cfg.Databases[i].Name)
+		}
+		if err := cfg.Databases[i].ChangeControl.validate(); err != nil {
+			return nil, fmt.Errorf("database %q: change_control: %w", cfg.Databases[i].Name, err)
+		}
+		if err := cfg.Databases[i].Replication.normalize(); err != nil {
+			return nil, fmt.Errorf("database %q: replication: %w", cfg.Databases[i].Name, err)
+		}
+		if dir := cfg.Databases[i].AuditImportDir; dir != "" && !filepath.IsAbs(dir) {
+			return nil, fmt.Errorf("database %q: audit_import_dir must be an absolute path", cfg.Databases[i].Name)
+		}
+	}
 
 	return &cfg, nil
 }
@@ -148,3 +148,43 @@ server:
 		t.Fatalf("AllowedBranches len = %d, want 2", len(db.AllowedBranches))
 	}
 }
+
+func TestLoadStaleBranchPolicy(t *testing.T) {
+	cfg, err := Load(writeConfigFile(t, `
+targets:
+  - id: local
+    host: localhost
+    port: 3306
+    user: root
+databases:
+  - target_id: local
+    name: test_db
+  - target_id: local
+    name: other_db
+    stale_branches:
+      idle_days: 30
+      action: archive
+      interval_minutes: 60
+`))
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if policy := cfg.Databases[0].StaleBranches; policy.Enabled() || policy.Action != StaleActionReport {
+		t.Fatalf("unexpected default policy: %+v", policy)
+	}
+	if policy := cfg.Databases[1].StaleBranches; !policy.Enabled() || policy.Action != StaleActionArchive || policy.IntervalMinutes != 60 {
+		t.Fatalf("unexpected configured policy: %+v", policy)
+	}
+
+	_, err = Load(writeConfigFile(t, `
+databases:
+  - target_id: local
+    name: test_db
+    stale_branches:
+      idle_days: 30
+      action: purge
+`))
+	if err == nil {
+		t.Fatal("expected error for unknown stale_branches.action")
+	}
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+)
+
+// Replication kinds.
+const (
+	ReplicationKindBackup = "backup" // DOLT_BACKUP sync-url: every branch, tag and working set
+	ReplicationKindRemote = "remote" // DOLT_REMOTE + DOLT_PUSH of main; DOLT_FETCH on demand
+)
+
+// Replication lists where a database is backed up or pushed. file:// URLs keep
+// copies on local or mounted storage for networks without outside access.
+type Replication struct {
+	Remotes         []ReplicationRemote `yaml:"remotes"`
+	IntervalMinutes int                 `yaml:"interval_minutes"` // >0 syncs every remote on a schedule
+}
+
+// ReplicationRemote is one backup or remote destination.
+type ReplicationRemote struct {
+	Name string `yaml:"name"`
+	URL  string `yaml:"url"`  // file:///path, https://, aws://, gs://
+	Kind string `yaml:"kind"` // backup (default) | remote
+}
+
+// FindRemote returns the remote with the given name.
+func (r Replication) FindRemote(name string) (*ReplicationRemote, bool) {
+	for i := range r.Remotes {
+		if r.Remotes[i].Name == name {
+			return &r.Remotes[i], true
+		}
+	}
+	return nil, false
+}
+
+var replicationNameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,63}$`)
+
+var replicationSchemes = map[string]bool{"file": true, "http": true, "https": true, "aws": true, "gs": true}
+
+// normalize applies the default kind and validates names, kinds and URLs.
+func (r *Replication) normalize() error {
+	if r.IntervalMinutes < 0 {
+		return fmt.Errorf("interval_minutes must not be negative")
+	}
+	seen := make(map[string]bool)
+	for i := range r.Remotes {
+		remote := &r.Remotes[i]
+		if !replicationNameRe.MatchString(remote.Name) {
+			return fmt.Errorf("invalid remote name %q", remote.Name)
+		}
+		if seen[remote.Name] {
+			return fmt.Errorf("duplicate remote name %q", remote.Name)
+		}
+		seen[remote.Name] = true
+		if remote.Kind == "" {
+			remote.Kind = ReplicationKindBackup
+		}
+		if remote.Kind != ReplicationKindBackup && remote.Kind != ReplicationKindRemote {
+			return fmt.Errorf("remote %q: unknown kind %q", remote.Name, remote.Kind)
+		}
+		parsed, err := url.Parse(remote.URL)
+		if err != nil || !replicationSchemes[parsed.Scheme] {
+			return fmt.Errorf("remote %q: url must use file://, http(s)://, aws:// or gs://", remote.Name)
+		}
+		if parsed.Scheme == "file" && parsed.Path == "" {
+			return fmt.Errorf("remote %q: file url needs an absolute path", remote.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package config
+
+import "testing"
+
+func TestLoadNormalizesReplication(t *testing.T) {
+	cfg, err := Load(writeConfigFile(t, `
+databases:
+  - target_id: local
+    name: test_db
+    replication:
+      interval_minutes: 60
+      remotes:
+        - name: nas
+          url: file:///mnt/backup/test_db
+        - name: mirror
+          url: file:///mnt/mirror/test_db
+          kind: remote
+`))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	replication := cfg.Databases[0].Replication
+	if replication.Remotes[0].Kind != ReplicationKindBackup || replication.Remotes[1].Kind != ReplicationKindRemote {
+		t.Fatalf("unexpected kinds: %+v", replication.Remotes)
+	}
+	if remote, ok := replication.FindRemote("mirror"); !ok || remote.URL != "file:///mnt/mirror/test_db" {
+		t.Fatalf("FindRemote(mirror) = %+v, %v", remote, ok)
+	}
+}
+
+func TestLoadRejectsInvalidReplication(t *testing.T) {
+	for name, body := range map[string]string{
+		"bad scheme":     "remotes: [{name: nas, url: \"ssh://host/db\"}]",
+		"relative file":  "remotes: [{name: nas, url: \"file://\"}]",
+		"bad name":       "remotes: [{name: \"../x\", url: \"file:///b\"}]",
+		"duplicate name": "remotes: [{name: nas, url: \"file:///a\"}, {name: nas, url: \"file:///b\"}]",
+		"bad kind":       "remotes: [{name: nas, url: \"file:///a\", kind: mirror}]",
+	} {
+		_, err := Load(writeConfigFile(t, `
+databases:
+  - target_id: local
+    name: test_db
+    replication:
+      `+body+`
+`))
+		if err == nil {
+			t.Errorf("%s: expected load error", name)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package footer
 import (
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -17,6 +18,8 @@ type ApprovalFooter struct {
 	// Optional fields
 	SubmittedMainHash  string // 32-char lowercase hex (may be empty)
 	RequestSubmittedAt string // RFC3339 UTC (may be empty)
+	TicketID           string // external ticket from the work item registry (may be empty)
+	ScheduledAt        string // RFC3339 effective time of a scheduled approval (may be empty)
 }
 
 var doltHashRe = regexp.MustCompile(`^[0-9a-z]{32}$`)
@@ -45,6 +48,14 @@ func BuildApprovalFooter(humanSubject string, f ApprovalFooter) string {
 		b.WriteString("\nRequest-Submitted-At: ")
 		b.WriteString(f.RequestSubmittedAt)
 	}
+	if f.TicketID != "" {
+		b.WriteString("\nTicket-Id: ")
+		b.WriteString(f.TicketID)
+	}
+	if f.ScheduledAt != "" {
+		b.WriteString("\nScheduled-At: ")
+		b.WriteString(f.ScheduledAt)
+	}
 	return b.String()
 }
 
@@ -73,6 +84,8 @@ func ParseApprovalFooter(commitMsg string) (*ApprovalFooter, error) {
 	f.SubmittedWorkHash = trailers["submitted-work-hash"]
 	f.SubmittedMainHash = trailers["submitted-main-hash"]
 	f.RequestSubmittedAt = trailers["request-submitted-at"]
+	f.TicketID = trailers["ticket-id"]
+	f.ScheduledAt = trailers["scheduled-at"]
 
 	if f.RequestID == "" {
 		return nil, fmt.Errorf("approval footer missing required field: Request-Id")
@@ -104,6 +117,116 @@ func ParseApprovalFooter(commitMsg string) (*ApprovalFooter, error) {
 	return f, nil
 }
 
+// ImportFooter holds the machine-readable fields of an audit snapshot import commit.
+type ImportFooter struct {
+	Schema     string   // "v1"
+	Snapshot   string   // snapshot directory name
+	Tables     []string // imported tables, sorted
+	Rows       int      // total rows loaded
+	ImportedAt string   // RFC3339 UTC
+}
+
+// BuildImportFooter constructs an audit import commit message with trailers.
+func BuildImportFooter(humanSubject string, f ImportFooter) string {
+	var b strings.Builder
+	b.WriteString(humanSubject)
+	b.WriteString("\n\n")
+	b.WriteString("Dolt-Import-Schema: ")
+	b.WriteString(f.Schema)
+	b.WriteString("\nImport-Snapshot: ")
+	b.WriteString(f.Snapshot)
+	b.WriteString("\nImport-Tables: ")
+	b.WriteString(strings.Join(f.Tables, ","))
+	b.WriteString("\nImport-Rows: ")
+	b.WriteString(strconv.Itoa(f.Rows))
+	b.WriteString("\nImported-At: ")
+	b.WriteString(f.ImportedAt)
+	return b.String()
+}
+
+// ParseImportFooter extracts the import footer from a commit message.
+// Returns (nil, nil) if the commit is not an audit import.
+func ParseImportFooter(commitMsg string) (*ImportFooter, error) {
+	trailers := ParseTrailers(commitMsg)
+	schema := trailers["dolt-import-schema"]
+	if schema == "" {
+		return nil, nil
+	}
+	if schema != "v1" {
+		return nil, fmt.Errorf("unsupported import schema: %s", schema)
+	}
+
+	f := &ImportFooter{Schema: schema, Snapshot: trailers["import-snapshot"], ImportedAt: trailers["imported-at"]}
+	if f.Snapshot == "" {
+		return nil, fmt.Errorf("import footer missing required field: Import-Snapshot")
+	}
+	if tables := trailers["import-tables"]; tables != "" {
+		f.Tables = strings.Split(tables, ",")
+	}
+	rows, err := strconv.Atoi(trailers["import-rows"])
+	if err != nil {
+		return nil, fmt.Errorf("import footer invalid Import-Rows: %q", trailers["import-rows"])
+	}
+	f.Rows = rows
+	return f, nil
+}
+
+// ReplayFooter holds the machine-readable fields of a replayed approval commit.
+type ReplayFooter struct {
+	Schema       string // "v1"
+	ReplayedFrom string // "<db>@<merge hash>"; <db> is "<target>:<db>" on another target
+	WorkItem     string // work item of the replayed approval
+	Applied      int    // rows inserted, updated or deleted
+	Skipped      int    // rows that could not be applied
+}
+
+// BuildReplayFooter constructs a replay commit message with trailers.
+func BuildReplayFooter(humanSubject string, f ReplayFooter) string {
+	var b strings.Builder
+	b.WriteString(humanSubject)
+	b.WriteString("\n\n")
+	b.WriteString("Dolt-Replay-Schema: ")
+	b.WriteString(f.Schema)
+	b.WriteString("\nReplayed-From: ")
+	b.WriteString(f.ReplayedFrom)
+	b.WriteString("\nReplayed-Work-Item: ")
+	b.WriteString(f.WorkItem)
+	b.WriteString("\nReplay-Applied: ")
+	b.WriteString(strconv.Itoa(f.Applied))
+	b.WriteString("\nReplay-Skipped: ")
+	b.WriteString(strconv.Itoa(f.Skipped))
+	return b.String()
+}
+
+// ParseReplayFooter extracts the replay footer from a commit message.
+// Returns (nil, nil) if the commit is not a replay.
+func ParseReplayFooter(commitMsg string) (*ReplayFooter, error) {
+	trailers := ParseTrailers(commitMsg)
+	schema := trailers["dolt-replay-schema"]
+	if schema == "" {
+		return nil, nil
+	}
+	if schema != "v1" {
+		return nil, fmt.Errorf("unsupported replay schema: %s", schema)
+	}
+
+	f := &ReplayFooter{Schema: schema, ReplayedFrom: trailers["replayed-from"], WorkItem: trailers["replayed-work-item"]}
+	at := strings.LastIndex(f.ReplayedFrom, "@")
+	if at < 1 || !doltHashRe.MatchString(f.ReplayedFrom[at+1:]) {
+		return nil, fmt.Errorf("replay footer invalid Replayed-From: %q", f.ReplayedFrom)
+	}
+	applied, err := strconv.Atoi(trailers["replay-applied"])
+	if err != nil {
+		return nil, fmt.Errorf("replay footer invalid Replay-Applied: %q", trailers["replay-applied"])
+	}
+	skipped, err := strconv.Atoi(trailers["replay-skipped"])
+	if err != nil {
+		return nil, fmt.Errorf("replay footer invalid Replay-Skipped: %q", trailers["replay-skipped"])
+	}
+	f.Applied, f.Skipped = applied, skipped
+	return f, nil
+}
+
 // ParseTrailers extracts key-value pairs from the last paragraph of a commit message.
 // Keys are normalized to lowercase. Git trailer format: "Key: Value".
 func ParseTrailers(commitMsg string) map[string]string {
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+)
+
+func (h *Handler) ImportAuditSnapshot(w http.ResponseWriter, r *http.Request) {
+	var req model.AuditImportRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "invalid request body")
+		return
+	}
+	if req.TargetID == "" || req.DBName == "" || req.Snapshot == "" {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "target_id, db_name, and snapshot are required")
+		return
+	}
+
+	resp, err := h.svc.ImportAuditSnapshot(r.Context(), req)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) ReconcileAudit(w http.ResponseWriter, r *http.Request) {
+	targetID := r.URL.Query().Get("target_id")
+	dbName := r.URL.Query().Get("db_name")
+	if targetID == "" || dbName == "" {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "target_id and db_name are required")
+		return
+	}
+
+	resp, err := h.svc.ReconcileAudit(r.Context(), targetID, dbName)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) ExportAuditReconcileZip(w http.ResponseWriter, r *http.Request) {
+	targetID := r.URL.Query().Get("target_id")
+	dbName := r.URL.Query().Get("db_name")
+	if targetID == "" || dbName == "" {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "target_id and db_name are required")
+		return
+	}
+
+	// Streamed like /diff/export-zip: per-file errors go to manifest.json.
+	started := false
+	err := h.svc.ExportAuditReconcileZip(r.Context(), targetID, dbName, func(zipName string) io.Writer {
+		w.Header().Set("Content-Type", "application/zip")
+		safeZipName := strings.Map(func(r rune) rune {
+			if r == '"' || r == '\r' || r == '\n' || r < 0x20 {
+				return -1
+			}
+			return r
+		}, zipName)
+		w.Header().Set("Content-Disposition", `attachment; filename="`+safeZipName+`"`)
+		w.WriteHeader(http.StatusOK)
+		started = true
+		return w
+	})
+	if err != nil {
+		if !started {
+			handleServiceError(w, err)
+			return
+		}
+		log.Printf("ERROR: audit reconcile export aborted mid-stream: db=%s error=%v", dbName, err)
+	}
+}
@@ -94,6 +94,94 @@ func (h *Handler) CrossCopyTable(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, result)
 }
 
+func (h *Handler) CrossCopyTablesPreview(w http.ResponseWriter, r *http.Request) {
+	var req model.CrossCopyTablesRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "invalid request body")
+		return
+	}
+
+	if req.TargetID == "" || req.SourceDB == "" || req.SourceBranch == "" || len(req.SourceTables) == 0 || req.DestDB == "" {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "target_id, source_db, source_branch, source_tables, and dest_db are required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+	result, err := h.svc.CrossCopyTablesPreview(ctx, req)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (h *Handler) CrossCopyTables(w http.ResponseWriter, r *http.Request) {
+	var req model.CrossCopyTablesRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "invalid request body")
+		return
+	}
+
+	if req.TargetID == "" || req.SourceDB == "" || req.SourceBranch == "" || len(req.SourceTables) == 0 || req.DestDB == "" {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "target_id, source_db, source_branch, source_tables, and dest_db are required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 120*time.Second)
+	defer cancel()
+	result, err := h.svc.CrossCopyTables(ctx, req)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (h *Handler) ReplayApprovalPreview(w http.ResponseWriter, r *http.Request) {
+	var req model.ReplayApprovalRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "invalid request body")
+		return
+	}
+
+	if req.TargetID == "" || req.SourceDB == "" || req.MergeHash == "" || req.DestDB == "" {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "target_id, source_db, merge_hash, and dest_db are required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+	result, err := h.svc.ReplayApprovalPreview(ctx, req)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (h *Handler) ReplayApproval(w http.ResponseWriter, r *http.Request) {
+	var req model.ReplayApprovalRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "invalid request body")
+		return
+	}
+
+	if req.TargetID == "" || req.SourceDB == "" || req.MergeHash == "" || req.DestDB == "" {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "target_id, source_db, merge_hash, and dest_db are required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 120*time.Second)
+	defer cancel()
+	result, err := h.svc.ReplayApproval(ctx, req)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
 func (h *Handler) CrossCopyAdminPrepareTable(w http.ResponseWriter, r *http.Request) {
 	var req model.CrossCopyAdminPrepareTableRequest
 	if err := decodeJSON(r, &req); err != nil {
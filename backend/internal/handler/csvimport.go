@@ -3,6 +3,8 @@ package handler
 import (
 	"context"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
@@ -29,6 +31,55 @@ func (h *Handler) CSVPreview(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, result)
 }
 
+func (h *Handler) CSVPreviewErrors(w http.ResponseWriter, r *http.Request) {
+	previewID := r.URL.Query().Get("preview_id")
+	if previewID == "" {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "preview_id is required")
+		return
+	}
+	page := 1
+	if p := r.URL.Query().Get("page"); p != "" {
+		v, err := strconv.Atoi(p)
+		if err != nil || v < 1 {
+			writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "page must be a positive integer")
+			return
+		}
+		page = v
+	}
+
+	result, err := h.svc.CSVPreviewErrors(previewID, page)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (h *Handler) ExportCSVPreviewErrors(w http.ResponseWriter, r *http.Request) {
+	previewID := r.URL.Query().Get("preview_id")
+	if previewID == "" {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "preview_id is required")
+		return
+	}
+
+	data, fileName, err := h.svc.ExportCSVPreviewErrors(previewID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	safeFileName := strings.Map(func(r rune) rune {
+		if r == '"' || r == '\r' || r == '\n' || r < 0x20 {
+			return -1
+		}
+		return r
+	}, fileName)
+	w.Header().Set("Content-Disposition", `attachment; filename="`+safeFileName+`"`)
+	w.WriteHeader(http.StatusOK)
+	w.Write(data) //nolint:errcheck
+}
+
 func (h *Handler) CSVApply(w http.ResponseWriter, r *http.Request) {
 	var req model.CSVApplyRequest
 	if err := decodeJSON(r, &req); err != nil {
@@ -51,3 +102,57 @@ func (h *Handler) CSVApply(w http.ResponseWriter, r *http.Request) {
 	}
 	writeJSON(w, http.StatusOK, result)
 }
+
+func (h *Handler) ListCSVProfiles(w http.ResponseWriter, r *http.Request) {
+	targetID := r.URL.Query().Get("target_id")
+	dbName := r.URL.Query().Get("db_name")
+	table := r.URL.Query().Get("table")
+	if targetID == "" || dbName == "" || table == "" {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "target_id, db_name, and table are required")
+		return
+	}
+
+	profiles, err := h.svc.ListCSVMappingProfiles(r.Context(), targetID, dbName, table)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, profiles)
+}
+
+func (h *Handler) SaveCSVProfile(w http.ResponseWriter, r *http.Request) {
+	var req model.SaveCSVMappingProfileRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "invalid request body")
+		return
+	}
+	if req.TargetID == "" || req.DBName == "" || req.Table == "" || req.Name == "" {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "target_id, db_name, table, and name are required")
+		return
+	}
+
+	profile, err := h.svc.SaveCSVMappingProfile(r.Context(), req)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, profile)
+}
+
+func (h *Handler) DeleteCSVProfile(w http.ResponseWriter, r *http.Request) {
+	var req model.DeleteCSVMappingProfileRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "invalid request body")
+		return
+	}
+	if req.TargetID == "" || req.DBName == "" || req.Table == "" || req.Name == "" {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "target_id, db_name, table, and name are required")
+		return
+	}
+
+	if err := h.svc.DeleteCSVMappingProfile(r.Context(), req); err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+)
+
+// CSVUploadPath is exempt from body_limit_mb; server.upload.limit_mb applies instead.
+const CSVUploadPath = "/api/v1/csv/upload"
+
+// recordingReader remembers the first read error so an oversized upload can be
+// reported as 413 even after the parser wrapped the error.
+type recordingReader struct {
+	r   io.Reader
+	err error
+}
+
+func (r *recordingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if err != nil && err != io.EOF && r.err == nil {
+		r.err = err
+	}
+	return n, err
+}
+
+// CSVUpload accepts multipart/form-data with a "file" part and optional
+// "encoding", "delimiter", "header_row" and "sheet" fields. The fields must come
+// before the file part, which is parsed while it streams in.
+func (h *Handler) CSVUpload(w http.ResponseWriter, r *http.Request) {
+	mr, err := r.MultipartReader()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "multipart/form-data body is required")
+		return
+	}
+
+	var opts model.CSVUploadOptions
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			writeUploadReadError(w, err)
+			return
+		}
+
+		if part.FormName() != "file" {
+			value, err := io.ReadAll(io.LimitReader(part, 1024))
+			if err != nil {
+				writeUploadReadError(w, err)
+				return
+			}
+			v := strings.TrimSpace(string(value))
+			switch part.FormName() {
+			case "encoding":
+				opts.Encoding = v
+			case "delimiter":
+				opts.Delimiter = string(value) // a tab must not be trimmed away
+			case "header_row":
+				if v != "" {
+					if opts.HeaderRow, err = strconv.Atoi(v); err != nil || opts.HeaderRow < 1 {
+						writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "header_row must be a positive integer")
+						return
+					}
+				}
+			case "sheet":
+				opts.Sheet = v
+			}
+			continue
+		}
+
+		opts.FileName = part.FileName()
+		if opts.FileName == "" {
+			writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "file part has no file name")
+			return
+		}
+		body := &recordingReader{r: part}
+		resp, err := h.svc.CSVUpload(body, opts)
+		if err != nil {
+			if body.err != nil {
+				writeUploadReadError(w, body.err)
+				return
+			}
+			handleServiceError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
+	writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "file is required")
+}
+
+func writeUploadReadError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeError(w, http.StatusRequestEntityTooLarge, model.CodeInvalidArgument,
+			fmt.Sprintf("ファイルが上限 (%d MB) を超えています", tooLarge.Limit/1024/1024))
+		return
+	}
+	writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "failed to read upload: "+err.Error())
+}
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"io"
+	"log"
 	"net/http"
 	"strconv"
 	"strings"
@@ -123,26 +125,33 @@ func (h *Handler) ExportDiffZip(w http.ResponseWriter, r *http.Request) {
 		mode = "three_dot"
 	}
 
-	data, zipName, warning, err := h.svc.ExportDiffZip(r.Context(), targetID, dbName, branchName, fromRef, toRef, mode)
+	format := r.URL.Query().Get("format")
+
+	// The archive is streamed: headers go out when the service opens the
+	// writer, after all up-front checks. A later error can only be logged;
+	// per-file query errors are reported in the manifest instead.
+	started := false
+	err := h.svc.ExportDiffZip(r.Context(), targetID, dbName, branchName, fromRef, toRef, mode, format, func(zipName string) io.Writer {
+		w.Header().Set("Content-Type", "application/zip")
+		// NEW-6: sanitize zipName to prevent Content-Disposition header injection.
+		safeZipName := strings.Map(func(r rune) rune {
+			if r == '"' || r == '\r' || r == '\n' || r < 0x20 {
+				return -1
+			}
+			return r
+		}, zipName)
+		w.Header().Set("Content-Disposition", `attachment; filename="`+safeZipName+`"`)
+		w.WriteHeader(http.StatusOK)
+		started = true
+		return w
+	})
 	if err != nil {
-		handleServiceError(w, err)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/zip")
-	// NEW-6: sanitize zipName to prevent Content-Disposition header injection.
-	safeZipName := strings.Map(func(r rune) rune {
-		if r == '"' || r == '\r' || r == '\n' || r < 0x20 {
-			return -1
+		if !started {
+			handleServiceError(w, err)
+			return
 		}
-		return r
-	}, zipName)
-	w.Header().Set("Content-Disposition", `attachment; filename="`+safeZipName+`"`)
-	if warning != "" {
-		w.Header().Set("X-Diff-Warning", warning)
-	}
-	w.WriteHeader(http.StatusOK)
-	w.Write(data) //nolint:errcheck
+		log.Printf("ERROR: diff export aborted mid-stream: branch=%s from_ref=%s to_ref=%s error=%v", branchName, fromRef, toRef, err)
+	}
 }
 
 func (h *Handler) HistoryRow(w http.ResponseWriter, r *http.Request) {
@@ -26,14 +26,19 @@ func Register(r chi.Router, svc *service.Service, cfg *config.Config) {
 		r.Get("/targets", h.ListTargets)
 		r.Get("/databases", h.ListDatabases)
 		r.Get("/branches", h.ListBranches)
+		r.Get("/branches/status", h.BranchStatus)
+		r.Get("/branches/stale", h.ListStaleBranches)
 		r.Get("/branches/ready", h.GetBranchReady)
 		r.Post("/branches/create", h.CreateBranch)
 		r.Post("/branches/delete", h.DeleteBranch)
+		r.Post("/branches/admin/cleanup-stale", h.CleanupStaleBranches)
 		r.Get("/head", h.GetHead)
 
 		// Tables
 		r.Get("/tables", h.ListTables)
 		r.Get("/table/schema", h.GetTableSchema)
+		r.Get("/schema/compare", h.CompareSchemas)
+		r.Get("/schema/compare/export", h.ExportSchemaCompare)
 		r.Get("/table/rows", h.GetTableRows)
 		r.Get("/table/row", h.GetTableRow)
 
@@ -42,8 +47,12 @@ func Register(r chi.Router, svc *service.Service, cfg *config.Config) {
 
 		// Write operations
 		r.Post("/commit", h.Commit)
+		r.Post("/commit/validate", h.CommitValidate)
 		r.Post("/sync", h.SyncBranch)
 		r.Post("/merge/abort", h.MergeAbort) // L3-2: escape hatch for stuck merges
+		r.Post("/branch/undo-commit", h.UndoCommit)
+		r.Post("/branch/squash", h.SquashBranch)
+		r.Post("/branch/cherry-pick", h.CherryPick)
 
 		// Diff & History
 		r.Get("/diff/table", h.DiffTable)
@@ -53,13 +62,29 @@ func Register(r chi.Router, svc *service.Service, cfg *config.Config) {
 		r.Get("/history/commits", h.HistoryCommits)
 		r.Get("/history/row", h.HistoryRow)
 
+		// Releases
+		r.Get("/releases", h.ListReleases)
+		r.Post("/releases", h.CreateRelease)
+
 		// Request/Approval
 		r.Post("/request/submit", h.SubmitRequest)
 		r.Get("/requests", h.ListRequests)
+		r.Get("/overlaps", h.ListOverlaps)
 		r.Get("/request", h.GetRequest)
+		r.Get("/request/check", h.CheckRequests)
 		r.Post("/request/approve", h.ApproveRequest)
+		r.Get("/merge-queue", h.GetMergeQueue)
+		r.Post("/merge-queue/enqueue", h.EnqueueMerge)
+		r.Get("/approvals/scheduled", h.ListScheduledApprovals)
+		r.Post("/approvals/scheduled/cancel", h.CancelScheduledApproval)
 		r.Post("/request/reject", h.RejectRequest)
 
+		// Work item registry
+		r.Get("/work-items", h.ListWorkItems)
+		r.Get("/work-item", h.GetWorkItem)
+		r.Post("/work-items/save", h.SaveWorkItem)
+		r.Post("/work-items/delete", h.DeleteWorkItem)
+
 		// Cell Memos
 		r.Get("/memo", h.GetMemo)
 		r.Get("/memo/map", h.GetMemoMap)
@@ -68,22 +93,40 @@ func Register(r chi.Router, svc *service.Service, cfg *config.Config) {
 		r.Post("/cross-copy/preview", h.CrossCopyPreview)
 		r.Post("/cross-copy/rows", h.CrossCopyRows)
 		r.Post("/cross-copy/table", h.CrossCopyTable)
+		r.Post("/cross-copy/tables/preview", h.CrossCopyTablesPreview)
+		r.Post("/cross-copy/tables", h.CrossCopyTables)
+		r.Post("/cross-copy/replay-approval/preview", h.ReplayApprovalPreview)
+		r.Post("/cross-copy/replay-approval", h.ReplayApproval)
 		r.Post("/cross-copy/admin/prepare-rows", h.CrossCopyAdminPrepareRows)
 		r.Post("/cross-copy/admin/prepare-table", h.CrossCopyAdminPrepareTable)
 		r.Post("/cross-copy/admin/cleanup-import", h.CrossCopyAdminCleanupImport)
 
 		// CSV Import
+		r.Post("/csv/upload", h.CSVUpload)
 		r.Post("/csv/preview", h.CSVPreview)
+		r.Get("/csv/preview/errors", h.CSVPreviewErrors)
+		r.Get("/csv/preview/errors/export", h.ExportCSVPreviewErrors)
 		r.Post("/csv/apply", h.CSVApply)
+		r.Get("/csv/profiles", h.ListCSVProfiles)
+		r.Post("/csv/profiles/save", h.SaveCSVProfile)
+		r.Post("/csv/profiles/delete", h.DeleteCSVProfile)
 
 		// Search
 		r.Get("/search", h.Search)
+
+		// Replication
+		r.Get("/replication/status", h.GetReplicationStatus)
+		r.Post("/replication/admin/run", h.RunReplication)
+		r.Post("/replication/admin/restore", h.RestoreReplica)
+
+		// Audit
+		r.Post("/audit/admin/import", h.ImportAuditSnapshot)
+		r.Get("/audit/reconcile", h.ReconcileAudit)
+		r.Get("/audit/reconcile/export-zip", h.ExportAuditReconcileZip)
 	})
 
 	// Health check
-	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
-		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
-	})
+	r.Get("/health", h.Health)
 }
 
 func writeJSON(w http.ResponseWriter, status int, v interface{}) {
@@ -100,9 +143,14 @@ func writeErrorWithDetails(w http.ResponseWriter, status int, code, message stri
 	writeJSON(w, status, model.NewError(code, message, details))
 }
 
+// decodeJSON decodes the request body. Numbers inside interface{} fields (op values,
+// PKs, CSV rows) are kept as json.Number so DECIMAL and BIGINT values are not
+// rounded through float64 on their way to SQL.
 func decodeJSON(r *http.Request, v interface{}) error {
 	defer r.Body.Close()
-	return json.NewDecoder(r.Body).Decode(v)
+	dec := json.NewDecoder(r.Body)
+	dec.UseNumber()
+	return dec.Decode(v)
 }
 
 // parseQueryContext extracts target_id, db_name, and branch_name from query params.
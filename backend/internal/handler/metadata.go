@@ -46,6 +46,78 @@ func (h *Handler) ListBranches(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, branches)
 }
 
+// BranchStatus returns ahead/behind, lock, merge and working-set state with the
+// actions the backend would accept for each work branch.
+func (h *Handler) BranchStatus(w http.ResponseWriter, r *http.Request) {
+	targetID := r.URL.Query().Get("target_id")
+	dbName := r.URL.Query().Get("db_name")
+	branchName := r.URL.Query().Get("branch_name")
+	if targetID == "" || dbName == "" {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "target_id and db_name are required")
+		return
+	}
+
+	statuses, err := h.svc.ListBranchStatus(r.Context(), targetID, dbName, branchName)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, statuses)
+}
+
+func (h *Handler) ListOverlaps(w http.ResponseWriter, r *http.Request) {
+	targetID := r.URL.Query().Get("target_id")
+	dbName := r.URL.Query().Get("db_name")
+	branchName := r.URL.Query().Get("branch_name")
+	if targetID == "" || dbName == "" || branchName == "" {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "target_id, db_name, and branch_name are required")
+		return
+	}
+
+	resp, err := h.svc.ListOverlaps(r.Context(), targetID, dbName, branchName)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) ListStaleBranches(w http.ResponseWriter, r *http.Request) {
+	targetID := r.URL.Query().Get("target_id")
+	dbName := r.URL.Query().Get("db_name")
+	if targetID == "" || dbName == "" {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "target_id and db_name are required")
+		return
+	}
+
+	resp, err := h.svc.ListStaleBranches(r.Context(), targetID, dbName)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) CleanupStaleBranches(w http.ResponseWriter, r *http.Request) {
+	var req model.CleanupStaleBranchesRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "invalid request body")
+		return
+	}
+
+	if req.TargetID == "" || req.DBName == "" {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "target_id and db_name are required")
+		return
+	}
+
+	resp, err := h.svc.CleanupStaleBranches(r.Context(), req)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
 func (h *Handler) CreateBranch(w http.ResponseWriter, r *http.Request) {
 	var req model.CreateBranchRequest
 	if err := decodeJSON(r, &req); err != nil {
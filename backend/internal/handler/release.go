@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+)
+
+func (h *Handler) ListReleases(w http.ResponseWriter, r *http.Request) {
+	targetID := r.URL.Query().Get("target_id")
+	dbName := r.URL.Query().Get("db_name")
+	if targetID == "" || dbName == "" {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "target_id and db_name are required")
+		return
+	}
+
+	releases, err := h.svc.ListReleases(r.Context(), targetID, dbName)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, releases)
+}
+
+func (h *Handler) CreateRelease(w http.ResponseWriter, r *http.Request) {
+	var req model.CreateReleaseRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "invalid request body")
+		return
+	}
+	if req.TargetID == "" || req.DBName == "" || req.Name == "" {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "target_id, db_name, and name are required")
+		return
+	}
+
+	release, err := h.svc.CreateRelease(r.Context(), req)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, release)
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+)
+
+func (h *Handler) GetReplicationStatus(w http.ResponseWriter, r *http.Request) {
+	targetID := r.URL.Query().Get("target_id")
+	dbName := r.URL.Query().Get("db_name")
+	if targetID == "" || dbName == "" {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "target_id and db_name are required")
+		return
+	}
+
+	resp, err := h.svc.GetReplicationStatus(targetID, dbName)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) RunReplication(w http.ResponseWriter, r *http.Request) {
+	var req model.RunReplicationRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "invalid request body")
+		return
+	}
+	if req.TargetID == "" || req.DBName == "" {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "target_id and db_name are required")
+		return
+	}
+
+	resp, err := h.svc.RunReplication(r.Context(), req)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) RestoreReplica(w http.ResponseWriter, r *http.Request) {
+	var req model.RestoreReplicaRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "invalid request body")
+		return
+	}
+	if req.TargetID == "" || req.DBName == "" || req.Remote == "" || req.NewDBName == "" {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "target_id, db_name, remote, and new_db_name are required")
+		return
+	}
+
+	resp, err := h.svc.RestoreReplica(r.Context(), req)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) Health(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, model.HealthResponse{
+		Status:      "ok",
+		Replication: h.svc.ReplicationHealth(),
+	})
+}
@@ -70,6 +70,99 @@ func (h *Handler) GetRequest(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, result)
 }
 
+func (h *Handler) CheckRequests(w http.ResponseWriter, r *http.Request) {
+	targetID := r.URL.Query().Get("target_id")
+	dbName := r.URL.Query().Get("db_name")
+	requestID := r.URL.Query().Get("request_id")
+	if targetID == "" || dbName == "" {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument,
+			"target_id and db_name are required")
+		return
+	}
+
+	result, err := h.svc.CheckRequests(r.Context(), targetID, dbName, requestID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (h *Handler) GetMergeQueue(w http.ResponseWriter, r *http.Request) {
+	targetID := r.URL.Query().Get("target_id")
+	dbName := r.URL.Query().Get("db_name")
+	if targetID == "" || dbName == "" {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument,
+			"target_id and db_name are required")
+		return
+	}
+
+	result, err := h.svc.GetMergeQueue(r.Context(), targetID, dbName)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (h *Handler) EnqueueMerge(w http.ResponseWriter, r *http.Request) {
+	var req model.EnqueueMergeRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "invalid request body")
+		return
+	}
+
+	if req.TargetID == "" || req.DBName == "" || req.RequestID == "" {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument,
+			"target_id, db_name, and request_id are required")
+		return
+	}
+
+	entry, err := h.svc.EnqueueMerge(r.Context(), req)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, entry)
+}
+
+func (h *Handler) ListScheduledApprovals(w http.ResponseWriter, r *http.Request) {
+	targetID := r.URL.Query().Get("target_id")
+	dbName := r.URL.Query().Get("db_name")
+	if targetID == "" || dbName == "" {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument,
+			"target_id and db_name are required")
+		return
+	}
+
+	result, err := h.svc.ListScheduledApprovals(r.Context(), targetID, dbName)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (h *Handler) CancelScheduledApproval(w http.ResponseWriter, r *http.Request) {
+	var req model.CancelScheduledApprovalRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "invalid request body")
+		return
+	}
+
+	if req.TargetID == "" || req.DBName == "" || req.RequestID == "" {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument,
+			"target_id, db_name, and request_id are required")
+		return
+	}
+
+	if err := h.svc.CancelScheduledApproval(r.Context(), req); err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
 func (h *Handler) ApproveRequest(w http.ResponseWriter, r *http.Request) {
 	var req model.ApproveRequest
 	if err := decodeJSON(r, &req); err != nil {
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+)
+
+// parseSchemaCompareQuery reads target_id, from_db, from_ref, to_db and
+// to_ref. to_db defaults to from_db.
+func parseSchemaCompareQuery(w http.ResponseWriter, r *http.Request) (targetID, fromDB, fromRef, toDB, toRef string, ok bool) {
+	q := r.URL.Query()
+	targetID, fromDB, fromRef, toDB, toRef = q.Get("target_id"), q.Get("from_db"), q.Get("from_ref"), q.Get("to_db"), q.Get("to_ref")
+	if toDB == "" {
+		toDB = fromDB
+	}
+	if targetID == "" || fromDB == "" || fromRef == "" || toRef == "" {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "target_id, from_db, from_ref, and to_ref are required")
+		return "", "", "", "", "", false
+	}
+	return targetID, fromDB, fromRef, toDB, toRef, true
+}
+
+func (h *Handler) CompareSchemas(w http.ResponseWriter, r *http.Request) {
+	targetID, fromDB, fromRef, toDB, toRef, ok := parseSchemaCompareQuery(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+	resp, err := h.svc.CompareSchemas(ctx, targetID, fromDB, fromRef, toDB, toRef)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) ExportSchemaCompare(w http.ResponseWriter, r *http.Request) {
+	targetID, fromDB, fromRef, toDB, toRef, ok := parseSchemaCompareQuery(w, r)
+	if !ok {
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "markdown"
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+	data, fileName, err := h.svc.ExportSchemaCompare(ctx, targetID, fromDB, fromRef, toDB, toRef, format)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	} else {
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	}
+	safeFileName := strings.Map(func(r rune) rune {
+		if r == '"' || r == '\r' || r == '\n' || r < 0x20 {
+			return -1
+		}
+		return r
+	}, fileName)
+	w.Header().Set("Content-Disposition", `attachment; filename="`+safeFileName+`"`)
+	w.WriteHeader(http.StatusOK)
+	w.Write(data) //nolint:errcheck
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+)
+
+func (h *Handler) ListWorkItems(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	targetID := q.Get("target_id")
+	dbName := q.Get("db_name")
+	if targetID == "" || dbName == "" {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "target_id and db_name are required")
+		return
+	}
+
+	items, err := h.svc.ListWorkItemMeta(r.Context(), targetID, dbName, model.WorkItemMetaFilter{
+		Owner:     q.Get("owner"),
+		TicketID:  q.Get("ticket_id"),
+		Label:     q.Get("label"),
+		Keyword:   q.Get("keyword"),
+		DueBefore: q.Get("due_before"),
+		Sort:      q.Get("sort"),
+		Order:     q.Get("order"),
+	})
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, items)
+}
+
+func (h *Handler) GetWorkItem(w http.ResponseWriter, r *http.Request) {
+	targetID := r.URL.Query().Get("target_id")
+	dbName := r.URL.Query().Get("db_name")
+	workItem := r.URL.Query().Get("work_item")
+	if targetID == "" || dbName == "" || workItem == "" {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "target_id, db_name, and work_item are required")
+		return
+	}
+
+	item, err := h.svc.GetWorkItemMeta(r.Context(), targetID, dbName, workItem)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, item)
+}
+
+func (h *Handler) SaveWorkItem(w http.ResponseWriter, r *http.Request) {
+	var req model.SaveWorkItemMetaRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "invalid request body")
+		return
+	}
+	if req.TargetID == "" || req.DBName == "" || req.WorkItem == "" {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "target_id, db_name, and work_item are required")
+		return
+	}
+
+	item, err := h.svc.SaveWorkItemMeta(r.Context(), req)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, item)
+}
+
+func (h *Handler) DeleteWorkItem(w http.ResponseWriter, r *http.Request) {
+	var req model.DeleteWorkItemMetaRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "invalid request body")
+		return
+	}
+	if req.TargetID == "" || req.DBName == "" || req.WorkItem == "" {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "target_id, db_name, and work_item are required")
+		return
+	}
+
+	if err := h.svc.DeleteWorkItemMeta(r.Context(), req); err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
@@ -34,6 +34,33 @@ func (h *Handler) Commit(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, result)
 }
 
+// CommitValidate dry-runs a commit and reports every failing op without committing.
+func (h *Handler) CommitValidate(w http.ResponseWriter, r *http.Request) {
+	var req model.CommitRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "invalid request body")
+		return
+	}
+
+	if req.TargetID == "" || req.DBName == "" || req.BranchName == "" {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "target_id, db_name, and branch_name are required")
+		return
+	}
+
+	if mainGuard(w, req.BranchName) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+	result, err := h.svc.ValidateCommit(ctx, req)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
 // MergeAbort L3-2: Escape hatch to abort a stuck merge state.
 func (h *Handler) MergeAbort(w http.ResponseWriter, r *http.Request) {
 	var req struct {
@@ -60,3 +87,78 @@ func (h *Handler) MergeAbort(w http.ResponseWriter, r *http.Request) {
 	}
 	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
 }
+
+// UndoCommit drops the HEAD commit of a work branch.
+func (h *Handler) UndoCommit(w http.ResponseWriter, r *http.Request) {
+	var req model.UndoCommitRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "invalid request body")
+		return
+	}
+	if req.TargetID == "" || req.DBName == "" || req.BranchName == "" {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "target_id, db_name, and branch_name are required")
+		return
+	}
+	if mainGuard(w, req.BranchName) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+	result, err := h.svc.UndoCommit(ctx, req)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// SquashBranch collapses work-branch commits since the merge base into one.
+func (h *Handler) SquashBranch(w http.ResponseWriter, r *http.Request) {
+	var req model.SquashRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "invalid request body")
+		return
+	}
+	if req.TargetID == "" || req.DBName == "" || req.BranchName == "" {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "target_id, db_name, and branch_name are required")
+		return
+	}
+	if mainGuard(w, req.BranchName) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+	result, err := h.svc.SquashBranch(ctx, req)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// CherryPick applies commits from another work branch.
+func (h *Handler) CherryPick(w http.ResponseWriter, r *http.Request) {
+	var req model.CherryPickRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "invalid request body")
+		return
+	}
+	if req.TargetID == "" || req.DBName == "" || req.BranchName == "" || req.SourceBranch == "" {
+		writeError(w, http.StatusBadRequest, model.CodeInvalidArgument, "target_id, db_name, branch_name, and source_branch are required")
+		return
+	}
+	if mainGuard(w, req.BranchName) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+	result, err := h.svc.CherryPickCommits(ctx, req)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
@@ -49,9 +49,123 @@ type DatabaseResponse struct {
 }
 
 // BranchResponse represents a branch.
+// BaseRef/BaseHash are set when the branch was created from a historical ref.
 type BranchResponse struct {
-	Name string `json:"name"`
-	Hash string `json:"hash"`
+	Name         string        `json:"name"`
+	Hash         string        `json:"hash"`
+	BaseRef      string        `json:"base_ref,omitempty"`
+	BaseHash     string        `json:"base_hash,omitempty"`
+	WorkItemMeta *WorkItemMeta `json:"work_item_meta,omitempty"`
+}
+
+// WorkItemMeta is the registry entry attached to a work item (wi/<WorkItem>).
+type WorkItemMeta struct {
+	WorkItem    string   `json:"work_item"`
+	Owner       string   `json:"owner"`
+	TicketID    string   `json:"ticket_id"`
+	Description string   `json:"description"`
+	Labels      []string `json:"labels"`
+	DueDate     string   `json:"due_date,omitempty"` // YYYY-MM-DD
+	UpdatedAt   string   `json:"updated_at,omitempty"`
+}
+
+// SaveWorkItemMetaRequest creates or replaces a work item registry entry.
+type SaveWorkItemMetaRequest struct {
+	TargetID    string   `json:"target_id"`
+	DBName      string   `json:"db_name"`
+	WorkItem    string   `json:"work_item"`
+	Owner       string   `json:"owner"`
+	TicketID    string   `json:"ticket_id"`
+	Description string   `json:"description"`
+	Labels      []string `json:"labels"`
+	DueDate     string   `json:"due_date"`
+}
+
+// DeleteWorkItemMetaRequest removes a work item registry entry.
+type DeleteWorkItemMetaRequest struct {
+	TargetID string `json:"target_id"`
+	DBName   string `json:"db_name"`
+	WorkItem string `json:"work_item"`
+}
+
+// WorkItemMetaFilter narrows and orders ListWorkItemMeta results.
+type WorkItemMetaFilter struct {
+	Owner     string
+	TicketID  string
+	Label     string
+	Keyword   string // substring of work_item or description
+	DueBefore string // YYYY-MM-DD inclusive
+	Sort      string // work_item (default) | owner | ticket_id | due_date | updated_at
+	Order     string // asc (default) | desc
+}
+
+// BranchStatus summarizes a work branch for state-driven UI actions.
+type BranchStatus struct {
+	Name             string               `json:"name"`
+	Hash             string               `json:"hash"`
+	Ahead            int                  `json:"ahead"`
+	Behind           int                  `json:"behind"`
+	Locked           bool                 `json:"locked"`
+	RequestID        string               `json:"request_id,omitempty"`
+	Merging          bool                 `json:"merging"`
+	WorkingTables    []WorkingTableStatus `json:"working_tables"`
+	LastCommitAuthor string               `json:"last_commit_author"`
+	LastCommitAt     string               `json:"last_commit_at"`
+	AllowedActions   []string             `json:"allowed_actions"`
+}
+
+// WorkingTableStatus is one uncommitted table from dolt_status.
+type WorkingTableStatus struct {
+	Table  string `json:"table"`
+	Staged bool   `json:"staged"`
+	Status string `json:"status"`
+}
+
+// Branch actions reported in BranchStatus.AllowedActions.
+const (
+	BranchActionCommit     = "commit"
+	BranchActionSync       = "sync"
+	BranchActionSubmit     = "submit"
+	BranchActionDelete     = "delete"
+	BranchActionAbortMerge = "abort_merge"
+)
+
+// StaleBranch is a work branch matched by the database's stale branch policy.
+type StaleBranch struct {
+	Name         string `json:"name"`
+	Hash         string `json:"hash"`
+	LastCommitAt string `json:"last_commit_at"`
+	IdleDays     int    `json:"idle_days"`
+}
+
+// StaleBranchesResponse lists stale branches under the configured policy.
+type StaleBranchesResponse struct {
+	IdleDays int           `json:"idle_days"`
+	Action   string        `json:"action"`
+	Branches []StaleBranch `json:"branches"`
+}
+
+// CleanupStaleBranchesRequest applies the stale branch policy now.
+// Action overrides the configured action ("delete" or "archive").
+type CleanupStaleBranchesRequest struct {
+	TargetID string `json:"target_id"`
+	DBName   string `json:"db_name"`
+	Action   string `json:"action,omitempty"`
+}
+
+// StaleBranchCleanupResult is the per-branch outcome of a cleanup run.
+type StaleBranchCleanupResult struct {
+	Name       string `json:"name"`
+	Deleted    bool   `json:"deleted"`
+	ArchiveTag string `json:"archive_tag,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// CleanupStaleBranchesResponse is the result of a cleanup run.
+type CleanupStaleBranchesResponse struct {
+	Action  string                     `json:"action"`
+	Results []StaleBranchCleanupResult `json:"results"`
+	OperationResultFields
 }
 
 // HeadResponse represents the current HEAD hash.
@@ -93,10 +207,12 @@ type ReadResultFields struct {
 }
 
 // CreateBranchRequest represents a request to create a work branch.
+// FromRef optionally branches from a historical point instead of main.
 type CreateBranchRequest struct {
 	TargetID   string `json:"target_id"`
 	DBName     string `json:"db_name"`
 	BranchName string `json:"branch_name"`
+	FromRef    string `json:"from_ref,omitempty"`
 }
 
 // DeleteBranchRequest represents a request to delete a work branch.
@@ -125,6 +241,88 @@ type SchemaResponse struct {
 	Columns []ColumnSchema `json:"columns"`
 }
 
+// Schema comparison statuses of a table, column, index or foreign key.
+const (
+	SchemaDiffOnlyInFrom = "only_in_from"
+	SchemaDiffOnlyInTo   = "only_in_to"
+	SchemaDiffChanged    = "changed"
+)
+
+// SchemaRef names one side of a schema comparison.
+type SchemaRef struct {
+	DB  string `json:"db"`
+	Ref string `json:"ref"`
+}
+
+// SchemaColumnDiff is a column that differs between the two sides. Changes
+// lists the differing attributes: type, nullable, primary_key.
+type SchemaColumnDiff struct {
+	Column  string        `json:"column"`
+	Status  string        `json:"status"`
+	From    *ColumnSchema `json:"from,omitempty"`
+	To      *ColumnSchema `json:"to,omitempty"`
+	Changes []string      `json:"changes,omitempty"`
+}
+
+// SchemaIndex is a secondary index.
+type SchemaIndex struct {
+	Name    string   `json:"name"`
+	Unique  bool     `json:"unique"`
+	Columns []string `json:"columns"`
+}
+
+// SchemaIndexDiff is an index, by name, that differs between the two sides.
+type SchemaIndexDiff struct {
+	Name   string       `json:"name"`
+	Status string       `json:"status"`
+	From   *SchemaIndex `json:"from,omitempty"`
+	To     *SchemaIndex `json:"to,omitempty"`
+}
+
+// SchemaForeignKey is a foreign key constraint.
+type SchemaForeignKey struct {
+	Name              string   `json:"name"`
+	Columns           []string `json:"columns"`
+	ReferencedTable   string   `json:"referenced_table"`
+	ReferencedColumns []string `json:"referenced_columns"`
+}
+
+// SchemaForeignKeyDiff is a foreign key present on one side only. Foreign
+// keys are matched by columns and referenced columns, not by name.
+type SchemaForeignKeyDiff struct {
+	Status     string           `json:"status"`
+	ForeignKey SchemaForeignKey `json:"foreign_key"`
+}
+
+// SchemaTableDiff is a table that differs between the two sides. For tables
+// on both sides, ExpandColumns lists the "to" columns a cross-copy from "from"
+// would have to widen first.
+type SchemaTableDiff struct {
+	Table         string                 `json:"table"`
+	Status        string                 `json:"status"`
+	Columns       []SchemaColumnDiff     `json:"columns"`
+	Indexes       []SchemaIndexDiff      `json:"indexes"`
+	ForeignKeys   []SchemaForeignKeyDiff `json:"foreign_keys"`
+	ExpandColumns []ExpandColumn         `json:"expand_columns"`
+}
+
+// SchemaCompareSummary counts the compared tables.
+type SchemaCompareSummary struct {
+	Identical  int `json:"identical"`
+	Changed    int `json:"changed"`
+	OnlyInFrom int `json:"only_in_from"`
+	OnlyInTo   int `json:"only_in_to"`
+}
+
+// SchemaCompareResponse lists the tables whose schema differs between two
+// (db, ref) pairs. Identical tables are only counted.
+type SchemaCompareResponse struct {
+	From    SchemaRef            `json:"from"`
+	To      SchemaRef            `json:"to"`
+	Tables  []SchemaTableDiff    `json:"tables"`
+	Summary SchemaCompareSummary `json:"summary"`
+}
+
 // RowsResponse represents paginated rows.
 type RowsResponse struct {
 	Rows       []map[string]interface{} `json:"rows"`
@@ -156,6 +354,14 @@ type CommitResponse struct {
 	Hash string `json:"hash"`
 }
 
+// CommitValidateResponse represents the result of a dry-run commit.
+// Errors use the same shape as PreviewResponse.Errors; row_index is the ops index
+// (-1 when a constraint violation cannot be traced back to a single op).
+type CommitValidateResponse struct {
+	Valid  bool           `json:"valid"`
+	Errors []PreviewError `json:"errors"`
+}
+
 // SyncRequest represents a sync (merge main → work) operation.
 type SyncRequest struct {
 	TargetID     string `json:"target_id"`
@@ -171,9 +377,46 @@ type OverwrittenTable struct {
 }
 
 // SyncResponse represents the result of a sync.
+// BehindMain is the number of main commits the branch lacked before the merge.
 type SyncResponse struct {
 	Hash              string             `json:"hash"`
 	OverwrittenTables []OverwrittenTable `json:"overwritten_tables,omitempty"`
+	BehindMain        int                `json:"behind_main"`
+	BaseRef           string             `json:"base_ref,omitempty"`
+}
+
+// UndoCommitRequest represents a request to drop the HEAD commit of a work branch.
+type UndoCommitRequest struct {
+	TargetID     string `json:"target_id"`
+	DBName       string `json:"db_name"`
+	BranchName   string `json:"branch_name"`
+	ExpectedHead string `json:"expected_head"`
+}
+
+// SquashRequest represents a request to collapse work-branch commits since the merge base.
+type SquashRequest struct {
+	TargetID      string `json:"target_id"`
+	DBName        string `json:"db_name"`
+	BranchName    string `json:"branch_name"`
+	ExpectedHead  string `json:"expected_head"`
+	CommitMessage string `json:"commit_message"`
+}
+
+// CherryPickRequest represents a request to apply commits from another work branch.
+type CherryPickRequest struct {
+	TargetID     string   `json:"target_id"`
+	DBName       string   `json:"db_name"`
+	BranchName   string   `json:"branch_name"`
+	ExpectedHead string   `json:"expected_head"`
+	SourceBranch string   `json:"source_branch"`
+	CommitHashes []string `json:"commit_hashes"`
+}
+
+// BranchRewriteResponse represents the result of undo, squash, or cherry-pick.
+type BranchRewriteResponse struct {
+	Hash         string `json:"hash"`
+	PreviousHead string `json:"previous_head"`
+	OperationResultFields
 }
 
 // DiffRow represents a single diff row.
@@ -203,6 +446,33 @@ type HistoryCommit struct {
 	Message     string `json:"message"`
 	Timestamp   string `json:"timestamp"`
 	MergeBranch string `json:"merge_branch,omitempty"` // 2a: work branch merged into main (merges_only filter)
+	TicketID    string `json:"ticket_id,omitempty"`    // Ticket-Id trailer recorded at approval time
+
+	WorkItemMeta *WorkItemMeta `json:"work_item_meta,omitempty"`
+}
+
+// Release is a named snapshot of main, stored as the annotated tag release/<Name>.
+// Approvals lists the approval merges reachable from Hash but not from
+// PreviousRelease (the release created before it), newest first.
+type Release struct {
+	Name            string          `json:"name"`
+	Tag             string          `json:"tag"`
+	Hash            string          `json:"hash"`
+	Notes           string          `json:"notes"`
+	CreatedBy       string          `json:"created_by"`
+	CreatedAt       string          `json:"created_at"`
+	PreviousRelease string          `json:"previous_release,omitempty"`
+	Approvals       []HistoryCommit `json:"approvals"`
+}
+
+// CreateReleaseRequest tags a main commit as release/<Name>.
+// An empty CommitHash releases the current main HEAD.
+type CreateReleaseRequest struct {
+	TargetID   string `json:"target_id"`
+	DBName     string `json:"db_name"`
+	Name       string `json:"name"`
+	CommitHash string `json:"commit_hash,omitempty"`
+	Notes      string `json:"notes"`
 }
 
 // SubmitRequestRequest represents a request submission for approval.
@@ -220,9 +490,37 @@ type SubmitRequestResponse struct {
 	SubmittedMainHash string             `json:"submitted_main_hash"`
 	SubmittedWorkHash string             `json:"submitted_work_hash"`
 	OverwrittenTables []OverwrittenTable `json:"overwritten_tables,omitempty"`
+	BehindMain        int                `json:"behind_main"`
+	BaseRef           string             `json:"base_ref,omitempty"`
+	Overlaps          []BranchOverlap    `json:"overlaps,omitempty"`
 	OperationResultFields
 }
 
+// OverlapRow is a row changed by two work branches relative to main.
+// Cells lists the columns both branches changed; it is empty when the
+// branches touched different columns of the same row.
+type OverlapRow struct {
+	Table string                 `json:"table"`
+	PK    map[string]interface{} `json:"pk"`
+	Cells []string               `json:"cells"`
+}
+
+// BranchOverlap groups the overlapping rows shared with one other work branch.
+// RequestID is set when that branch has a pending approval request.
+type BranchOverlap struct {
+	Branch    string       `json:"branch"`
+	RequestID string       `json:"request_id,omitempty"`
+	Rows      []OverlapRow `json:"rows"`
+}
+
+// OverlapsResponse lists rows of BranchName that other work branches also changed.
+// Truncated is set when a table diff exceeded the scan limit.
+type OverlapsResponse struct {
+	BranchName string          `json:"branch_name"`
+	Overlaps   []BranchOverlap `json:"overlaps"`
+	Truncated  bool            `json:"truncated"`
+}
+
 // RequestSummary represents a pending approval request.
 type RequestSummary struct {
 	RequestID         string `json:"request_id"`
@@ -231,14 +529,64 @@ type RequestSummary struct {
 	SubmittedWorkHash string `json:"submitted_work_hash"`
 	SummaryJa         string `json:"summary_ja"`
 	SubmittedAt       string `json:"submitted_at,omitempty"`
+
+	WorkItemMeta *WorkItemMeta      `json:"work_item_meta,omitempty"`
+	Check        *RequestMergeCheck `json:"check,omitempty"`
+}
+
+// RequestMergeCheck tells whether a pending request still merges cleanly into
+// current main. Tables counts changes since the merge base with main.
+// Error is set when the check itself failed; the other fields are then unset.
+type RequestMergeCheck struct {
+	Mergeable            bool               `json:"mergeable"`
+	MainMoved            bool               `json:"main_moved"`
+	CurrentMainHash      string             `json:"current_main_hash"`
+	ConflictTables       []string           `json:"conflict_tables,omitempty"`
+	SchemaConflictTables []string           `json:"schema_conflict_tables,omitempty"`
+	Tables               []DiffSummaryEntry `json:"tables"`
+	Error                string             `json:"error,omitempty"`
+}
+
+// RequestCheckResponse lists pending requests with merge checks, ready-to-merge first.
+type RequestCheckResponse struct {
+	MainHash string           `json:"main_hash"`
+	Requests []RequestSummary `json:"requests"`
 }
 
 // ApproveRequest represents an approval action.
+// EffectiveAt (RFC3339) in the future schedules the merge instead of running it now.
 type ApproveRequest struct {
 	TargetID       string `json:"target_id"`
 	DBName         string `json:"db_name"`
 	RequestID      string `json:"request_id"`
 	MergeMessageJa string `json:"merge_message_ja"`
+	EffectiveAt    string `json:"effective_at,omitempty"`
+}
+
+// Scheduled approval states.
+const (
+	ScheduledApprovalPending = "scheduled"
+	ScheduledApprovalFailed  = "failed"
+)
+
+// ScheduledApproval is an approval waiting for its effective time.
+// LastError is set when the scheduler could not merge it.
+type ScheduledApproval struct {
+	RequestID      string `json:"request_id"`
+	RequestHash    string `json:"request_hash"` // req/<WorkItem> commit the approval applies to
+	WorkBranch     string `json:"work_branch"`
+	EffectiveAt    string `json:"effective_at"`
+	MergeMessageJa string `json:"merge_message_ja"`
+	CreatedAt      string `json:"created_at"`
+	Status         string `json:"status"`
+	LastError      string `json:"last_error,omitempty"`
+}
+
+// CancelScheduledApprovalRequest removes a scheduled approval; the request stays pending.
+type CancelScheduledApprovalRequest struct {
+	TargetID  string `json:"target_id"`
+	DBName    string `json:"db_name"`
+	RequestID string `json:"request_id"`
 }
 
 // RejectRequest represents a rejection action.
@@ -309,9 +657,48 @@ type ApproveResponse struct {
 	ActiveBranch         string `json:"active_branch"`
 	ActiveBranchAdvanced bool   `json:"active_branch_advanced"`
 	ArchiveTag           string `json:"archive_tag,omitempty"`
+	ScheduledFor         string `json:"scheduled_for,omitempty"`
 	OperationResultFields
 }
 
+// Merge queue entry states.
+const (
+	MergeQueueQueued  = "queued"
+	MergeQueueMerging = "merging"
+	MergeQueueMerged  = "merged"
+	MergeQueueFailed  = "failed"
+)
+
+// EnqueueMergeRequest adds an approved request to the database's merge queue.
+type EnqueueMergeRequest struct {
+	TargetID       string `json:"target_id"`
+	DBName         string `json:"db_name"`
+	RequestID      string `json:"request_id"`
+	MergeMessageJa string `json:"merge_message_ja"`
+}
+
+// MergeQueueEntry is one approval in the merge queue. Reason carries the error
+// code of a failed entry; Result is the approval outcome of a merged one.
+type MergeQueueEntry struct {
+	ID             int64            `json:"id"`
+	RequestID      string           `json:"request_id"`
+	MergeMessageJa string           `json:"merge_message_ja"`
+	Status         string           `json:"status"`
+	Position       int              `json:"position,omitempty"`
+	EnqueuedAt     string           `json:"enqueued_at"`
+	StartedAt      string           `json:"started_at,omitempty"`
+	FinishedAt     string           `json:"finished_at,omitempty"`
+	Reason         string           `json:"reason,omitempty"`
+	Message        string           `json:"message,omitempty"`
+	Result         *ApproveResponse `json:"result,omitempty"`
+}
+
+// MergeQueueResponse is the state of one database's merge queue.
+type MergeQueueResponse struct {
+	Running bool              `json:"running"`
+	Entries []MergeQueueEntry `json:"entries"`
+}
+
 type RejectResponse struct {
 	Status string `json:"status"`
 	OperationResultFields
@@ -330,6 +717,36 @@ type DiffSummaryResponse struct {
 	Entries []DiffSummaryEntry `json:"entries"`
 }
 
+// Diff export formats. An empty format is DiffExportCSV.
+const (
+	DiffExportCSV      = "csv"       // {table}_{insert,update,delete}.csv; updates hold the new values
+	DiffExportCSVPairs = "csv_pairs" // as csv, but updates hold old_<col>/new_<col> pairs
+	DiffExportXLSX     = "xlsx"      // diff.xlsx with one sheet per table
+	DiffExportJSONL    = "jsonl"     // {table}.jsonl with one diff row per line
+	DiffExportSQL      = "sql"       // patch.sql turning from_ref into to_ref
+)
+
+// DiffExportFile reports one file (or, for xlsx and sql, one table within
+// the single file) of a diff export. Rows counts the rows written; Error is
+// set when the file is incomplete or missing.
+type DiffExportFile struct {
+	Name     string `json:"name"`
+	Table    string `json:"table"`
+	DiffType string `json:"diff_type,omitempty"`
+	Rows     int    `json:"rows"`
+	Error    string `json:"error,omitempty"`
+}
+
+// DiffExportManifest is manifest.json, the last entry of a diff export ZIP.
+type DiffExportManifest struct {
+	FromRef  string           `json:"from_ref"`
+	ToRef    string           `json:"to_ref"`
+	Mode     string           `json:"mode"`
+	Format   string           `json:"format"`
+	Files    []DiffExportFile `json:"files"`
+	Complete bool             `json:"complete"` // no file has an error
+}
+
 // DiffSummaryLightEntry is the lightweight per-table change summary.
 type DiffSummaryLightEntry struct {
 	Table           string `json:"table"`
@@ -376,15 +793,28 @@ type MemoResponse struct {
 
 // --- Cross-DB Copy ---
 
+// CrossCopyMapping copies source columns into differently named destination
+// columns. Source columns that are neither mapped nor skipped are matched by
+// name as usual.
+type CrossCopyMapping struct {
+	Columns   map[string]string `json:"columns,omitempty"`   // source column -> destination column
+	Constants map[string]string `json:"constants,omitempty"` // destination column -> value for every row
+	Skip      []string          `json:"skip,omitempty"`      // source columns not copied
+}
+
 // CrossCopyPreviewRequest represents a request to preview cross-DB row copy.
+// Source rows are selected by SourcePKs or by SourceFilters, not both.
 type CrossCopyPreviewRequest struct {
-	TargetID     string   `json:"target_id"`
-	SourceDB     string   `json:"source_db"`
-	SourceBranch string   `json:"source_branch"`
-	SourceTable  string   `json:"source_table"`
-	SourcePKs    []string `json:"source_pks"`
-	DestDB       string   `json:"dest_db"`
-	DestBranch   string   `json:"dest_branch"`
+	TargetID       string            `json:"target_id"`
+	SourceTargetID string            `json:"source_target_id,omitempty"` // another Dolt server; defaults to TargetID
+	SourceDB       string            `json:"source_db"`
+	SourceBranch   string            `json:"source_branch"`
+	SourceTable    string            `json:"source_table"`
+	SourcePKs      []string          `json:"source_pks"`
+	SourceFilters  []FilterCondition `json:"source_filters,omitempty"`
+	Mapping        *CrossCopyMapping `json:"mapping,omitempty"`
+	DestDB         string            `json:"dest_db"`
+	DestBranch     string            `json:"dest_branch"`
 }
 
 // CrossCopyPreviewRow represents one row in the cross-copy preview.
@@ -409,17 +839,21 @@ type CrossCopyPreviewResponse struct {
 	Warnings       []string              `json:"warnings"`
 	Rows           []CrossCopyPreviewRow `json:"rows"`
 	ExpandColumns  []ExpandColumn        `json:"expand_columns,omitempty"`
+	ColumnMap      map[string]string     `json:"column_map,omitempty"` // destination -> source column, with a mapping
 }
 
 // CrossCopyRowsRequest represents a request to copy rows across databases.
 type CrossCopyRowsRequest struct {
-	TargetID     string   `json:"target_id"`
-	SourceDB     string   `json:"source_db"`
-	SourceBranch string   `json:"source_branch"`
-	SourceTable  string   `json:"source_table"`
-	SourcePKs    []string `json:"source_pks"`
-	DestDB       string   `json:"dest_db"`
-	DestBranch   string   `json:"dest_branch"`
+	TargetID       string            `json:"target_id"`
+	SourceTargetID string            `json:"source_target_id,omitempty"` // another Dolt server; defaults to TargetID
+	SourceDB       string            `json:"source_db"`
+	SourceBranch   string            `json:"source_branch"`
+	SourceTable    string            `json:"source_table"`
+	SourcePKs      []string          `json:"source_pks"`
+	SourceFilters  []FilterCondition `json:"source_filters,omitempty"`
+	Mapping        *CrossCopyMapping `json:"mapping,omitempty"`
+	DestDB         string            `json:"dest_db"`
+	DestBranch     string            `json:"dest_branch"`
 }
 
 // CrossCopyRowsResponse represents the result of a cross-DB row copy.
@@ -433,12 +867,13 @@ type CrossCopyRowsResponse struct {
 
 // CrossCopyAdminPrepareRowsRequest prepares destination main and syncs the destination work branch.
 type CrossCopyAdminPrepareRowsRequest struct {
-	TargetID     string `json:"target_id"`
-	SourceDB     string `json:"source_db"`
-	SourceBranch string `json:"source_branch"`
-	SourceTable  string `json:"source_table"`
-	DestDB       string `json:"dest_db"`
-	DestBranch   string `json:"dest_branch"`
+	TargetID       string `json:"target_id"`
+	SourceTargetID string `json:"source_target_id,omitempty"` // another Dolt server; defaults to TargetID
+	SourceDB       string `json:"source_db"`
+	SourceBranch   string `json:"source_branch"`
+	SourceTable    string `json:"source_table"`
+	DestDB         string `json:"dest_db"`
+	DestBranch     string `json:"dest_branch"`
 }
 
 // CrossCopyAdminPrepareRowsResponse reports the result of the rows admin lane.
@@ -452,11 +887,12 @@ type CrossCopyAdminPrepareRowsResponse struct {
 
 // CrossCopyTableRequest represents a request to copy an entire table across databases.
 type CrossCopyTableRequest struct {
-	TargetID     string `json:"target_id"`
-	SourceDB     string `json:"source_db"`
-	SourceBranch string `json:"source_branch"`
-	SourceTable  string `json:"source_table"`
-	DestDB       string `json:"dest_db"`
+	TargetID       string `json:"target_id"`
+	SourceTargetID string `json:"source_target_id,omitempty"` // another Dolt server; defaults to TargetID
+	SourceDB       string `json:"source_db"`
+	SourceBranch   string `json:"source_branch"`
+	SourceTable    string `json:"source_table"`
+	DestDB         string `json:"dest_db"`
 }
 
 // CrossCopyTableResponse represents the result of a cross-DB table copy.
@@ -472,11 +908,12 @@ type CrossCopyTableResponse struct {
 
 // CrossCopyAdminPrepareTableRequest prepares destination main for a subsequent table copy retry.
 type CrossCopyAdminPrepareTableRequest struct {
-	TargetID     string `json:"target_id"`
-	SourceDB     string `json:"source_db"`
-	SourceBranch string `json:"source_branch"`
-	SourceTable  string `json:"source_table"`
-	DestDB       string `json:"dest_db"`
+	TargetID       string `json:"target_id"`
+	SourceTargetID string `json:"source_target_id,omitempty"` // another Dolt server; defaults to TargetID
+	SourceDB       string `json:"source_db"`
+	SourceBranch   string `json:"source_branch"`
+	SourceTable    string `json:"source_table"`
+	DestDB         string `json:"dest_db"`
 }
 
 // CrossCopyAdminPrepareTableResponse reports the result of the table admin lane.
@@ -486,6 +923,113 @@ type CrossCopyAdminPrepareTableResponse struct {
 	OperationResultFields
 }
 
+// CrossCopyTablesRequest represents a request to copy a set of related tables
+// into one import branch. Preview and copy take the same request.
+type CrossCopyTablesRequest struct {
+	TargetID       string   `json:"target_id"`
+	SourceTargetID string   `json:"source_target_id,omitempty"` // another Dolt server; defaults to TargetID
+	SourceDB       string   `json:"source_db"`
+	SourceBranch   string   `json:"source_branch"`
+	SourceTables   []string `json:"source_tables"`
+	DestDB         string   `json:"dest_db"`
+}
+
+// CrossCopyTablePlan describes one table of a multi-table copy.
+type CrossCopyTablePlan struct {
+	Table          string         `json:"table"`
+	SharedColumns  []string       `json:"shared_columns"`
+	SourceOnlyCols []string       `json:"source_only_columns"`
+	DestOnlyCols   []string       `json:"dest_only_columns"`
+	SourceRows     int            `json:"source_rows"`
+	DestRows       int            `json:"dest_rows"`
+	DependsOn      []string       `json:"depends_on"` // selected tables this table references
+	ExpandColumns  []ExpandColumn `json:"expand_columns,omitempty"`
+}
+
+// CrossCopyTablesPreviewResponse represents the plan of a multi-table copy.
+// Order lists the tables parents first; rows are deleted in reverse order.
+type CrossCopyTablesPreviewResponse struct {
+	BranchName string               `json:"branch_name"`
+	Order      []string             `json:"order"`
+	Tables     []CrossCopyTablePlan `json:"tables"`
+	Warnings   []string             `json:"warnings"`
+}
+
+// CrossCopyTableCount is the number of rows copied into one table.
+type CrossCopyTableCount struct {
+	Table    string `json:"table"`
+	RowCount int    `json:"row_count"`
+}
+
+// CrossCopyTablesResponse represents the result of a multi-table copy.
+type CrossCopyTablesResponse struct {
+	Hash       string                `json:"hash"`
+	BranchName string                `json:"branch_name"`
+	Order      []string              `json:"order"`
+	Tables     []CrossCopyTableCount `json:"tables"`
+	RowCount   int                   `json:"row_count"`
+	OperationResultFields
+}
+
+// ReplayApprovalRequest replays the row changes of an approval merge commit
+// of SourceDB onto a new wi/replay-<WorkItem> branch of DestDB.
+type ReplayApprovalRequest struct {
+	TargetID       string `json:"target_id"`
+	SourceTargetID string `json:"source_target_id,omitempty"` // another Dolt server; defaults to TargetID
+	SourceDB       string `json:"source_db"`
+	MergeHash      string `json:"merge_hash"` // approval merge commit on source main
+	DestDB         string `json:"dest_db"`
+}
+
+// Reasons a replayed row is skipped.
+const (
+	ReplaySkipMissingPK   = "missing_pk"   // the updated or deleted row is not in the destination
+	ReplaySkipBaseChanged = "base_changed" // a destination value differs from the source value before the approval
+	ReplaySkipPKExists    = "pk_exists"    // the inserted key already holds a different row
+)
+
+// ReplaySkippedRow is a source change that could not be applied.
+type ReplaySkippedRow struct {
+	Table    string                 `json:"table"`
+	DiffType string                 `json:"diff_type"` // added, modified or removed in the source
+	PK       map[string]interface{} `json:"pk"`
+	Reason   string                 `json:"reason"`
+	Columns  []string               `json:"columns,omitempty"` // the conflicting columns
+}
+
+// ReplayTableResult counts the replayed changes of one table. Unchanged rows
+// already matched the approved values in the destination.
+type ReplayTableResult struct {
+	Table     string `json:"table"`
+	Inserted  int    `json:"inserted"`
+	Updated   int    `json:"updated"`
+	Deleted   int    `json:"deleted"`
+	Unchanged int    `json:"unchanged"`
+	Skipped   int    `json:"skipped"`
+}
+
+// ReplayApprovalPreviewResponse reports what a replay would apply to the
+// current destination main.
+type ReplayApprovalPreviewResponse struct {
+	BranchName string              `json:"branch_name"`
+	RequestID  string              `json:"request_id"`
+	WorkItem   string              `json:"work_item"`
+	Tables     []ReplayTableResult `json:"tables"`
+	Skipped    []ReplaySkippedRow  `json:"skipped"`
+	Warnings   []string            `json:"warnings"`
+}
+
+// ReplayApprovalResponse represents the result of a replay.
+type ReplayApprovalResponse struct {
+	Hash       string              `json:"hash"`
+	BranchName string              `json:"branch_name"`
+	RequestID  string              `json:"request_id"`
+	WorkItem   string              `json:"work_item"`
+	Tables     []ReplayTableResult `json:"tables"`
+	Skipped    []ReplaySkippedRow  `json:"skipped"`
+	OperationResultFields
+}
+
 // CrossCopyAdminCleanupImportRequest removes a deterministic import branch.
 type CrossCopyAdminCleanupImportRequest struct {
 	TargetID   string `json:"target_id"`
@@ -501,13 +1045,27 @@ type CrossCopyAdminCleanupImportResponse struct {
 
 // --- CSV Import ---
 
+// CSV import modes. An empty mode is CSVModeUpsert.
+const (
+	CSVModeUpsert     = "upsert"      // insert new PKs, update existing ones
+	CSVModeInsertOnly = "insert_only" // existing PKs are errors
+	CSVModeUpdateOnly = "update_only" // unknown PKs are errors
+	CSVModeReplace    = "replace"     // upsert, then delete rows missing from the file (within ReplaceFilter)
+)
+
 // CSVPreviewRequest represents a CSV preview request.
+// Rows are sent inline, or UploadID names a file parsed by POST /csv/upload.
 type CSVPreviewRequest struct {
-	TargetID   string                   `json:"target_id"`
-	DBName     string                   `json:"db_name"`
-	BranchName string                   `json:"branch_name"`
-	Table      string                   `json:"table"`
-	Rows       []map[string]interface{} `json:"rows"`
+	TargetID      string                   `json:"target_id"`
+	DBName        string                   `json:"db_name"`
+	BranchName    string                   `json:"branch_name"`
+	Table         string                   `json:"table"`
+	Rows          []map[string]interface{} `json:"rows"`
+	UploadID      string                   `json:"upload_id,omitempty"`
+	Mode          string                   `json:"mode,omitempty"`
+	ReplaceFilter []FilterCondition        `json:"replace_filter,omitempty"` // replace only: rows outside it are never deleted
+	Profile       string                   `json:"profile,omitempty"`        // saved CSVMappingProfile for Table
+	Mapping       *CSVMapping              `json:"mapping,omitempty"`        // inline mapping; overrides Profile
 }
 
 // CSVDiffRow represents a single row in the CSV diff preview.
@@ -525,12 +1083,25 @@ type CSVPreviewError struct {
 
 // CSVPreviewResponse represents the result of a CSV preview.
 type CSVPreviewResponse struct {
-	Inserts       int               `json:"inserts"`
-	Updates       int               `json:"updates"`
-	Skips         int               `json:"skips"`
-	Errors        int               `json:"errors"`
-	SampleDiffs   []CSVDiffRow      `json:"sample_diffs"`
-	PreviewErrors []CSVPreviewError `json:"preview_errors,omitempty"`
+	Inserts       int                      `json:"inserts"`
+	Updates       int                      `json:"updates"`
+	Skips         int                      `json:"skips"`
+	Deletes       int                      `json:"deletes"`
+	Errors        int                      `json:"errors"`
+	SampleDiffs   []CSVDiffRow             `json:"sample_diffs"`
+	SampleDeletes []map[string]interface{} `json:"sample_deletes,omitempty"`
+	PreviewErrors []CSVPreviewError        `json:"preview_errors,omitempty"` // first page; see PreviewID
+	ColumnErrors  map[string]int           `json:"column_errors,omitempty"`  // mapping only: invalid values per column
+	PreviewID     string                   `json:"preview_id,omitempty"`     // set when Errors > 0; pages and report of all errors
+}
+
+// CSVPreviewErrorsResponse is one page of the errors of a preview.
+type CSVPreviewErrorsResponse struct {
+	PreviewID string            `json:"preview_id"`
+	Page      int               `json:"page"`
+	PageSize  int               `json:"page_size"`
+	Total     int               `json:"total"`
+	Errors    []CSVPreviewError `json:"errors"`
 }
 
 // CSVApplyRequest represents a request to apply CSV data.
@@ -542,13 +1113,82 @@ type CSVApplyRequest struct {
 	ExpectedHead  string                   `json:"expected_head"`
 	CommitMessage string                   `json:"commit_message"`
 	Rows          []map[string]interface{} `json:"rows"`
+	UploadID      string                   `json:"upload_id,omitempty"`
+	Mode          string                   `json:"mode,omitempty"`
+	ReplaceFilter []FilterCondition        `json:"replace_filter,omitempty"`
+	Profile       string                   `json:"profile,omitempty"`
+	Mapping       *CSVMapping              `json:"mapping,omitempty"`
 }
 
 type CSVApplyResponse struct {
-	Hash string `json:"hash"`
+	Hash    string `json:"hash"`
+	Inserts int    `json:"inserts"`
+	Updates int    `json:"updates"`
+	Deletes int    `json:"deletes"`
 	OperationResultFields
 }
 
+// CSVMapping turns file columns into table columns and coerces values to the
+// column types before preview/apply. Headers that are neither mapped, ignored
+// nor table columns are rejected.
+type CSVMapping struct {
+	Columns   map[string]string `json:"columns,omitempty"`    // file header -> table column
+	Ignore    []string          `json:"ignore,omitempty"`     // file headers to drop
+	Constants map[string]string `json:"constants,omitempty"`  // table column -> value for every row
+	Defaults  map[string]string `json:"defaults,omitempty"`   // table column -> value when the cell is empty or absent
+	Trim      bool              `json:"trim,omitempty"`       // trim surrounding whitespace
+	HalfWidth bool              `json:"half_width,omitempty"` // full-width letters, digits, symbols and spaces -> half-width
+}
+
+// CSVMappingProfile is a CSVMapping saved by name for one table.
+type CSVMappingProfile struct {
+	Table string `json:"table"`
+	Name  string `json:"name"`
+	CSVMapping
+	UpdatedAt string `json:"updated_at,omitempty"`
+}
+
+// SaveCSVMappingProfileRequest creates or replaces a mapping profile.
+type SaveCSVMappingProfileRequest struct {
+	TargetID string `json:"target_id"`
+	DBName   string `json:"db_name"`
+	CSVMappingProfile
+}
+
+// DeleteCSVMappingProfileRequest removes a mapping profile.
+type DeleteCSVMappingProfileRequest struct {
+	TargetID string `json:"target_id"`
+	DBName   string `json:"db_name"`
+	Table    string `json:"table"`
+	Name     string `json:"name"`
+}
+
+// CSVUploadOptions are the optional form fields of POST /csv/upload.
+// Empty values are detected from the file.
+type CSVUploadOptions struct {
+	FileName  string `json:"file_name"`
+	Encoding  string `json:"encoding,omitempty"`   // utf-8 | shift_jis | euc-jp
+	Delimiter string `json:"delimiter,omitempty"`  // "," | "\t" | ";" | "|"
+	HeaderRow int    `json:"header_row,omitempty"` // 1-based line of the header
+	Sheet     string `json:"sheet,omitempty"`      // XLSX sheet name (default: first sheet)
+}
+
+// CSVUploadResponse describes a parsed upload. UploadID is passed to
+// CSVPreview/CSVApply until ExpiresAt.
+type CSVUploadResponse struct {
+	UploadID   string              `json:"upload_id"`
+	FileName   string              `json:"file_name"`
+	Format     string              `json:"format"` // csv | tsv | xlsx
+	Encoding   string              `json:"encoding,omitempty"`
+	Delimiter  string              `json:"delimiter,omitempty"`
+	Sheet      string              `json:"sheet,omitempty"`
+	HeaderRow  int                 `json:"header_row"`
+	Headers    []string            `json:"headers"`
+	RowCount   int                 `json:"row_count"`
+	SampleRows []map[string]string `json:"sample_rows"`
+	ExpiresAt  string              `json:"expires_at"`
+}
+
 // --- Search ---
 
 // SearchResult represents a single search hit.
@@ -566,3 +1206,133 @@ type SearchResponse struct {
 	Total   int            `json:"total"`
 	ReadResultFields
 }
+
+// --- Replication ---
+
+// Replication run states.
+const (
+	ReplicationSucceeded = "succeeded"
+	ReplicationFailed    = "failed"
+)
+
+// ReplicationRun is one backup sync, push or fetch against a configured remote.
+type ReplicationRun struct {
+	Remote     string `json:"remote"`
+	Action     string `json:"action"` // sync | push | fetch
+	Status     string `json:"status"`
+	StartedAt  string `json:"started_at"`
+	FinishedAt string `json:"finished_at"`
+	Error      string `json:"error,omitempty"`
+}
+
+// ReplicationRemoteStatus is a configured remote with its recent runs, newest first.
+type ReplicationRemoteStatus struct {
+	Name          string           `json:"name"`
+	URL           string           `json:"url"`
+	Kind          string           `json:"kind"`
+	LastSuccessAt string           `json:"last_success_at,omitempty"`
+	History       []ReplicationRun `json:"history"`
+}
+
+// ReplicationStatusResponse lists the replication remotes of a database.
+type ReplicationStatusResponse struct {
+	IntervalMinutes int                       `json:"interval_minutes"`
+	Remotes         []ReplicationRemoteStatus `json:"remotes"`
+}
+
+// RunReplicationRequest syncs one remote, or every remote when Remote is empty.
+// Action defaults to sync for backups and push for remotes; fetch is remote-only.
+type RunReplicationRequest struct {
+	TargetID string `json:"target_id"`
+	DBName   string `json:"db_name"`
+	Remote   string `json:"remote,omitempty"`
+	Action   string `json:"action,omitempty"`
+}
+
+// RunReplicationResponse reports each run; Outcome is failed if any run failed.
+type RunReplicationResponse struct {
+	Runs []ReplicationRun `json:"runs"`
+	OperationResultFields
+}
+
+// RestoreReplicaRequest restores a configured remote into a new database on the
+// same server. The new database is not served until it is added to the config.
+type RestoreReplicaRequest struct {
+	TargetID  string `json:"target_id"`
+	DBName    string `json:"db_name"`
+	Remote    string `json:"remote"`
+	NewDBName string `json:"new_db_name"`
+}
+
+// RestoreReplicaResponse is the result of a restore.
+type RestoreReplicaResponse struct {
+	NewDBName string `json:"new_db_name"`
+	OperationResultFields
+}
+
+// ReplicationHealth is the last replication state of one remote, for /health.
+type ReplicationHealth struct {
+	TargetID      string `json:"target_id"`
+	DBName        string `json:"db_name"`
+	Remote        string `json:"remote"`
+	LastSuccessAt string `json:"last_success_at,omitempty"`
+	LastStatus    string `json:"last_status,omitempty"`
+}
+
+// HealthResponse is the /health body.
+type HealthResponse struct {
+	Status      string              `json:"status"`
+	Replication []ReplicationHealth `json:"replication,omitempty"`
+}
+
+// --- Audit ---
+
+// AuditImportRequest loads the snapshot directory <audit_import_dir>/<Snapshot>
+// into the audit branch. ExpectedHead, when set, must match the audit HEAD.
+type AuditImportRequest struct {
+	TargetID     string `json:"target_id"`
+	DBName       string `json:"db_name"`
+	Snapshot     string `json:"snapshot"`
+	ExpectedHead string `json:"expected_head,omitempty"`
+}
+
+// AuditImportTable is one snapshot file loaded into a table.
+type AuditImportTable struct {
+	Table string `json:"table"`
+	File  string `json:"file"`
+	Rows  int    `json:"rows"`
+}
+
+// AuditImportResponse is the result of an audit import.
+type AuditImportResponse struct {
+	Hash     string             `json:"hash"`
+	Snapshot string             `json:"snapshot"`
+	Tables   []AuditImportTable `json:"tables"`
+	OperationResultFields
+}
+
+// AuditImportInfo describes the latest import commit on the audit branch.
+type AuditImportInfo struct {
+	Hash       string   `json:"hash"`
+	Snapshot   string   `json:"snapshot"`
+	Tables     []string `json:"tables"`
+	Rows       int      `json:"rows"`
+	ImportedAt string   `json:"imported_at"`
+}
+
+// AuditReconcileTable counts the differences between main and audit for one table.
+type AuditReconcileTable struct {
+	Table          string `json:"table"`
+	OnlyInMain     int    `json:"only_in_main"`
+	OnlyInAudit    int    `json:"only_in_audit"`
+	DifferingRows  int    `json:"differing_rows"`
+	DifferingCells int    `json:"differing_cells"`
+}
+
+// AuditReconcileResponse lists tables whose rows differ between main and audit.
+type AuditReconcileResponse struct {
+	MainHash   string                `json:"main_hash"`
+	AuditHash  string                `json:"audit_hash"`
+	LastImport *AuditImportInfo      `json:"last_import,omitempty"`
+	Tables     []AuditReconcileTable `json:"tables"`
+}
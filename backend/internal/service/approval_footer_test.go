@@ -43,6 +43,30 @@ func TestApprovalFooter_RoundTrip_Valid(t *testing.T) {
 				RequestSubmittedAt: "2026-03-11T09:15:00Z",
 			},
 		},
+		{
+			name:    "with ticket id",
+			subject: "承認マージ: feature-456",
+			footer: approvalFooter{
+				Schema:            "v1",
+				RequestID:         "req/feature-456",
+				WorkItem:          "feature-456",
+				WorkBranch:        "wi/feature-456",
+				SubmittedWorkHash: validHash,
+				TicketID:          "JIRA-1234",
+			},
+		},
+		{
+			name:    "with scheduled time",
+			subject: "承認マージ: feature-789",
+			footer: approvalFooter{
+				Schema:            "v1",
+				RequestID:         "req/feature-789",
+				WorkItem:          "feature-789",
+				WorkBranch:        "wi/feature-789",
+				SubmittedWorkHash: validHash,
+				ScheduledAt:       "2026-03-14T22:00:00+09:00",
+			},
+		},
 		{
 			name:    "work item with slash in name",
 			subject: "承認マージ: team/task-99",
@@ -89,6 +113,12 @@ func TestApprovalFooter_RoundTrip_Valid(t *testing.T) {
 			if got.RequestSubmittedAt != tt.footer.RequestSubmittedAt {
 				t.Errorf("RequestSubmittedAt: got %q want %q", got.RequestSubmittedAt, tt.footer.RequestSubmittedAt)
 			}
+			if got.TicketID != tt.footer.TicketID {
+				t.Errorf("TicketID: got %q want %q", got.TicketID, tt.footer.TicketID)
+			}
+			if got.ScheduledAt != tt.footer.ScheduledAt {
+				t.Errorf("ScheduledAt: got %q want %q", got.ScheduledAt, tt.footer.ScheduledAt)
+			}
 		})
 	}
 }
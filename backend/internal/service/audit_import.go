@@ -0,0 +1,336 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/footer"
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/validation"
+)
+
+// auditImportBatchRows caps the rows folded into one INSERT during an audit import.
+const auditImportBatchRows = 500
+
+var snapshotNameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,127}$`)
+
+// auditSnapshotFile is one <table>.csv or <table>.tsv file with its header.
+// The rows are streamed from disk by insertAuditRows.
+type auditSnapshotFile struct {
+	table  string
+	file   string
+	path   string
+	tsv    bool
+	header []string
+}
+
+// readAuditSnapshot lists the CSV/TSV files of a snapshot directory and reads
+// their headers. The file name (without extension) is the table name; the
+// first line is the header.
+func readAuditSnapshot(dir string) ([]auditSnapshotFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, &model.APIError{Status: 404, Code: model.CodeNotFound, Msg: fmt.Sprintf("snapshot %s not found", filepath.Base(dir))}
+		}
+		return nil, fmt.Errorf("failed to read snapshot directory: %w", err)
+	}
+
+	files := make([]auditSnapshotFile, 0)
+	seen := make(map[string]string)
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".csv" && ext != ".tsv") {
+			continue
+		}
+		table := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		if err := validation.ValidateIdentifier("table", table); err != nil || isHiddenTableName(table) {
+			return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: fmt.Sprintf("%s: invalid table name", entry.Name())}
+		}
+		if other, dup := seen[table]; dup {
+			return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: fmt.Sprintf("%s and %s load the same table", other, entry.Name())}
+		}
+		seen[table] = entry.Name()
+
+		file := auditSnapshotFile{table: table, file: entry.Name(), path: filepath.Join(dir, entry.Name()), tsv: ext == ".tsv"}
+		f, reader, err := file.open()
+		if err != nil {
+			return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: fmt.Sprintf("%s: %v", entry.Name(), err)}
+		}
+		file.header, err = readAuditSnapshotHeader(reader)
+		f.Close()
+		if err != nil {
+			return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: fmt.Sprintf("%s: %v", entry.Name(), err)}
+		}
+		files = append(files, file)
+	}
+	if len(files) == 0 {
+		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "snapshot has no .csv or .tsv files"}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].table < files[j].table })
+	return files, nil
+}
+
+// open returns the file and a reader positioned at its header line.
+func (f auditSnapshotFile) open() (*os.File, *csv.Reader, error) {
+	file, err := os.Open(f.path)
+	if err != nil {
+		return nil, nil, err
+	}
+	reader := csv.NewReader(file)
+	if f.tsv {
+		reader.Comma = '\t'
+		reader.LazyQuotes = true
+	}
+	return file, reader, nil
+}
+
+func readAuditSnapshotHeader(reader *csv.Reader) ([]string, error) {
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("missing header line")
+	}
+	if err != nil {
+		return nil, err
+	}
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	columns := make(map[string]bool, len(header))
+	for i, col := range header {
+		header[i] = strings.TrimSpace(col)
+		if err := validation.ValidateIdentifier("column", header[i]); err != nil {
+			return nil, err
+		}
+		if columns[header[i]] {
+			return nil, fmt.Errorf("duplicate column %s", header[i])
+		}
+		columns[header[i]] = true
+	}
+	return header, nil
+}
+
+// ImportAuditSnapshot replaces the contents of every table that has a file in
+// the snapshot directory and commits the result on audit as one commit with an
+// import footer. Tables without a file are left unchanged.
+func (s *Service) ImportAuditSnapshot(ctx context.Context, req model.AuditImportRequest) (*model.AuditImportResponse, error) {
+	db, err := s.configuredDatabase(req.TargetID, req.DBName)
+	if err != nil {
+		return nil, err
+	}
+	if db.AuditImportDir == "" {
+		return nil, &model.APIError{
+			Status:  412,
+			Code:    model.CodePreconditionFailed,
+			Msg:     "audit import is not configured for this database",
+			Details: map[string]string{"reason": "audit_import_disabled"},
+		}
+	}
+	if !snapshotNameRe.MatchString(req.Snapshot) {
+		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: fmt.Sprintf("invalid snapshot name: %q", req.Snapshot)}
+	}
+	files, err := readAuditSnapshot(filepath.Join(db.AuditImportDir, req.Snapshot))
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := s.repo.ConnProtectedMaintenance(ctx, req.TargetID, req.DBName, "audit")
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close()
+
+	// Validate every file against the audit schema before anything is deleted.
+	nullable := make([]map[string]bool, len(files))
+	for i, file := range files {
+		cols, err := getSchemaColumns(ctx, conn, file.table)
+		if err != nil {
+			return nil, err
+		}
+		colMap := schemaColumnMap(cols)
+		nullable[i] = make(map[string]bool)
+		for _, col := range file.header {
+			schemaCol, ok := colMap[col]
+			if !ok {
+				return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: fmt.Sprintf("%s: column %s does not exist in %s", file.file, col, file.table)}
+			}
+			nullable[i][col] = schemaCol.Nullable
+		}
+		for _, pk := range getPKColumns(cols) {
+			if _, ok := nullable[i][pk]; !ok {
+				return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: fmt.Sprintf("%s: primary key column %s is missing", file.file, pk)}
+			}
+		}
+	}
+
+	// Tables are reloaded in name order; FK checks are deferred to DOLT_VERIFY_CONSTRAINTS.
+	if _, err := conn.ExecContext(ctx, "SET FOREIGN_KEY_CHECKS = 0"); err != nil {
+		return nil, fmt.Errorf("failed to disable foreign key checks: %w", err)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), "SET FOREIGN_KEY_CHECKS = 1"); err != nil {
+			log.Printf("WARN: failed to re-enable foreign key checks: %v", err)
+		}
+	}()
+
+	if _, err := conn.ExecContext(ctx, "START TRANSACTION"); err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	if req.ExpectedHead != "" {
+		var currentHead string
+		if err := conn.QueryRowContext(ctx, "SELECT DOLT_HASHOF('HEAD')").Scan(&currentHead); err != nil {
+			safeRollback(conn)
+			return nil, fmt.Errorf("failed to get HEAD: %w", err)
+		}
+		if currentHead != req.ExpectedHead {
+			safeRollback(conn)
+			return nil, &model.APIError{
+				Status:  409,
+				Code:    model.CodeStaleHead,
+				Msg:     "expected_head mismatch",
+				Details: map[string]string{"expected_head": req.ExpectedHead, "actual_head": currentHead},
+			}
+		}
+	}
+
+	results := make([]model.AuditImportTable, 0, len(files))
+	totalRows := 0
+	tables := make([]string, 0, len(files))
+	for i, file := range files {
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf("DELETE FROM `%s`", file.table)); err != nil {
+			safeRollback(conn)
+			return nil, fmt.Errorf("failed to clear %s: %w", file.table, err)
+		}
+		n, err := insertAuditRows(ctx, conn, file, nullable[i])
+		if err != nil {
+			safeRollback(conn)
+			return nil, err
+		}
+		results = append(results, model.AuditImportTable{Table: file.table, File: file.file, Rows: n})
+		tables = append(tables, file.table)
+		totalRows += n
+	}
+
+	if _, err := conn.ExecContext(ctx, "CALL DOLT_VERIFY_CONSTRAINTS()"); err != nil {
+		safeRollback(conn)
+		return nil, fmt.Errorf("failed to verify constraints: %w", err)
+	}
+	var violationCount int
+	if err := conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM dolt_constraint_violations").Scan(&violationCount); err == nil && violationCount > 0 {
+		safeRollback(conn)
+		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "constraint violations detected"}
+	}
+
+	if _, err := conn.ExecContext(ctx, "CALL DOLT_ADD('.')"); err != nil {
+		safeRollback(conn)
+		return nil, fmt.Errorf("failed to add: %w", err)
+	}
+	commitMsg := footer.BuildImportFooter(
+		fmt.Sprintf("[audit-import] スナップショット %s を取り込み（%dテーブル, %d行）", req.Snapshot, len(files), totalRows),
+		footer.ImportFooter{
+			Schema:     "v1",
+			Snapshot:   req.Snapshot,
+			Tables:     tables,
+			Rows:       totalRows,
+			ImportedAt: time.Now().UTC().Format(time.RFC3339),
+		},
+	)
+	if _, err := conn.ExecContext(ctx, "CALL DOLT_COMMIT('--allow-empty', '-m', ?)", commitMsg); err != nil {
+		safeRollback(conn)
+		return nil, fmt.Errorf("failed to commit: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, "COMMIT"); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	var newHead string
+	if err := conn.QueryRowContext(ctx, "SELECT DOLT_HASHOF('HEAD')").Scan(&newHead); err != nil {
+		return nil, fmt.Errorf("failed to get new HEAD: %w", err)
+	}
+	return &model.AuditImportResponse{
+		Hash:     newHead,
+		Snapshot: req.Snapshot,
+		Tables:   results,
+		OperationResultFields: model.OperationResultFields{
+			Outcome:    model.OperationOutcomeCompleted,
+			Message:    fmt.Sprintf("スナップショット %s を audit に取り込みました", req.Snapshot),
+			Completion: map[string]bool{"audit_imported": true},
+		},
+	}, nil
+}
+
+// insertAuditRows streams the file rows into multi-row batches and returns how
+// many were loaded. Empty cells become NULL for nullable columns. A malformed
+// line fails the import; the caller rolls back.
+func insertAuditRows(ctx context.Context, conn *sql.Conn, file auditSnapshotFile, nullable map[string]bool) (int, error) {
+	f, reader, err := file.open()
+	if err != nil {
+		return 0, fmt.Errorf("failed to open %s: %w", file.file, err)
+	}
+	defer f.Close()
+	if _, err := reader.Read(); err != nil { // header, validated by readAuditSnapshot
+		return 0, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: fmt.Sprintf("%s: %v", file.file, err)}
+	}
+
+	quoted := make([]string, len(file.header))
+	for i, col := range file.header {
+		quoted[i] = fmt.Sprintf("`%s`", col)
+	}
+	rowPlaceholder := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(file.header)), ", ") + ")"
+	batchRows := auditImportBatchRows
+	if limit := commitBatchMaxPlaceholders / len(file.header); limit < batchRows {
+		batchRows = limit
+	}
+
+	total := 0
+	placeholders := make([]string, 0, batchRows)
+	args := make([]interface{}, 0, batchRows*len(file.header))
+	flush := func() error {
+		if len(placeholders) == 0 {
+			return nil
+		}
+		query := fmt.Sprintf("INSERT INTO `%s` (%s) VALUES %s", file.table, strings.Join(quoted, ", "), strings.Join(placeholders, ", "))
+		if _, err := conn.ExecContext(ctx, query, args...); err != nil {
+			return &model.APIError{
+				Status: 400,
+				Code:   model.CodeInvalidArgument,
+				Msg:    fmt.Sprintf("%s: rows %d-%d could not be loaded: %v", file.file, total-len(placeholders)+2, total+1, err),
+			}
+		}
+		placeholders, args = placeholders[:0], args[:0]
+		return nil
+	}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return total, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: fmt.Sprintf("%s: %v", file.file, err)}
+		}
+		placeholders = append(placeholders, rowPlaceholder)
+		for i, value := range record {
+			if value == "" && nullable[file.header[i]] {
+				args = append(args, nil)
+			} else {
+				args = append(args, value)
+			}
+		}
+		total++
+		if len(placeholders) == batchRows {
+			if err := flush(); err != nil {
+				return total, err
+			}
+		}
+	}
+	return total, flush()
+}
@@ -0,0 +1,255 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/footer"
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+)
+
+func auditImportTestService(t *testing.T, files map[string]string, handler func(query string, args []driver.NamedValue) (testQueryResult, error)) *Service {
+	t.Helper()
+	root := t.TempDir()
+	dir := filepath.Join(root, "2026-10-01")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	repo := newCrossCopyTestRepo(t, nil, nil)
+	repo.protectedHandler = func(dbName, branchName, query string, args []driver.NamedValue) (testQueryResult, error) {
+		if branchName != "audit" {
+			return testQueryResult{}, fmt.Errorf("unexpected branch %s", branchName)
+		}
+		return handler(query, args)
+	}
+	cfg := testServiceConfig()
+	cfg.Databases[0].AuditImportDir = root
+	return newWithDeps(repo, cfg)
+}
+
+func TestImportAuditSnapshot_ReplacesTablesInOneCommit(t *testing.T) {
+	var executed []string
+	var commitMsg string
+	svc := auditImportTestService(t, map[string]string{
+		"users.csv":  "\ufeffid,name\n1,Alice\n2,\n",
+		"notes.txt":  "ignored",
+		"orders.tsv": "id\tname\n10\tBox\n",
+	}, func(query string, args []driver.NamedValue) (testQueryResult, error) {
+		switch {
+		case strings.HasPrefix(query, "SHOW COLUMNS FROM"):
+			return showColumnsResult("varchar(255)"), nil
+		case query == "SELECT COUNT(*) FROM dolt_constraint_violations":
+			return testQueryResult{columns: []string{"count"}, rows: [][]driver.Value{{int64(0)}}}, nil
+		case query == "SELECT DOLT_HASHOF('HEAD')":
+			return testQueryResult{columns: []string{"hash"}, rows: [][]driver.Value{{"audit-head-2"}}}, nil
+		case query == "CALL DOLT_COMMIT('--allow-empty', '-m', ?)":
+			commitMsg = args[0].Value.(string)
+			return testQueryResult{}, nil
+		case strings.HasPrefix(query, "INSERT INTO"):
+			values := make([]string, len(args))
+			for i, arg := range args {
+				values[i] = fmt.Sprintf("%v", arg.Value)
+			}
+			executed = append(executed, query+" "+strings.Join(values, "|"))
+			return testQueryResult{}, nil
+		case strings.HasPrefix(query, "DELETE FROM"):
+			executed = append(executed, query)
+			return testQueryResult{}, nil
+		}
+		return testQueryResult{}, nil
+	})
+
+	resp, err := svc.ImportAuditSnapshot(context.Background(), model.AuditImportRequest{TargetID: "local", DBName: "test_db", Snapshot: "2026-10-01"})
+	if err != nil {
+		t.Fatalf("ImportAuditSnapshot: %v", err)
+	}
+	want := []string{
+		"DELETE FROM `orders`",
+		"INSERT INTO `orders` (`id`, `name`) VALUES (?, ?) 10|Box",
+		"DELETE FROM `users`",
+		"INSERT INTO `users` (`id`, `name`) VALUES (?, ?), (?, ?) 1|Alice|2|<nil>",
+	}
+	if got := strings.Join(executed, "\n"); got != strings.Join(want, "\n") {
+		t.Fatalf("executed:\n%s\nwant:\n%s", got, strings.Join(want, "\n"))
+	}
+	if resp.Hash != "audit-head-2" || len(resp.Tables) != 2 || resp.Outcome != model.OperationOutcomeCompleted {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	f, err := footer.ParseImportFooter(commitMsg)
+	if err != nil || f == nil {
+		t.Fatalf("missing import footer in %q: %v", commitMsg, err)
+	}
+	if f.Snapshot != "2026-10-01" || f.Rows != 3 || strings.Join(f.Tables, ",") != "orders,users" {
+		t.Fatalf("unexpected footer: %+v", f)
+	}
+}
+
+func TestImportAuditSnapshot_RejectsBadFilesBeforeDeleting(t *testing.T) {
+	cases := map[string]string{
+		"id,email\n1,a@example.com\n": "column email does not exist",
+		"name\nAlice\n":               "primary key column id is missing",
+	}
+	for content, wantMsg := range cases {
+		svc := auditImportTestService(t, map[string]string{"users.csv": content}, func(query string, args []driver.NamedValue) (testQueryResult, error) {
+			if query == "SHOW COLUMNS FROM `users`" {
+				return showColumnsResult("varchar(255)"), nil
+			}
+			return testQueryResult{}, fmt.Errorf("unexpected query: %s", query)
+		})
+		_, err := svc.ImportAuditSnapshot(context.Background(), model.AuditImportRequest{TargetID: "local", DBName: "test_db", Snapshot: "2026-10-01"})
+		apiErr, ok := err.(*model.APIError)
+		if !ok || apiErr.Code != model.CodeInvalidArgument || !strings.Contains(apiErr.Msg, wantMsg) {
+			t.Fatalf("expected %q, got %v", wantMsg, err)
+		}
+	}
+
+	var executed []string
+	svc := auditImportTestService(t, map[string]string{"users.csv": "id,name\n1,Alice\n2\n"}, func(query string, args []driver.NamedValue) (testQueryResult, error) {
+		executed = append(executed, query)
+		if strings.HasPrefix(query, "SHOW COLUMNS FROM") {
+			return showColumnsResult("varchar(255)"), nil
+		}
+		return testQueryResult{}, nil
+	})
+	_, err := svc.ImportAuditSnapshot(context.Background(), model.AuditImportRequest{TargetID: "local", DBName: "test_db", Snapshot: "2026-10-01"})
+	if apiErr, ok := err.(*model.APIError); !ok || apiErr.Code != model.CodeInvalidArgument || !strings.Contains(apiErr.Msg, "users.csv") {
+		t.Fatalf("expected INVALID_ARGUMENT for a malformed line, got %v", err)
+	}
+	if !containsQuery(executed, "ROLLBACK") || containsQuery(executed, "COMMIT") {
+		t.Fatalf("expected a rollback without commit, executed %v", executed)
+	}
+
+	svc = auditImportTestService(t, map[string]string{"users.csv": "id\n1\n"}, nil)
+	_, err = svc.ImportAuditSnapshot(context.Background(), model.AuditImportRequest{TargetID: "local", DBName: "test_db", Snapshot: "../etc"})
+	if apiErr, ok := err.(*model.APIError); !ok || apiErr.Code != model.CodeInvalidArgument {
+		t.Fatalf("expected INVALID_ARGUMENT for snapshot name, got %v", err)
+	}
+}
+
+func TestReconcileAudit_ReportsDifferencesAndLastImport(t *testing.T) {
+	importMsg := footer.BuildImportFooter("[audit-import] snapshot", footer.ImportFooter{
+		Schema: "v1", Snapshot: "2026-10-01", Tables: []string{"users"}, Rows: 3, ImportedAt: "2026-10-01T00:00:00Z",
+	})
+	repo := newRecordingSessionRepo(t, func(refName, query string, args []driver.NamedValue) (testQueryResult, error) {
+		switch query {
+		case "SELECT DOLT_HASHOF('main'), DOLT_HASHOF('audit')":
+			return testQueryResult{columns: []string{"main", "audit"}, rows: [][]driver.Value{{"main-hash", "audit-hash"}}}, nil
+		case "SELECT commit_hash, message FROM dolt_log('audit') ORDER BY date DESC LIMIT 200":
+			return testQueryResult{columns: []string{"commit_hash", "message"}, rows: [][]driver.Value{
+				{"hash-manual", "manual fix"},
+				{"hash-import", importMsg},
+			}}, nil
+		case "SELECT table_name, rows_deleted, rows_added, rows_modified, cells_modified FROM DOLT_DIFF_STAT('main', 'audit')":
+			return testQueryResult{
+				columns: []string{"table_name", "rows_deleted", "rows_added", "rows_modified", "cells_modified"},
+				rows: [][]driver.Value{
+					{"users", int64(1), int64(2), int64(3), int64(4)},
+					{"_memo_users", int64(5), int64(0), int64(0), int64(0)},
+					{"orders", int64(0), int64(0), int64(0), int64(0)},
+				},
+			}, nil
+		}
+		return testQueryResult{}, fmt.Errorf("unexpected query on %s: %s", refName, query)
+	})
+	svc := newWithDeps(repo, testServiceConfig())
+
+	resp, err := svc.ReconcileAudit(context.Background(), "local", "test_db")
+	if err != nil {
+		t.Fatalf("ReconcileAudit: %v", err)
+	}
+	if resp.MainHash != "main-hash" || resp.AuditHash != "audit-hash" {
+		t.Fatalf("unexpected hashes: %+v", resp)
+	}
+	if resp.LastImport == nil || resp.LastImport.Hash != "hash-import" || resp.LastImport.Snapshot != "2026-10-01" {
+		t.Fatalf("unexpected last import: %+v", resp.LastImport)
+	}
+	want := model.AuditReconcileTable{Table: "users", OnlyInMain: 1, OnlyInAudit: 2, DifferingRows: 3, DifferingCells: 4}
+	if len(resp.Tables) != 1 || resp.Tables[0] != want {
+		t.Fatalf("unexpected tables: %+v", resp.Tables)
+	}
+}
+
+func TestExportAuditReconcileZip_StreamsEveryRowAndRecordsFailures(t *testing.T) {
+	diffCols := []string{"to_id", "to_name", "to_commit", "to_commit_date", "from_id", "from_name", "from_commit", "from_commit_date", "diff_type"}
+	diffRows := [][]driver.Value{
+		{nil, nil, "c", nil, int64(1), "Ann", "c", nil, "removed"},
+		{int64(2), "Bob", "c", nil, nil, nil, "c", nil, "added"},
+		{int64(3), "Cara", "c", nil, int64(3), "Carol", "c", nil, "modified"},
+	}
+	repo := newRecordingSessionRepo(t, func(refName, query string, args []driver.NamedValue) (testQueryResult, error) {
+		switch {
+		case query == "SELECT DOLT_HASHOF('main'), DOLT_HASHOF('audit')":
+			return testQueryResult{columns: []string{"main", "audit"}, rows: [][]driver.Value{{"mainhash", "audithash"}}}, nil
+		case strings.HasPrefix(query, "SELECT commit_hash, message FROM dolt_log('audit')"):
+			return testQueryResult{columns: []string{"commit_hash", "message"}}, nil
+		case strings.HasPrefix(query, "SELECT table_name, rows_deleted, rows_added, rows_modified, cells_modified FROM DOLT_DIFF_STAT"):
+			return testQueryResult{
+				columns: []string{"table_name", "rows_deleted", "rows_added", "rows_modified", "cells_modified"},
+				rows:    [][]driver.Value{{"broken", int64(1), int64(0), int64(0), int64(0)}, {"users", int64(1), int64(1), int64(1), int64(1)}},
+			}, nil
+		case strings.Contains(query, "'broken'"):
+			return testQueryResult{}, errors.New("table broken: diff unavailable")
+		case query == "SELECT * FROM DOLT_DIFF('mainhash', 'audithash', 'users') LIMIT 0":
+			return testQueryResult{columns: diffCols}, nil
+		case query == "SHOW COLUMNS FROM `users`":
+			return schemaResult([]model.ColumnSchema{{Name: "id", Type: "int", PrimaryKey: true}, {Name: "name", Type: "varchar(50)"}}), nil
+		case strings.HasPrefix(query, "SELECT * FROM DOLT_DIFF('mainhash', 'audithash', 'users') WHERE diff_type = ?"):
+			result := testQueryResult{columns: diffCols}
+			if len(args) == 1 {
+				for _, row := range diffRows {
+					if row[8] == args[0].Value {
+						result.rows = append(result.rows, row)
+					}
+				}
+			}
+			return result, nil
+		}
+		return testQueryResult{}, fmt.Errorf("unexpected query on %s: %s", refName, query)
+	})
+	svc := newWithDeps(repo, testServiceConfig())
+
+	var buf bytes.Buffer
+	var zipName string
+	err := svc.ExportAuditReconcileZip(context.Background(), "local", "test_db", func(name string) io.Writer {
+		zipName = name
+		return &buf
+	})
+	if err != nil {
+		t.Fatalf("ExportAuditReconcileZip: %v", err)
+	}
+	if zipName != "reconcile-main-audit-audithash.zip" {
+		t.Fatalf("zip name = %q", zipName)
+	}
+	files := readZipEntries(t, buf.Bytes())
+	want := map[string]string{
+		"users_only_main.csv":       "id,name\n1,Ann\n",
+		"users_only_audit.csv":      "id,name\n2,Bob\n",
+		"users_differing_cells.csv": "id,column,main_value,audit_value\n3,name,Carol,Cara\n",
+	}
+	for name, content := range want {
+		if files[name] != content {
+			t.Fatalf("%s = %q, want %q", name, files[name], content)
+		}
+	}
+	var manifest model.DiffExportManifest
+	if err := json.Unmarshal([]byte(files["manifest.json"]), &manifest); err != nil {
+		t.Fatalf("manifest: %v", err)
+	}
+	if manifest.Complete || len(manifest.Files) != 4 || manifest.Files[0].Table != "broken" || !strings.Contains(manifest.Files[0].Error, "diff unavailable") {
+		t.Fatalf("expected the broken table in the manifest, got %+v", manifest)
+	}
+}
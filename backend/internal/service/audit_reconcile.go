@@ -0,0 +1,244 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/footer"
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/validation"
+)
+
+// ReconcileAudit compares main with the audit snapshot. Per table it counts rows
+// only in main, rows only in audit, and rows (and cells) whose values differ.
+func (s *Service) ReconcileAudit(ctx context.Context, targetID, dbName string) (*model.AuditReconcileResponse, error) {
+	if err := s.ensureHistoryRef(ctx, targetID, dbName, "audit"); err != nil {
+		return nil, err
+	}
+	conn, err := s.connMetadataRevision(ctx, targetID, dbName)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	resp := &model.AuditReconcileResponse{}
+	if err := conn.QueryRowContext(ctx, "SELECT DOLT_HASHOF('main'), DOLT_HASHOF('audit')").Scan(&resp.MainHash, &resp.AuditHash); err != nil {
+		return nil, fmt.Errorf("failed to resolve main and audit: %w", err)
+	}
+	if resp.LastImport, err = lastAuditImport(ctx, conn); err != nil {
+		return nil, err
+	}
+	if resp.Tables, err = auditReconcileTables(ctx, conn); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func auditReconcileTables(ctx context.Context, conn *sql.Conn) ([]model.AuditReconcileTable, error) {
+	// Per v6f spec 1.4, DOLT_DIFF_STAT takes literal refs; both are constants here.
+	rows, err := conn.QueryContext(ctx,
+		"SELECT table_name, rows_deleted, rows_added, rows_modified, cells_modified FROM DOLT_DIFF_STAT('main', 'audit')")
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare main and audit: %w", err)
+	}
+	defer rows.Close()
+
+	tables := make([]model.AuditReconcileTable, 0)
+	for rows.Next() {
+		var t model.AuditReconcileTable
+		if err := rows.Scan(&t.Table, &t.OnlyInMain, &t.OnlyInAudit, &t.DifferingRows, &t.DifferingCells); err != nil {
+			return nil, fmt.Errorf("failed to scan comparison: %w", err)
+		}
+		if isHiddenTableName(t.Table) || t.OnlyInMain+t.OnlyInAudit+t.DifferingRows == 0 {
+			continue
+		}
+		tables = append(tables, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read comparison: %w", err)
+	}
+	sort.Slice(tables, func(i, j int) bool { return tables[i].Table < tables[j].Table })
+	return tables, nil
+}
+
+// lastAuditImport finds the newest audit commit carrying an import footer.
+func lastAuditImport(ctx context.Context, conn *sql.Conn) (*model.AuditImportInfo, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT commit_hash, message FROM dolt_log('audit') ORDER BY date DESC LIMIT 200")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit log: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var hash, message string
+		if err := rows.Scan(&hash, &message); err != nil {
+			return nil, fmt.Errorf("failed to scan audit log: %w", err)
+		}
+		f, err := footer.ParseImportFooter(message)
+		if err != nil {
+			return nil, historyIntegrityError(hash, err)
+		}
+		if f != nil {
+			return &model.AuditImportInfo{
+				Hash:       hash,
+				Snapshot:   f.Snapshot,
+				Tables:     f.Tables,
+				Rows:       f.Rows,
+				ImportedAt: f.ImportedAt,
+			}, nil
+		}
+	}
+	return nil, rows.Err()
+}
+
+// ExportAuditReconcileZip streams the reconciliation as CSV files per table:
+// <table>_only_main.csv, <table>_only_audit.csv and <table>_differing_cells.csv
+// (one line per differing cell, keyed by primary key). Rows are read from
+// DOLT_DIFF between the resolved main and audit commits with keyset paging, so
+// there is no row cap. As with ExportDiffZip, a failed file is recorded in
+// manifest.json, written last, and open is called once the up-front checks pass.
+func (s *Service) ExportAuditReconcileZip(ctx context.Context, targetID, dbName string, open func(zipName string) io.Writer) error {
+	report, err := s.ReconcileAudit(ctx, targetID, dbName)
+	if err != nil {
+		return err
+	}
+	conn, err := s.connMetadataRevision(ctx, targetID, dbName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	auditConn, err := s.connHistoryRevision(ctx, targetID, dbName, "audit")
+	if err != nil {
+		return err
+	}
+	defer auditConn.Close()
+
+	sink := &diffExportSink{w: open(fmt.Sprintf("reconcile-main-audit-%s.zip", report.AuditHash))}
+	zw := zip.NewWriter(sink)
+	manifest := model.DiffExportManifest{
+		FromRef:  "main",
+		ToRef:    "audit",
+		Mode:     "two_dot",
+		Format:   model.DiffExportCSV,
+		Files:    make([]model.DiffExportFile, 0),
+		Complete: true,
+	}
+	record := func(file model.DiffExportFile, err error) {
+		if err != nil {
+			file.Error = err.Error()
+			manifest.Complete = false
+		}
+		manifest.Files = append(manifest.Files, file)
+	}
+
+	for _, table := range report.Tables {
+		entry := model.DiffSummaryEntry{Table: table.Table, Added: table.OnlyInAudit, Modified: table.DifferingRows, Removed: table.OnlyInMain}
+		var t *diffExportTable
+		err := validation.ValidateIdentifier("table", entry.Table)
+		if err == nil {
+			t, err = loadDiffExportTable(ctx, conn, auditConn, conn, report.MainHash, report.AuditHash, entry)
+		}
+		if err != nil {
+			record(model.DiffExportFile{Table: entry.Table}, err)
+			continue
+		}
+		for _, part := range []struct {
+			diffType string
+			suffix   string
+		}{{"removed", "only_main"}, {"added", "only_audit"}, {"modified", "differing_cells"}} {
+			if diffExportCount(entry, part.diffType) == 0 {
+				continue
+			}
+			name := fmt.Sprintf("%s_%s.csv", entry.Table, part.suffix)
+			fw, err := zw.Create(name)
+			if err != nil {
+				return err
+			}
+			n, err := writeAuditReconcileCSV(ctx, fw, conn, report.MainHash, report.AuditHash, t, part.diffType)
+			record(model.DiffExportFile{Name: name, Table: entry.Table, DiffType: part.diffType, Rows: n}, err)
+			if sink.err != nil {
+				return sink.err
+			}
+		}
+	}
+
+	mw, err := zw.Create("manifest.json")
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(mw)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(manifest); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// writeAuditReconcileCSV writes the rows of one diff type: the main side of
+// rows only in main, the audit side of rows only in audit, and for modified
+// rows pk columns, column, main_value, audit_value for every differing cell.
+// It returns the number of diff rows read.
+func writeAuditReconcileCSV(ctx context.Context, w io.Writer, conn *sql.Conn, mainRef, auditRef string, t *diffExportTable, diffType string) (int, error) {
+	cw := csv.NewWriter(w)
+	var header []string
+	isPK := make(map[string]bool, len(t.pk))
+	for _, pk := range t.pk {
+		isPK[pk] = true
+	}
+	switch diffType {
+	case "modified":
+		header = append(append(header, t.pk...), "column", "main_value", "audit_value")
+	default:
+		for _, col := range t.cols {
+			if diffType == "removed" && t.fromCols[col] || diffType == "added" && t.toCols[col] {
+				header = append(header, col)
+			}
+		}
+	}
+	if err := cw.Write(header); err != nil {
+		return 0, err
+	}
+
+	n, err := scanDiffExportRows(ctx, conn, mainRef, auditRef, t, diffType, func(row diffExportRow) error {
+		switch diffType {
+		case "removed", "added":
+			side := row.from
+			if diffType == "added" {
+				side = row.to
+			}
+			values := make([]string, len(header))
+			for i, col := range header {
+				values[i] = diffExportText(side[col])
+			}
+			return cw.Write(values)
+		}
+		key := make([]string, len(t.pk))
+		for i, pk := range t.pk {
+			key[i] = diffExportText(row.to[pk])
+		}
+		for _, col := range t.cols {
+			if isPK[col] {
+				continue
+			}
+			mainValue, auditValue := row.from[col], row.to[col]
+			if (mainValue == nil) == (auditValue == nil) && diffExportText(mainValue) == diffExportText(auditValue) {
+				continue
+			}
+			if err := cw.Write(append(append([]string{}, key...), col, diffExportText(mainValue), diffExportText(auditValue))); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	cw.Flush()
+	if err == nil {
+		err = cw.Error()
+	}
+	return n, err
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// branchBaseTagPrefix marks the fork point of work branches. The tag
+// base/<WorkItem> points at the fork commit and carries branchBaseMeta as JSON.
+// Branches created from main are recorded too (FromRef "main"), only so their
+// creation time is known; readBranchBase and listBranchBases skip them.
+const branchBaseTagPrefix = "base/"
+
+const branchBaseMainRef = "main"
+
+type branchBaseMeta struct {
+	Schema    string `json:"schema"`
+	FromRef   string `json:"from_ref"`
+	FromHash  string `json:"from_hash"`
+	CreatedAt string `json:"created_at"`
+}
+
+// recordBranchBase replaces base/<WorkItem> with the fork point of branchName.
+func recordBranchBase(ctx context.Context, conn *sql.Conn, branchName, fromRef, fromHash string) error {
+	workItem, ok := workItemFromWorkBranch(branchName)
+	if !ok {
+		return nil
+	}
+	tagName := baseTagForWorkItem(workItem)
+	message, err := json.Marshal(branchBaseMeta{
+		Schema:    "dolt-webui/branch-base@1",
+		FromRef:   fromRef,
+		FromHash:  fromHash,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal branch base: %w", err)
+	}
+	if err := dropBranchBase(ctx, conn, branchName); err != nil {
+		return err
+	}
+	if _, err := conn.ExecContext(ctx, "CALL DOLT_TAG('-m', ?, ?, ?)", string(message), tagName, fromHash); err != nil {
+		return fmt.Errorf("failed to record branch base: %w", err)
+	}
+	return nil
+}
+
+// readBranchBase returns the recorded fork point, or nil for branches created from main.
+func readBranchBase(ctx context.Context, conn *sql.Conn, branchName string) (*branchBaseMeta, error) {
+	workItem, ok := workItemFromWorkBranch(branchName)
+	if !ok {
+		return nil, nil
+	}
+	var message string
+	err := conn.QueryRowContext(ctx, "SELECT message FROM dolt_tags WHERE tag_name = ?", baseTagForWorkItem(workItem)).Scan(&message)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read branch base: %w", err)
+	}
+	var meta branchBaseMeta
+	if err := json.Unmarshal([]byte(message), &meta); err != nil {
+		log.Printf("WARN: failed to parse branch base tag for %s: %v", branchName, err)
+		return nil, nil
+	}
+	if meta.FromRef == branchBaseMainRef {
+		return nil, nil
+	}
+	return &meta, nil
+}
+
+// dropBranchBase removes base/<WorkItem> if present.
+func dropBranchBase(ctx context.Context, conn *sql.Conn, branchName string) error {
+	workItem, ok := workItemFromWorkBranch(branchName)
+	if !ok {
+		return nil
+	}
+	tagName := baseTagForWorkItem(workItem)
+	var count int
+	if err := conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM dolt_tags WHERE tag_name = ?", tagName).Scan(&count); err != nil || count == 0 {
+		return nil
+	}
+	if _, err := conn.ExecContext(ctx, "CALL DOLT_TAG('-d', ?)", tagName); err != nil {
+		return fmt.Errorf("failed to delete branch base tag: %w", err)
+	}
+	return nil
+}
+
+// listBranchBases maps work branch name → recorded fork point, for branches
+// created from a historical ref.
+func listBranchBases(ctx context.Context, conn *sql.Conn) (map[string]branchBaseMeta, error) {
+	bases, err := listBranchBaseRecords(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	for name, meta := range bases {
+		if meta.FromRef == branchBaseMainRef {
+			delete(bases, name)
+		}
+	}
+	return bases, nil
+}
+
+// listBranchBaseRecords maps work branch name → every recorded base tag,
+// including branches created from main.
+func listBranchBaseRecords(ctx context.Context, conn *sql.Conn) (map[string]branchBaseMeta, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT tag_name, message FROM dolt_tags WHERE tag_name LIKE ?", branchBaseTagPrefix+"%")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query branch bases: %w", err)
+	}
+	defer rows.Close()
+
+	bases := make(map[string]branchBaseMeta)
+	for rows.Next() {
+		var tagName, message string
+		if err := rows.Scan(&tagName, &message); err != nil {
+			return nil, fmt.Errorf("failed to scan branch base: %w", err)
+		}
+		var meta branchBaseMeta
+		if err := json.Unmarshal([]byte(message), &meta); err != nil {
+			log.Printf("WARN: failed to parse branch base tag %s: %v", tagName, err)
+			continue
+		}
+		bases["wi/"+strings.TrimPrefix(tagName, branchBaseTagPrefix)] = meta
+	}
+	return bases, rows.Err()
+}
+
+// commitsBehindMain counts main commits not yet reachable from the session HEAD.
+func commitsBehindMain(ctx context.Context, conn *sql.Conn) (int, error) {
+	var behind int
+	if err := conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM dolt_log('main', '--not', 'HEAD')").Scan(&behind); err != nil {
+		return 0, fmt.Errorf("failed to count commits behind main: %w", err)
+	}
+	return behind, nil
+}
+
+func behindMainWarning(behind int, base *branchBaseMeta) string {
+	if base == nil || behind == 0 {
+		return ""
+	}
+	return fmt.Sprintf("このブランチは %s から作成され、main より %d コミット遅れていました。main の変更を取り込んでから申請しています。", base.FromRef, behind)
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+)
+
+func TestCreateBranch_FromRefRecordsBaseTag(t *testing.T) {
+	const fromRef = "merged/hotfix/01"
+	var branchArgs, tagArgs []driver.NamedValue
+	repo := newCrossCopyTestRepo(t,
+		func(dbName, refName, query string, args []driver.NamedValue) (testQueryResult, error) {
+			switch query {
+			case "SELECT COUNT(*) FROM dolt_branches WHERE name = ?":
+				return testQueryResult{columns: []string{"count"}, rows: [][]driver.Value{{int64(0)}}}, nil
+			case "SELECT COUNT(*) FROM dolt_tags WHERE tag_name = ?":
+				return testQueryResult{columns: []string{"count"}, rows: [][]driver.Value{{int64(1)}}}, nil
+			}
+			return testQueryResult{}, fmt.Errorf("unexpected revision query: %s", query)
+		},
+		nil,
+	)
+	repo.protectedHandler = func(dbName, branchName, query string, args []driver.NamedValue) (testQueryResult, error) {
+		switch query {
+		case "SELECT DOLT_HASHOF(?)":
+			return testQueryResult{columns: []string{"hash"}, rows: [][]driver.Value{{"fork-hash"}}}, nil
+		case "CALL DOLT_BRANCH(?, ?)":
+			branchArgs = args
+			return testQueryResult{}, nil
+		case "SELECT COUNT(*) FROM dolt_tags WHERE tag_name = ?":
+			return testQueryResult{columns: []string{"count"}, rows: [][]driver.Value{{int64(0)}}}, nil
+		case "CALL DOLT_TAG('-m', ?, ?, ?)":
+			tagArgs = args
+			return testQueryResult{}, nil
+		}
+		return testQueryResult{}, fmt.Errorf("unexpected protected query: %s", query)
+	}
+	svc := newWithDeps(repo, testServiceConfig())
+	svc.branchReadinessProbe = func(ctx context.Context, targetID, dbName, branch string) branchQueryabilityResult {
+		return branchQueryabilityResult{Ready: true, Attempts: 1}
+	}
+
+	err := svc.CreateBranch(context.Background(), model.CreateBranchRequest{
+		TargetID: "local", DBName: "test_db", BranchName: "wi/hotfix", FromRef: fromRef,
+	})
+	if err != nil {
+		t.Fatalf("CreateBranch: %v", err)
+	}
+	if len(branchArgs) != 2 || branchArgs[0].Value != "wi/hotfix" || branchArgs[1].Value != "fork-hash" {
+		t.Fatalf("unexpected DOLT_BRANCH args: %v", branchArgs)
+	}
+	if len(tagArgs) != 3 || tagArgs[1].Value != "base/hotfix" || tagArgs[2].Value != "fork-hash" {
+		t.Fatalf("unexpected DOLT_TAG args: %v", tagArgs)
+	}
+	var meta branchBaseMeta
+	if err := json.Unmarshal([]byte(tagArgs[0].Value.(string)), &meta); err != nil {
+		t.Fatalf("base tag message: %v", err)
+	}
+	if meta.FromRef != fromRef || meta.FromHash != "fork-hash" {
+		t.Fatalf("unexpected base meta: %+v", meta)
+	}
+}
+
+func TestBehindMainWarning_OnlyForHistoricalBranches(t *testing.T) {
+	if got := behindMainWarning(3, nil); got != "" {
+		t.Fatalf("expected no warning for main-based branch, got %q", got)
+	}
+	base := &branchBaseMeta{FromRef: "merged/hotfix/01"}
+	if got := behindMainWarning(0, base); got != "" {
+		t.Fatalf("expected no warning when up to date, got %q", got)
+	}
+	if got := behindMainWarning(3, base); !strings.Contains(got, "merged/hotfix/01") || !strings.Contains(got, "3 コミット") {
+		t.Fatalf("unexpected warning: %q", got)
+	}
+}
@@ -15,7 +15,7 @@ import (
 	"github.com/Makeinu1/dolt-web-ui/backend/internal/repository"
 )
 
-func newIntegrationService(t *testing.T) *Service {
+func newIntegrationService(t testing.TB) *Service {
 	t.Helper()
 
 	cfgPath := filepath.Join(testRepoRoot(), "config.test.yaml")
@@ -33,19 +33,19 @@ func newIntegrationService(t *testing.T) *Service {
 	return New(repo, cfg)
 }
 
-func integrationContext(t *testing.T) context.Context {
+func integrationContext(t testing.TB) context.Context {
 	t.Helper()
 	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
 	t.Cleanup(cancel)
 	return ctx
 }
 
-func uniqueWorkBranch(t *testing.T, prefix string) string {
+func uniqueWorkBranch(t testing.TB, prefix string) string {
 	t.Helper()
 	return fmt.Sprintf("wi/%s-%d", prefix, time.Now().UnixNano())
 }
 
-func mustHeadInDB(t *testing.T, svc *Service, dbName, branchName string) string {
+func mustHeadInDB(t testing.TB, svc *Service, dbName, branchName string) string {
 	t.Helper()
 	head, err := svc.GetHead(integrationContext(t), "local", dbName, branchName)
 	if err != nil {
@@ -54,7 +54,7 @@ func mustHeadInDB(t *testing.T, svc *Service, dbName, branchName string) string
 	return head.Hash
 }
 
-func mustHead(t *testing.T, svc *Service, branchName string) string {
+func mustHead(t testing.TB, svc *Service, branchName string) string {
 	t.Helper()
 	return mustHeadInDB(t, svc, "test_db", branchName)
 }
@@ -0,0 +1,345 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/validation"
+)
+
+// beginWorkBranchRewrite opens a write session on a work branch for undo, squash
+// or cherry-pick: lock check, START TRANSACTION, and the in-TX expected_head check.
+// On success the caller owns conn and must COMMIT or safeRollback.
+func (s *Service) beginWorkBranchRewrite(ctx context.Context, targetID, dbName, branchName, expectedHead string) (*sql.Conn, error) {
+	if validation.IsProtectedBranch(branchName) {
+		return nil, &model.APIError{Status: 403, Code: model.CodeForbidden, Msg: "write operations on protected branch are forbidden"}
+	}
+	if expectedHead == "" {
+		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "expected_head is required"}
+	}
+
+	conn, err := s.connAllowedWorkBranchWrite(ctx, targetID, dbName, branchName)
+	if err != nil {
+		return nil, err
+	}
+
+	if apiErr := checkBranchLocked(ctx, conn, branchName); apiErr != nil {
+		conn.Close()
+		return nil, apiErr
+	}
+
+	if _, err := conn.ExecContext(ctx, "START TRANSACTION"); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	var currentHead string
+	if err := conn.QueryRowContext(ctx, "SELECT DOLT_HASHOF('HEAD')").Scan(&currentHead); err != nil {
+		safeRollback(conn)
+		conn.Close()
+		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	if currentHead != expectedHead {
+		safeRollback(conn)
+		conn.Close()
+		return nil, &model.APIError{
+			Status:  409,
+			Code:    model.CodeStaleHead,
+			Msg:     "expected_head mismatch",
+			Details: map[string]string{"expected_head": expectedHead, "actual_head": currentHead},
+		}
+	}
+	return conn, nil
+}
+
+// finishWorkBranchRewrite commits the SQL transaction and returns the new HEAD.
+func finishWorkBranchRewrite(ctx context.Context, conn *sql.Conn) (string, error) {
+	if _, err := conn.ExecContext(ctx, "COMMIT"); err != nil {
+		return "", fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	var newHead string
+	if err := conn.QueryRowContext(ctx, "SELECT DOLT_HASHOF('HEAD')").Scan(&newHead); err != nil {
+		return "", fmt.Errorf("failed to get new HEAD: %w", err)
+	}
+	return newHead, nil
+}
+
+// requireCleanWorkingSet fails with 412 working_set_dirty when the branch has
+// uncommitted changes, which a history rewrite would either discard or sweep in.
+func requireCleanWorkingSet(ctx context.Context, conn *sql.Conn, head, action string) error {
+	var dirtyTables int
+	if err := conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM dolt_status").Scan(&dirtyTables); err != nil {
+		return fmt.Errorf("failed to read working set status: %w", err)
+	}
+	if dirtyTables > 0 {
+		return &model.APIError{
+			Status:  412,
+			Code:    model.CodePreconditionFailed,
+			Msg:     "未コミットの変更があるため" + action + "。先にコミットするか破棄してください。",
+			Details: map[string]string{"reason": "working_set_dirty", "head": head},
+		}
+	}
+	return nil
+}
+
+// isAncestorCommit reports whether ancestor is reachable from descendant.
+func isAncestorCommit(ctx context.Context, conn *sql.Conn, ancestor, descendant string) (bool, error) {
+	var base sql.NullString
+	if err := conn.QueryRowContext(ctx, "SELECT DOLT_MERGE_BASE(?, ?)", ancestor, descendant).Scan(&base); err != nil {
+		return false, fmt.Errorf("failed to resolve merge base: %w", err)
+	}
+	if !base.Valid {
+		return false, nil
+	}
+	var ancestorHash string
+	if err := conn.QueryRowContext(ctx, "SELECT DOLT_HASHOF(?)", ancestor).Scan(&ancestorHash); err != nil {
+		return false, fmt.Errorf("failed to resolve %s: %w", ancestor, err)
+	}
+	return base.String == ancestorHash, nil
+}
+
+func branchRewriteCompleted(hash, previousHead, message string) *model.BranchRewriteResponse {
+	return &model.BranchRewriteResponse{
+		Hash:         hash,
+		PreviousHead: previousHead,
+		OperationResultFields: model.OperationResultFields{
+			Outcome: model.OperationOutcomeCompleted,
+			Message: message,
+			Completion: map[string]bool{
+				"branch_rewritten": hash != previousHead,
+			},
+		},
+	}
+}
+
+// UndoCommit drops the HEAD commit of a work branch (DOLT_RESET --hard HEAD~1).
+// Commits already reachable from main cannot be undone; reverting those belongs
+// in a new commit instead.
+func (s *Service) UndoCommit(ctx context.Context, req model.UndoCommitRequest) (*model.BranchRewriteResponse, error) {
+	conn, err := s.beginWorkBranchRewrite(ctx, req.TargetID, req.DBName, req.BranchName, req.ExpectedHead)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// DOLT_RESET --hard would discard uncommitted changes along with the commit.
+	if err := requireCleanWorkingSet(ctx, conn, req.ExpectedHead, "コミットを取り消せません"); err != nil {
+		safeRollback(conn)
+		return nil, err
+	}
+
+	onMain, err := isAncestorCommit(ctx, conn, "HEAD", "main")
+	if err != nil {
+		safeRollback(conn)
+		return nil, err
+	}
+	if onMain {
+		safeRollback(conn)
+		return nil, &model.APIError{
+			Status:  412,
+			Code:    model.CodePreconditionFailed,
+			Msg:     "main に取り込み済みのコミットは取り消せません。",
+			Details: map[string]string{"reason": "commit_on_main", "head": req.ExpectedHead},
+		}
+	}
+
+	if _, err := conn.ExecContext(ctx, "CALL DOLT_RESET('--hard', 'HEAD~1')"); err != nil {
+		safeRollback(conn)
+		return nil, fmt.Errorf("failed to reset: %w", err)
+	}
+
+	newHead, err := finishWorkBranchRewrite(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	return branchRewriteCompleted(newHead, req.ExpectedHead, "直前のコミットを取り消しました"), nil
+}
+
+// SquashBranch collapses all work-branch commits since the merge base with main
+// into a single commit. Sync merges from main are folded in as well; the result
+// has the merge base as its only parent and the same tree as the old HEAD.
+// Only committed history is squashed: a branch with uncommitted changes is refused
+// so they are not swept into the new commit.
+func (s *Service) SquashBranch(ctx context.Context, req model.SquashRequest) (*model.BranchRewriteResponse, error) {
+	if strings.TrimSpace(req.CommitMessage) == "" {
+		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "commit_message is required"}
+	}
+
+	conn, err := s.beginWorkBranchRewrite(ctx, req.TargetID, req.DBName, req.BranchName, req.ExpectedHead)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := requireCleanWorkingSet(ctx, conn, req.ExpectedHead, "コミットをまとめられません"); err != nil {
+		safeRollback(conn)
+		return nil, err
+	}
+
+	var mergeBase string
+	if err := conn.QueryRowContext(ctx, "SELECT DOLT_MERGE_BASE('HEAD', 'main')").Scan(&mergeBase); err != nil {
+		safeRollback(conn)
+		return nil, fmt.Errorf("failed to resolve merge base: %w", err)
+	}
+
+	var commitCount int
+	if err := conn.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM dolt_log('HEAD', '--not', ?)", mergeBase,
+	).Scan(&commitCount); err != nil {
+		safeRollback(conn)
+		return nil, fmt.Errorf("failed to count commits since merge base: %w", err)
+	}
+	if commitCount <= 1 {
+		safeRollback(conn)
+		return branchRewriteCompleted(req.ExpectedHead, req.ExpectedHead, "まとめるコミットがありません"), nil
+	}
+
+	// Move HEAD to the merge base while keeping the working set, then record it as one commit.
+	if _, err := conn.ExecContext(ctx, "CALL DOLT_RESET('--soft', ?)", mergeBase); err != nil {
+		safeRollback(conn)
+		return nil, fmt.Errorf("failed to reset to merge base: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, "CALL DOLT_ADD('-A')"); err != nil {
+		safeRollback(conn)
+		return nil, fmt.Errorf("failed to add: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, "CALL DOLT_COMMIT('--allow-empty', '-m', ?)", req.CommitMessage); err != nil {
+		safeRollback(conn)
+		return nil, fmt.Errorf("failed to commit: %w", err)
+	}
+
+	newHead, err := finishWorkBranchRewrite(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	return branchRewriteCompleted(newHead, req.ExpectedHead, fmt.Sprintf("%d 件のコミットを 1 件にまとめました", commitCount)), nil
+}
+
+// CherryPickCommits applies commits from another work branch, in the given order.
+// Each hash must be reachable from source_branch and not already on main.
+// Conflicts abort the whole operation; nothing is applied.
+func (s *Service) CherryPickCommits(ctx context.Context, req model.CherryPickRequest) (*model.BranchRewriteResponse, error) {
+	if len(req.CommitHashes) == 0 {
+		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "commit_hashes must not be empty"}
+	}
+	if !isWorkBranchName(req.SourceBranch) {
+		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "source_branch must be a wi/* work branch"}
+	}
+	if req.SourceBranch == req.BranchName {
+		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "source_branch must differ from branch_name"}
+	}
+	if err := s.ensureAllowedBranchRef(req.TargetID, req.DBName, req.SourceBranch); err != nil {
+		return nil, err
+	}
+	for _, hash := range req.CommitHashes {
+		if !commitHashRefRe.MatchString(hash) {
+			return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: fmt.Sprintf("invalid commit hash: %s", hash)}
+		}
+	}
+
+	conn, err := s.beginWorkBranchRewrite(ctx, req.TargetID, req.DBName, req.BranchName, req.ExpectedHead)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	for _, hash := range req.CommitHashes {
+		onSource, err := isAncestorCommit(ctx, conn, hash, req.SourceBranch)
+		if err != nil {
+			safeRollback(conn)
+			return nil, err
+		}
+		if !onSource {
+			safeRollback(conn)
+			return nil, &model.APIError{
+				Status:  400,
+				Code:    model.CodeInvalidArgument,
+				Msg:     fmt.Sprintf("commit %s is not on %s", hash, req.SourceBranch),
+				Details: map[string]string{"reason": "commit_not_on_source", "commit_hash": hash},
+			}
+		}
+		onMain, err := isAncestorCommit(ctx, conn, hash, "main")
+		if err != nil {
+			safeRollback(conn)
+			return nil, err
+		}
+		if onMain {
+			safeRollback(conn)
+			return nil, &model.APIError{
+				Status:  412,
+				Code:    model.CodePreconditionFailed,
+				Msg:     "main に取り込み済みのコミットは取り込めません。",
+				Details: map[string]string{"reason": "commit_on_main", "commit_hash": hash},
+			}
+		}
+	}
+
+	for _, hash := range req.CommitHashes {
+		if apiErr, err := cherryPickOne(ctx, conn, hash); apiErr != nil || err != nil {
+			safeRollback(conn)
+			if apiErr != nil {
+				return nil, apiErr
+			}
+			return nil, err
+		}
+	}
+
+	newHead, err := finishWorkBranchRewrite(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	return branchRewriteCompleted(newHead, req.ExpectedHead, fmt.Sprintf("%d 件のコミットを取り込みました", len(req.CommitHashes))), nil
+}
+
+// cherryPickOne runs DOLT_CHERRY_PICK and maps conflict counters to API errors.
+// Dolt returns (hash, data_conflicts, schema_conflicts, constraint_violations);
+// older servers return only the hash.
+func cherryPickOne(ctx context.Context, conn *sql.Conn, hash string) (*model.APIError, error) {
+	rows, err := conn.QueryContext(ctx, "CALL DOLT_CHERRY_PICK(?)", hash)
+	if err != nil {
+		if strings.Contains(strings.ToLower(err.Error()), "conflict") {
+			return &model.APIError{
+				Status:  409,
+				Code:    model.CodeMergeConflictsPresent,
+				Msg:     "コミットの取り込みで競合が発生しました",
+				Details: map[string]string{"commit_hash": hash},
+			}, nil
+		}
+		return nil, fmt.Errorf("failed to cherry-pick %s: %w", hash, err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cherry-pick result: %w", err)
+	}
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+	values := make([]sql.NullInt64, len(columns))
+	dest := make([]interface{}, len(columns))
+	var newHash sql.NullString
+	dest[0] = &newHash
+	for i := 1; i < len(columns); i++ {
+		dest[i] = &values[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return nil, fmt.Errorf("failed to scan cherry-pick result: %w", err)
+	}
+
+	codes := []string{"", model.CodeMergeConflictsPresent, model.CodeSchemaConflictsPresent, model.CodeConstraintViolationsPresent}
+	for i := 1; i < len(columns) && i < len(codes); i++ {
+		if values[i].Int64 > 0 {
+			return &model.APIError{
+				Status:  409,
+				Code:    codes[i],
+				Msg:     "コミットの取り込みで競合が発生しました",
+				Details: map[string]interface{}{"commit_hash": hash, columns[i]: values[i].Int64},
+			}, nil
+		}
+	}
+	return nil, nil
+}
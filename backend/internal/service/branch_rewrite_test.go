@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+)
+
+const rewriteTestPickHash = "0123456789abcdefghijklmnopqrstuv"
+
+// rewriteAncestry answers DOLT_MERGE_BASE/DOLT_HASHOF(?); "a..b" keys in reachable mark a as an ancestor of b.
+// The working set is reported clean.
+func rewriteAncestry(query string, args []driver.NamedValue, reachable map[string]bool) (testQueryResult, bool) {
+	switch query {
+	case "SELECT COUNT(*) FROM dolt_status":
+		return testQueryResult{columns: []string{"count"}, rows: [][]driver.Value{{int64(0)}}}, true
+	case "SELECT DOLT_MERGE_BASE(?, ?)":
+		pair := fmt.Sprintf("%v..%v", args[0].Value, args[1].Value)
+		base := "other"
+		if reachable[pair] {
+			base = fmt.Sprint(args[0].Value)
+		}
+		return testQueryResult{columns: []string{"base"}, rows: [][]driver.Value{{base}}}, true
+	case "SELECT DOLT_HASHOF(?)":
+		return testQueryResult{columns: []string{"hash"}, rows: [][]driver.Value{{args[0].Value}}}, true
+	}
+	return testQueryResult{}, false
+}
+
+func TestUndoCommit_RejectsCommitAlreadyOnMain(t *testing.T) {
+	var executed []string
+	repo := newCrossCopyTestRepo(t, nil, commitWorkHandler(&executed, func(query string, args []driver.NamedValue) (testQueryResult, error) {
+		if res, ok := rewriteAncestry(query, args, map[string]bool{"HEAD..main": true}); ok {
+			return res, nil
+		}
+		return testQueryResult{}, fmt.Errorf("unexpected query: %s", query)
+	}))
+	svc := newWithDeps(repo, testServiceConfig())
+
+	_, err := svc.UndoCommit(context.Background(), model.UndoCommitRequest{
+		TargetID: "local", DBName: "test_db", BranchName: "wi/undo", ExpectedHead: "head-1",
+	})
+	var apiErr *model.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != model.CodePreconditionFailed {
+		t.Fatalf("expected PRECONDITION_FAILED, got %v", err)
+	}
+	if containsQuery(executed, "CALL DOLT_RESET('--hard', 'HEAD~1')") || !containsQuery(executed, "ROLLBACK") {
+		t.Fatalf("expected rollback without reset, executed %v", executed)
+	}
+}
+
+func TestUndoCommit_RejectsDirtyWorkingSet(t *testing.T) {
+	var executed []string
+	repo := newCrossCopyTestRepo(t, nil, commitWorkHandler(&executed, func(query string, args []driver.NamedValue) (testQueryResult, error) {
+		if query == "SELECT COUNT(*) FROM dolt_status" {
+			return testQueryResult{columns: []string{"count"}, rows: [][]driver.Value{{int64(2)}}}, nil
+		}
+		return testQueryResult{}, fmt.Errorf("unexpected query: %s", query)
+	}))
+	svc := newWithDeps(repo, testServiceConfig())
+
+	_, err := svc.UndoCommit(context.Background(), model.UndoCommitRequest{
+		TargetID: "local", DBName: "test_db", BranchName: "wi/undo", ExpectedHead: "head-1",
+	})
+	var apiErr *model.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != model.CodePreconditionFailed || apiErr.Details.(map[string]string)["reason"] != "working_set_dirty" {
+		t.Fatalf("expected PRECONDITION_FAILED working_set_dirty, got %v", err)
+	}
+	if containsQuery(executed, "CALL DOLT_RESET('--hard', 'HEAD~1')") || !containsQuery(executed, "ROLLBACK") {
+		t.Fatalf("expected rollback without reset, executed %v", executed)
+	}
+}
+
+func TestUndoCommit_StaleHead(t *testing.T) {
+	var executed []string
+	repo := newCrossCopyTestRepo(t, nil, commitWorkHandler(&executed, func(query string, args []driver.NamedValue) (testQueryResult, error) {
+		return testQueryResult{}, fmt.Errorf("unexpected query: %s", query)
+	}))
+	svc := newWithDeps(repo, testServiceConfig())
+
+	_, err := svc.UndoCommit(context.Background(), model.UndoCommitRequest{
+		TargetID: "local", DBName: "test_db", BranchName: "wi/undo", ExpectedHead: "head-0",
+	})
+	var apiErr *model.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != model.CodeStaleHead {
+		t.Fatalf("expected STALE_HEAD, got %v", err)
+	}
+}
+
+func TestUndoCommit_ResetsToParent(t *testing.T) {
+	var executed []string
+	repo := newCrossCopyTestRepo(t, nil, commitWorkHandler(&executed, func(query string, args []driver.NamedValue) (testQueryResult, error) {
+		if res, ok := rewriteAncestry(query, args, nil); ok {
+			return res, nil
+		}
+		if query == "CALL DOLT_RESET('--hard', 'HEAD~1')" {
+			return testQueryResult{}, nil
+		}
+		return testQueryResult{}, fmt.Errorf("unexpected query: %s", query)
+	}))
+	svc := newWithDeps(repo, testServiceConfig())
+
+	resp, err := svc.UndoCommit(context.Background(), model.UndoCommitRequest{
+		TargetID: "local", DBName: "test_db", BranchName: "wi/undo", ExpectedHead: "head-1",
+	})
+	if err != nil {
+		t.Fatalf("UndoCommit: %v", err)
+	}
+	if resp.Outcome != model.OperationOutcomeCompleted || !containsQuery(executed, "COMMIT") {
+		t.Fatalf("unexpected result %+v, executed %v", resp, executed)
+	}
+}
+
+func TestSquashBranch_SingleCommitIsNoop(t *testing.T) {
+	var executed []string
+	repo := newCrossCopyTestRepo(t, nil, commitWorkHandler(&executed, func(query string, args []driver.NamedValue) (testQueryResult, error) {
+		switch query {
+		case "SELECT COUNT(*) FROM dolt_status":
+			return testQueryResult{columns: []string{"count"}, rows: [][]driver.Value{{int64(0)}}}, nil
+		case "SELECT DOLT_MERGE_BASE('HEAD', 'main')":
+			return testQueryResult{columns: []string{"base"}, rows: [][]driver.Value{{"base-1"}}}, nil
+		case "SELECT COUNT(*) FROM dolt_log('HEAD', '--not', ?)":
+			return testQueryResult{columns: []string{"count"}, rows: [][]driver.Value{{int64(1)}}}, nil
+		}
+		return testQueryResult{}, fmt.Errorf("unexpected query: %s", query)
+	}))
+	svc := newWithDeps(repo, testServiceConfig())
+
+	resp, err := svc.SquashBranch(context.Background(), model.SquashRequest{
+		TargetID: "local", DBName: "test_db", BranchName: "wi/squash", ExpectedHead: "head-1", CommitMessage: "squashed",
+	})
+	if err != nil {
+		t.Fatalf("SquashBranch: %v", err)
+	}
+	if resp.Hash != "head-1" || resp.Completion["branch_rewritten"] {
+		t.Fatalf("expected no-op, got %+v", resp)
+	}
+	if containsQuery(executed, "CALL DOLT_RESET('--soft', ?)") {
+		t.Fatalf("expected no reset, executed %v", executed)
+	}
+}
+
+func TestSquashBranch_RejectsDirtyWorkingSet(t *testing.T) {
+	var executed []string
+	repo := newCrossCopyTestRepo(t, nil, commitWorkHandler(&executed, func(query string, args []driver.NamedValue) (testQueryResult, error) {
+		if query == "SELECT COUNT(*) FROM dolt_status" {
+			return testQueryResult{columns: []string{"count"}, rows: [][]driver.Value{{int64(1)}}}, nil
+		}
+		return testQueryResult{}, fmt.Errorf("unexpected query: %s", query)
+	}))
+	svc := newWithDeps(repo, testServiceConfig())
+
+	_, err := svc.SquashBranch(context.Background(), model.SquashRequest{
+		TargetID: "local", DBName: "test_db", BranchName: "wi/squash", ExpectedHead: "head-1", CommitMessage: "squashed",
+	})
+	var apiErr *model.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != model.CodePreconditionFailed || apiErr.Details.(map[string]string)["reason"] != "working_set_dirty" {
+		t.Fatalf("expected PRECONDITION_FAILED working_set_dirty, got %v", err)
+	}
+	if containsQuery(executed, "CALL DOLT_RESET('--soft', ?)") || containsQuery(executed, "CALL DOLT_ADD('-A')") || !containsQuery(executed, "ROLLBACK") {
+		t.Fatalf("expected rollback without rewrite, executed %v", executed)
+	}
+}
+
+func TestCherryPickCommits_ConflictRollsBack(t *testing.T) {
+	var executed []string
+	repo := newCrossCopyTestRepo(t, nil, commitWorkHandler(&executed, func(query string, args []driver.NamedValue) (testQueryResult, error) {
+		if res, ok := rewriteAncestry(query, args, map[string]bool{rewriteTestPickHash + "..wi/source": true}); ok {
+			return res, nil
+		}
+		if query == "CALL DOLT_CHERRY_PICK(?)" {
+			return testQueryResult{
+				columns: []string{"hash", "data_conflicts", "schema_conflicts", "constraint_violations"},
+				rows:    [][]driver.Value{{"", int64(2), int64(0), int64(0)}},
+			}, nil
+		}
+		return testQueryResult{}, fmt.Errorf("unexpected query: %s", query)
+	}))
+	svc := newWithDeps(repo, testServiceConfig())
+
+	_, err := svc.CherryPickCommits(context.Background(), model.CherryPickRequest{
+		TargetID: "local", DBName: "test_db", BranchName: "wi/target", ExpectedHead: "head-1",
+		SourceBranch: "wi/source", CommitHashes: []string{rewriteTestPickHash},
+	})
+	var apiErr *model.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != model.CodeMergeConflictsPresent {
+		t.Fatalf("expected MERGE_CONFLICTS_PRESENT, got %v", err)
+	}
+	if !containsQuery(executed, "ROLLBACK") || containsQuery(executed, "COMMIT") {
+		t.Fatalf("expected rollback, executed %v", executed)
+	}
+}
+
+func TestCherryPickCommits_RejectsCommitNotOnSource(t *testing.T) {
+	var executed []string
+	repo := newCrossCopyTestRepo(t, nil, commitWorkHandler(&executed, func(query string, args []driver.NamedValue) (testQueryResult, error) {
+		if res, ok := rewriteAncestry(query, args, nil); ok {
+			return res, nil
+		}
+		return testQueryResult{}, fmt.Errorf("unexpected query: %s", query)
+	}))
+	svc := newWithDeps(repo, testServiceConfig())
+
+	_, err := svc.CherryPickCommits(context.Background(), model.CherryPickRequest{
+		TargetID: "local", DBName: "test_db", BranchName: "wi/target", ExpectedHead: "head-1",
+		SourceBranch: "wi/source", CommitHashes: []string{rewriteTestPickHash},
+	})
+	var apiErr *model.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != model.CodeInvalidArgument {
+		t.Fatalf("expected INVALID_ARGUMENT, got %v", err)
+	}
+}
@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/config"
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/validation"
+)
+
+type staleArchiveMeta struct {
+	Schema     string `json:"schema"`
+	Branch     string `json:"branch"`
+	IdleDays   int    `json:"idle_days"`
+	ArchivedAt string `json:"archived_at"`
+}
+
+// ListStaleBranches returns the work branches matched by the database's
+// stale_branches policy. It never modifies anything.
+func (s *Service) ListStaleBranches(ctx context.Context, targetID, dbName string) (*model.StaleBranchesResponse, error) {
+	db, err := s.stalePolicyDatabase(targetID, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := s.connMetadataRevision(ctx, targetID, dbName)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	branches, err := findStaleBranches(ctx, conn, db, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	return &model.StaleBranchesResponse{
+		IdleDays: db.StaleBranches.IdleDays,
+		Action:   db.StaleBranches.Action,
+		Branches: branches,
+	}, nil
+}
+
+// CleanupStaleBranches applies the stale_branches policy now. Archive tags the
+// branch head as archived/<WorkItem>/<date> before deleting it. Each branch is
+// re-checked for a pending request just before deletion.
+func (s *Service) CleanupStaleBranches(ctx context.Context, req model.CleanupStaleBranchesRequest) (*model.CleanupStaleBranchesResponse, error) {
+	db, err := s.stalePolicyDatabase(req.TargetID, req.DBName)
+	if err != nil {
+		return nil, err
+	}
+	action := req.Action
+	if action == "" {
+		action = db.StaleBranches.Action
+	}
+	if action != config.StaleActionDelete && action != config.StaleActionArchive {
+		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "action must be delete or archive"}
+	}
+
+	conn, err := s.repo.ConnProtectedMaintenance(ctx, req.TargetID, req.DBName, "main")
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close()
+
+	now := time.Now().UTC()
+	branches, err := findStaleBranches(ctx, conn, db, now)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]model.StaleBranchCleanupResult, 0, len(branches))
+	failed := 0
+	for _, branch := range branches {
+		result := model.StaleBranchCleanupResult{Name: branch.Name}
+		if err := s.cleanupStaleBranch(ctx, conn, req.TargetID, action, branch, now, &result); err != nil {
+			result.Error = err.Error()
+			failed++
+		} else {
+			result.Deleted = true
+		}
+		results = append(results, result)
+	}
+
+	resp := &model.CleanupStaleBranchesResponse{
+		Action:  action,
+		Results: results,
+		OperationResultFields: model.OperationResultFields{
+			Outcome: model.OperationOutcomeCompleted,
+			Message: fmt.Sprintf("%d stale branches cleaned up", len(results)-failed),
+			Completion: map[string]bool{
+				"branches_cleaned": failed == 0,
+			},
+		},
+	}
+	if failed > 0 {
+		resp.Outcome = model.OperationOutcomeRetryRequired
+		resp.Message = fmt.Sprintf("%d of %d stale branches could not be cleaned up", failed, len(results))
+		resp.RetryReason = "stale_cleanup_partial"
+		resp.RetryActions = []model.RetryAction{
+			{Action: "cleanup_stale_branches", Label: "Retry cleanup"},
+		}
+	}
+	return resp, nil
+}
+
+func (s *Service) cleanupStaleBranch(ctx context.Context, conn *sql.Conn, targetID, action string, branch model.StaleBranch, now time.Time, result *model.StaleBranchCleanupResult) error {
+	if apiErr := checkBranchLocked(ctx, conn, branch.Name); apiErr != nil {
+		return apiErr
+	}
+	if action == config.StaleActionArchive {
+		tagName, err := archiveStaleBranch(ctx, conn, branch, now)
+		if err != nil {
+			return err
+		}
+		result.ArchiveTag = tagName
+	}
+	return s.deleteWorkBranch(ctx, conn, targetID, branch.Name)
+}
+
+// archiveStaleBranch tags the branch head so it can be restored after deletion.
+// A numeric suffix is appended when the same item was archived earlier that day.
+func archiveStaleBranch(ctx context.Context, conn *sql.Conn, branch model.StaleBranch, now time.Time) (string, error) {
+	workItem, ok := workItemFromWorkBranch(branch.Name)
+	if !ok {
+		return "", fmt.Errorf("not a work branch: %s", branch.Name)
+	}
+	base := abandonedTagForWorkItem(workItem, now.Format("2006-01-02"))
+	tagName := base
+	for i := 2; ; i++ {
+		var count int
+		if err := conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM dolt_tags WHERE tag_name = ?", tagName).Scan(&count); err != nil {
+			return "", fmt.Errorf("failed to check archive tag: %w", err)
+		}
+		if count == 0 {
+			break
+		}
+		tagName = fmt.Sprintf("%s-%d", base, i)
+	}
+
+	message, err := json.Marshal(staleArchiveMeta{
+		Schema:     "dolt-webui/stale-archive@1",
+		Branch:     branch.Name,
+		IdleDays:   branch.IdleDays,
+		ArchivedAt: now.Format(time.RFC3339),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal archive tag: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, "CALL DOLT_TAG('-m', ?, ?, ?)", string(message), tagName, branch.Hash); err != nil {
+		return "", fmt.Errorf("failed to create archive tag: %w", err)
+	}
+	return tagName, nil
+}
+
+// findStaleBranches reads candidates from dolt_branches on a main session: allowed
+// work branches idle for at least IdleDays, without a req/* tag and without changes
+// relative to their merge base with main. Idle time runs from the later of the
+// branch head's commit date and the branch creation recorded in base/<WorkItem>,
+// so a branch just forked from an old commit is not idle.
+func findStaleBranches(ctx context.Context, conn *sql.Conn, db *config.Database, now time.Time) ([]model.StaleBranch, error) {
+	bases, err := listBranchBaseRecords(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := conn.QueryContext(ctx,
+		"SELECT name, hash, DATE_FORMAT(latest_commit_date, '%Y-%m-%dT%H:%i:%sZ') FROM dolt_branches WHERE name LIKE 'wi/%' ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query branches: %w", err)
+	}
+	candidates := make([]model.StaleBranch, 0)
+	for rows.Next() {
+		var branch model.StaleBranch
+		var at sql.NullString
+		if err := rows.Scan(&branch.Name, &branch.Hash, &at); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan branch: %w", err)
+		}
+		if !isWorkBranchName(branch.Name) || !validation.IsAllowedBranch(branch.Name, db.AllowedBranches) {
+			continue
+		}
+		lastCommit, err := time.Parse(time.RFC3339, at.String)
+		if err != nil {
+			continue
+		}
+		branch.LastCommitAt = at.String
+		lastActive := lastCommit
+		if base, ok := bases[branch.Name]; ok {
+			if createdAt, err := time.Parse(time.RFC3339, base.CreatedAt); err == nil && createdAt.After(lastActive) {
+				lastActive = createdAt
+			}
+		}
+		branch.IdleDays = int(now.Sub(lastActive).Hours() / 24)
+		if branch.IdleDays < db.StaleBranches.IdleDays {
+			continue
+		}
+		candidates = append(candidates, branch)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	locks, err := pendingRequestTags(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	stale := make([]model.StaleBranch, 0, len(candidates))
+	for _, branch := range candidates {
+		if requestID, ok := requestIDFromWorkBranch(branch.Name); ok && locks[requestID] {
+			continue
+		}
+		var changed int
+		if err := conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM dolt_diff_summary(?)", "main..."+branch.Name).Scan(&changed); err != nil {
+			return nil, fmt.Errorf("failed to diff %s against main: %w", branch.Name, err)
+		}
+		if changed > 0 {
+			continue
+		}
+		stale = append(stale, branch)
+	}
+	return stale, nil
+}
+
+func (s *Service) stalePolicyDatabase(targetID, dbName string) (*config.Database, error) {
+	db, err := s.configuredDatabase(targetID, dbName)
+	if err != nil {
+		return nil, err
+	}
+	if !db.StaleBranches.Enabled() {
+		return nil, &model.APIError{
+			Status:  412,
+			Code:    model.CodePreconditionFailed,
+			Msg:     "stale_branches policy is not configured for this database",
+			Details: map[string]string{"reason": "stale_policy_disabled"},
+		}
+	}
+	return db, nil
+}
+
+// StartStaleBranchScheduler applies the stale_branches policy periodically for
+// every database whose policy sets interval_minutes and a delete/archive action.
+// The goroutines stop when ctx is cancelled.
+func (s *Service) StartStaleBranchScheduler(ctx context.Context) {
+	for _, db := range s.cfg.Databases {
+		policy := db.StaleBranches
+		if !policy.Enabled() || policy.IntervalMinutes <= 0 || policy.Action == config.StaleActionReport {
+			continue
+		}
+		go s.runStaleBranchSchedule(ctx, db.TargetID, db.Name, time.Duration(policy.IntervalMinutes)*time.Minute)
+	}
+}
+
+func (s *Service) runStaleBranchSchedule(ctx context.Context, targetID, dbName string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			resp, err := s.CleanupStaleBranches(ctx, model.CleanupStaleBranchesRequest{TargetID: targetID, DBName: dbName})
+			if err != nil {
+				log.Printf("WARN: stale branch cleanup failed for %s/%s: %v", targetID, dbName, err)
+				continue
+			}
+			for _, result := range resp.Results {
+				if result.Error != "" {
+					log.Printf("WARN: stale branch cleanup failed for %s/%s %s: %s", targetID, dbName, result.Name, result.Error)
+				} else {
+					log.Printf("stale branch cleanup: %s/%s %s removed (archive tag %q)", targetID, dbName, result.Name, result.ArchiveTag)
+				}
+			}
+		}
+	}
+}
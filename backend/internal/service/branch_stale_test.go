@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/config"
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+)
+
+func staleTestConfig(action string) *config.Config {
+	cfg := testServiceConfig()
+	cfg.Databases[0].StaleBranches = config.StaleBranchPolicy{IdleDays: 30, Action: action}
+	return cfg
+}
+
+// staleBranchQueries answers the detection queries: wi/old and wi/locked are idle,
+// wi/recent is not, wi/changed still differs from main and wi/locked has a request.
+func staleBranchQueries(query string, args []driver.NamedValue) (testQueryResult, bool) {
+	switch {
+	case strings.HasPrefix(query, "SELECT name, hash, DATE_FORMAT(latest_commit_date"):
+		return testQueryResult{
+			columns: []string{"name", "hash", "latest_commit_date"},
+			rows: [][]driver.Value{
+				{"wi/old", "hash-old", "2020-01-01T00:00:00Z"},
+				{"wi/changed", "hash-changed", "2020-01-01T00:00:00Z"},
+				{"wi/locked", "hash-locked", "2020-01-01T00:00:00Z"},
+				{"wi/recent", "hash-recent", "2999-01-01T00:00:00Z"},
+			},
+		}, true
+	case query == "SELECT tag_name, message FROM dolt_tags WHERE tag_name LIKE ?" && args[0].Value == "base/%":
+		return testQueryResult{
+			columns: []string{"tag_name", "message"},
+			rows:    [][]driver.Value{{"base/old", `{"schema":"dolt-webui/branch-base@1","from_ref":"main","created_at":"2019-12-01T00:00:00Z"}`}},
+		}, true
+	case query == "SELECT tag_name FROM dolt_tags WHERE tag_name LIKE 'req/%'":
+		return testQueryResult{columns: []string{"tag_name"}, rows: [][]driver.Value{{"req/locked"}}}, true
+	case query == "SELECT COUNT(*) FROM dolt_diff_summary(?)":
+		changed := int64(0)
+		if args[0].Value == "main...wi/changed" {
+			changed = 1
+		}
+		return testQueryResult{columns: []string{"count"}, rows: [][]driver.Value{{changed}}}, true
+	}
+	return testQueryResult{}, false
+}
+
+func TestListStaleBranches_SkipsRecentChangedAndLocked(t *testing.T) {
+	repo := newRecordingSessionRepo(t, func(refName, query string, args []driver.NamedValue) (testQueryResult, error) {
+		if result, ok := staleBranchQueries(query, args); ok {
+			return result, nil
+		}
+		return testQueryResult{}, fmt.Errorf("unexpected query on %s: %s", refName, query)
+	})
+	svc := newWithDeps(repo, staleTestConfig(config.StaleActionReport))
+
+	resp, err := svc.ListStaleBranches(context.Background(), "local", "test_db")
+	if err != nil {
+		t.Fatalf("ListStaleBranches: %v", err)
+	}
+	if len(resp.Branches) != 1 || resp.Branches[0].Name != "wi/old" || resp.Branches[0].IdleDays < 30 {
+		t.Fatalf("unexpected stale branches: %+v", resp.Branches)
+	}
+	if resp.Action != config.StaleActionReport || resp.IdleDays != 30 {
+		t.Fatalf("unexpected policy echo: %+v", resp)
+	}
+}
+
+func TestListStaleBranches_FreshBranchOffOldMainIsNotStale(t *testing.T) {
+	// wi/fresh was just created from a main commit dated 2020 and has no own commits.
+	created := time.Now().UTC().Add(-time.Hour).Format(time.RFC3339)
+	repo := newRecordingSessionRepo(t, func(refName, query string, args []driver.NamedValue) (testQueryResult, error) {
+		switch {
+		case strings.HasPrefix(query, "SELECT name, hash, DATE_FORMAT(latest_commit_date"):
+			return testQueryResult{
+				columns: []string{"name", "hash", "latest_commit_date"},
+				rows:    [][]driver.Value{{"wi/fresh", "hash-fresh", "2020-01-01T00:00:00Z"}},
+			}, nil
+		case query == "SELECT tag_name, message FROM dolt_tags WHERE tag_name LIKE ?":
+			return testQueryResult{
+				columns: []string{"tag_name", "message"},
+				rows:    [][]driver.Value{{"base/fresh", fmt.Sprintf(`{"schema":"dolt-webui/branch-base@1","from_ref":"main","created_at":%q}`, created)}},
+			}, nil
+		}
+		if result, ok := staleBranchQueries(query, args); ok {
+			return result, nil
+		}
+		return testQueryResult{}, fmt.Errorf("unexpected query on %s: %s", refName, query)
+	})
+	svc := newWithDeps(repo, staleTestConfig(config.StaleActionReport))
+
+	resp, err := svc.ListStaleBranches(context.Background(), "local", "test_db")
+	if err != nil {
+		t.Fatalf("ListStaleBranches: %v", err)
+	}
+	if len(resp.Branches) != 0 {
+		t.Fatalf("a fresh branch off an old main must not be stale: %+v", resp.Branches)
+	}
+}
+
+func TestListStaleBranches_DisabledPolicyIsPreconditionFailed(t *testing.T) {
+	repo := newRecordingSessionRepo(t, func(refName, query string, args []driver.NamedValue) (testQueryResult, error) {
+		return testQueryResult{}, fmt.Errorf("unexpected query: %s", query)
+	})
+	svc := newWithDeps(repo, testServiceConfig())
+
+	_, err := svc.ListStaleBranches(context.Background(), "local", "test_db")
+	apiErr, ok := err.(*model.APIError)
+	if !ok || apiErr.Code != model.CodePreconditionFailed {
+		t.Fatalf("expected PRECONDITION_FAILED, got %v", err)
+	}
+}
+
+func TestCleanupStaleBranches_ArchivesBeforeDelete(t *testing.T) {
+	var executed []string
+	var tagArgs, deleteArgs []driver.NamedValue
+	repo := newCrossCopyTestRepo(t, nil, nil)
+	repo.protectedHandler = func(dbName, branchName, query string, args []driver.NamedValue) (testQueryResult, error) {
+		if result, ok := staleBranchQueries(query, args); ok {
+			return result, nil
+		}
+		switch query {
+		case "SELECT COUNT(*) FROM dolt_tags WHERE tag_name = ?":
+			// The first archive tag name for the day is already taken.
+			taken := int64(0)
+			if name := args[0].Value.(string); strings.HasPrefix(name, "archived/old/") && !strings.HasSuffix(name, "-2") {
+				taken = 1
+			}
+			return testQueryResult{columns: []string{"count"}, rows: [][]driver.Value{{taken}}}, nil
+		case "CALL DOLT_TAG('-m', ?, ?, ?)":
+			executed = append(executed, "tag")
+			tagArgs = args
+			return testQueryResult{}, nil
+		case "CALL DOLT_BRANCH('-D', ?)":
+			executed = append(executed, "delete")
+			deleteArgs = args
+			return testQueryResult{}, nil
+		}
+		return testQueryResult{}, fmt.Errorf("unexpected protected query: %s", query)
+	}
+	svc := newWithDeps(repo, staleTestConfig(config.StaleActionArchive))
+
+	resp, err := svc.CleanupStaleBranches(context.Background(), model.CleanupStaleBranchesRequest{TargetID: "local", DBName: "test_db"})
+	if err != nil {
+		t.Fatalf("CleanupStaleBranches: %v", err)
+	}
+	if resp.Outcome != model.OperationOutcomeCompleted || len(resp.Results) != 1 || !resp.Results[0].Deleted {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if strings.Join(executed, ",") != "tag,delete" {
+		t.Fatalf("expected tag before delete, got %v", executed)
+	}
+	tagName := tagArgs[1].Value.(string)
+	if !strings.HasPrefix(tagName, "archived/old/") || !strings.HasSuffix(tagName, "-2") || tagArgs[2].Value != "hash-old" {
+		t.Fatalf("unexpected DOLT_TAG args: %v", tagArgs)
+	}
+	if resp.Results[0].ArchiveTag != tagName || deleteArgs[0].Value != "wi/old" {
+		t.Fatalf("unexpected result %+v / delete args %v", resp.Results[0], deleteArgs)
+	}
+}
+
+func TestCleanupStaleBranches_RejectsReportAction(t *testing.T) {
+	svc := newWithDeps(newCrossCopyTestRepo(t, nil, nil), staleTestConfig(config.StaleActionReport))
+
+	_, err := svc.CleanupStaleBranches(context.Background(), model.CleanupStaleBranchesRequest{TargetID: "local", DBName: "test_db"})
+	apiErr, ok := err.(*model.APIError)
+	if !ok || apiErr.Code != model.CodeInvalidArgument {
+		t.Fatalf("expected INVALID_ARGUMENT, got %v", err)
+	}
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/validation"
+)
+
+// ListBranchStatus returns the status of every allowed work branch, or of
+// branchName alone when it is set. Everything is read on one main session:
+// ahead/behind, lock and last-commit data from main's system tables, and merge
+// state and dolt_status through the `db/branch` database of each branch, with
+// one UNION ALL query per kind of data.
+func (s *Service) ListBranchStatus(ctx context.Context, targetID, dbName, branchName string) ([]model.BranchStatus, error) {
+	if branchName != "" {
+		if err := s.ensureAllowedWorkBranchWrite(targetID, dbName, branchName); err != nil {
+			return nil, err
+		}
+	}
+	db, err := s.configuredDatabase(targetID, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := s.connMetadataRevision(ctx, targetID, dbName)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	rows, err := conn.QueryContext(ctx,
+		"SELECT name, hash, latest_committer, DATE_FORMAT(latest_commit_date, '%Y-%m-%dT%H:%i:%sZ') FROM dolt_branches WHERE name LIKE 'wi/%' ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query branches: %w", err)
+	}
+	statuses := make([]model.BranchStatus, 0)
+	for rows.Next() {
+		var st model.BranchStatus
+		var author, at sql.NullString
+		if err := rows.Scan(&st.Name, &st.Hash, &author, &at); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan branch: %w", err)
+		}
+		if branchName != "" && st.Name != branchName {
+			continue
+		}
+		if !isWorkBranchName(st.Name) || !validation.IsAllowedBranch(st.Name, db.AllowedBranches) {
+			continue
+		}
+		st.LastCommitAuthor = author.String
+		st.LastCommitAt = at.String
+		statuses = append(statuses, st)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if branchName != "" && len(statuses) == 0 {
+		return nil, newBranchNotFoundError(branchName)
+	}
+
+	locks, err := pendingRequestTags(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(statuses) == 0 {
+		return statuses, nil
+	}
+	if err := readBranchCounts(ctx, conn, statuses); err != nil {
+		return nil, err
+	}
+	if err := readWorkingStates(ctx, conn, dbName, statuses); err != nil {
+		return nil, err
+	}
+	for i := range statuses {
+		st := &statuses[i]
+		if requestID, ok := requestIDFromWorkBranch(st.Name); ok && locks[requestID] {
+			st.Locked = true
+			st.RequestID = requestID
+		}
+		st.AllowedActions = allowedBranchActions(*st)
+	}
+	return statuses, nil
+}
+
+func pendingRequestTags(ctx context.Context, conn *sql.Conn) (map[string]bool, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT tag_name FROM dolt_tags WHERE tag_name LIKE 'req/%'")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query request tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := make(map[string]bool)
+	for rows.Next() {
+		var tagName string
+		if err := rows.Scan(&tagName); err != nil {
+			return nil, fmt.Errorf("failed to scan request tag: %w", err)
+		}
+		tags[tagName] = true
+	}
+	return tags, rows.Err()
+}
+
+// readBranchCounts fills Ahead and Behind for every status in one query.
+func readBranchCounts(ctx context.Context, conn *sql.Conn, statuses []model.BranchStatus) error {
+	parts := make([]string, 0, 2*len(statuses))
+	args := make([]interface{}, 0, 4*len(statuses))
+	for i, st := range statuses {
+		parts = append(parts,
+			"SELECT ? AS idx, 'ahead' AS kind, COUNT(*) AS n FROM dolt_log(?, '--not', 'main')",
+			"SELECT ?, 'behind', COUNT(*) FROM dolt_log('main', '--not', ?)")
+		args = append(args, i, st.Name, i, st.Name)
+	}
+	rows, err := conn.QueryContext(ctx, strings.Join(parts, " UNION ALL "), args...)
+	if err != nil {
+		return fmt.Errorf("failed to count commits ahead of and behind main: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var idx, n int
+		var kind string
+		if err := rows.Scan(&idx, &kind, &n); err != nil {
+			return fmt.Errorf("failed to scan commit counts: %w", err)
+		}
+		if idx < 0 || idx >= len(statuses) {
+			continue
+		}
+		if kind == "ahead" {
+			statuses[idx].Ahead = n
+		} else {
+			statuses[idx].Behind = n
+		}
+	}
+	return rows.Err()
+}
+
+// readWorkingStates fills Merging and WorkingTables from each branch's own
+// working set, addressed as the `db/branch` database.
+func readWorkingStates(ctx context.Context, conn *sql.Conn, dbName string, statuses []model.BranchStatus) error {
+	mergeParts := make([]string, len(statuses))
+	statusParts := make([]string, len(statuses))
+	args := make([]interface{}, len(statuses))
+	for i, st := range statuses {
+		ref := fmt.Sprintf("`%s/%s`", dbName, st.Name)
+		mergeParts[i] = fmt.Sprintf("SELECT ? AS idx, is_merging FROM %s.dolt_merge_status", ref)
+		statusParts[i] = fmt.Sprintf("SELECT ? AS idx, table_name, staged, status FROM %s.dolt_status", ref)
+		args[i] = i
+		statuses[i].WorkingTables = make([]model.WorkingTableStatus, 0)
+	}
+
+	rows, err := conn.QueryContext(ctx, strings.Join(mergeParts, " UNION ALL "), args...)
+	if err != nil {
+		return fmt.Errorf("failed to query merge status: %w", err)
+	}
+	for rows.Next() {
+		var idx int
+		var merging bool
+		if err := rows.Scan(&idx, &merging); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan merge status: %w", err)
+		}
+		if idx >= 0 && idx < len(statuses) {
+			statuses[idx].Merging = merging
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to query merge status: %w", err)
+	}
+
+	rows, err = conn.QueryContext(ctx, strings.Join(statusParts, " UNION ALL ")+" ORDER BY idx, table_name", args...)
+	if err != nil {
+		return fmt.Errorf("failed to query working set: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var idx int
+		var wt model.WorkingTableStatus
+		if err := rows.Scan(&idx, &wt.Table, &wt.Staged, &wt.Status); err != nil {
+			return fmt.Errorf("failed to scan working set: %w", err)
+		}
+		if idx >= 0 && idx < len(statuses) {
+			statuses[idx].WorkingTables = append(statuses[idx].WorkingTables, wt)
+		}
+	}
+	return rows.Err()
+}
+
+// allowedBranchActions mirrors the preconditions the write APIs enforce:
+// a pending request locks commit/sync/delete, and an unfinished merge only
+// allows abort.
+func allowedBranchActions(st model.BranchStatus) []string {
+	if st.Merging {
+		return []string{model.BranchActionAbortMerge}
+	}
+	actions := make([]string, 0, 4)
+	if !st.Locked {
+		actions = append(actions, model.BranchActionCommit)
+		if st.Behind > 0 {
+			actions = append(actions, model.BranchActionSync)
+		}
+	}
+	if st.Ahead > 0 && len(st.WorkingTables) == 0 {
+		actions = append(actions, model.BranchActionSubmit)
+	}
+	if !st.Locked {
+		actions = append(actions, model.BranchActionDelete)
+	}
+	return actions
+}
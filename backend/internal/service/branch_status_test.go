@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+)
+
+func TestListBranchStatus_ReportsLockMergeAndActions(t *testing.T) {
+	repo := newRecordingSessionRepo(t, func(refName, query string, args []driver.NamedValue) (testQueryResult, error) {
+		if refName != "main" {
+			return testQueryResult{}, fmt.Errorf("branch status must be read on one main session, got a session on %s", refName)
+		}
+		switch {
+		case strings.HasPrefix(query, "SELECT name, hash, latest_committer"):
+			return testQueryResult{
+				columns: []string{"name", "hash", "latest_committer", "latest_commit_date"},
+				rows: [][]driver.Value{
+					{"wi/clean", "hash-clean", "alice", "2026-03-11T10:00:00Z"},
+					{"wi/locked", "hash-locked", "bob", "2026-03-11T11:00:00Z"},
+					{"wi/merging", "hash-merging", "carol", "2026-03-11T12:00:00Z"},
+				},
+			}, nil
+		case query == "SELECT tag_name FROM dolt_tags WHERE tag_name LIKE 'req/%'":
+			return testQueryResult{columns: []string{"tag_name"}, rows: [][]driver.Value{{"req/locked"}}}, nil
+		case strings.HasPrefix(query, "SELECT ? AS idx, 'ahead' AS kind"):
+			result := testQueryResult{columns: []string{"idx", "kind", "n"}}
+			for i := 0; i < len(args); i += 4 {
+				result.rows = append(result.rows,
+					[]driver.Value{args[i].Value, "ahead", int64(2)},
+					[]driver.Value{args[i+2].Value, "behind", int64(1)})
+			}
+			return result, nil
+		case strings.HasPrefix(query, "SELECT ? AS idx, is_merging FROM `test_db/wi/clean`.dolt_merge_status UNION ALL"):
+			return testQueryResult{columns: []string{"idx", "is_merging"}, rows: [][]driver.Value{
+				{int64(0), false}, {int64(1), false}, {int64(2), true},
+			}}, nil
+		case strings.HasPrefix(query, "SELECT ? AS idx, table_name, staged, status FROM `test_db/wi/clean`.dolt_status UNION ALL"):
+			return testQueryResult{columns: []string{"idx", "table_name", "staged", "status"}, rows: [][]driver.Value{
+				{int64(1), "users", false, "modified"}, {int64(2), "users", false, "modified"},
+			}}, nil
+		}
+		return testQueryResult{}, fmt.Errorf("unexpected query on %s: %s", refName, query)
+	})
+	svc := newWithDeps(repo, testServiceConfig())
+
+	statuses, err := svc.ListBranchStatus(context.Background(), "local", "test_db", "")
+	if err != nil {
+		t.Fatalf("ListBranchStatus: %v", err)
+	}
+	got := make(map[string]model.BranchStatus)
+	for _, st := range statuses {
+		got[st.Name] = st
+	}
+
+	if st := got["wi/clean"]; strings.Join(st.AllowedActions, ",") != "commit,sync,submit,delete" || st.Ahead != 2 || st.Behind != 1 {
+		t.Fatalf("unexpected clean status: %+v", st)
+	}
+	if st := got["wi/locked"]; !st.Locked || st.RequestID != "req/locked" || strings.Join(st.AllowedActions, ",") != "" {
+		t.Fatalf("unexpected locked status: %+v", st)
+	}
+	if st := got["wi/merging"]; !st.Merging || strings.Join(st.AllowedActions, ",") != "abort_merge" || len(st.WorkingTables) != 1 {
+		t.Fatalf("unexpected merging status: %+v", st)
+	}
+}
+
+func TestListBranchStatus_UnknownBranchIsNotFound(t *testing.T) {
+	repo := newRecordingSessionRepo(t, func(refName, query string, args []driver.NamedValue) (testQueryResult, error) {
+		if strings.HasPrefix(query, "SELECT name, hash, latest_committer") {
+			return testQueryResult{columns: []string{"name", "hash", "latest_committer", "latest_commit_date"}}, nil
+		}
+		return testQueryResult{}, fmt.Errorf("unexpected query: %s", query)
+	})
+	svc := newWithDeps(repo, testServiceConfig())
+
+	_, err := svc.ListBranchStatus(context.Background(), "local", "test_db", "wi/missing")
+	apiErr, ok := err.(*model.APIError)
+	if !ok || apiErr.Code != model.CodeNotFound {
+		t.Fatalf("expected NOT_FOUND, got %v", err)
+	}
+}
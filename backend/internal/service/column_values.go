@@ -0,0 +1,146 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+)
+
+// maxSafeJSONInteger is the largest integer a JavaScript number represents exactly (2^53-1).
+const maxSafeJSONInteger = 1<<53 - 1
+
+// columnKind classifies a MySQL column type for lossless JSON encoding.
+type columnKind int
+
+const (
+	columnKindOther columnKind = iota
+	columnKindInteger
+	columnKindDecimal
+	columnKindFloat
+	columnKindBit
+	columnKindTemporal
+)
+
+// columnKindOf maps a SHOW COLUMNS type such as "bigint unsigned" or "decimal(20,6)".
+func columnKindOf(columnType string) columnKind {
+	t := strings.ToLower(strings.TrimSpace(columnType))
+	if i := strings.IndexAny(t, "( "); i >= 0 {
+		t = t[:i]
+	}
+	switch t {
+	case "tinyint", "smallint", "mediumint", "int", "integer", "bigint", "bool", "boolean":
+		return columnKindInteger
+	case "decimal", "numeric", "dec", "fixed":
+		return columnKindDecimal
+	case "float", "double", "real":
+		return columnKindFloat
+	case "bit":
+		return columnKindBit
+	case "date", "datetime", "timestamp", "time", "year":
+		return columnKindTemporal
+	default:
+		return columnKindOther
+	}
+}
+
+// columnKindsFromSchema indexes column kinds by column name.
+func columnKindsFromSchema(cols []model.ColumnSchema) map[string]columnKind {
+	kinds := make(map[string]columnKind, len(cols))
+	for _, c := range cols {
+		kinds[c.Name] = columnKindOf(c.Type)
+	}
+	return kinds
+}
+
+// jsonSafeInteger returns a JSON number when the integer is exactly representable
+// in JavaScript and its decimal string otherwise.
+func jsonSafeInteger(digits string) interface{} {
+	n, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return digits
+	}
+	if n.IsInt64() && n.Int64() <= maxSafeJSONInteger && n.Int64() >= -maxSafeJSONInteger {
+		return n.Int64()
+	}
+	return n.String()
+}
+
+// formatColumnValue converts a scanned driver value into a JSON-safe value without
+// losing precision: DECIMAL stays a string, integers beyond 2^53 become strings,
+// BIT is decoded from its big-endian bytes, and temporal values keep fractional seconds.
+func formatColumnValue(kind columnKind, val interface{}) interface{} {
+	switch v := val.(type) {
+	case nil:
+		return nil
+	case []byte:
+		switch kind {
+		case columnKindBit:
+			return jsonSafeInteger(new(big.Int).SetBytes(v).String())
+		case columnKindInteger:
+			return jsonSafeInteger(string(v))
+		case columnKindFloat:
+			if f, err := strconv.ParseFloat(string(v), 64); err == nil {
+				return f
+			}
+		}
+		return string(v)
+	case int64:
+		if kind == columnKindDecimal {
+			return strconv.FormatInt(v, 10)
+		}
+		return jsonSafeInteger(strconv.FormatInt(v, 10))
+	case uint64:
+		if kind == columnKindDecimal {
+			return strconv.FormatUint(v, 10)
+		}
+		return jsonSafeInteger(strconv.FormatUint(v, 10))
+	case float64:
+		if kind == columnKindDecimal {
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		}
+		return v
+	default:
+		return val
+	}
+}
+
+// formatRowValues builds a row map from scanned values using schema-derived kinds.
+// Columns missing from kinds (e.g. dolt_history commit_* columns) use the generic rule.
+func formatRowValues(colNames []string, values []interface{}, kinds map[string]columnKind) map[string]interface{} {
+	row := make(map[string]interface{}, len(colNames))
+	for i, col := range colNames {
+		row[col] = formatColumnValue(kinds[col], values[i])
+	}
+	return row
+}
+
+// unmarshalJSONNumbers decodes JSON keeping numbers as json.Number so that
+// DECIMAL and BIGINT values are not rounded through float64.
+func unmarshalJSONNumbers(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// sqlArgFromJSON converts a decoded JSON value into a lossless query argument.
+// Integer json.Number literals that fit in int64 are sent as int64 so that BIT
+// and integer columns receive numbers; other literals (DECIMAL, BIGINT UNSIGNED
+// above 2^63) are sent as their exact text. Integral float64 values from legacy decoders are sent as int64.
+func sqlArgFromJSON(v interface{}) interface{} {
+	switch n := v.(type) {
+	case json.Number:
+		if i, err := strconv.ParseInt(n.String(), 10, 64); err == nil {
+			return i
+		}
+		return n.String()
+	case float64:
+		if n == float64(int64(n)) {
+			return int64(n)
+		}
+	}
+	return v
+}
@@ -0,0 +1,65 @@
+//go:build integration
+
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+)
+
+func TestNumericRoundTrip_DecimalBigintDatetimeBit(t *testing.T) {
+	svc := newIntegrationService(t)
+	branchName := createIntegrationBranch(t, svc, "numeric")
+	defer deleteIntegrationBranch(t, svc, branchName)
+
+	ctx := integrationContext(t)
+	conn, err := svc.connAllowedWorkBranchWrite(ctx, "local", "test_db", branchName)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	for _, stmt := range []string{
+		"CREATE TABLE numeric_roundtrip (id BIGINT UNSIGNED PRIMARY KEY, price DECIMAL(20,6) NOT NULL, ts DATETIME(6) NOT NULL, flags BIT(8) NOT NULL)",
+		"CALL DOLT_COMMIT('-Am', 'integration numeric fixture')",
+	} {
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			conn.Close()
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	conn.Close()
+
+	body := `[
+		{"type":"insert","table":"numeric_roundtrip","values":{"id":18446744073709551615,"price":12345678901234.123456,"ts":"2026-03-11 10:30:00.123456","flags":5}},
+		{"type":"insert","table":"numeric_roundtrip","values":{"id":9007199254740993,"price":0.000001,"ts":"2026-03-11 10:30:00.000001","flags":255}}
+	]`
+	var ops []model.CommitOp
+	if err := unmarshalJSONNumbers([]byte(body), &ops); err != nil {
+		t.Fatalf("decode ops: %v", err)
+	}
+	if _, err := commitOps(t, svc, branchName, ops); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+
+	tests := []struct {
+		pk   string
+		want string
+	}{
+		{pk: `{"id":18446744073709551615}`, want: `{"flags":5,"id":"18446744073709551615","price":"12345678901234.123456","ts":"2026-03-11 10:30:00.123456"}`},
+		{pk: `{"id":9007199254740993}`, want: `{"flags":255,"id":"9007199254740993","price":"0.000001","ts":"2026-03-11 10:30:00.000001"}`},
+	}
+	for _, tc := range tests {
+		row, err := svc.GetTableRow(integrationContext(t), "local", "test_db", branchName, "numeric_roundtrip", tc.pk)
+		if err != nil {
+			t.Fatalf("get row %s: %v", tc.pk, err)
+		}
+		got, err := json.Marshal(row)
+		if err != nil {
+			t.Fatalf("marshal: %v", err)
+		}
+		if string(got) != tc.want {
+			t.Fatalf("round trip mismatch for %s:\n got %s\nwant %s", tc.pk, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+)
+
+func TestColumnKindOf(t *testing.T) {
+	tests := map[string]columnKind{
+		"bigint unsigned": columnKindInteger,
+		"int":             columnKindInteger,
+		"tinyint(1)":      columnKindInteger,
+		"decimal(20,6)":   columnKindDecimal,
+		"double":          columnKindFloat,
+		"bit(8)":          columnKindBit,
+		"datetime(6)":     columnKindTemporal,
+		"varchar(255)":    columnKindOther,
+		"json":            columnKindOther,
+	}
+	for columnType, want := range tests {
+		if got := columnKindOf(columnType); got != want {
+			t.Errorf("columnKindOf(%q) = %v, want %v", columnType, got, want)
+		}
+	}
+}
+
+func TestFormatColumnValue(t *testing.T) {
+	tests := []struct {
+		name string
+		kind columnKind
+		in   interface{}
+		want interface{}
+	}{
+		{name: "decimal bytes", kind: columnKindDecimal, in: []byte("12345678901234.123456"), want: "12345678901234.123456"},
+		{name: "bigint unsigned max", kind: columnKindInteger, in: uint64(18446744073709551615), want: "18446744073709551615"},
+		{name: "bigint above 2^53", kind: columnKindInteger, in: int64(9007199254740993), want: "9007199254740993"},
+		{name: "bigint text protocol", kind: columnKindInteger, in: []byte("-9007199254740993"), want: "-9007199254740993"},
+		{name: "safe int stays number", kind: columnKindInteger, in: int64(42), want: int64(42)},
+		{name: "datetime(6)", kind: columnKindTemporal, in: []byte("2026-03-11 10:30:00.123456"), want: "2026-03-11 10:30:00.123456"},
+		{name: "bit(1)", kind: columnKindBit, in: []byte{0x01}, want: int64(1)},
+		{name: "bit(16)", kind: columnKindBit, in: []byte{0x01, 0x00}, want: int64(256)},
+		{name: "bit(64) all ones", kind: columnKindBit, in: []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, want: "18446744073709551615"},
+		{name: "null", kind: columnKindDecimal, in: nil, want: nil},
+		{name: "text", kind: columnKindOther, in: []byte("hello"), want: "hello"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := formatColumnValue(tc.kind, tc.in); got != tc.want {
+				t.Fatalf("got %#v, want %#v", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestNumericRoundTrip_RequestToSQLToResponse sends lossless literals through
+// request decoding, the Commit SQL args, and back through row formatting.
+func TestNumericRoundTrip_RequestToSQLToResponse(t *testing.T) {
+	body := `{"type":"insert","table":"prices","values":{"id":18446744073709551615,"price":12345678901234.123456,"ts":"2026-03-11 10:30:00.123456","flags":5}}`
+	var op model.CommitOp
+	if err := unmarshalJSONNumbers([]byte(body), &op); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	var insertArgs []driver.NamedValue
+	var executed []string
+	repo := newCrossCopyTestRepo(t, nil, commitWorkHandler(&executed, func(query string, args []driver.NamedValue) (testQueryResult, error) {
+		// Single-op batches use the per-op path, whose column order follows the map.
+		if strings.HasPrefix(query, "INSERT INTO `prices` (") {
+			insertArgs = args
+			return testQueryResult{}, nil
+		}
+		return testQueryResult{}, fmt.Errorf("unexpected query: %s", query)
+	}))
+	svc := newWithDeps(repo, testServiceConfig())
+	if _, err := svc.Commit(context.Background(), model.CommitRequest{
+		TargetID:     "local",
+		DBName:       "test_db",
+		BranchName:   "wi/numeric",
+		ExpectedHead: "head-1",
+		Ops:          []model.CommitOp{op},
+	}); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	sent := make(map[string]bool)
+	for _, arg := range insertArgs {
+		sent[fmt.Sprint(arg.Value)] = true
+	}
+	for _, literal := range []string{"18446744073709551615", "12345678901234.123456", "2026-03-11 10:30:00.123456", "5"} {
+		if !sent[literal] {
+			t.Fatalf("expected exact SQL arg %s, got %v", literal, insertArgs)
+		}
+	}
+
+	// Values as the MySQL driver returns them for the same row.
+	row := formatRowValues(
+		[]string{"id", "price", "ts", "flags"},
+		[]interface{}{uint64(18446744073709551615), []byte("12345678901234.123456"), []byte("2026-03-11 10:30:00.123456"), []byte{0x05}},
+		columnKindsFromSchema([]model.ColumnSchema{
+			{Name: "id", Type: "bigint unsigned"},
+			{Name: "price", Type: "decimal(20,6)"},
+			{Name: "ts", Type: "datetime(6)"},
+			{Name: "flags", Type: "bit(8)"},
+		}),
+	)
+	out, err := json.Marshal(row)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	want := `{"flags":5,"id":"18446744073709551615","price":"12345678901234.123456","ts":"2026-03-11 10:30:00.123456"}`
+	if string(out) != want {
+		t.Fatalf("unexpected response JSON:\n got %s\nwant %s", out, want)
+	}
+}
+
+func TestFilterSQL_KeepsNumericFilterValuesExact(t *testing.T) {
+	var filters []model.FilterCondition
+	filterJSON := `[{"column":"id","op":"eq","value":9007199254740993},` +
+		`{"column":"price","op":"in","value":[0.10000000000000000001,18446744073709551615]}]`
+	if err := unmarshalJSONNumbers([]byte(filterJSON), &filters); err != nil {
+		t.Fatal(err)
+	}
+	allowed := map[string]bool{"id": true, "price": true}
+	want := []interface{}{int64(9007199254740993), "0.10000000000000000001", "18446744073709551615"}
+
+	for name, build := range map[string]func(model.FilterCondition, map[string]bool) (string, []interface{}, *model.APIError){
+		"table": buildFilterSQL,
+		"diff":  buildDiffFilterSQL,
+	} {
+		var args []interface{}
+		for _, f := range filters {
+			_, fArgs, apiErr := build(f, allowed)
+			if apiErr != nil {
+				t.Fatalf("%s: %v", name, apiErr)
+			}
+			args = append(args, fArgs...)
+		}
+		if fmt.Sprintf("%#v", args) != fmt.Sprintf("%#v", want) {
+			t.Fatalf("%s: args = %#v, want %#v", name, args, want)
+		}
+	}
+}
@@ -65,7 +65,9 @@ func collectMemoTables(ops []model.CommitOp) []string {
 	return tables
 }
 
-func validateMemoOpsAgainstRowOps(ops []model.CommitOp) error {
+// memoOpsOnDeletedRows returns the indexes of memo insert/update ops that target
+// a base row deleted by the same Commit.
+func memoOpsOnDeletedRows(ops []model.CommitOp) []int {
 	deletedBaseRows := make(map[string]struct{})
 	for _, op := range ops {
 		if strings.HasPrefix(op.Table, "_memo_") || op.Type != "delete" || op.PK == nil {
@@ -78,7 +80,8 @@ func validateMemoOpsAgainstRowOps(ops []model.CommitOp) error {
 		deletedBaseRows[op.Table+"\x00"+string(pkJSON)] = struct{}{}
 	}
 
-	for _, op := range ops {
+	indexes := make([]int, 0)
+	for i, op := range ops {
 		if !strings.HasPrefix(op.Table, "_memo_") {
 			continue
 		}
@@ -91,14 +94,22 @@ func validateMemoOpsAgainstRowOps(ops []model.CommitOp) error {
 		}
 		key := memoBaseTableName(op.Table) + "\x00" + pkValue
 		if _, ok := deletedBaseRows[key]; ok {
-			return &model.APIError{
-				Status: 400,
-				Code:   model.CodeInvalidArgument,
-				Msg:    "同じ Commit 内で削除する行にはメモを保存できません。",
-			}
+			indexes = append(indexes, i)
 		}
 	}
+	return indexes
+}
+
+const memoOnDeletedRowMsg = "同じ Commit 内で削除する行にはメモを保存できません。"
 
+func validateMemoOpsAgainstRowOps(ops []model.CommitOp) error {
+	if len(memoOpsOnDeletedRows(ops)) > 0 {
+		return &model.APIError{
+			Status: 400,
+			Code:   model.CodeInvalidArgument,
+			Msg:    memoOnDeletedRowMsg,
+		}
+	}
 	return nil
 }
 
@@ -140,6 +151,7 @@ func checkBranchLocked(ctx context.Context, conn *sql.Conn, branchName string) *
 // Commit applies draft operations to the work branch.
 // Per v6f spec section 4.1: expected_head check, START TRANSACTION, apply ops,
 // DOLT_VERIFY_CONSTRAINTS, DOLT_ADD, DOLT_COMMIT.
+// Ops are applied as multi-row batches (see commit_batch.go); errors still name ops[i].
 func (s *Service) Commit(ctx context.Context, req model.CommitRequest) (*model.CommitResponse, error) {
 	if validation.IsProtectedBranch(req.BranchName) {
 		return nil, &model.APIError{Status: 403, Code: model.CodeForbidden, Msg: "write operations on main branch are forbidden"}
@@ -159,6 +171,12 @@ func (s *Service) Commit(ctx context.Context, req model.CommitRequest) (*model.C
 		return nil, apiErr
 	}
 
+	// Step 0a: Validate ops and plan batched statements before touching the branch.
+	batches, err := planCommitBatches(req.Ops)
+	if err != nil {
+		return nil, err
+	}
+
 	// Step 1a: Validate memo ops and ensure memo tables (DDL cannot run inside a transaction)
 	if err := preprocessMemoOps(ctx, conn, req.Ops); err != nil {
 		return nil, err
@@ -186,32 +204,14 @@ func (s *Service) Commit(ctx context.Context, req model.CommitRequest) (*model.C
 		}
 	}
 
-	// Step 2: Apply ops
-	for i, op := range req.Ops {
-		if err := validation.ValidateIdentifier("table", op.Table); err != nil {
-			safeRollback(conn)
-			return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: fmt.Sprintf("ops[%d]: invalid table name", i)}
-		}
-
-		switch op.Type {
-		case "insert":
-			if err := applyInsert(ctx, conn, op); err != nil {
-				safeRollback(conn)
-				return nil, err
-			}
-		case "update":
-			if err := applyUpdate(ctx, conn, op); err != nil {
-				safeRollback(conn)
-				return nil, err
-			}
-		case "delete":
-			if err := applyDelete(ctx, conn, op); err != nil {
-				safeRollback(conn)
-				return nil, err
-			}
-		default:
+	// Step 2: Apply ops in batches. Each batch is a run of consecutive ops on the
+	// same table/type/column set, so the original op order is preserved.
+	executor := newCommitBatchExecutor(conn)
+	defer executor.close()
+	for _, batch := range batches {
+		if err := executor.apply(ctx, req.Ops, batch); err != nil {
 			safeRollback(conn)
-			return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: fmt.Sprintf("ops[%d]: unknown operation type %q", i, op.Type)}
+			return nil, err
 		}
 	}
 
@@ -258,7 +258,7 @@ func applyInsert(ctx context.Context, conn *sql.Conn, op model.CommitOp) error {
 		}
 		cols = append(cols, fmt.Sprintf("`%s`", col))
 		placeholders = append(placeholders, "?")
-		args = append(args, val)
+		args = append(args, sqlArgFromJSON(val))
 	}
 
 	query := fmt.Sprintf("INSERT INTO `%s` (%s) VALUES (%s)",
@@ -287,7 +287,7 @@ func applyUpdate(ctx context.Context, conn *sql.Conn, op model.CommitOp) error {
 			return &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: fmt.Sprintf("invalid column name: %s", col)}
 		}
 		setParts = append(setParts, fmt.Sprintf("`%s` = ?", col))
-		setArgs = append(setArgs, val)
+		setArgs = append(setArgs, sqlArgFromJSON(val))
 	}
 
 	// Build WHERE from all PK columns (composite support)
@@ -298,7 +298,7 @@ func applyUpdate(ctx context.Context, conn *sql.Conn, op model.CommitOp) error {
 			return &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "invalid pk column name: " + k}
 		}
 		whereParts = append(whereParts, fmt.Sprintf("`%s` = ?", k))
-		whereArgs = append(whereArgs, v)
+		whereArgs = append(whereArgs, sqlArgFromJSON(v))
 	}
 
 	query := fmt.Sprintf("UPDATE `%s` SET %s WHERE %s",
@@ -334,7 +334,7 @@ func applyDelete(ctx context.Context, conn *sql.Conn, op model.CommitOp) error {
 			return &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "invalid pk column name: " + k}
 		}
 		whereParts = append(whereParts, fmt.Sprintf("`%s` = ?", k))
-		whereArgs = append(whereArgs, v)
+		whereArgs = append(whereArgs, sqlArgFromJSON(v))
 	}
 
 	query := fmt.Sprintf("DELETE FROM `%s` WHERE %s", op.Table, strings.Join(whereParts, " AND "))
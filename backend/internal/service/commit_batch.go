@@ -0,0 +1,540 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/validation"
+)
+
+const (
+	// commitBatchMaxRows caps the number of ops folded into one multi-row statement.
+	commitBatchMaxRows = 500
+	// commitBatchMaxPlaceholders keeps a statement well below the MySQL protocol
+	// limit of 65,535 bind parameters per prepared statement.
+	commitBatchMaxPlaceholders = 60000
+	commitBatchSavepoint       = "commit_batch"
+)
+
+// errCommitBatchFallback signals that a batch could not be applied as one
+// statement and must be replayed op by op to reproduce per-op semantics.
+var errCommitBatchFallback = errors.New("commit batch requires per-op replay")
+
+// commitBatch is a run of consecutive ops that share table, type and column set.
+// Only consecutive ops are grouped so that the original op order is preserved.
+type commitBatch struct {
+	table   string
+	opType  string
+	cols    []string // sorted value columns (insert/update)
+	pkCols  []string // sorted pk columns (update/delete)
+	indexes []int    // positions in CommitRequest.Ops
+	pkKeys  map[string]struct{}
+}
+
+func (b *commitBatch) key() string {
+	return b.table + "\x00" + b.opType + "\x00" + strings.Join(b.cols, ",") + "\x00" + strings.Join(b.pkCols, ",")
+}
+
+func (b *commitBatch) placeholdersPerOp() int {
+	return len(b.cols) + len(b.pkCols)
+}
+
+// opErrorAt prefixes err with the op position so that callers can locate the
+// offending draft row. APIErrors keep their status and code.
+func opErrorAt(i int, err error) error {
+	var apiErr *model.APIError
+	if errors.As(err, &apiErr) {
+		attributed := *apiErr
+		attributed.Msg = fmt.Sprintf("ops[%d]: %s", i, apiErr.Msg)
+		return &attributed
+	}
+	return fmt.Errorf("ops[%d]: %w", i, err)
+}
+
+func sortedMapKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// validateCommitOp checks identifiers and required fields of one op before any SQL runs.
+func validateCommitOp(i int, op model.CommitOp) error {
+	if err := validation.ValidateIdentifier("table", op.Table); err != nil {
+		return &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: fmt.Sprintf("ops[%d]: invalid table name", i)}
+	}
+	switch op.Type {
+	case "insert", "update", "delete":
+	default:
+		return &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: fmt.Sprintf("ops[%d]: unknown operation type %q", i, op.Type)}
+	}
+	if op.Type != "delete" {
+		if len(op.Values) == 0 {
+			return &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: fmt.Sprintf("ops[%d]: values must not be empty for %s", i, op.Type)}
+		}
+		for col := range op.Values {
+			if err := validation.ValidateIdentifier("column", col); err != nil {
+				return &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: fmt.Sprintf("ops[%d]: invalid column name: %s", i, col)}
+			}
+		}
+	}
+	if op.Type != "insert" {
+		if len(op.PK) < 1 {
+			return &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: fmt.Sprintf("ops[%d]: pk must not be empty for %s", i, op.Type)}
+		}
+		for k := range op.PK {
+			if err := validation.ValidateIdentifier("pk column", k); err != nil {
+				return &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: fmt.Sprintf("ops[%d]: invalid pk column name: %s", i, k)}
+			}
+		}
+	}
+	return nil
+}
+
+// planCommitBatches validates every op and groups consecutive ops into batches.
+// A new batch starts when the table, type or column set changes, when a batch
+// is full, or when an update/delete targets a PK already present in the batch.
+func planCommitBatches(ops []model.CommitOp) ([]commitBatch, error) {
+	batches := make([]commitBatch, 0)
+	for i, op := range ops {
+		if err := validateCommitOp(i, op); err != nil {
+			return nil, err
+		}
+
+		next := commitBatch{table: op.Table, opType: op.Type}
+		if op.Type != "delete" {
+			next.cols = sortedMapKeys(op.Values)
+		}
+		var pkKey string
+		if op.Type != "insert" {
+			next.pkCols = sortedMapKeys(op.PK)
+			pkJSON, _ := json.Marshal(normalizePkJSON(op.PK))
+			pkKey = string(pkJSON)
+		}
+
+		if n := len(batches); n > 0 {
+			last := &batches[n-1]
+			_, duplicatePK := last.pkKeys[pkKey]
+			fits := len(last.indexes) < commitBatchMaxRows &&
+				(len(last.indexes)+1)*last.placeholdersPerOp() <= commitBatchMaxPlaceholders
+			if last.key() == next.key() && fits && !duplicatePK {
+				last.indexes = append(last.indexes, i)
+				if pkKey != "" {
+					last.pkKeys[pkKey] = struct{}{}
+				}
+				continue
+			}
+		}
+
+		next.indexes = []int{i}
+		next.pkKeys = make(map[string]struct{})
+		if pkKey != "" {
+			next.pkKeys[pkKey] = struct{}{}
+		}
+		batches = append(batches, next)
+	}
+	return batches, nil
+}
+
+// commitTableInfo holds the schema facts that decide whether an update batch
+// can be written as INSERT ... ON DUPLICATE KEY UPDATE.
+type commitTableInfo struct {
+	pkCols         []string
+	requiredCols   map[string]struct{} // NOT NULL, no default, not auto-increment
+	hasUniqueIndex bool                // unique index other than PRIMARY
+}
+
+// commitBatchExecutor applies planned batches on one write session, reusing
+// prepared statements across batches that share the same shape.
+type commitBatchExecutor struct {
+	conn   *sql.Conn
+	stmts  map[string]*sql.Stmt
+	tables map[string]*commitTableInfo
+	// keysMatched is set by callers that looked every update key up inside
+	// the transaction (CSVApply). An UPDATE that leaves such a row unchanged
+	// affects zero rows, which then does not mean the row is missing.
+	keysMatched bool
+}
+
+func newCommitBatchExecutor(conn *sql.Conn) *commitBatchExecutor {
+	return &commitBatchExecutor{
+		conn:   conn,
+		stmts:  make(map[string]*sql.Stmt),
+		tables: make(map[string]*commitTableInfo),
+	}
+}
+
+func (e *commitBatchExecutor) close() {
+	for _, stmt := range e.stmts {
+		stmt.Close()
+	}
+}
+
+func (e *commitBatchExecutor) stmt(ctx context.Context, query string) (*sql.Stmt, error) {
+	if stmt, ok := e.stmts[query]; ok {
+		return stmt, nil
+	}
+	stmt, err := e.conn.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	e.stmts[query] = stmt
+	return stmt, nil
+}
+
+func (e *commitBatchExecutor) exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	stmt, err := e.stmt(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.ExecContext(ctx, args...)
+}
+
+// apply runs one batch. Single-op batches use the per-op path directly. Multi-op
+// batches run under a savepoint; if the batch statement fails, the savepoint is
+// rolled back and the ops are replayed one by one so that the error is reported
+// against the exact ops[i] that caused it.
+func (e *commitBatchExecutor) apply(ctx context.Context, ops []model.CommitOp, batch commitBatch) error {
+	if len(batch.indexes) == 1 && !e.matchedUpdate(batch) {
+		return applyCommitOpAt(ctx, e.conn, ops, batch.indexes[0])
+	}
+
+	if _, err := e.conn.ExecContext(ctx, "SAVEPOINT "+commitBatchSavepoint); err != nil {
+		return fmt.Errorf("failed to create savepoint: %w", err)
+	}
+
+	var err error
+	switch batch.opType {
+	case "insert":
+		err = e.applyInsertBatch(ctx, ops, batch)
+	case "update":
+		err = e.applyUpdateBatch(ctx, ops, batch)
+	case "delete":
+		err = e.applyDeleteBatch(ctx, ops, batch)
+	}
+	if err == nil {
+		if _, relErr := e.conn.ExecContext(ctx, "RELEASE SAVEPOINT "+commitBatchSavepoint); relErr != nil {
+			return fmt.Errorf("failed to release savepoint: %w", relErr)
+		}
+		return nil
+	}
+
+	var apiErr *model.APIError
+	if errors.As(err, &apiErr) {
+		// Already attributed to a single op.
+		return err
+	}
+	if _, rbErr := e.conn.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+commitBatchSavepoint); rbErr != nil {
+		return fmt.Errorf("failed to roll back to savepoint: %w", rbErr)
+	}
+	if e.matchedUpdate(batch) {
+		return e.applyUpdateEach(ctx, ops, batch)
+	}
+	for _, i := range batch.indexes {
+		if err := applyCommitOpAt(ctx, e.conn, ops, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *commitBatchExecutor) matchedUpdate(batch commitBatch) bool {
+	return e.keysMatched && batch.opType == "update"
+}
+
+func applyCommitOpAt(ctx context.Context, conn *sql.Conn, ops []model.CommitOp, i int) error {
+	var err error
+	switch ops[i].Type {
+	case "insert":
+		err = applyInsert(ctx, conn, ops[i])
+	case "update":
+		err = applyUpdate(ctx, conn, ops[i])
+	case "delete":
+		err = applyDelete(ctx, conn, ops[i])
+	}
+	if err != nil {
+		return opErrorAt(i, err)
+	}
+	return nil
+}
+
+func quoteIdentifiers(names []string) []string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = fmt.Sprintf("`%s`", name)
+	}
+	return quoted
+}
+
+func rowPlaceholders(n int) string {
+	return "(" + strings.TrimSuffix(strings.Repeat("?, ", n), ", ") + ")"
+}
+
+func repeatedTuples(tuple string, n int) string {
+	return strings.TrimSuffix(strings.Repeat(tuple+", ", n), ", ")
+}
+
+// pkInClause builds `pk` IN (?, ...) or (`a`, `b`) IN ((?, ?), ...) for n rows.
+func pkInClause(pkCols []string, n int) string {
+	quoted := quoteIdentifiers(pkCols)
+	if len(pkCols) == 1 {
+		return fmt.Sprintf("%s IN %s", quoted[0], rowPlaceholders(n))
+	}
+	return fmt.Sprintf("(%s) IN (%s)", strings.Join(quoted, ", "), repeatedTuples(rowPlaceholders(len(pkCols)), n))
+}
+
+func pkArgs(ops []model.CommitOp, batch commitBatch) []interface{} {
+	args := make([]interface{}, 0, len(batch.indexes)*len(batch.pkCols))
+	for _, i := range batch.indexes {
+		for _, col := range batch.pkCols {
+			args = append(args, sqlArgFromJSON(ops[i].PK[col]))
+		}
+	}
+	return args
+}
+
+func (e *commitBatchExecutor) applyInsertBatch(ctx context.Context, ops []model.CommitOp, batch commitBatch) error {
+	query := fmt.Sprintf("INSERT INTO `%s` (%s) VALUES %s",
+		batch.table, strings.Join(quoteIdentifiers(batch.cols), ", "),
+		repeatedTuples(rowPlaceholders(len(batch.cols)), len(batch.indexes)))
+	args := make([]interface{}, 0, len(batch.indexes)*len(batch.cols))
+	for _, i := range batch.indexes {
+		for _, col := range batch.cols {
+			args = append(args, sqlArgFromJSON(ops[i].Values[col]))
+		}
+	}
+	_, err := e.exec(ctx, query, args...)
+	return err
+}
+
+// countExistingPKs returns how many of the batch PKs currently exist in the table.
+// Batches never contain the same PK twice, so a full match equals len(indexes).
+func (e *commitBatchExecutor) countExistingPKs(ctx context.Context, ops []model.CommitOp, batch commitBatch) (int, error) {
+	query := fmt.Sprintf("SELECT COUNT(*) FROM `%s` WHERE %s", batch.table, pkInClause(batch.pkCols, len(batch.indexes)))
+	stmt, err := e.stmt(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	var count int
+	if err := stmt.QueryRowContext(ctx, pkArgs(ops, batch)...).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (e *commitBatchExecutor) applyDeleteBatch(ctx context.Context, ops []model.CommitOp, batch commitBatch) error {
+	count, err := e.countExistingPKs(ctx, ops, batch)
+	if err != nil {
+		return err
+	}
+	if count != len(batch.indexes) {
+		return errCommitBatchFallback
+	}
+
+	args := pkArgs(ops, batch)
+	query := fmt.Sprintf("DELETE FROM `%s` WHERE %s", batch.table, pkInClause(batch.pkCols, len(batch.indexes)))
+	if _, err := e.exec(ctx, query, args...); err != nil {
+		return err
+	}
+
+	// Cascade-delete memos for the deleted rows (memo table may not exist).
+	memoArgs := make([]interface{}, 0, len(batch.indexes))
+	for _, i := range batch.indexes {
+		if pkJSON, jsonErr := json.Marshal(normalizePkJSON(ops[i].PK)); jsonErr == nil {
+			memoArgs = append(memoArgs, string(pkJSON))
+		}
+	}
+	if len(memoArgs) > 0 {
+		e.conn.ExecContext(ctx, //nolint:errcheck
+			fmt.Sprintf("DELETE FROM `%s` WHERE pk_value IN %s", memoTableName(batch.table), rowPlaceholders(len(memoArgs))),
+			memoArgs...)
+	}
+	return nil
+}
+
+// applyUpdateBatch writes updates as one INSERT ... ON DUPLICATE KEY UPDATE when
+// that is equivalent to per-row UPDATEs: the op PK is the table PK, the values
+// do not touch PK columns, every NOT NULL column without default is supplied,
+// and no other unique index could redirect the upsert to a different row.
+// Otherwise the batch runs a single prepared UPDATE once per op.
+func (e *commitBatchExecutor) applyUpdateBatch(ctx context.Context, ops []model.CommitOp, batch commitBatch) error {
+	info, err := e.tableInfo(ctx, batch.table)
+	if err != nil {
+		return err
+	}
+	if !info.allowsUpsert(batch) {
+		return e.applyUpdateEach(ctx, ops, batch)
+	}
+
+	count, err := e.countExistingPKs(ctx, ops, batch)
+	if err != nil {
+		return err
+	}
+	if count != len(batch.indexes) {
+		return errCommitBatchFallback
+	}
+
+	insertCols := append(append([]string{}, batch.pkCols...), batch.cols...)
+	updateParts := make([]string, len(batch.cols))
+	for i, col := range batch.cols {
+		updateParts[i] = fmt.Sprintf("`%s` = VALUES(`%s`)", col, col)
+	}
+	query := fmt.Sprintf("INSERT INTO `%s` (%s) VALUES %s ON DUPLICATE KEY UPDATE %s",
+		batch.table, strings.Join(quoteIdentifiers(insertCols), ", "),
+		repeatedTuples(rowPlaceholders(len(insertCols)), len(batch.indexes)),
+		strings.Join(updateParts, ", "))
+	args := make([]interface{}, 0, len(batch.indexes)*len(insertCols))
+	for _, i := range batch.indexes {
+		for _, col := range batch.pkCols {
+			args = append(args, sqlArgFromJSON(ops[i].PK[col]))
+		}
+		for _, col := range batch.cols {
+			args = append(args, sqlArgFromJSON(ops[i].Values[col]))
+		}
+	}
+	_, err = e.exec(ctx, query, args...)
+	return err
+}
+
+func (e *commitBatchExecutor) applyUpdateEach(ctx context.Context, ops []model.CommitOp, batch commitBatch) error {
+	setParts := make([]string, len(batch.cols))
+	for i, col := range batch.cols {
+		setParts[i] = fmt.Sprintf("`%s` = ?", col)
+	}
+	whereParts := make([]string, len(batch.pkCols))
+	for i, col := range batch.pkCols {
+		whereParts[i] = fmt.Sprintf("`%s` = ?", col)
+	}
+	query := fmt.Sprintf("UPDATE `%s` SET %s WHERE %s",
+		batch.table, strings.Join(setParts, ", "), strings.Join(whereParts, " AND "))
+
+	for _, i := range batch.indexes {
+		args := make([]interface{}, 0, batch.placeholdersPerOp())
+		for _, col := range batch.cols {
+			args = append(args, sqlArgFromJSON(ops[i].Values[col]))
+		}
+		for _, col := range batch.pkCols {
+			args = append(args, sqlArgFromJSON(ops[i].PK[col]))
+		}
+		result, err := e.exec(ctx, query, args...)
+		if err != nil {
+			return opErrorAt(i, fmt.Errorf("failed to update %s: %w", batch.table, err))
+		}
+		affected, _ := result.RowsAffected()
+		if affected == 0 && !e.keysMatched {
+			return opErrorAt(i, &model.APIError{Status: 404, Code: model.CodeNotFound, Msg: fmt.Sprintf("row not found in %s", batch.table)})
+		}
+	}
+	return nil
+}
+
+func (info *commitTableInfo) allowsUpsert(batch commitBatch) bool {
+	if info.hasUniqueIndex || strings.Join(info.pkCols, ",") != strings.Join(batch.pkCols, ",") {
+		return false
+	}
+	supplied := make(map[string]struct{}, len(batch.cols)+len(batch.pkCols))
+	for _, col := range batch.pkCols {
+		supplied[col] = struct{}{}
+	}
+	for _, col := range batch.cols {
+		if _, isPK := supplied[col]; isPK {
+			return false
+		}
+		supplied[col] = struct{}{}
+	}
+	for col := range info.requiredCols {
+		if _, ok := supplied[col]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *commitBatchExecutor) tableInfo(ctx context.Context, table string) (*commitTableInfo, error) {
+	if info, ok := e.tables[table]; ok {
+		return info, nil
+	}
+
+	info := &commitTableInfo{requiredCols: make(map[string]struct{})}
+	rows, err := e.conn.QueryContext(ctx, fmt.Sprintf("SHOW COLUMNS FROM `%s`", table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schema for %s: %w", table, err)
+	}
+	for rows.Next() {
+		var field, colType, null, key string
+		var defaultVal, extra sql.NullString
+		if err := rows.Scan(&field, &colType, &null, &key, &defaultVal, &extra); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan column: %w", err)
+		}
+		if key == "PRI" {
+			info.pkCols = append(info.pkCols, field)
+		}
+		if null == "NO" && !defaultVal.Valid && !strings.Contains(strings.ToLower(extra.String), "auto_increment") {
+			info.requiredCols[field] = struct{}{}
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate columns: %w", err)
+	}
+	sort.Strings(info.pkCols)
+
+	hasUnique, err := e.hasSecondaryUniqueIndex(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+	info.hasUniqueIndex = hasUnique
+
+	e.tables[table] = info
+	return info, nil
+}
+
+func (e *commitBatchExecutor) hasSecondaryUniqueIndex(ctx context.Context, table string) (bool, error) {
+	rows, err := e.conn.QueryContext(ctx, fmt.Sprintf("SHOW INDEX FROM `%s`", table))
+	if err != nil {
+		return false, fmt.Errorf("failed to get indexes for %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return false, fmt.Errorf("failed to get index columns: %w", err)
+	}
+	nonUniqueIdx, keyNameIdx := -1, -1
+	for i, col := range columns {
+		switch strings.ToLower(col) {
+		case "non_unique":
+			nonUniqueIdx = i
+		case "key_name":
+			keyNameIdx = i
+		}
+	}
+	if nonUniqueIdx < 0 || keyNameIdx < 0 {
+		// Unknown layout: assume the worst so that updates keep UPDATE semantics.
+		return true, nil
+	}
+
+	values := make([]sql.RawBytes, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return false, fmt.Errorf("failed to scan index: %w", err)
+		}
+		if string(values[nonUniqueIdx]) == "0" && string(values[keyNameIdx]) != "PRIMARY" {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
@@ -0,0 +1,175 @@
+//go:build integration
+
+package service
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+)
+
+// Run the benchmarks against the local test Dolt server with:
+//
+//	go test -tags integration -run '^$' -bench CommitBatch ./internal/service/
+
+func createIntegrationBranch(t testing.TB, svc *Service, prefix string) string {
+	t.Helper()
+	branchName := uniqueWorkBranch(t, prefix)
+	if err := svc.CreateBranch(integrationContext(t), model.CreateBranchRequest{
+		TargetID:   "local",
+		DBName:     "test_db",
+		BranchName: branchName,
+	}); err != nil {
+		t.Fatalf("create branch: %v", err)
+	}
+	return branchName
+}
+
+func deleteIntegrationBranch(t testing.TB, svc *Service, branchName string) {
+	t.Helper()
+	if err := svc.DeleteBranch(integrationContext(t), model.DeleteBranchRequest{
+		TargetID:   "local",
+		DBName:     "test_db",
+		BranchName: branchName,
+	}); err != nil {
+		t.Fatalf("delete branch %s: %v", branchName, err)
+	}
+}
+
+func benchmarkUserOps(opType string, n int) []model.CommitOp {
+	ops := make([]model.CommitOp, n)
+	for i := range ops {
+		id := 100000 + i
+		switch opType {
+		case "insert":
+			ops[i] = model.CommitOp{Type: "insert", Table: "users", Values: map[string]interface{}{"id": id, "name": fmt.Sprintf("bench-%d", id), "role": "user"}}
+		case "update":
+			ops[i] = model.CommitOp{Type: "update", Table: "users", PK: map[string]interface{}{"id": id}, Values: map[string]interface{}{"name": fmt.Sprintf("bench-%d", id), "role": "admin"}}
+		case "delete":
+			ops[i] = model.CommitOp{Type: "delete", Table: "users", PK: map[string]interface{}{"id": id}}
+		}
+	}
+	return ops
+}
+
+func commitOps(t testing.TB, svc *Service, branchName string, ops []model.CommitOp) (string, error) {
+	t.Helper()
+	resp, err := svc.Commit(integrationContext(t), model.CommitRequest{
+		TargetID:      "local",
+		DBName:        "test_db",
+		BranchName:    branchName,
+		ExpectedHead:  mustHead(t, svc, branchName),
+		CommitMessage: fmt.Sprintf("integration batch commit (%d ops)", len(ops)),
+		Ops:           ops,
+	})
+	if err != nil {
+		return "", err
+	}
+	return resp.Hash, nil
+}
+
+func TestCommit_BatchedOpsPreserveOrderAndAttribution(t *testing.T) {
+	svc := newIntegrationService(t)
+	branchName := createIntegrationBranch(t, svc, "batch-order")
+
+	// insert → delete → re-insert of the same PK only succeeds if order is kept.
+	ops := append(benchmarkUserOps("insert", 3), benchmarkUserOps("delete", 3)...)
+	ops = append(ops, benchmarkUserOps("insert", 3)...)
+	ops = append(ops, benchmarkUserOps("update", 3)...)
+	if _, err := commitOps(t, svc, branchName, ops); err != nil {
+		t.Fatalf("commit ordered ops: %v", err)
+	}
+
+	row, err := svc.GetTableRow(integrationContext(t), "local", "test_db", branchName, "users", `{"id":100001}`)
+	if err != nil {
+		t.Fatalf("get row: %v", err)
+	}
+	if row["role"] != "admin" {
+		t.Fatalf("expected updated role, got %v", row["role"])
+	}
+
+	// Second op collides with the seeded row id=1.
+	_, err = commitOps(t, svc, branchName, []model.CommitOp{
+		{Type: "insert", Table: "users", Values: map[string]interface{}{"id": 200000, "name": "new", "role": "user"}},
+		{Type: "insert", Table: "users", Values: map[string]interface{}{"id": 1, "name": "dup", "role": "user"}},
+	})
+	expectAPIErrorCode(t, err, model.CodePKCollision)
+	if msg := err.Error(); !strings.HasPrefix(msg, "ops[1]: ") {
+		t.Fatalf("expected error attributed to ops[1], got %q", msg)
+	}
+
+	_, err = commitOps(t, svc, branchName, []model.CommitOp{
+		{Type: "update", Table: "users", PK: map[string]interface{}{"id": 100000}, Values: map[string]interface{}{"name": "x", "role": "y"}},
+		{Type: "update", Table: "users", PK: map[string]interface{}{"id": 999999}, Values: map[string]interface{}{"name": "x", "role": "y"}},
+	})
+	expectAPIErrorCode(t, err, model.CodeNotFound)
+	if msg := err.Error(); !strings.HasPrefix(msg, "ops[1]: ") {
+		t.Fatalf("expected error attributed to ops[1], got %q", msg)
+	}
+}
+
+func benchmarkCommitBatch(b *testing.B, opType string, n int) {
+	svc := newIntegrationService(b)
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		branchName := createIntegrationBranch(b, svc, "bench-"+opType)
+		if opType != "insert" {
+			if _, err := commitOps(b, svc, branchName, benchmarkUserOps("insert", n)); err != nil {
+				b.Fatalf("seed rows: %v", err)
+			}
+		}
+		ops := benchmarkUserOps(opType, n)
+		b.StartTimer()
+
+		if _, err := commitOps(b, svc, branchName, ops); err != nil {
+			b.Fatalf("commit %d %s ops: %v", n, opType, err)
+		}
+
+		b.StopTimer()
+		deleteIntegrationBranch(b, svc, branchName)
+		b.StartTimer()
+	}
+}
+
+func BenchmarkCommitBatch(b *testing.B) {
+	for _, opType := range []string{"insert", "update", "delete"} {
+		for _, n := range []int{100, 2000, 20000} {
+			b.Run(fmt.Sprintf("%s_%d", opType, n), func(b *testing.B) {
+				benchmarkCommitBatch(b, opType, n)
+			})
+		}
+	}
+}
+
+// BenchmarkCommitBatch_PerOpBaseline measures the pre-batching statement-per-op
+// apply step on the same data so that the two can be compared directly.
+func BenchmarkCommitBatch_PerOpBaseline(b *testing.B) {
+	for _, n := range []int{100, 2000} {
+		b.Run(fmt.Sprintf("insert_%d", n), func(b *testing.B) {
+			svc := newIntegrationService(b)
+			branchName := createIntegrationBranch(b, svc, "bench-perop")
+			defer deleteIntegrationBranch(b, svc, branchName)
+			ops := benchmarkUserOps("insert", n)
+
+			for i := 0; i < b.N; i++ {
+				ctx := integrationContext(b)
+				conn, err := svc.connAllowedWorkBranchWrite(ctx, "local", "test_db", branchName)
+				if err != nil {
+					b.Fatalf("connect: %v", err)
+				}
+				if _, err := conn.ExecContext(ctx, "START TRANSACTION"); err != nil {
+					b.Fatalf("start transaction: %v", err)
+				}
+				for j := range ops {
+					if err := applyCommitOpAt(ctx, conn, ops, j); err != nil {
+						b.Fatalf("apply: %v", err)
+					}
+				}
+				safeRollback(conn)
+				conn.Close()
+			}
+		})
+	}
+}
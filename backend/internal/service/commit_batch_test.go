@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+)
+
+func batchIndexes(batches []commitBatch) [][]int {
+	out := make([][]int, len(batches))
+	for i, b := range batches {
+		out[i] = b.indexes
+	}
+	return out
+}
+
+func TestPlanCommitBatches_GroupsConsecutiveOpsOnly(t *testing.T) {
+	ops := []model.CommitOp{
+		{Type: "insert", Table: "users", Values: map[string]interface{}{"id": 1, "name": "a"}},
+		{Type: "insert", Table: "users", Values: map[string]interface{}{"name": "b", "id": 2}},
+		{Type: "update", Table: "users", Values: map[string]interface{}{"name": "c"}, PK: map[string]interface{}{"id": 1}},
+		{Type: "insert", Table: "users", Values: map[string]interface{}{"id": 3, "name": "d"}},
+		{Type: "insert", Table: "users", Values: map[string]interface{}{"id": 4}},
+		{Type: "delete", Table: "users", PK: map[string]interface{}{"id": 3}},
+		{Type: "delete", Table: "users", PK: map[string]interface{}{"id": 4}},
+		{Type: "delete", Table: "users", PK: map[string]interface{}{"id": 3}},
+	}
+
+	batches, err := planCommitBatches(ops)
+	if err != nil {
+		t.Fatalf("planCommitBatches: %v", err)
+	}
+	got := fmt.Sprint(batchIndexes(batches))
+	want := "[[0 1] [2] [3] [4] [5 6] [7]]"
+	if got != want {
+		t.Fatalf("unexpected batches: got %s, want %s", got, want)
+	}
+	if strings.Join(batches[0].cols, ",") != "id,name" {
+		t.Fatalf("expected sorted columns, got %v", batches[0].cols)
+	}
+}
+
+func TestPlanCommitBatches_SplitsAtRowLimit(t *testing.T) {
+	ops := make([]model.CommitOp, commitBatchMaxRows+1)
+	for i := range ops {
+		ops[i] = model.CommitOp{Type: "insert", Table: "users", Values: map[string]interface{}{"id": i}}
+	}
+
+	batches, err := planCommitBatches(ops)
+	if err != nil {
+		t.Fatalf("planCommitBatches: %v", err)
+	}
+	if len(batches) != 2 || len(batches[0].indexes) != commitBatchMaxRows || batches[1].indexes[0] != commitBatchMaxRows {
+		t.Fatalf("unexpected batch sizes: %d batches", len(batches))
+	}
+}
+
+func TestPlanCommitBatches_RejectsInvalidOpWithIndex(t *testing.T) {
+	tests := []struct {
+		name string
+		op   model.CommitOp
+		want string
+	}{
+		{name: "bad table", op: model.CommitOp{Type: "insert", Table: "bad;table", Values: map[string]interface{}{"id": 1}}, want: "ops[1]: invalid table name"},
+		{name: "bad type", op: model.CommitOp{Type: "upsert", Table: "users"}, want: `ops[1]: unknown operation type "upsert"`},
+		{name: "missing pk", op: model.CommitOp{Type: "delete", Table: "users"}, want: "ops[1]: pk must not be empty for delete"},
+		{name: "bad column", op: model.CommitOp{Type: "update", Table: "users", Values: map[string]interface{}{"x`y": 1}, PK: map[string]interface{}{"id": 1}}, want: "ops[1]: invalid column name: x`y"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ops := []model.CommitOp{
+				{Type: "insert", Table: "users", Values: map[string]interface{}{"id": 1}},
+				tc.op,
+			}
+			_, err := planCommitBatches(ops)
+			var apiErr *model.APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("expected APIError, got %T: %v", err, err)
+			}
+			if apiErr.Code != model.CodeInvalidArgument || apiErr.Msg != tc.want {
+				t.Fatalf("unexpected error: %s %q", apiErr.Code, apiErr.Msg)
+			}
+		})
+	}
+}
+
+// commitWorkHandler answers the fixed queries of Commit and delegates the rest.
+func commitWorkHandler(executed *[]string, apply func(query string, args []driver.NamedValue) (testQueryResult, error)) func(dbName, branchName, query string, args []driver.NamedValue) (testQueryResult, error) {
+	return func(dbName, branchName, query string, args []driver.NamedValue) (testQueryResult, error) {
+		*executed = append(*executed, query)
+		switch query {
+		case "SELECT COUNT(*) FROM dolt_tags WHERE tag_name = ?", "SELECT COUNT(*) FROM dolt_constraint_violations":
+			return testQueryResult{columns: []string{"count"}, rows: [][]driver.Value{{int64(0)}}}, nil
+		case "SELECT DOLT_HASHOF('HEAD')":
+			return testQueryResult{columns: []string{"hash"}, rows: [][]driver.Value{{"head-1"}}}, nil
+		case "START TRANSACTION", "ROLLBACK", "COMMIT", "CALL DOLT_VERIFY_CONSTRAINTS()", "CALL DOLT_ADD('.')",
+			"CALL DOLT_COMMIT('--allow-empty', '-m', ?)",
+			"SAVEPOINT commit_batch", "RELEASE SAVEPOINT commit_batch", "ROLLBACK TO SAVEPOINT commit_batch":
+			return testQueryResult{}, nil
+		}
+		return apply(query, args)
+	}
+}
+
+func containsQuery(executed []string, query string) bool {
+	for _, q := range executed {
+		if q == query {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCommit_BatchInsertFailureIsAttributedToFailingOp(t *testing.T) {
+	var executed []string
+	repo := newCrossCopyTestRepo(t, nil, commitWorkHandler(&executed, func(query string, args []driver.NamedValue) (testQueryResult, error) {
+		switch query {
+		case "INSERT INTO `users` (`id`) VALUES (?), (?), (?)":
+			return testQueryResult{}, fmt.Errorf("Duplicate entry '2' for key 'PRIMARY'")
+		case "INSERT INTO `users` (`id`) VALUES (?)":
+			if args[0].Value == int64(2) {
+				return testQueryResult{}, fmt.Errorf("Duplicate entry '2' for key 'PRIMARY'")
+			}
+			return testQueryResult{}, nil
+		}
+		return testQueryResult{}, fmt.Errorf("unexpected query: %s", query)
+	}))
+	svc := newWithDeps(repo, testServiceConfig())
+
+	_, err := svc.Commit(context.Background(), model.CommitRequest{
+		TargetID:     "local",
+		DBName:       "test_db",
+		BranchName:   "wi/batch",
+		ExpectedHead: "head-1",
+		Ops: []model.CommitOp{
+			{Type: "insert", Table: "users", Values: map[string]interface{}{"id": 1}},
+			{Type: "insert", Table: "users", Values: map[string]interface{}{"id": 2}},
+			{Type: "insert", Table: "users", Values: map[string]interface{}{"id": 3}},
+		},
+	})
+
+	var apiErr *model.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected APIError, got %T: %v", err, err)
+	}
+	if apiErr.Code != model.CodePKCollision || !strings.HasPrefix(apiErr.Msg, "ops[1]: ") {
+		t.Fatalf("unexpected error: %s %q", apiErr.Code, apiErr.Msg)
+	}
+	if !containsQuery(executed, "ROLLBACK TO SAVEPOINT commit_batch") {
+		t.Fatalf("expected savepoint rollback before per-op replay, got %v", executed)
+	}
+	if !containsQuery(executed, "ROLLBACK") {
+		t.Fatalf("expected transaction rollback, got %v", executed)
+	}
+}
+
+func TestCommit_BatchUpdateUsesUpsertWhenSchemaAllows(t *testing.T) {
+	var executed []string
+	repo := newCrossCopyTestRepo(t, nil, commitWorkHandler(&executed, func(query string, args []driver.NamedValue) (testQueryResult, error) {
+		switch query {
+		case "SHOW COLUMNS FROM `users`":
+			return testQueryResult{
+				columns: []string{"Field", "Type", "Null", "Key", "Default", "Extra"},
+				rows: [][]driver.Value{
+					{"id", "int", "NO", "PRI", nil, ""},
+					{"name", "varchar(255)", "NO", "", nil, ""},
+					{"role", "varchar(255)", "YES", "", nil, ""},
+				},
+			}, nil
+		case "SHOW INDEX FROM `users`":
+			return testQueryResult{
+				columns: []string{"Table", "Non_unique", "Key_name", "Column_name"},
+				rows:    [][]driver.Value{{"users", "0", "PRIMARY", "id"}},
+			}, nil
+		case "SELECT COUNT(*) FROM `users` WHERE `id` IN (?, ?)":
+			return testQueryResult{columns: []string{"count"}, rows: [][]driver.Value{{int64(2)}}}, nil
+		case "INSERT INTO `users` (`id`, `name`, `role`) VALUES (?, ?, ?), (?, ?, ?) ON DUPLICATE KEY UPDATE `name` = VALUES(`name`), `role` = VALUES(`role`)":
+			if len(args) != 6 || args[0].Value != int64(1) || args[3].Value != int64(2) {
+				return testQueryResult{}, fmt.Errorf("unexpected args: %v", args)
+			}
+			return testQueryResult{}, nil
+		}
+		return testQueryResult{}, fmt.Errorf("unexpected query: %s", query)
+	}))
+	svc := newWithDeps(repo, testServiceConfig())
+
+	resp, err := svc.Commit(context.Background(), model.CommitRequest{
+		TargetID:      "local",
+		DBName:        "test_db",
+		BranchName:    "wi/batch",
+		ExpectedHead:  "head-1",
+		CommitMessage: "batch update",
+		Ops: []model.CommitOp{
+			{Type: "update", Table: "users", Values: map[string]interface{}{"name": "a", "role": "x"}, PK: map[string]interface{}{"id": 1}},
+			{Type: "update", Table: "users", Values: map[string]interface{}{"role": "y", "name": "b"}, PK: map[string]interface{}{"id": 2}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Commit: %v (executed %v)", err, executed)
+	}
+	if resp.Hash != "head-1" {
+		t.Fatalf("unexpected hash %q", resp.Hash)
+	}
+	if containsQuery(executed, "ROLLBACK TO SAVEPOINT commit_batch") {
+		t.Fatalf("did not expect per-op replay, got %v", executed)
+	}
+}
+
+func TestCommit_BatchDeleteMissingRowFallsBackToPerOpNotFound(t *testing.T) {
+	var executed []string
+	repo := newCrossCopyTestRepo(t, nil, commitWorkHandler(&executed, func(query string, args []driver.NamedValue) (testQueryResult, error) {
+		switch query {
+		case "SELECT COUNT(*) FROM `settings` WHERE `key_name` IN (?, ?)":
+			return testQueryResult{columns: []string{"count"}, rows: [][]driver.Value{{int64(1)}}}, nil
+		case "DELETE FROM `settings` WHERE `key_name` = ?":
+			// The fake driver reports no affected rows, which the per-op path maps to 404.
+			return testQueryResult{}, nil
+		}
+		return testQueryResult{}, fmt.Errorf("unexpected query: %s", query)
+	}))
+	svc := newWithDeps(repo, testServiceConfig())
+
+	_, err := svc.Commit(context.Background(), model.CommitRequest{
+		TargetID:     "local",
+		DBName:       "test_db",
+		BranchName:   "wi/batch",
+		ExpectedHead: "head-1",
+		Ops: []model.CommitOp{
+			{Type: "delete", Table: "settings", PK: map[string]interface{}{"key_name": "mode"}},
+			{Type: "delete", Table: "settings", PK: map[string]interface{}{"key_name": "missing"}},
+		},
+	})
+
+	var apiErr *model.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected APIError, got %T: %v", err, err)
+	}
+	if apiErr.Code != model.CodeNotFound || !strings.HasPrefix(apiErr.Msg, "ops[0]: ") {
+		t.Fatalf("unexpected error: %s %q", apiErr.Code, apiErr.Msg)
+	}
+	if containsQuery(executed, "DELETE FROM `settings` WHERE `key_name` IN (?, ?)") {
+		t.Fatalf("batch delete must not run when a PK is missing, got %v", executed)
+	}
+}
@@ -0,0 +1,355 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/validation"
+)
+
+const commitValidateSavepoint = "commit_validate"
+
+var (
+	writeErrorColumnRe = regexp.MustCompile(`(?i)(?:column|field) '([^']+)'`)
+	writeErrorKeyRe    = regexp.MustCompile(`(?i)for key '([^']+)'`)
+)
+
+// classifyCommitOpError maps an apply error for ops[i] to a PreviewError with
+// table, column and a stable reason in Details.
+func classifyCommitOpError(i int, op model.CommitOp, err error) model.PreviewError {
+	details := map[string]string{"table": op.Table}
+	if m := writeErrorColumnRe.FindStringSubmatch(err.Error()); m != nil {
+		details["column"] = m[1]
+	}
+
+	var apiErr *model.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.Code {
+		case model.CodePKCollision:
+			details["reason"] = "duplicate_key"
+		case model.CodeNotFound:
+			details["reason"] = "row_not_found"
+		default:
+			details["reason"] = "invalid_op"
+		}
+		return model.PreviewError{RowIndex: i, Code: apiErr.Code, Message: strings.TrimPrefix(apiErr.Msg, fmt.Sprintf("ops[%d]: ", i)), Details: details}
+	}
+
+	if class, ok := classifyCopyError(err); ok {
+		details["reason"] = class.reason
+		return model.PreviewError{RowIndex: i, Code: class.code, Message: class.prefix + err.Error(), Details: details}
+	}
+
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "Duplicate entry") || strings.Contains(msg, "duplicate unique key"):
+		details["reason"] = "duplicate_key"
+		if m := writeErrorKeyRe.FindStringSubmatch(msg); m != nil {
+			details["key"] = m[1]
+		}
+		return model.PreviewError{RowIndex: i, Code: model.CodePKCollision, Message: "一意キー重複: " + msg, Details: details}
+	case strings.Contains(msg, "cannot be null"):
+		details["reason"] = "not_null"
+		return model.PreviewError{RowIndex: i, Code: model.CodeCopyDataError, Message: "必須カラム未設定: " + msg, Details: details}
+	case strings.Contains(msg, "Check constraint"):
+		details["reason"] = "check_constraint"
+		return model.PreviewError{RowIndex: i, Code: model.CodeCopyDataError, Message: "チェック制約違反: " + msg, Details: details}
+	}
+	details["reason"] = "unknown"
+	return model.PreviewError{RowIndex: i, Code: model.CodeCopyDataError, Message: msg, Details: details}
+}
+
+// ValidateCommit executes the ops of a commit on the work branch inside a
+// transaction, runs DOLT_VERIFY_CONSTRAINTS and always rolls back. Every failing
+// op is reported with its index so that the grid can highlight it before the
+// real Commit. An empty expected_head skips the HEAD check.
+func (s *Service) ValidateCommit(ctx context.Context, req model.CommitRequest) (*model.CommitValidateResponse, error) {
+	if validation.IsProtectedBranch(req.BranchName) {
+		return nil, &model.APIError{Status: 403, Code: model.CodeForbidden, Msg: "write operations on main branch are forbidden"}
+	}
+	if len(req.Ops) == 0 {
+		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "ops must not be empty"}
+	}
+
+	previewErrors := make([]model.PreviewError, 0)
+	validOps := make([]int, 0, len(req.Ops))
+	for i, op := range req.Ops {
+		if err := validateCommitOp(i, op); err != nil {
+			previewErrors = append(previewErrors, classifyCommitOpError(i, op, err))
+			continue
+		}
+		validOps = append(validOps, i)
+	}
+	for _, i := range memoOpsOnDeletedRows(req.Ops) {
+		previewErrors = append(previewErrors, model.PreviewError{
+			RowIndex: i,
+			Code:     model.CodeInvalidArgument,
+			Message:  memoOnDeletedRowMsg,
+			Details:  map[string]string{"table": req.Ops[i].Table, "reason": "memo_on_deleted_row"},
+		})
+	}
+
+	conn, err := s.connAllowedWorkBranchWrite(ctx, req.TargetID, req.DBName, req.BranchName)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if apiErr := checkBranchLocked(ctx, conn, req.BranchName); apiErr != nil {
+		return nil, apiErr
+	}
+
+	if _, err := conn.ExecContext(ctx, "START TRANSACTION"); err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	// Dry run: nothing is ever committed.
+	defer safeRollback(conn)
+
+	if req.ExpectedHead != "" {
+		var currentHead string
+		if err := conn.QueryRowContext(ctx, "SELECT DOLT_HASHOF('HEAD')").Scan(&currentHead); err != nil {
+			return nil, fmt.Errorf("failed to get HEAD: %w", err)
+		}
+		if currentHead != req.ExpectedHead {
+			return nil, &model.APIError{
+				Status:  409,
+				Code:    model.CodeStaleHead,
+				Msg:     "expected_head mismatch",
+				Details: map[string]string{"expected_head": req.ExpectedHead, "actual_head": currentHead},
+			}
+		}
+	}
+
+	validSubset := make([]model.CommitOp, len(validOps))
+	for j, i := range validOps {
+		validSubset[j] = req.Ops[i]
+	}
+	batches, err := planCommitBatches(validSubset)
+	if err != nil {
+		return nil, err
+	}
+
+	executor := newCommitBatchExecutor(conn)
+	defer executor.close()
+	for _, batch := range batches {
+		opErrors, err := validateCommitBatch(ctx, conn, executor, validSubset, batch)
+		if err != nil {
+			return nil, err
+		}
+		for _, pe := range opErrors {
+			// Map back from the valid subset to the original ops index.
+			origIndex := validOps[pe.RowIndex]
+			pe.RowIndex = origIndex
+			previewErrors = append(previewErrors, pe)
+		}
+	}
+
+	if _, err := conn.ExecContext(ctx, "CALL DOLT_VERIFY_CONSTRAINTS()"); err != nil {
+		return nil, fmt.Errorf("failed to verify constraints: %w", err)
+	}
+	violationErrors, err := collectConstraintViolationErrors(ctx, conn, req.Ops)
+	if err != nil {
+		return nil, err
+	}
+	previewErrors = append(previewErrors, violationErrors...)
+
+	sort.SliceStable(previewErrors, func(a, b int) bool {
+		return previewErrors[a].RowIndex < previewErrors[b].RowIndex
+	})
+	return &model.CommitValidateResponse{Valid: len(previewErrors) == 0, Errors: previewErrors}, nil
+}
+
+// validateCommitBatch applies one batch under a savepoint. If the batch fails,
+// each op is replayed under its own savepoint so that every failing op is
+// collected, not just the first. Failed ops are rolled back; successful ones
+// stay applied so that later ops see the same state as in the real Commit.
+func validateCommitBatch(ctx context.Context, conn *sql.Conn, executor *commitBatchExecutor, ops []model.CommitOp, batch commitBatch) ([]model.PreviewError, error) {
+	if _, err := conn.ExecContext(ctx, "SAVEPOINT "+commitValidateSavepoint); err != nil {
+		return nil, fmt.Errorf("failed to create savepoint: %w", err)
+	}
+	if err := executor.apply(ctx, ops, batch); err == nil {
+		return nil, nil
+	}
+	if _, err := conn.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+commitValidateSavepoint); err != nil {
+		return nil, fmt.Errorf("failed to roll back to savepoint: %w", err)
+	}
+
+	opErrors := make([]model.PreviewError, 0)
+	for _, i := range batch.indexes {
+		if _, err := conn.ExecContext(ctx, "SAVEPOINT "+commitValidateSavepoint); err != nil {
+			return nil, fmt.Errorf("failed to create savepoint: %w", err)
+		}
+		err := applyCommitOpAt(ctx, conn, ops, i)
+		if err == nil {
+			continue
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if strings.HasPrefix(ops[i].Table, "_memo_") && isMissingMemoTable(err) {
+			// Commit creates memo tables before the transaction; a dry run must not.
+			if _, rbErr := conn.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+commitValidateSavepoint); rbErr != nil {
+				return nil, fmt.Errorf("failed to roll back to savepoint: %w", rbErr)
+			}
+			continue
+		}
+		opErrors = append(opErrors, classifyCommitOpError(i, ops[i], err))
+		if _, rbErr := conn.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+commitValidateSavepoint); rbErr != nil {
+			return nil, fmt.Errorf("failed to roll back to savepoint: %w", rbErr)
+		}
+	}
+	return opErrors, nil
+}
+
+// collectConstraintViolationErrors reads dolt_constraint_violations after
+// DOLT_VERIFY_CONSTRAINTS and maps each violating row back to the op that wrote it.
+func collectConstraintViolationErrors(ctx context.Context, conn *sql.Conn, ops []model.CommitOp) ([]model.PreviewError, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT `table`, num_violations FROM dolt_constraint_violations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read constraint violations: %w", err)
+	}
+	tables := make([]string, 0)
+	for rows.Next() {
+		var table string
+		var count int
+		if err := rows.Scan(&table, &count); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan constraint violations: %w", err)
+		}
+		if count > 0 {
+			tables = append(tables, table)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate constraint violations: %w", err)
+	}
+
+	previewErrors := make([]model.PreviewError, 0)
+	for _, table := range tables {
+		if err := validation.ValidateIdentifier("table", table); err != nil {
+			continue
+		}
+		tableErrors, err := constraintViolationErrorsForTable(ctx, conn, table, ops)
+		if err != nil {
+			return nil, err
+		}
+		previewErrors = append(previewErrors, tableErrors...)
+	}
+	return previewErrors, nil
+}
+
+func constraintViolationErrorsForTable(ctx context.Context, conn *sql.Conn, table string, ops []model.CommitOp) ([]model.PreviewError, error) {
+	schemaCols, err := getSchemaColumns(ctx, conn, table)
+	if err != nil {
+		return nil, err
+	}
+	pkCols := getPKColumns(schemaCols)
+
+	// Index ops on this table by the string form of their PK values.
+	opsByPK := make(map[string][]int)
+	for i, op := range ops {
+		if op.Table != table {
+			continue
+		}
+		source := op.PK
+		if op.Type == "insert" {
+			source = op.Values
+		}
+		key := pkValueKey(pkCols, func(col string) (string, bool) {
+			v, ok := source[col]
+			return fmt.Sprint(v), ok
+		})
+		opsByPK[key] = append(opsByPK[key], i)
+	}
+
+	rows, err := conn.QueryContext(ctx, fmt.Sprintf("SELECT * FROM `dolt_constraint_violations_%s`", table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read constraint violations for %s: %w", table, err)
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get constraint violation columns: %w", err)
+	}
+	values := make([]sql.NullString, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	colIndex := make(map[string]int, len(columns))
+	for i, col := range columns {
+		colIndex[col] = i
+	}
+
+	previewErrors := make([]model.PreviewError, 0)
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan constraint violation: %w", err)
+		}
+		violationType := ""
+		if idx, ok := colIndex["violation_type"]; ok {
+			violationType = values[idx].String
+		}
+		key := pkValueKey(pkCols, func(col string) (string, bool) {
+			idx, ok := colIndex[col]
+			if !ok {
+				return "", false
+			}
+			return values[idx].String, values[idx].Valid
+		})
+
+		pe := constraintViolationPreviewError(table, violationType)
+		indexes := opsByPK[key]
+		if len(indexes) == 0 {
+			pe.RowIndex = -1
+			pe.Details.(map[string]string)["pk"] = key
+			previewErrors = append(previewErrors, pe)
+			continue
+		}
+		for _, i := range indexes {
+			attributed := pe
+			attributed.RowIndex = i
+			previewErrors = append(previewErrors, attributed)
+		}
+	}
+	return previewErrors, rows.Err()
+}
+
+func pkValueKey(pkCols []string, value func(col string) (string, bool)) string {
+	parts := make([]string, len(pkCols))
+	for i, col := range pkCols {
+		v, ok := value(col)
+		if !ok {
+			return ""
+		}
+		parts[i] = v
+	}
+	return strings.Join(parts, "\x00")
+}
+
+func constraintViolationPreviewError(table, violationType string) model.PreviewError {
+	details := map[string]string{"table": table, "violation_type": violationType}
+	switch strings.ToLower(violationType) {
+	case "foreign key":
+		details["reason"] = "foreign_key"
+		return model.PreviewError{Code: model.CodeCopyFKError, Message: "外部キー制約違反", Details: details}
+	case "unique index":
+		details["reason"] = "duplicate_key"
+		return model.PreviewError{Code: model.CodePKCollision, Message: "一意キー重複", Details: details}
+	case "check constraint":
+		details["reason"] = "check_constraint"
+		return model.PreviewError{Code: model.CodeCopyDataError, Message: "チェック制約違反", Details: details}
+	case "not null":
+		details["reason"] = "not_null"
+		return model.PreviewError{Code: model.CodeCopyDataError, Message: "必須カラム未設定", Details: details}
+	}
+	details["reason"] = "constraint_violation"
+	return model.PreviewError{Code: model.CodeConstraintViolationsPresent, Message: "制約違反", Details: details}
+}
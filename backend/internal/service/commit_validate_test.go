@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+)
+
+func TestClassifyCommitOpError(t *testing.T) {
+	op := model.CommitOp{Type: "insert", Table: "users"}
+	tests := []struct {
+		name   string
+		err    error
+		code   string
+		reason string
+		column string
+	}{
+		{name: "data too long", err: fmt.Errorf("Data too long for column 'name' at row 1"), code: model.CodeCopyDataError, reason: "data_too_long", column: "name"},
+		{name: "missing default", err: fmt.Errorf("Field 'role' doesn't have a default value"), code: model.CodeCopyDataError, reason: "not_null", column: "role"},
+		{name: "null", err: fmt.Errorf("column 'role' cannot be null"), code: model.CodeCopyDataError, reason: "not_null", column: "role"},
+		{name: "fk", err: fmt.Errorf("cannot add or update a child row - foreign key constraint fails"), code: model.CodeCopyFKError, reason: "foreign_key"},
+		{name: "unique", err: fmt.Errorf("Duplicate entry 'a' for key 'users.name_uq'"), code: model.CodePKCollision, reason: "duplicate_key"},
+		{name: "api error", err: opErrorAt(2, &model.APIError{Status: 404, Code: model.CodeNotFound, Msg: "row not found in users"}), code: model.CodeNotFound, reason: "row_not_found"},
+		{name: "unknown", err: fmt.Errorf("boom"), code: model.CodeCopyDataError, reason: "unknown"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pe := classifyCommitOpError(2, op, tc.err)
+			details := pe.Details.(map[string]string)
+			if pe.RowIndex != 2 || pe.Code != tc.code || details["reason"] != tc.reason || details["column"] != tc.column {
+				t.Fatalf("unexpected preview error: %+v", pe)
+			}
+		})
+	}
+}
+
+func TestValidateCommit_CollectsEveryFailingOpAndAlwaysRollsBack(t *testing.T) {
+	var executed []string
+	repo := newCrossCopyTestRepo(t, nil, commitWorkHandler(&executed, func(query string, args []driver.NamedValue) (testQueryResult, error) {
+		switch query {
+		case "SAVEPOINT commit_validate", "ROLLBACK TO SAVEPOINT commit_validate":
+			return testQueryResult{}, nil
+		case "INSERT INTO `users` (`id`, `name`) VALUES (?, ?), (?, ?), (?, ?)":
+			return testQueryResult{}, fmt.Errorf("Data too long for column 'name' at row 2")
+		case "INSERT INTO `users` (`id`, `name`) VALUES (?, ?)", "INSERT INTO `users` (`name`, `id`) VALUES (?, ?)":
+			for _, arg := range args {
+				if arg.Value == "too-long" {
+					return testQueryResult{}, fmt.Errorf("Data too long for column 'name' at row 1")
+				}
+			}
+			return testQueryResult{}, nil
+		case "SELECT `table`, num_violations FROM dolt_constraint_violations":
+			return testQueryResult{columns: []string{"table", "num_violations"}, rows: [][]driver.Value{{"users", int64(1)}}}, nil
+		case "SHOW COLUMNS FROM `users`":
+			return showColumnsResult("varchar(10)"), nil
+		case "SELECT * FROM `dolt_constraint_violations_users`":
+			return testQueryResult{
+				columns: []string{"from_root_ish", "violation_type", "id", "name", "violation_info"},
+				rows:    [][]driver.Value{{"abc", "foreign key", "3", "ok", "{}"}},
+			}, nil
+		}
+		return testQueryResult{}, fmt.Errorf("unexpected query: %s", query)
+	}))
+	svc := newWithDeps(repo, testServiceConfig())
+
+	resp, err := svc.ValidateCommit(context.Background(), model.CommitRequest{
+		TargetID:   "local",
+		DBName:     "test_db",
+		BranchName: "wi/validate",
+		Ops: []model.CommitOp{
+			{Type: "insert", Table: "users", Values: map[string]interface{}{"id": 1, "name": "too-long"}},
+			{Type: "insert", Table: "users", Values: map[string]interface{}{"id": 2, "name": "too-long"}},
+			{Type: "insert", Table: "users", Values: map[string]interface{}{"id": 3, "name": "ok"}},
+			{Type: "merge", Table: "users"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ValidateCommit: %v (executed %v)", err, executed)
+	}
+	if resp.Valid {
+		t.Fatalf("expected invalid result")
+	}
+
+	got := make([]string, 0, len(resp.Errors))
+	for _, pe := range resp.Errors {
+		got = append(got, fmt.Sprintf("%d:%s:%s", pe.RowIndex, pe.Code, pe.Details.(map[string]string)["reason"]))
+	}
+	want := fmt.Sprint([]string{
+		"0:COPY_DATA_ERROR:data_too_long",
+		"1:COPY_DATA_ERROR:data_too_long",
+		"2:COPY_FK_ERROR:foreign_key",
+		"3:INVALID_ARGUMENT:invalid_op",
+	})
+	if fmt.Sprint(got) != want {
+		t.Fatalf("unexpected errors:\n got %v\nwant %v", got, want)
+	}
+
+	if executed[len(executed)-1] != "ROLLBACK" {
+		t.Fatalf("expected dry run to end with ROLLBACK, got %v", executed)
+	}
+	for _, q := range executed {
+		if q == "CALL DOLT_COMMIT('--allow-empty', '-m', ?)" || q == "COMMIT" {
+			t.Fatalf("dry run must not commit, got %v", executed)
+		}
+	}
+}
@@ -3,7 +3,6 @@ package service
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 	"fmt"
 	"regexp"
 	"strconv"
@@ -77,26 +76,42 @@ func needsExpansion(srcType, dstType string) (bool, string) {
 	return false, ""
 }
 
+// copyErrorClass maps a MySQL error substring to a stable reason and API error.
+type copyErrorClass struct {
+	needle string
+	reason string
+	code   string
+	prefix string
+}
+
+var copyErrorClasses = []copyErrorClass{
+	{needle: "Data too long", reason: "data_too_long", code: model.CodeCopyDataError, prefix: "データ長超過: "},
+	{needle: "Out of range", reason: "out_of_range", code: model.CodeCopyDataError, prefix: "値域超過: "},
+	{needle: "Data truncated", reason: "data_truncated", code: model.CodeCopyDataError, prefix: "データ切り捨て: "},
+	{needle: "doesn't have a default", reason: "not_null", code: model.CodeCopyDataError, prefix: "必須カラム未設定: "},
+	{needle: "Incorrect string value", reason: "invalid_encoding", code: model.CodeCopyDataError, prefix: "文字コード不一致: "},
+	{needle: "foreign key constraint fails", reason: "foreign_key", code: model.CodeCopyFKError, prefix: "外部キー制約違反: "},
+}
+
+// classifyCopyError returns the matching error class for err, if any.
+func classifyCopyError(err error) (copyErrorClass, bool) {
+	s := err.Error()
+	for _, class := range copyErrorClasses {
+		if strings.Contains(s, class.needle) {
+			return class, true
+		}
+	}
+	return copyErrorClass{}, false
+}
+
 // parseCopyError maps MySQL data errors to user-friendly COPY_DATA_ERROR responses.
-// Returns nil if the error is not a recognized copy-related error.
+// Returns nil if the error is not a recognized data error.
 func parseCopyError(err error) *model.APIError {
-	s := err.Error()
-	switch {
-	case strings.Contains(s, "Data too long"):
-		return &model.APIError{Status: 400, Code: model.CodeCopyDataError, Msg: "データ長超過: " + s}
-	case strings.Contains(s, "Out of range"):
-		return &model.APIError{Status: 400, Code: model.CodeCopyDataError, Msg: "値域超過: " + s}
-	case strings.Contains(s, "Data truncated"):
-		return &model.APIError{Status: 400, Code: model.CodeCopyDataError, Msg: "データ切り捨て: " + s}
-	case strings.Contains(s, "doesn't have a default"):
-		return &model.APIError{Status: 400, Code: model.CodeCopyDataError, Msg: "必須カラム未設定: " + s}
-	case strings.Contains(s, "Incorrect string value"):
-		return &model.APIError{Status: 400, Code: model.CodeCopyDataError, Msg: "文字コード不一致: " + s}
-	case strings.Contains(s, "foreign key constraint fails"):
-		return &model.APIError{Status: 400, Code: model.CodeCopyFKError, Msg: "外部キー制約違反: " + s}
-	default:
+	class, ok := classifyCopyError(err)
+	if !ok {
 		return nil
 	}
+	return &model.APIError{Status: 400, Code: class.code, Msg: class.prefix + err.Error()}
 }
 
 // getSchemaColumns fetches column schema for a table on a given connection.
@@ -229,6 +244,24 @@ func crossCopyRowsRetryResponse(hash string, inserted, updated int, message, ret
 	}
 }
 
+// crossCopySourceTarget returns the target the source rows are read from:
+// sourceTargetID when set, otherwise the destination target.
+func crossCopySourceTarget(targetID, sourceTargetID string) string {
+	if sourceTargetID == "" {
+		return targetID
+	}
+	return sourceTargetID
+}
+
+// crossCopySourceLabel names the source in commit messages. A source on
+// another target is written as target:db.
+func crossCopySourceLabel(targetID, sourceTargetID, sourceDB string) string {
+	if src := crossCopySourceTarget(targetID, sourceTargetID); src != targetID {
+		return src + ":" + sourceDB
+	}
+	return sourceDB
+}
+
 // getPKColumns returns PK column names from a schema.
 func getPKColumns(cols []model.ColumnSchema) []string {
 	pks := make([]string, 0)
@@ -319,7 +352,7 @@ func fetchRowsByPKs(ctx context.Context, conn *sql.Conn, table string, pkCols []
 // parsePKJSON parses a PK JSON string like {"id":"1"} into a map.
 func parsePKJSON(pkJSON string) (map[string]interface{}, error) {
 	var m map[string]interface{}
-	if err := json.Unmarshal([]byte(pkJSON), &m); err != nil {
+	if err := unmarshalJSONNumbers([]byte(pkJSON), &m); err != nil {
 		return nil, err
 	}
 	return m, nil
@@ -337,7 +370,8 @@ func (s *Service) CrossCopyPreview(ctx context.Context, req model.CrossCopyPrevi
 	if err := validation.ValidateBranchName(req.SourceBranch); err != nil {
 		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "無効なソースブランチ名"}
 	}
-	if err := s.ensureCrossCopySourceRef(req.TargetID, req.SourceDB, req.SourceBranch); err != nil {
+	sourceTarget := crossCopySourceTarget(req.TargetID, req.SourceTargetID)
+	if err := s.ensureCrossCopySourceRef(sourceTarget, req.SourceDB, req.SourceBranch); err != nil {
 		return nil, err
 	}
 	if err := s.ensureCrossCopyDestinationBranch(req.TargetID, req.DestDB, req.DestBranch); err != nil {
@@ -346,12 +380,12 @@ func (s *Service) CrossCopyPreview(ctx context.Context, req model.CrossCopyPrevi
 	if err := validation.ValidateIdentifier("table", req.SourceTable); err != nil {
 		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "無効なテーブル名"}
 	}
-	if len(req.SourcePKs) == 0 {
-		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "source_pks は空にできません"}
+	if err := validateCrossCopySelection(req.SourcePKs, req.SourceFilters); err != nil {
+		return nil, err
 	}
 
 	// Get source connection (read-only)
-	srcConn, err := s.connCrossCopySourceRevision(ctx, req.TargetID, req.SourceDB, req.SourceBranch)
+	srcConn, err := s.connCrossCopySourceRevision(ctx, sourceTarget, req.SourceDB, req.SourceBranch)
 	if err != nil {
 		return nil, err
 	}
@@ -374,74 +408,72 @@ func (s *Service) CrossCopyPreview(ctx context.Context, req model.CrossCopyPrevi
 		return nil, err
 	}
 
-	shared, srcOnly, dstOnly := computeSharedColumns(srcCols, dstCols)
-	if len(shared) == 0 {
+	plan, err := newCrossCopyColumnPlan(srcCols, dstCols, req.Mapping)
+	if err != nil {
+		return nil, err
+	}
+	if len(plan.src) == 0 {
 		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "共有カラムがありません"}
 	}
 
 	// Generate warnings
 	warnings := make([]string, 0)
-	if len(srcOnly) > 0 {
-		warnings = append(warnings, fmt.Sprintf("コピーされないカラム（コピー元のみ）: %s", strings.Join(srcOnly, ", ")))
+	if len(plan.srcOnly) > 0 {
+		warnings = append(warnings, fmt.Sprintf("コピーされないカラム（コピー元のみ）: %s", strings.Join(plan.srcOnly, ", ")))
 	}
 
 	// Check dest-only NOT NULL without DEFAULT columns
-	srcColMap := make(map[string]model.ColumnSchema, len(srcCols))
-	for _, c := range srcCols {
-		srcColMap[c.Name] = c
-	}
 	dstColMap := make(map[string]model.ColumnSchema, len(dstCols))
 	for _, c := range dstCols {
 		dstColMap[c.Name] = c
 	}
 
-	for _, colName := range dstOnly {
+	for _, colName := range plan.dstOnly {
 		dc := dstColMap[colName]
 		if !dc.Nullable {
 			warnings = append(warnings, fmt.Sprintf("宛先固有カラム %s は NOT NULL です（INSERT失敗の可能性）", colName))
 		}
 	}
 
-	// Check type mismatches on shared columns; detect string columns that need expansion.
-	expandColumns := expandColumnsForShared(srcCols, dstCols, shared)
-	for _, colName := range shared {
-		sc := srcColMap[colName]
-		dc := dstColMap[colName]
-		if sc.Type != dc.Type {
-			if expand, _ := needsExpansion(sc.Type, dc.Type); !expand {
-				warnings = append(warnings, fmt.Sprintf("カラム %s の型が異なります（コピー元: %s, 宛先: %s）", colName, sc.Type, dc.Type))
-			}
-		}
-	}
+	// Check type mismatches on copied columns; detect string columns that need expansion.
+	expandColumns, typeWarnings := plan.typeChecks(srcCols, dstCols)
+	warnings = append(warnings, typeWarnings...)
 
 	// Fetch source rows
-	pkCols := getPKColumns(srcCols)
-	if len(pkCols) == 0 {
+	if len(getPKColumns(srcCols)) == 0 {
 		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "ソーステーブルに主キーがありません"}
 	}
-	srcRows, err := fetchRowsByPKs(ctx, srcConn, req.SourceTable, pkCols, req.SourcePKs, shared)
+	srcRows, err := fetchCrossCopySourceRows(ctx, srcConn, req.SourceTable, srcCols, req.SourcePKs, req.SourceFilters, plan.src)
 	if err != nil {
+		if _, ok := err.(*model.APIError); ok {
+			return nil, err
+		}
 		return nil, fmt.Errorf("failed to fetch source rows: %w", err)
 	}
 
-	// Fetch dest rows for same PKs
+	// Fetch dest rows for the same destination PKs
 	dstPKCols := getPKColumns(dstCols)
 	if len(dstPKCols) == 0 {
 		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "宛先テーブルに主キーがありません"}
 	}
-	dstRows, err := fetchRowsByPKs(ctx, dstConn, req.SourceTable, dstPKCols, req.SourcePKs, shared)
+	written := plan.written()
+	mappedRows, dstPKJSONs, err := crossCopyDestRows(plan, srcRows, dstPKCols)
+	if err != nil {
+		return nil, err
+	}
+	dstRows, err := fetchRowsByPKs(ctx, dstConn, req.SourceTable, dstPKCols, dstPKJSONs, written)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch dest rows: %w", err)
 	}
 
-	// Build preview rows
-	previewRows := make([]model.CrossCopyPreviewRow, 0, len(req.SourcePKs))
-	for _, pkJSON := range req.SourcePKs {
-		srcRow, ok := srcRows[pkJSON]
-		if !ok {
-			continue // source row not found, skip
+	// Build preview rows. With a mapping, source_row holds the values written,
+	// keyed by destination column.
+	previewRows := make([]model.CrossCopyPreviewRow, 0, len(srcRows))
+	for i, srcRow := range srcRows {
+		if plan.renamed {
+			srcRow = mappedRows[i]
 		}
-		dstRow, exists := dstRows[pkJSON]
+		dstRow, exists := dstRows[dstPKJSONs[i]]
 		action := "insert"
 		if exists {
 			action = "update"
@@ -457,12 +489,13 @@ func (s *Service) CrossCopyPreview(ctx context.Context, req model.CrossCopyPrevi
 	}
 
 	return &model.CrossCopyPreviewResponse{
-		SharedColumns:  shared,
-		SourceOnlyCols: srcOnly,
-		DestOnlyCols:   dstOnly,
+		SharedColumns:  written,
+		SourceOnlyCols: plan.srcOnly,
+		DestOnlyCols:   plan.dstOnly,
 		Warnings:       warnings,
 		Rows:           previewRows,
 		ExpandColumns:  expandColumns,
+		ColumnMap:      plan.columnMap(),
 	}, nil
 }
 
@@ -496,6 +529,8 @@ func applyExpandColumns(ctx context.Context, conn *sql.Conn, table string, srcCo
 }
 
 // CrossCopyRows copies selected rows from source DB/branch to dest DB/branch.
+// Rows are read from the source and written by value, so the source may be on
+// another target (SourceTargetID).
 func (s *Service) CrossCopyRows(ctx context.Context, req model.CrossCopyRowsRequest) (*model.CrossCopyRowsResponse, error) {
 	// Validate inputs
 	if err := validation.ValidateDBName(req.SourceDB); err != nil {
@@ -507,7 +542,8 @@ func (s *Service) CrossCopyRows(ctx context.Context, req model.CrossCopyRowsRequ
 	if err := validation.ValidateBranchName(req.SourceBranch); err != nil {
 		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "無効なソースブランチ名"}
 	}
-	if err := s.ensureCrossCopySourceRef(req.TargetID, req.SourceDB, req.SourceBranch); err != nil {
+	sourceTarget := crossCopySourceTarget(req.TargetID, req.SourceTargetID)
+	if err := s.ensureCrossCopySourceRef(sourceTarget, req.SourceDB, req.SourceBranch); err != nil {
 		return nil, err
 	}
 	if err := s.ensureCrossCopyDestinationBranch(req.TargetID, req.DestDB, req.DestBranch); err != nil {
@@ -516,12 +552,12 @@ func (s *Service) CrossCopyRows(ctx context.Context, req model.CrossCopyRowsRequ
 	if err := validation.ValidateIdentifier("table", req.SourceTable); err != nil {
 		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "無効なテーブル名"}
 	}
-	if len(req.SourcePKs) == 0 {
-		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "source_pks は空にできません"}
+	if err := validateCrossCopySelection(req.SourcePKs, req.SourceFilters); err != nil {
+		return nil, err
 	}
 
 	// Source connection (read-only)
-	srcConn, err := s.connCrossCopySourceRevision(ctx, req.TargetID, req.SourceDB, req.SourceBranch)
+	srcConn, err := s.connCrossCopySourceRevision(ctx, sourceTarget, req.SourceDB, req.SourceBranch)
 	if err != nil {
 		return nil, err
 	}
@@ -549,23 +585,28 @@ func (s *Service) CrossCopyRows(ctx context.Context, req model.CrossCopyRowsRequ
 		return nil, err
 	}
 
-	shared, _, _ := computeSharedColumns(srcCols, dstCols)
-	if len(shared) == 0 {
+	plan, err := newCrossCopyColumnPlan(srcCols, dstCols, req.Mapping)
+	if err != nil {
+		return nil, err
+	}
+	if len(plan.src) == 0 {
 		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "共有カラムがありません"}
 	}
 
-	expandColumns := expandColumnsForShared(srcCols, dstCols, shared)
+	expandColumns, _ := plan.typeChecks(srcCols, dstCols)
 	if len(expandColumns) > 0 {
 		return nil, crossCopySchemaPreconditionError(expandColumns)
 	}
 
 	// Fetch source rows
-	pkCols := getPKColumns(srcCols)
-	if len(pkCols) == 0 {
+	if len(getPKColumns(srcCols)) == 0 {
 		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "ソーステーブルに主キーがありません"}
 	}
-	srcRows, err := fetchRowsByPKs(ctx, srcConn, req.SourceTable, pkCols, req.SourcePKs, shared)
+	srcRows, err := fetchCrossCopySourceRows(ctx, srcConn, req.SourceTable, srcCols, req.SourcePKs, req.SourceFilters, plan.src)
 	if err != nil {
+		if _, ok := err.(*model.APIError); ok {
+			return nil, err
+		}
 		return nil, fmt.Errorf("failed to fetch source rows: %w", err)
 	}
 
@@ -574,7 +615,11 @@ func (s *Service) CrossCopyRows(ctx context.Context, req model.CrossCopyRowsRequ
 	if len(dstPKCols) == 0 {
 		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "宛先テーブルに主キーがありません"}
 	}
-	dstRows, err := fetchRowsByPKs(ctx, dstConn, req.SourceTable, dstPKCols, req.SourcePKs, dstPKCols)
+	mappedRows, dstPKJSONs, err := crossCopyDestRows(plan, srcRows, dstPKCols)
+	if err != nil {
+		return nil, err
+	}
+	dstRows, err := fetchRowsByPKs(ctx, dstConn, req.SourceTable, dstPKCols, dstPKJSONs, dstPKCols)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch dest rows: %w", err)
 	}
@@ -591,30 +636,26 @@ func (s *Service) CrossCopyRows(ctx context.Context, req model.CrossCopyRowsRequ
 	}
 
 	// Build INSERT ... ON DUPLICATE KEY UPDATE for each row
-	quotedCols := make([]string, len(shared))
-	for i, c := range shared {
+	written := plan.written()
+	quotedCols := make([]string, len(written))
+	for i, c := range written {
 		quotedCols[i] = fmt.Sprintf("`%s`", c)
 	}
 	colList := strings.Join(quotedCols, ", ")
 
-	updateParts := make([]string, 0, len(shared))
-	for _, c := range shared {
+	updateParts := make([]string, 0, len(written))
+	for _, c := range written {
 		updateParts = append(updateParts, fmt.Sprintf("`%s` = VALUES(`%s`)", c, c))
 	}
 	updateClause := strings.Join(updateParts, ", ")
 
 	inserted, updated := 0, 0
-	for _, pkJSON := range req.SourcePKs {
-		srcRow, ok := srcRows[pkJSON]
-		if !ok {
-			continue
-		}
-
-		placeholders := make([]string, len(shared))
-		args := make([]interface{}, len(shared))
-		for i, c := range shared {
-			placeholders[i] = "?"
-			args[i] = srcRow[c]
+	for i, row := range mappedRows {
+		placeholders := make([]string, len(written))
+		args := make([]interface{}, len(written))
+		for j, c := range written {
+			placeholders[j] = "?"
+			args[j] = row[c]
 		}
 
 		query := fmt.Sprintf("INSERT INTO `%s` (%s) VALUES (%s) ON DUPLICATE KEY UPDATE %s",
@@ -628,7 +669,7 @@ func (s *Service) CrossCopyRows(ctx context.Context, req model.CrossCopyRowsRequ
 			return nil, fmt.Errorf("failed to insert/update row: %w", err)
 		}
 
-		if _, exists := dstRows[pkJSON]; exists {
+		if _, exists := dstRows[dstPKJSONs[i]]; exists {
 			updated++
 		} else {
 			inserted++
@@ -677,7 +718,7 @@ func (s *Service) CrossCopyRows(ctx context.Context, req model.CrossCopyRowsRequ
 		return nil, fmt.Errorf("failed to dolt add: %w", err)
 	}
 
-	commitMsg := fmt.Sprintf("[cross-copy] %sから%d件コピー（%s）", req.SourceDB, inserted+updated, req.SourceTable)
+	commitMsg := fmt.Sprintf("[cross-copy] %sから%d件コピー（%s）", crossCopySourceLabel(req.TargetID, req.SourceTargetID, req.SourceDB), inserted+updated, req.SourceTable)
 	if _, err := dstConn.ExecContext(ctx, "CALL DOLT_COMMIT('--allow-empty', '-m', ?)", commitMsg); err != nil {
 		dstConn.ExecContext(context.Background(), "ROLLBACK")
 		return nil, fmt.Errorf("failed to commit: %w", err)
@@ -39,8 +39,8 @@ func currentHeadHash(ctx context.Context, conn *sql.Conn) (string, error) {
 	return hash, nil
 }
 
-func (s *Service) crossCopySchemaPreparation(ctx context.Context, targetID, sourceDB, sourceBranch, sourceTable, destDB string) (*crossCopySchemaPreparation, error) {
-	srcConn, err := s.connCrossCopySourceRevision(ctx, targetID, sourceDB, sourceBranch)
+func (s *Service) crossCopySchemaPreparation(ctx context.Context, targetID, sourceTargetID, sourceDB, sourceBranch, sourceTable, destDB string) (*crossCopySchemaPreparation, error) {
+	srcConn, err := s.connCrossCopySourceRevision(ctx, crossCopySourceTarget(targetID, sourceTargetID), sourceDB, sourceBranch)
 	if err != nil {
 		return nil, err
 	}
@@ -99,6 +99,11 @@ func (s *Service) prepareCrossCopySchemaOnMain(
 		return hash, false, nil
 	}
 
+	// The schema preparation commits on main, so it waits for approval merges.
+	mainLock := &s.mergeQueues.queue(targetID, destDB).mainLock
+	mainLock.Lock()
+	defer mainLock.Unlock()
+
 	conn, connErr := s.repo.ConnProtectedMaintenance(ctx, targetID, destDB, "main")
 	if connErr != nil {
 		return "", true, fmt.Errorf("failed to connect to destination main: %w", connErr)
@@ -288,7 +293,7 @@ func (s *Service) CrossCopyAdminPrepareRows(ctx context.Context, req model.Cross
 	if err := validation.ValidateBranchName(req.SourceBranch); err != nil {
 		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "無効なソースブランチ名"}
 	}
-	if err := s.ensureCrossCopySourceRef(req.TargetID, req.SourceDB, req.SourceBranch); err != nil {
+	if err := s.ensureCrossCopySourceRef(crossCopySourceTarget(req.TargetID, req.SourceTargetID), req.SourceDB, req.SourceBranch); err != nil {
 		return nil, err
 	}
 	if err := s.ensureCrossCopyDestinationBranch(req.TargetID, req.DestDB, req.DestBranch); err != nil {
@@ -298,7 +303,7 @@ func (s *Service) CrossCopyAdminPrepareRows(ctx context.Context, req model.Cross
 		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "無効なテーブル名"}
 	}
 
-	prep, err := s.crossCopySchemaPreparation(ctx, req.TargetID, req.SourceDB, req.SourceBranch, req.SourceTable, req.DestDB)
+	prep, err := s.crossCopySchemaPreparation(ctx, req.TargetID, req.SourceTargetID, req.SourceDB, req.SourceBranch, req.SourceTable, req.DestDB)
 	if err != nil {
 		return nil, err
 	}
@@ -308,7 +313,7 @@ func (s *Service) CrossCopyAdminPrepareRows(ctx context.Context, req model.Cross
 		return nil, err
 	}
 
-	mainHash, attempted, err := s.prepareCrossCopySchemaOnMain(ctx, req.TargetID, req.SourceDB, req.SourceBranch, req.SourceTable, req.DestDB, prep)
+	mainHash, attempted, err := s.prepareCrossCopySchemaOnMain(ctx, req.TargetID, crossCopySourceLabel(req.TargetID, req.SourceTargetID, req.SourceDB), req.SourceBranch, req.SourceTable, req.DestDB, prep)
 	if err != nil {
 		if attempted {
 			return adminPrepareRowsRetryResponse(
@@ -398,19 +403,19 @@ func (s *Service) CrossCopyAdminPrepareTable(ctx context.Context, req model.Cros
 	if err := validation.ValidateBranchName(req.SourceBranch); err != nil {
 		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "無効なソースブランチ名"}
 	}
-	if err := s.ensureCrossCopySourceRef(req.TargetID, req.SourceDB, req.SourceBranch); err != nil {
+	if err := s.ensureCrossCopySourceRef(crossCopySourceTarget(req.TargetID, req.SourceTargetID), req.SourceDB, req.SourceBranch); err != nil {
 		return nil, err
 	}
 	if err := validation.ValidateIdentifier("table", req.SourceTable); err != nil {
 		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "無効なテーブル名"}
 	}
 
-	prep, err := s.crossCopySchemaPreparation(ctx, req.TargetID, req.SourceDB, req.SourceBranch, req.SourceTable, req.DestDB)
+	prep, err := s.crossCopySchemaPreparation(ctx, req.TargetID, req.SourceTargetID, req.SourceDB, req.SourceBranch, req.SourceTable, req.DestDB)
 	if err != nil {
 		return nil, err
 	}
 
-	mainHash, attempted, err := s.prepareCrossCopySchemaOnMain(ctx, req.TargetID, req.SourceDB, req.SourceBranch, req.SourceTable, req.DestDB, prep)
+	mainHash, attempted, err := s.prepareCrossCopySchemaOnMain(ctx, req.TargetID, crossCopySourceLabel(req.TargetID, req.SourceTargetID, req.SourceDB), req.SourceBranch, req.SourceTable, req.DestDB, prep)
 	if err != nil {
 		if attempted {
 			return adminPrepareTableRetryResponse(
@@ -43,6 +43,10 @@ func TestCrossCopyAdminPrepareRows_PreparesMainAndSyncsBranch(t *testing.T) {
 				return testQueryResult{columns: []string{"table", "data_conflicts", "schema_conflicts"}, rows: nil}, nil
 			case "START TRANSACTION":
 				return testQueryResult{}, nil
+			case "SELECT COUNT(*) FROM dolt_log('main', '--not', 'HEAD')":
+				return testQueryResult{columns: []string{"count(*)"}, rows: [][]driver.Value{{int64(1)}}}, nil
+			case "SELECT message FROM dolt_tags WHERE tag_name = ?":
+				return testQueryResult{columns: []string{"message"}}, nil
 			case "CALL DOLT_MERGE('main')":
 				return syncSuccessResult("merge-hash"), nil
 			case "COMMIT":
@@ -125,6 +129,10 @@ func TestCrossCopyAdminPrepareRows_NoSchemaPrepSkipsProtectedMaintenance(t *test
 				return testQueryResult{columns: []string{"table", "data_conflicts", "schema_conflicts"}, rows: nil}, nil
 			case "START TRANSACTION":
 				return testQueryResult{}, nil
+			case "SELECT COUNT(*) FROM dolt_log('main', '--not', 'HEAD')":
+				return testQueryResult{columns: []string{"count(*)"}, rows: [][]driver.Value{{int64(1)}}}, nil
+			case "SELECT message FROM dolt_tags WHERE tag_name = ?":
+				return testQueryResult{columns: []string{"message"}}, nil
 			case "CALL DOLT_MERGE('main')":
 				return syncSuccessResult("merge-hash"), nil
 			case "COMMIT":
@@ -0,0 +1,314 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+)
+
+// crossCopyMaxFilterRows caps the source rows a filter may select for a row copy.
+const crossCopyMaxFilterRows = 1000
+
+// crossCopyColumnPlan pairs each copied source column with its destination
+// column. Without a mapping, columns are matched by name.
+type crossCopyColumnPlan struct {
+	src       []string               // source columns read, in source order
+	dst       []string               // destination column of src[i]
+	constants map[string]interface{} // destination column -> value for every row
+	srcOnly   []string               // source columns not copied
+	dstOnly   []string               // destination columns not written
+	renamed   bool
+}
+
+func crossCopyMappingError(format string, args ...interface{}) *model.APIError {
+	return &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "mapping: " + fmt.Sprintf(format, args...)}
+}
+
+// newCrossCopyColumnPlan resolves mapping against both schemas. Every mapped,
+// skipped or constant column must exist, two source columns may not write the
+// same destination column, and constants must fit their column type.
+func newCrossCopyColumnPlan(srcCols, dstCols []model.ColumnSchema, mapping *model.CrossCopyMapping) (*crossCopyColumnPlan, error) {
+	if mapping == nil {
+		shared, srcOnly, dstOnly := computeSharedColumns(srcCols, dstCols)
+		return &crossCopyColumnPlan{src: shared, dst: shared, srcOnly: srcOnly, dstOnly: dstOnly}, nil
+	}
+
+	srcByName := make(map[string]bool, len(srcCols))
+	for _, c := range srcCols {
+		srcByName[c.Name] = true
+	}
+	dstByName := make(map[string]model.ColumnSchema, len(dstCols))
+	for _, c := range dstCols {
+		dstByName[c.Name] = c
+	}
+	skip := make(map[string]bool, len(mapping.Skip))
+	for _, c := range mapping.Skip {
+		if !srcByName[c] {
+			return nil, crossCopyMappingError("コピー元にカラム %s がありません", c)
+		}
+		skip[c] = true
+	}
+	for _, src := range sortedStringKeys(mapping.Columns) {
+		if !srcByName[src] {
+			return nil, crossCopyMappingError("コピー元にカラム %s がありません", src)
+		}
+		if _, ok := dstByName[mapping.Columns[src]]; !ok {
+			return nil, crossCopyMappingError("宛先にカラム %s がありません", mapping.Columns[src])
+		}
+		if skip[src] {
+			return nil, crossCopyMappingError("カラム %s はマップとスキップの両方に指定されています", src)
+		}
+	}
+
+	plan := &crossCopyColumnPlan{constants: make(map[string]interface{}, len(mapping.Constants)), renamed: true}
+	written := make(map[string]string)
+	for _, dst := range sortedStringKeys(mapping.Constants) {
+		col, ok := dstByName[dst]
+		if !ok {
+			return nil, crossCopyMappingError("宛先にカラム %s がありません", dst)
+		}
+		v, err := coerceCSVValue(col, mapping.Constants[dst])
+		if err != nil {
+			return nil, crossCopyMappingError("定数 %s: %v", dst, err)
+		}
+		plan.constants[dst] = v
+		written[dst] = "定数"
+	}
+	for _, c := range srcCols {
+		dst, mapped := mapping.Columns[c.Name]
+		if !mapped {
+			dst = c.Name
+		}
+		if _, ok := dstByName[dst]; skip[c.Name] || !ok {
+			plan.srcOnly = append(plan.srcOnly, c.Name)
+			continue
+		}
+		if prev, ok := written[dst]; ok {
+			return nil, crossCopyMappingError("宛先カラム %s に %s と %s の両方が割り当てられています", dst, prev, c.Name)
+		}
+		written[dst] = c.Name
+		plan.src = append(plan.src, c.Name)
+		plan.dst = append(plan.dst, dst)
+	}
+	for _, c := range dstCols {
+		if _, ok := written[c.Name]; !ok {
+			plan.dstOnly = append(plan.dstOnly, c.Name)
+		}
+	}
+	if plan.srcOnly == nil {
+		plan.srcOnly = make([]string, 0)
+	}
+	if plan.dstOnly == nil {
+		plan.dstOnly = make([]string, 0)
+	}
+	return plan, nil
+}
+
+// written returns the destination columns an INSERT writes: the copied
+// columns, then the constants by name.
+func (p *crossCopyColumnPlan) written() []string {
+	cols := append([]string(nil), p.dst...)
+	consts := make([]string, 0, len(p.constants))
+	for c := range p.constants {
+		consts = append(consts, c)
+	}
+	sort.Strings(consts)
+	return append(cols, consts...)
+}
+
+// destRow turns a source row into the row written to the destination.
+func (p *crossCopyColumnPlan) destRow(src map[string]interface{}) map[string]interface{} {
+	row := make(map[string]interface{}, len(p.dst)+len(p.constants))
+	for i, c := range p.src {
+		row[p.dst[i]] = src[c]
+	}
+	for c, v := range p.constants {
+		row[c] = v
+	}
+	return row
+}
+
+// columnMap returns destination -> source column for a renamed plan.
+func (p *crossCopyColumnPlan) columnMap() map[string]string {
+	if !p.renamed {
+		return nil
+	}
+	m := make(map[string]string, len(p.dst))
+	for i, dst := range p.dst {
+		m[dst] = p.src[i]
+	}
+	return m
+}
+
+// typeChecks compares the type of each copied pair. Destination string
+// columns narrower than the source need expansion; other mismatches are
+// returned as warnings.
+func (p *crossCopyColumnPlan) typeChecks(srcCols, dstCols []model.ColumnSchema) ([]model.ExpandColumn, []string) {
+	srcTypes := make(map[string]string, len(srcCols))
+	for _, c := range srcCols {
+		srcTypes[c.Name] = c.Type
+	}
+	dstTypes := make(map[string]string, len(dstCols))
+	for _, c := range dstCols {
+		dstTypes[c.Name] = c.Type
+	}
+
+	expandColumns := make([]model.ExpandColumn, 0)
+	var warnings []string
+	for i, src := range p.src {
+		dst := p.dst[i]
+		st, dt := srcTypes[src], dstTypes[dst]
+		if st == dt {
+			continue
+		}
+		if expand, _ := needsExpansion(st, dt); expand {
+			expandColumns = append(expandColumns, model.ExpandColumn{Name: dst, SrcType: st, DstType: dt})
+			continue
+		}
+		name := dst
+		if src != dst {
+			name = src + " → " + dst
+		}
+		warnings = append(warnings, fmt.Sprintf("カラム %s の型が異なります（コピー元: %s, 宛先: %s）", name, st, dt))
+	}
+	return expandColumns, warnings
+}
+
+// destPKJSON returns the destination primary key of a destination row in the
+// source_pks format, or false if a key column is not written.
+func destPKJSON(row map[string]interface{}, pkCols []string) (string, bool) {
+	pk := make(map[string]interface{}, len(pkCols))
+	for _, c := range pkCols {
+		v, ok := row[c]
+		if !ok {
+			return "", false
+		}
+		pk[c] = v
+	}
+	b, err := json.Marshal(pk)
+	if err != nil {
+		return "", false
+	}
+	return string(b), true
+}
+
+// crossCopyDestRows maps source rows to destination rows and returns the
+// destination primary key of each. Every destination key column must be
+// written by the plan.
+func crossCopyDestRows(plan *crossCopyColumnPlan, srcRows []map[string]interface{}, dstPKCols []string) ([]map[string]interface{}, []string, error) {
+	rows := make([]map[string]interface{}, len(srcRows))
+	pkJSONs := make([]string, len(srcRows))
+	for i, src := range srcRows {
+		rows[i] = plan.destRow(src)
+		pkJSON, ok := destPKJSON(rows[i], dstPKCols)
+		if !ok {
+			return nil, nil, &model.APIError{
+				Status: 400,
+				Code:   model.CodeInvalidArgument,
+				Msg:    fmt.Sprintf("宛先の主キー (%s) がすべてコピーされるようにしてください", strings.Join(dstPKCols, ", ")),
+			}
+		}
+		pkJSONs[i] = pkJSON
+	}
+	return rows, pkJSONs, nil
+}
+
+// validateCrossCopySelection checks that exactly one of source_pks and
+// source_filters selects the rows.
+func validateCrossCopySelection(pks []string, filters []model.FilterCondition) error {
+	if len(pks) > 0 && len(filters) > 0 {
+		return &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "source_pks と source_filters は同時に指定できません"}
+	}
+	if len(pks) == 0 && len(filters) == 0 {
+		return &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "source_pks か source_filters を指定してください"}
+	}
+	return nil
+}
+
+// fetchCrossCopySourceRows reads selectCols of the selected source rows: in
+// the order of pkJSONs (missing keys are skipped), or the rows matching
+// filters in primary key order. More than crossCopyMaxFilterRows matching rows
+// is 400 INVALID_ARGUMENT.
+func fetchCrossCopySourceRows(ctx context.Context, conn *sql.Conn, table string, cols []model.ColumnSchema, pkJSONs []string, filters []model.FilterCondition, selectCols []string) ([]map[string]interface{}, error) {
+	pkCols := getPKColumns(cols)
+	if len(filters) == 0 {
+		byPK, err := fetchRowsByPKs(ctx, conn, table, pkCols, pkJSONs, selectCols)
+		if err != nil {
+			return nil, err
+		}
+		rows := make([]map[string]interface{}, 0, len(byPK))
+		for _, pkJSON := range pkJSONs {
+			if row, ok := byPK[pkJSON]; ok {
+				rows = append(rows, row)
+			}
+		}
+		return rows, nil
+	}
+
+	allowedCols := make(map[string]bool, len(cols))
+	for _, c := range cols {
+		allowedCols[c.Name] = true
+	}
+	whereParts := make([]string, 0, len(filters))
+	var whereArgs []interface{}
+	for _, f := range filters {
+		part, args, apiErr := buildFilterSQL(f, allowedCols)
+		if apiErr != nil {
+			return nil, apiErr
+		}
+		whereParts = append(whereParts, part)
+		whereArgs = append(whereArgs, args...)
+	}
+	orderBy, apiErr := buildStableOrderByClause("", allowedCols, pkCols)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	quotedCols := make([]string, len(selectCols))
+	for i, c := range selectCols {
+		quotedCols[i] = fmt.Sprintf("`%s`", c)
+	}
+	query := fmt.Sprintf("SELECT %s FROM `%s` WHERE %s %s LIMIT %d",
+		strings.Join(quotedCols, ", "), table, strings.Join(whereParts, " AND "), orderBy, crossCopyMaxFilterRows+1)
+
+	rows, err := conn.QueryContext(ctx, query, whereArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query source rows: %w", err)
+	}
+	defer rows.Close()
+	result := make([]map[string]interface{}, 0)
+	for rows.Next() {
+		vals := make([]interface{}, len(selectCols))
+		ptrs := make([]interface{}, len(selectCols))
+		for i := range vals {
+			ptrs[i] = &vals[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, fmt.Errorf("failed to scan source row: %w", err)
+		}
+		row := make(map[string]interface{}, len(selectCols))
+		for i, c := range selectCols {
+			if b, ok := vals[i].([]byte); ok {
+				row[c] = string(b)
+			} else {
+				row[c] = vals[i]
+			}
+		}
+		result = append(result, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read source rows: %w", err)
+	}
+	if len(result) > crossCopyMaxFilterRows {
+		return nil, &model.APIError{
+			Status: 400,
+			Code:   model.CodeInvalidArgument,
+			Msg:    fmt.Sprintf("フィルタに一致する行が上限 %d 件を超えています。条件を絞り込むか、テーブルコピーを使用してください", crossCopyMaxFilterRows),
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+)
+
+var (
+	crossCopyRenameSource = []model.ColumnSchema{
+		{Name: "id", Type: "int", PrimaryKey: true},
+		{Name: "full_name", Type: "varchar(100)", Nullable: true},
+		{Name: "note", Type: "text", Nullable: true},
+	}
+	crossCopyRenameDest = []model.ColumnSchema{
+		{Name: "id", Type: "int", PrimaryKey: true},
+		{Name: "name", Type: "varchar(50)", Nullable: true},
+		{Name: "status", Type: "enum('active','retired')"},
+	}
+)
+
+func schemaResult(cols []model.ColumnSchema) testQueryResult {
+	result := testQueryResult{columns: []string{"Field", "Type", "Null", "Key", "Default", "Extra"}}
+	for _, c := range cols {
+		null, key := "NO", ""
+		if c.Nullable {
+			null = "YES"
+		}
+		if c.PrimaryKey {
+			key = "PRI"
+		}
+		result.rows = append(result.rows, []driver.Value{c.Name, c.Type, null, key, nil, ""})
+	}
+	return result
+}
+
+func TestNewCrossCopyColumnPlan_RenamesSkipsAndConstants(t *testing.T) {
+	mapping := &model.CrossCopyMapping{
+		Columns:   map[string]string{"full_name": "name"},
+		Constants: map[string]string{"status": "active"},
+		Skip:      []string{"note"},
+	}
+	plan, err := newCrossCopyColumnPlan(crossCopyRenameSource, crossCopyRenameDest, mapping)
+	if err != nil {
+		t.Fatalf("newCrossCopyColumnPlan: %v", err)
+	}
+	if got := plan.written(); !reflect.DeepEqual(got, []string{"id", "name", "status"}) {
+		t.Fatalf("written = %v", got)
+	}
+	if !reflect.DeepEqual(plan.srcOnly, []string{"note"}) || len(plan.dstOnly) != 0 {
+		t.Fatalf("srcOnly = %v, dstOnly = %v", plan.srcOnly, plan.dstOnly)
+	}
+	row := plan.destRow(map[string]interface{}{"id": int64(1), "full_name": "Alice"})
+	if row["name"] != "Alice" || row["status"] != "active" {
+		t.Fatalf("destRow = %v", row)
+	}
+	expand, _ := plan.typeChecks(crossCopyRenameSource, crossCopyRenameDest)
+	if len(expand) != 1 || expand[0].Name != "name" || expand[0].SrcType != "varchar(100)" {
+		t.Fatalf("expected the renamed column to need expansion, got %+v", expand)
+	}
+
+	for name, bad := range map[string]*model.CrossCopyMapping{
+		"unknown destination": {Columns: map[string]string{"full_name": "nickname"}},
+		"unknown source":      {Skip: []string{"missing"}},
+		"bad constant":        {Constants: map[string]string{"status": "deleted"}},
+		"two writers":         {Columns: map[string]string{"full_name": "id"}},
+	} {
+		if _, err := newCrossCopyColumnPlan(crossCopyRenameSource, crossCopyRenameDest, bad); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestCrossCopyRows_ByFilterWithRenamedColumns(t *testing.T) {
+	dest := append([]model.ColumnSchema(nil), crossCopyRenameDest...)
+	dest[1].Type = "varchar(100)"
+	var upserts [][]driver.NamedValue
+	var upsertQuery string
+	repo := newCrossCopyTestRepo(t,
+		func(dbName, refName, query string, args []driver.NamedValue) (testQueryResult, error) {
+			switch query {
+			case "SHOW COLUMNS FROM `users`":
+				return schemaResult(crossCopyRenameSource), nil
+			case "SELECT `id`, `full_name` FROM `users` WHERE `full_name` LIKE CONCAT(?, '%') ORDER BY `id` ASC LIMIT 1001":
+				return testQueryResult{
+					columns: []string{"id", "full_name"},
+					rows:    [][]driver.Value{{int64(1), "Alice"}, {int64(2), "Aaron"}},
+				}, nil
+			}
+			return testQueryResult{}, fmt.Errorf("unexpected revision query on %s/%s: %s", dbName, refName, query)
+		},
+		func(dbName, branchName, query string, args []driver.NamedValue) (testQueryResult, error) {
+			switch {
+			case query == "SHOW COLUMNS FROM `users`":
+				return schemaResult(dest), nil
+			case query == "SELECT COUNT(*) FROM dolt_tags WHERE tag_name = ?",
+				query == "SELECT COUNT(*) FROM dolt_constraint_violations":
+				return testQueryResult{columns: []string{"count"}, rows: [][]driver.Value{{int64(0)}}}, nil
+			case query == "SELECT `id` FROM `users` WHERE `id` = ? LIMIT 1":
+				if fmt.Sprint(args[0].Value) == "1" {
+					return testQueryResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}}}, nil
+				}
+				return testQueryResult{columns: []string{"id"}}, nil
+			case query == "SELECT DOLT_HASHOF('HEAD')":
+				return testQueryResult{columns: []string{"hash"}, rows: [][]driver.Value{{"head"}}}, nil
+			case strings.HasPrefix(query, "INSERT INTO `users`"):
+				upsertQuery = query
+				upserts = append(upserts, args)
+			}
+			return testQueryResult{}, nil
+		},
+	)
+	svc := newWithDeps(repo, testServiceConfig())
+	svc.branchReadinessProbe = func(ctx context.Context, targetID, dbName, branch string) branchQueryabilityResult {
+		return branchQueryabilityResult{Ready: true, Attempts: 1}
+	}
+
+	resp, err := svc.CrossCopyRows(context.Background(), model.CrossCopyRowsRequest{
+		TargetID:      "local",
+		SourceDB:      "test_db",
+		SourceBranch:  "main",
+		SourceTable:   "users",
+		SourceFilters: []model.FilterCondition{{Column: "full_name", Op: "startsWith", Value: "A"}},
+		Mapping: &model.CrossCopyMapping{
+			Columns:   map[string]string{"full_name": "name"},
+			Constants: map[string]string{"status": "active"},
+			Skip:      []string{"note"},
+		},
+		DestDB:     "test_db",
+		DestBranch: "wi/dest-users",
+	})
+	if err != nil {
+		t.Fatalf("CrossCopyRows: %v", err)
+	}
+	if resp.Inserted != 1 || resp.Updated != 1 || resp.Outcome != model.OperationOutcomeCompleted {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if !strings.HasPrefix(upsertQuery, "INSERT INTO `users` (`id`, `name`, `status`) VALUES (?, ?, ?)") {
+		t.Fatalf("unexpected upsert: %s", upsertQuery)
+	}
+	if len(upserts) != 2 || upserts[1][1].Value != "Aaron" || upserts[1][2].Value != "active" {
+		t.Fatalf("unexpected upsert args: %+v", upserts)
+	}
+
+	_, err = svc.CrossCopyRows(context.Background(), model.CrossCopyRowsRequest{
+		TargetID: "local", SourceDB: "test_db", SourceBranch: "main", SourceTable: "users",
+		SourcePKs:     []string{`{"id":1}`},
+		SourceFilters: []model.FilterCondition{{Column: "id", Op: "eq", Value: 1}},
+		DestDB:        "test_db", DestBranch: "wi/dest-users",
+	})
+	if apiErr, ok := err.(*model.APIError); !ok || apiErr.Code != model.CodeInvalidArgument {
+		t.Fatalf("expected pks and filters together to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/config"
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+)
+
+// testTwoTargetConfig adds a "staging" target whose master_db is the copy source.
+func testTwoTargetConfig() *config.Config {
+	cfg := testServiceConfig()
+	cfg.Targets = append(cfg.Targets, config.Target{ID: "staging"})
+	cfg.Databases = append(cfg.Databases, config.Database{
+		TargetID:        "staging",
+		Name:            "master_db",
+		AllowedBranches: []string{"main", "audit"},
+	})
+	return cfg
+}
+
+func TestCrossCopyTable_OtherTargetStreamsRowsIntoImportBranch(t *testing.T) {
+	var workQueries []string
+	var insertArgs []driver.NamedValue
+	var commitMsg string
+	repo := newCrossCopyTestRepo(t,
+		func(dbName, refName, query string, args []driver.NamedValue) (testQueryResult, error) {
+			switch {
+			case query == "SHOW COLUMNS FROM `users`":
+				return showColumnsResult("varchar(255)"), nil
+			case dbName == "master_db" && query == "SELECT `id`, `name` FROM `users`":
+				return testQueryResult{
+					columns: []string{"id", "name"},
+					rows:    [][]driver.Value{{int64(1), "Alice"}, {int64(2), "Bob"}, {int64(3), nil}},
+				}, nil
+			case dbName == "test_db" && query == "SELECT COUNT(*) FROM dolt_branches WHERE name = ?":
+				return testQueryResult{columns: []string{"count(*)"}, rows: [][]driver.Value{{int64(0)}}}, nil
+			case dbName == "test_db" && query == "CALL DOLT_BRANCH(?)":
+				return testQueryResult{}, nil
+			}
+			return testQueryResult{}, fmt.Errorf("unexpected revision query on %s/%s: %s", dbName, refName, query)
+		},
+		func(dbName, branchName, query string, args []driver.NamedValue) (testQueryResult, error) {
+			workQueries = append(workQueries, query)
+			switch {
+			case query == "SELECT COUNT(*) FROM dolt_constraint_violations":
+				return testQueryResult{columns: []string{"count"}, rows: [][]driver.Value{{int64(0)}}}, nil
+			case query == "SELECT DOLT_HASHOF('HEAD')":
+				return testQueryResult{columns: []string{"hash"}, rows: [][]driver.Value{{"head-2"}}}, nil
+			case strings.HasPrefix(query, "INSERT INTO `users`"):
+				insertArgs = args
+			case strings.HasPrefix(query, "CALL DOLT_COMMIT"):
+				commitMsg = fmt.Sprint(args[0].Value)
+			}
+			return testQueryResult{}, nil
+		},
+	)
+	svc := newWithDeps(repo, testTwoTargetConfig())
+	svc.branchReadinessProbe = func(ctx context.Context, targetID, dbName, branch string) branchQueryabilityResult {
+		return branchQueryabilityResult{Ready: true, Attempts: 1}
+	}
+
+	resp, err := svc.CrossCopyTable(context.Background(), model.CrossCopyTableRequest{
+		TargetID:       "local",
+		SourceTargetID: "staging",
+		SourceDB:       "master_db",
+		SourceBranch:   "main",
+		SourceTable:    "users",
+		DestDB:         "test_db",
+	})
+	if err != nil {
+		t.Fatalf("CrossCopyTable: %v", err)
+	}
+	if resp.Outcome != model.OperationOutcomeCompleted || resp.RowCount != 3 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	joined := strings.Join(workQueries, "\n")
+	if strings.Contains(joined, "master_db/") {
+		t.Fatalf("same-server revision syntax used for another target:\n%s", joined)
+	}
+	if !strings.Contains(joined, "INSERT INTO `users` (`id`, `name`) VALUES (?, ?), (?, ?), (?, ?)") || len(insertArgs) != 6 || insertArgs[5].Value != nil {
+		t.Fatalf("unexpected batched insert (%d args):\n%s", len(insertArgs), joined)
+	}
+	if !strings.Contains(commitMsg, "staging:master_db") {
+		t.Fatalf("commit message should name the source target: %q", commitMsg)
+	}
+}
+
+func TestCrossCopyRows_OtherTargetMustBeConfigured(t *testing.T) {
+	repo := newCrossCopyTestRepo(t, nil, nil)
+	svc := newWithDeps(repo, testTwoTargetConfig())
+
+	_, err := svc.CrossCopyRows(context.Background(), model.CrossCopyRowsRequest{
+		TargetID:       "local",
+		SourceTargetID: "staging",
+		SourceDB:       "test_db",
+		SourceBranch:   "main",
+		SourceTable:    "users",
+		SourcePKs:      []string{`{"id":1}`},
+		DestDB:         "test_db",
+		DestBranch:     "wi/copy",
+	})
+	if apiErr, ok := err.(*model.APIError); !ok || apiErr.Code != model.CodeNotFound {
+		t.Fatalf("expected NOT_FOUND for a database missing on the source target, got %v", err)
+	}
+	if len(repo.calls) != 0 {
+		t.Fatalf("expected no connections, got %+v", repo.calls)
+	}
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"log"
 	"strings"
@@ -26,6 +27,28 @@ func (s *Service) cleanupCrossCopyBranch(ctx context.Context, targetID, dbName,
 	return nil
 }
 
+// createImportBranch creates a deterministic import branch from destination
+// main. An existing branch is 409 BRANCH_EXISTS.
+func (s *Service) createImportBranch(ctx context.Context, targetID, destDB, branchName string) error {
+	conn, err := s.connMetadataRevision(ctx, targetID, destDB)
+	if err != nil {
+		return fmt.Errorf("failed to connect to dest DB: %w", err)
+	}
+	defer conn.Close()
+
+	exists, err := branchExists(ctx, conn, branchName)
+	if err != nil {
+		return fmt.Errorf("failed to check branch %s: %w", branchName, err)
+	}
+	if exists {
+		return newBranchExistsError(branchName)
+	}
+	if _, err := conn.ExecContext(ctx, "CALL DOLT_BRANCH(?)", branchName); err != nil {
+		return classifyBranchCreateError(ctx, conn, branchName, err)
+	}
+	return nil
+}
+
 func crossCopyTableFailureResponse(branchName string, shared, srcOnly, dstOnly []string, cleanupErr error) *model.CrossCopyTableResponse {
 	outcome := model.OperationOutcomeFailed
 	message := "インポートブランチの準備に失敗しました。残留ブランチは掃除済みです。"
@@ -84,7 +107,103 @@ func crossCopyTableRetryResponse(branchName, hash string, rowCount int, shared,
 	}
 }
 
+// crossCopyStreamBatchRows caps the rows of one INSERT when a table is streamed
+// from another target; crossCopyStreamMaxArgs keeps wide tables under the
+// placeholder limit of a prepared statement.
+const (
+	crossCopyStreamBatchRows = 500
+	crossCopyStreamMaxArgs   = 60000
+)
+
+// streamCopyTableRows copies cols of every source row into the destination table
+// with batched multi-row INSERTs. It replaces INSERT ... SELECT when the source
+// is on another server. Insert errors are returned unwrapped for parseCopyError.
+func streamCopyTableRows(ctx context.Context, srcConn, dstConn *sql.Conn, table string, cols []string) (int64, error) {
+	quotedCols := make([]string, len(cols))
+	for i, c := range cols {
+		quotedCols[i] = fmt.Sprintf("`%s`", c)
+	}
+	colList := strings.Join(quotedCols, ", ")
+	rowPlaceholder := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(cols)), ", ") + ")"
+	batchRows := min(crossCopyStreamBatchRows, max(1, crossCopyStreamMaxArgs/len(cols)))
+
+	rows, err := srcConn.QueryContext(ctx, fmt.Sprintf("SELECT %s FROM `%s`", colList, table))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read source table: %w", err)
+	}
+	defer rows.Close()
+
+	var copied int64
+	args := make([]interface{}, 0, batchRows*len(cols))
+	flush := func() error {
+		if len(args) == 0 {
+			return nil
+		}
+		n := len(args) / len(cols)
+		placeholders := strings.TrimSuffix(strings.Repeat(rowPlaceholder+", ", n), ", ")
+		query := fmt.Sprintf("INSERT INTO `%s` (%s) VALUES %s", table, colList, placeholders)
+		if _, err := dstConn.ExecContext(ctx, query, args...); err != nil {
+			return err
+		}
+		copied += int64(n)
+		args = args[:0]
+		return nil
+	}
+	for rows.Next() {
+		vals := make([]interface{}, len(cols))
+		ptrs := make([]interface{}, len(cols))
+		for i := range vals {
+			ptrs[i] = &vals[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return copied, fmt.Errorf("failed to read source row: %w", err)
+		}
+		args = append(args, vals...)
+		if len(args) == batchRows*len(cols) {
+			if err := flush(); err != nil {
+				return copied, err
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return copied, fmt.Errorf("failed to read source table: %w", err)
+	}
+	return copied, flush()
+}
+
+// copyTableRows inserts the shared columns of every source row into the table
+// on dstConn, which is inside the caller's transaction. A source on the same
+// server is copied with INSERT ... SELECT; a source on another target is
+// streamed, as db/branch revision syntax only reaches the same server.
+func (s *Service) copyTableRows(ctx context.Context, dstConn *sql.Conn, targetID, sourceTarget, sourceDB, sourceBranch, table string, shared []string) (int64, error) {
+	if sourceTarget != targetID {
+		srcConn, err := s.connCrossCopySourceRevision(ctx, sourceTarget, sourceDB, sourceBranch)
+		if err != nil {
+			return 0, err
+		}
+		defer srcConn.Close()
+		return streamCopyTableRows(ctx, srcConn, dstConn, table, shared)
+	}
+
+	quotedCols := make([]string, len(shared))
+	for i, c := range shared {
+		quotedCols[i] = fmt.Sprintf("`%s`", c)
+	}
+	colList := strings.Join(quotedCols, ", ")
+	sourceRef := fmt.Sprintf("`%s/%s`", sourceDB, sourceBranch)
+
+	insertQuery := fmt.Sprintf("INSERT INTO `%s` (%s) SELECT %s FROM %s.`%s`",
+		table, colList, colList, sourceRef, table)
+	result, err := dstConn.ExecContext(ctx, insertQuery)
+	if err != nil {
+		return 0, err
+	}
+	rowCount, _ := result.RowsAffected()
+	return rowCount, nil
+}
+
 // CrossCopyTable copies an entire table from source DB to a new branch in dest DB.
+// A source on another target (SourceTargetID) is streamed through the service.
 func (s *Service) CrossCopyTable(ctx context.Context, req model.CrossCopyTableRequest) (*model.CrossCopyTableResponse, error) {
 	if err := validation.ValidateDBName(req.SourceDB); err != nil {
 		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "無効なソースDB名"}
@@ -95,14 +214,15 @@ func (s *Service) CrossCopyTable(ctx context.Context, req model.CrossCopyTableRe
 	if err := validation.ValidateBranchName(req.SourceBranch); err != nil {
 		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "無効なソースブランチ名"}
 	}
-	if err := s.ensureCrossCopySourceRef(req.TargetID, req.SourceDB, req.SourceBranch); err != nil {
+	sourceTarget := crossCopySourceTarget(req.TargetID, req.SourceTargetID)
+	if err := s.ensureCrossCopySourceRef(sourceTarget, req.SourceDB, req.SourceBranch); err != nil {
 		return nil, err
 	}
 	if err := validation.ValidateIdentifier("table", req.SourceTable); err != nil {
 		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "無効なテーブル名"}
 	}
 
-	srcConn, err := s.connCrossCopySourceRevision(ctx, req.TargetID, req.SourceDB, req.SourceBranch)
+	srcConn, err := s.connCrossCopySourceRevision(ctx, sourceTarget, req.SourceDB, req.SourceBranch)
 	if err != nil {
 		return nil, err
 	}
@@ -132,27 +252,10 @@ func (s *Service) CrossCopyTable(ctx context.Context, req model.CrossCopyTableRe
 		return nil, crossCopySchemaPreconditionError(expandColumns)
 	}
 
-	dstConnDB, err := s.connMetadataRevision(ctx, req.TargetID, req.DestDB)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to dest DB: %w", err)
-	}
 	newBranchName := importWorkBranchName(req.SourceDB, req.SourceTable)
-	exists, err := branchExists(ctx, dstConnDB, newBranchName)
-	if err != nil {
-		dstConnDB.Close()
-		return nil, fmt.Errorf("failed to check branch %s: %w", newBranchName, err)
-	}
-	if exists {
-		dstConnDB.Close()
-		return nil, newBranchExistsError(newBranchName)
-	}
-
-	if _, err := dstConnDB.ExecContext(ctx, "CALL DOLT_BRANCH(?)", newBranchName); err != nil {
-		classifiedErr := classifyBranchCreateError(ctx, dstConnDB, newBranchName, err)
-		dstConnDB.Close()
-		return nil, classifiedErr
+	if err := s.createImportBranch(ctx, req.TargetID, req.DestDB, newBranchName); err != nil {
+		return nil, err
 	}
-	dstConnDB.Close()
 
 	cleanupIfNeeded := func() error {
 		cleanupErr := s.cleanupCrossCopyBranch(context.Background(), req.TargetID, req.DestDB, newBranchName)
@@ -190,17 +293,7 @@ func (s *Service) CrossCopyTable(ctx context.Context, req model.CrossCopyTableRe
 		return crossCopyTableFailureResponse(newBranchName, shared, srcOnly, dstOnly, cleanupIfNeeded()), nil
 	}
 
-	quotedCols := make([]string, len(shared))
-	for i, c := range shared {
-		quotedCols[i] = fmt.Sprintf("`%s`", c)
-	}
-	colList := strings.Join(quotedCols, ", ")
-	sourceRef := fmt.Sprintf("`%s/%s`", req.SourceDB, req.SourceBranch)
-
-	insertQuery := fmt.Sprintf("INSERT INTO `%s` (%s) SELECT %s FROM %s.`%s`",
-		req.SourceTable, colList, colList, sourceRef, req.SourceTable)
-
-	result, err := dstConn.ExecContext(ctx, insertQuery)
+	rowCount, err := s.copyTableRows(ctx, dstConn, req.TargetID, sourceTarget, req.SourceDB, req.SourceBranch, req.SourceTable, shared)
 	if err != nil {
 		safeRollback(dstConn)
 		if apiErr := parseCopyError(err); apiErr != nil {
@@ -213,8 +306,6 @@ func (s *Service) CrossCopyTable(ctx context.Context, req model.CrossCopyTableRe
 		return crossCopyTableFailureResponse(newBranchName, shared, srcOnly, dstOnly, cleanupIfNeeded()), nil
 	}
 
-	rowCount, _ := result.RowsAffected()
-
 	if _, err := dstConn.ExecContext(ctx, "CALL DOLT_VERIFY_CONSTRAINTS()"); err != nil {
 		safeRollback(dstConn)
 		return crossCopyTableFailureResponse(newBranchName, shared, srcOnly, dstOnly, cleanupIfNeeded()), nil
@@ -234,7 +325,7 @@ func (s *Service) CrossCopyTable(ctx context.Context, req model.CrossCopyTableRe
 		return crossCopyTableFailureResponse(newBranchName, shared, srcOnly, dstOnly, cleanupIfNeeded()), nil
 	}
 
-	commitMsg := fmt.Sprintf("[cross-copy] %sから%sテーブルを全件コピー（%d行）", req.SourceDB, req.SourceTable, rowCount)
+	commitMsg := fmt.Sprintf("[cross-copy] %sから%sテーブルを全件コピー（%d行）", crossCopySourceLabel(req.TargetID, req.SourceTargetID, req.SourceDB), req.SourceTable, rowCount)
 	if _, err := dstConn.ExecContext(ctx, "CALL DOLT_COMMIT('--allow-empty', '-m', ?)", commitMsg); err != nil {
 		safeRollback(dstConn)
 		return crossCopyTableFailureResponse(newBranchName, shared, srcOnly, dstOnly, cleanupIfNeeded()), nil
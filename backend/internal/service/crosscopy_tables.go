@@ -0,0 +1,402 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/validation"
+)
+
+// crossCopyMaxTables caps the tables of one multi-table copy.
+const crossCopyMaxTables = 50
+
+// crossCopyForeignKeyQuery lists the foreign keys of the current database.
+const crossCopyForeignKeyQuery = "SELECT TABLE_NAME, REFERENCED_TABLE_NAME FROM INFORMATION_SCHEMA.KEY_COLUMN_USAGE " +
+	"WHERE TABLE_SCHEMA = DATABASE() AND REFERENCED_TABLE_NAME IS NOT NULL"
+
+// loadForeignKeys returns, per table, the tables it references. Self references
+// are dropped: they do not constrain the order between tables.
+func loadForeignKeys(ctx context.Context, conn *sql.Conn) (map[string][]string, error) {
+	rows, err := conn.QueryContext(ctx, crossCopyForeignKeyQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query foreign keys: %w", err)
+	}
+	defer rows.Close()
+
+	seen := make(map[string]bool)
+	refs := make(map[string][]string)
+	for rows.Next() {
+		var table, referenced string
+		if err := rows.Scan(&table, &referenced); err != nil {
+			return nil, fmt.Errorf("failed to scan foreign key: %w", err)
+		}
+		if table == referenced || seen[table+"\x00"+referenced] {
+			continue
+		}
+		seen[table+"\x00"+referenced] = true
+		refs[table] = append(refs[table], referenced)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read foreign keys: %w", err)
+	}
+	for table := range refs {
+		sort.Strings(refs[table])
+	}
+	return refs, nil
+}
+
+// orderTablesByForeignKeys sorts tables so that every table comes after the
+// selected tables it references; ties are broken by name. Only references
+// between selected tables count. A reference cycle is 400 INVALID_ARGUMENT.
+func orderTablesByForeignKeys(tables []string, refs map[string][]string) ([]string, error) {
+	selected := make(map[string]bool, len(tables))
+	for _, t := range tables {
+		selected[t] = true
+	}
+	pending := make(map[string]int, len(tables))
+	children := make(map[string][]string)
+	for _, t := range tables {
+		pending[t] = 0
+		for _, parent := range refs[t] {
+			if selected[parent] {
+				pending[t]++
+				children[parent] = append(children[parent], t)
+			}
+		}
+	}
+
+	var ready []string
+	for _, t := range tables {
+		if pending[t] == 0 {
+			ready = append(ready, t)
+		}
+	}
+	order := make([]string, 0, len(tables))
+	for len(ready) > 0 {
+		sort.Strings(ready)
+		t := ready[0]
+		ready = ready[1:]
+		order = append(order, t)
+		for _, child := range children[t] {
+			pending[child]--
+			if pending[child] == 0 {
+				ready = append(ready, child)
+			}
+		}
+	}
+	if len(order) < len(tables) {
+		var cycle []string
+		for _, t := range tables {
+			if pending[t] > 0 {
+				cycle = append(cycle, t)
+			}
+		}
+		sort.Strings(cycle)
+		return nil, &model.APIError{
+			Status:  400,
+			Code:    model.CodeInvalidArgument,
+			Msg:     fmt.Sprintf("外部キーが循環しているためコピー順を決められません: %s", strings.Join(cycle, ", ")),
+			Details: map[string]interface{}{"cycle": cycle},
+		}
+	}
+	return order, nil
+}
+
+func countTableRows(ctx context.Context, conn *sql.Conn, table string) (int, error) {
+	var n int
+	if err := conn.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM `%s`", table)).Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to count rows of %s: %w", table, err)
+	}
+	return n, nil
+}
+
+func validateCrossCopyTablesRequest(req model.CrossCopyTablesRequest) error {
+	if err := validation.ValidateDBName(req.SourceDB); err != nil {
+		return &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "無効なソースDB名"}
+	}
+	if err := validation.ValidateDBName(req.DestDB); err != nil {
+		return &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "無効な宛先DB名"}
+	}
+	if err := validation.ValidateBranchName(req.SourceBranch); err != nil {
+		return &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "無効なソースブランチ名"}
+	}
+	if len(req.SourceTables) == 0 || len(req.SourceTables) > crossCopyMaxTables {
+		return &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: fmt.Sprintf("source_tables must list 1-%d tables", crossCopyMaxTables)}
+	}
+	seen := make(map[string]bool, len(req.SourceTables))
+	for _, table := range req.SourceTables {
+		if err := validation.ValidateIdentifier("table", table); err != nil || isHiddenTableName(table) {
+			return &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: fmt.Sprintf("無効なテーブル名: %s", table)}
+		}
+		if seen[table] {
+			return &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: fmt.Sprintf("テーブル %s が重複しています", table)}
+		}
+		seen[table] = true
+	}
+	return nil
+}
+
+// planCrossCopyTables compares the schema of every selected table, reads the
+// destination foreign keys and orders the tables. Row counts are read only
+// for previews.
+func (s *Service) planCrossCopyTables(ctx context.Context, req model.CrossCopyTablesRequest, withCounts bool) (*model.CrossCopyTablesPreviewResponse, error) {
+	if err := validateCrossCopyTablesRequest(req); err != nil {
+		return nil, err
+	}
+	sourceTarget := crossCopySourceTarget(req.TargetID, req.SourceTargetID)
+	srcConn, err := s.connCrossCopySourceRevision(ctx, sourceTarget, req.SourceDB, req.SourceBranch)
+	if err != nil {
+		return nil, err
+	}
+	defer srcConn.Close()
+	dstConn, err := s.connMetadataRevision(ctx, req.TargetID, req.DestDB)
+	if err != nil {
+		return nil, err
+	}
+	defer dstConn.Close()
+
+	plans := make(map[string]*model.CrossCopyTablePlan, len(req.SourceTables))
+	for _, table := range req.SourceTables {
+		srcCols, err := getSchemaColumns(ctx, srcConn, table)
+		if err != nil {
+			return nil, err
+		}
+		dstCols, err := getSchemaColumns(ctx, dstConn, table)
+		if err != nil {
+			return nil, err
+		}
+		shared, srcOnly, dstOnly := computeSharedColumns(srcCols, dstCols)
+		if len(shared) == 0 {
+			return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: fmt.Sprintf("テーブル %s に共有カラムがありません", table)}
+		}
+		plan := &model.CrossCopyTablePlan{
+			Table:          table,
+			SharedColumns:  shared,
+			SourceOnlyCols: srcOnly,
+			DestOnlyCols:   dstOnly,
+			DependsOn:      []string{},
+			ExpandColumns:  expandColumnsForShared(srcCols, dstCols, shared),
+		}
+		if withCounts {
+			if plan.SourceRows, err = countTableRows(ctx, srcConn, table); err != nil {
+				return nil, err
+			}
+			if plan.DestRows, err = countTableRows(ctx, dstConn, table); err != nil {
+				return nil, err
+			}
+		}
+		plans[table] = plan
+	}
+
+	refs, err := loadForeignKeys(ctx, dstConn)
+	if err != nil {
+		return nil, err
+	}
+	order, err := orderTablesByForeignKeys(req.SourceTables, refs)
+	if err != nil {
+		return nil, err
+	}
+
+	warnings := make([]string, 0)
+	referencing := make([]string, 0, len(refs))
+	for table := range refs {
+		referencing = append(referencing, table)
+	}
+	sort.Strings(referencing)
+	for _, table := range referencing {
+		for _, parent := range refs[table] {
+			switch {
+			case plans[table] != nil && plans[parent] != nil:
+				plans[table].DependsOn = append(plans[table].DependsOn, parent)
+			case plans[table] != nil:
+				warnings = append(warnings, fmt.Sprintf("%s は選択外の %s を参照しています。宛先の既存行に対応する値のみコピーできます", table, parent))
+			case plans[parent] != nil:
+				warnings = append(warnings, fmt.Sprintf("選択外の %s が %s を参照しています。参照中の行が削除されると制約違反になります", table, parent))
+			}
+		}
+	}
+
+	resp := &model.CrossCopyTablesPreviewResponse{
+		BranchName: importTablesBranchName(req.SourceDB, req.SourceTables),
+		Order:      order,
+		Tables:     make([]model.CrossCopyTablePlan, 0, len(order)),
+		Warnings:   warnings,
+	}
+	for _, table := range order {
+		resp.Tables = append(resp.Tables, *plans[table])
+	}
+	return resp, nil
+}
+
+// CrossCopyTablesPreview returns the copy order and, per table, the column
+// comparison and the source and destination row counts.
+func (s *Service) CrossCopyTablesPreview(ctx context.Context, req model.CrossCopyTablesRequest) (*model.CrossCopyTablesPreviewResponse, error) {
+	return s.planCrossCopyTables(ctx, req, true)
+}
+
+func crossCopyTablesFailureResponse(branchName string, order []string, cleanupErr error) *model.CrossCopyTablesResponse {
+	single := crossCopyTableFailureResponse(branchName, nil, nil, nil, cleanupErr)
+	return &model.CrossCopyTablesResponse{
+		BranchName:            single.BranchName,
+		Order:                 order,
+		OperationResultFields: single.OperationResultFields,
+	}
+}
+
+func crossCopyTablesRetryResponse(branchName, hash string, order []string, counts []model.CrossCopyTableCount, total int, message, retryReason string, retryActions []model.RetryAction) *model.CrossCopyTablesResponse {
+	single := crossCopyTableRetryResponse(branchName, hash, total, nil, nil, nil, message, retryReason, retryActions)
+	return &model.CrossCopyTablesResponse{
+		Hash:                  hash,
+		BranchName:            branchName,
+		Order:                 order,
+		Tables:                counts,
+		RowCount:              total,
+		OperationResultFields: single.OperationResultFields,
+	}
+}
+
+// CrossCopyTables replaces the rows of several related tables in one new import
+// branch and one commit. Rows are deleted children first and inserted parents
+// first, following the destination foreign keys.
+func (s *Service) CrossCopyTables(ctx context.Context, req model.CrossCopyTablesRequest) (*model.CrossCopyTablesResponse, error) {
+	plan, err := s.planCrossCopyTables(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+	expandByTable := make(map[string][]model.ExpandColumn)
+	for _, table := range plan.Tables {
+		if len(table.ExpandColumns) > 0 {
+			expandByTable[table.Table] = table.ExpandColumns
+		}
+	}
+	if len(expandByTable) > 0 {
+		apiErr := crossCopySchemaPreconditionError(nil)
+		apiErr.Details = map[string]interface{}{"expand_columns_by_table": expandByTable}
+		return nil, apiErr
+	}
+
+	sourceTarget := crossCopySourceTarget(req.TargetID, req.SourceTargetID)
+	branchName := plan.BranchName
+	order := plan.Order
+	if err := s.createImportBranch(ctx, req.TargetID, req.DestDB, branchName); err != nil {
+		return nil, err
+	}
+
+	cleanupIfNeeded := func() error {
+		cleanupErr := s.cleanupCrossCopyBranch(context.Background(), req.TargetID, req.DestDB, branchName)
+		if cleanupErr != nil {
+			log.Printf("WARN: %v", cleanupErr)
+		}
+		return cleanupErr
+	}
+
+	readiness := s.branchReadiness(ctx, req.TargetID, req.DestDB, branchName)
+	if !readiness.Ready {
+		logBranchQueryabilityFailure("cross_copy_branch_not_ready", req.TargetID, req.DestDB, branchName, readiness)
+		return crossCopyTablesFailureResponse(branchName, order, cleanupIfNeeded()), nil
+	}
+
+	dstConn, err := s.repo.ConnWorkBranchWrite(ctx, req.TargetID, req.DestDB, branchName)
+	if err != nil {
+		return crossCopyTablesFailureResponse(branchName, order, cleanupIfNeeded()), nil
+	}
+	defer dstConn.Close()
+
+	// failCopy rolls back a failed DELETE/INSERT. A classified copy error is
+	// returned as such once the branch is gone.
+	failCopy := func(table string, err error) (*model.CrossCopyTablesResponse, error) {
+		safeRollback(dstConn)
+		cleanupErr := cleanupIfNeeded()
+		if apiErr := parseCopyError(err); apiErr != nil && cleanupErr == nil {
+			apiErr.Msg = fmt.Sprintf("テーブル %s: %s", table, apiErr.Msg)
+			return nil, apiErr
+		}
+		return crossCopyTablesFailureResponse(branchName, order, cleanupErr), nil
+	}
+
+	if _, err := dstConn.ExecContext(ctx, "START TRANSACTION"); err != nil {
+		return crossCopyTablesFailureResponse(branchName, order, cleanupIfNeeded()), nil
+	}
+	for i := len(order) - 1; i >= 0; i-- {
+		if _, err := dstConn.ExecContext(ctx, fmt.Sprintf("DELETE FROM `%s`", order[i])); err != nil {
+			return failCopy(order[i], err)
+		}
+	}
+	counts := make([]model.CrossCopyTableCount, 0, len(order))
+	total := 0
+	for _, table := range plan.Tables {
+		rowCount, err := s.copyTableRows(ctx, dstConn, req.TargetID, sourceTarget, req.SourceDB, req.SourceBranch, table.Table, table.SharedColumns)
+		if err != nil {
+			return failCopy(table.Table, err)
+		}
+		counts = append(counts, model.CrossCopyTableCount{Table: table.Table, RowCount: int(rowCount)})
+		total += int(rowCount)
+	}
+
+	if _, err := dstConn.ExecContext(ctx, "CALL DOLT_VERIFY_CONSTRAINTS()"); err != nil {
+		safeRollback(dstConn)
+		return crossCopyTablesFailureResponse(branchName, order, cleanupIfNeeded()), nil
+	}
+	var violationCount int
+	if err := dstConn.QueryRowContext(ctx, "SELECT COUNT(*) FROM dolt_constraint_violations").Scan(&violationCount); err == nil && violationCount > 0 {
+		safeRollback(dstConn)
+		cleanupErr := cleanupIfNeeded()
+		if cleanupErr != nil {
+			return crossCopyTablesFailureResponse(branchName, order, cleanupErr), nil
+		}
+		return nil, &model.APIError{Status: 400, Code: model.CodeCopyFKError, Msg: "制約違反が検出されました"}
+	}
+
+	if _, err := dstConn.ExecContext(ctx, "CALL DOLT_ADD('.')"); err != nil {
+		safeRollback(dstConn)
+		return crossCopyTablesFailureResponse(branchName, order, cleanupIfNeeded()), nil
+	}
+	commitMsg := fmt.Sprintf("[cross-copy] %sから%dテーブルを全件コピー（%s、%d行）",
+		crossCopySourceLabel(req.TargetID, req.SourceTargetID, req.SourceDB), len(order), strings.Join(order, ", "), total)
+	if _, err := dstConn.ExecContext(ctx, "CALL DOLT_COMMIT('--allow-empty', '-m', ?)", commitMsg); err != nil {
+		safeRollback(dstConn)
+		return crossCopyTablesFailureResponse(branchName, order, cleanupIfNeeded()), nil
+	}
+	if _, err := dstConn.ExecContext(ctx, "COMMIT"); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	var newHead string
+	if err := dstConn.QueryRowContext(ctx, "SELECT DOLT_HASHOF('HEAD')").Scan(&newHead); err != nil {
+		return crossCopyTablesRetryResponse(branchName, "", order, counts, total,
+			"コピーコミットは作成された可能性がありますが、確認に失敗しました。インポートブランチを確認して再試行してください。",
+			"destination_commit_uncertain",
+			[]model.RetryAction{{Action: "open_import_branch", Label: "インポートブランチを確認する"}}), nil
+	}
+
+	readiness = s.branchReadiness(ctx, req.TargetID, req.DestDB, branchName)
+	if !readiness.Ready {
+		logBranchQueryabilityFailure("cross_copy_committed_branch_not_ready", req.TargetID, req.DestDB, branchName, readiness)
+		return crossCopyTablesRetryResponse(branchName, newHead, order, counts, total,
+			"コピーコミットは作成されましたが、インポートブランチの接続反映を確認できませんでした。時間をおいて開き直してください。",
+			"destination_branch_not_ready",
+			[]model.RetryAction{{Action: "open_import_branch", Label: "インポートブランチを開き直す"}}), nil
+	}
+
+	return &model.CrossCopyTablesResponse{
+		Hash:       newHead,
+		BranchName: branchName,
+		Order:      order,
+		Tables:     counts,
+		RowCount:   total,
+		OperationResultFields: model.OperationResultFields{
+			Outcome: model.OperationOutcomeCompleted,
+			Message: "他DBへテーブルをまとめてコピーしました",
+			Completion: map[string]bool{
+				"destination_committed":    true,
+				"destination_branch_ready": true,
+				"protected_refs_clean":     true,
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+)
+
+func TestOrderTablesByForeignKeys(t *testing.T) {
+	refs := map[string][]string{
+		"order_lines": {"items", "orders"},
+		"orders":      {"customers"},
+		"audit":       {"users"},
+	}
+	order, err := orderTablesByForeignKeys([]string{"order_lines", "orders", "items", "customers"}, refs)
+	if err != nil {
+		t.Fatalf("orderTablesByForeignKeys: %v", err)
+	}
+	if want := []string{"customers", "items", "orders", "order_lines"}; !reflect.DeepEqual(order, want) {
+		t.Fatalf("order = %v, want %v", order, want)
+	}
+
+	refs["customers"] = []string{"order_lines"}
+	_, err = orderTablesByForeignKeys([]string{"order_lines", "orders", "items", "customers"}, refs)
+	if apiErr, ok := err.(*model.APIError); !ok || !strings.Contains(apiErr.Msg, "customers, order_lines, orders") {
+		t.Fatalf("expected cycle error, got %v", err)
+	}
+}
+
+func TestCrossCopyTables_CopiesInForeignKeyOrderInOneCommit(t *testing.T) {
+	var workQueries []string
+	var commitMsg string
+	repo := newCrossCopyTestRepo(t,
+		func(dbName, refName, query string, args []driver.NamedValue) (testQueryResult, error) {
+			switch {
+			case strings.HasPrefix(query, "SHOW COLUMNS FROM "):
+				return showColumnsResult("varchar(255)"), nil
+			case query == crossCopyForeignKeyQuery && dbName == "test_db":
+				return testQueryResult{
+					columns: []string{"TABLE_NAME", "REFERENCED_TABLE_NAME"},
+					rows: [][]driver.Value{
+						{"order_lines", "orders"},
+						{"orders", "customers"},
+						{"orders", "orders"},
+						{"invoices", "orders"},
+					},
+				}, nil
+			case query == "SELECT COUNT(*) FROM dolt_branches WHERE name = ?":
+				if args[0].Value != "wi/import-test_db-customers.order_lines.orders" {
+					return testQueryResult{}, fmt.Errorf("unexpected branch %v", args[0].Value)
+				}
+				return testQueryResult{columns: []string{"count(*)"}, rows: [][]driver.Value{{int64(0)}}}, nil
+			case query == "CALL DOLT_BRANCH(?)":
+				return testQueryResult{}, nil
+			}
+			return testQueryResult{}, fmt.Errorf("unexpected revision query on %s/%s: %s", dbName, refName, query)
+		},
+		func(dbName, branchName, query string, args []driver.NamedValue) (testQueryResult, error) {
+			workQueries = append(workQueries, query)
+			switch {
+			case query == "SELECT COUNT(*) FROM dolt_constraint_violations":
+				return testQueryResult{columns: []string{"count"}, rows: [][]driver.Value{{int64(0)}}}, nil
+			case query == "SELECT DOLT_HASHOF('HEAD')":
+				return testQueryResult{columns: []string{"hash"}, rows: [][]driver.Value{{"head-2"}}}, nil
+			case strings.HasPrefix(query, "CALL DOLT_COMMIT"):
+				commitMsg = fmt.Sprint(args[0].Value)
+			}
+			return testQueryResult{}, nil
+		},
+	)
+	svc := newWithDeps(repo, testServiceConfig())
+	svc.branchReadinessProbe = func(ctx context.Context, targetID, dbName, branch string) branchQueryabilityResult {
+		return branchQueryabilityResult{Ready: true, Attempts: 1}
+	}
+	req := model.CrossCopyTablesRequest{
+		TargetID:     "local",
+		SourceDB:     "test_db",
+		SourceBranch: "main",
+		SourceTables: []string{"order_lines", "orders", "customers"},
+		DestDB:       "test_db",
+	}
+
+	plan, err := svc.planCrossCopyTables(context.Background(), req, false)
+	if err != nil {
+		t.Fatalf("planCrossCopyTables: %v", err)
+	}
+	if len(plan.Warnings) != 1 || !strings.Contains(plan.Warnings[0], "invoices") {
+		t.Fatalf("expected a warning for the unselected child table, got %v", plan.Warnings)
+	}
+	if !reflect.DeepEqual(plan.Tables[2].DependsOn, []string{"orders"}) {
+		t.Fatalf("unexpected plan: %+v", plan.Tables)
+	}
+
+	resp, err := svc.CrossCopyTables(context.Background(), req)
+	if err != nil {
+		t.Fatalf("CrossCopyTables: %v", err)
+	}
+	if resp.Outcome != model.OperationOutcomeCompleted || !reflect.DeepEqual(resp.Order, []string{"customers", "orders", "order_lines"}) {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+
+	var writes []string
+	for _, q := range workQueries {
+		if strings.HasPrefix(q, "DELETE FROM ") || strings.HasPrefix(q, "INSERT INTO ") {
+			open := strings.Index(q, "`")
+			writes = append(writes, q[:open+strings.Index(q[open+1:], "`")+2])
+		}
+	}
+	want := []string{
+		"DELETE FROM `order_lines`", "DELETE FROM `orders`", "DELETE FROM `customers`",
+		"INSERT INTO `customers`", "INSERT INTO `orders`", "INSERT INTO `order_lines`",
+	}
+	if !reflect.DeepEqual(writes, want) {
+		t.Fatalf("writes = %v, want %v", writes, want)
+	}
+	if strings.Count(strings.Join(workQueries, "\n"), "CALL DOLT_COMMIT") != 1 || !strings.Contains(commitMsg, "3テーブル") {
+		t.Fatalf("expected one commit for all tables, got %q in:\n%s", commitMsg, strings.Join(workQueries, "\n"))
+	}
+}
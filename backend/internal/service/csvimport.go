@@ -2,7 +2,12 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
@@ -17,6 +22,15 @@ func (s *Service) CSVPreview(ctx context.Context, req model.CSVPreviewRequest) (
 	if err := validation.ValidateIdentifier("table", req.Table); err != nil {
 		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "無効なテーブル名"}
 	}
+	rows, err := s.csvRequestRows(req.UploadID, req.Rows)
+	if err != nil {
+		return nil, err
+	}
+	req.Rows = rows
+	mode, err := normalizeCSVMode(req.Mode, req.ReplaceFilter)
+	if err != nil {
+		return nil, err
+	}
 	if len(req.Rows) == 0 {
 		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "CSVデータが空です"}
 	}
@@ -38,201 +52,252 @@ func (s *Service) CSVPreview(ctx context.Context, req model.CSVPreviewRequest) (
 	if len(pkCols) == 0 {
 		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "テーブルに主キーがありません"}
 	}
-
-	// Validate CSV headers contain all PK columns
-	if len(req.Rows) == 0 {
-		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "CSVにデータ行がありません"}
+	isPK := make(map[string]bool, len(pkCols))
+	for _, pk := range pkCols {
+		isPK[pk] = true
 	}
-	headers := make(map[string]bool)
-	for k := range req.Rows[0] {
-		headers[k] = true
+
+	var previewErrors []model.CSVPreviewError
+	var columnErrors map[string]int
+	fileRows := req.Rows // before mapping, for the error report
+	mapping, err := s.csvRequestMapping(ctx, req.TargetID, req.DBName, req.Table, req.Profile, req.Mapping)
+	if err != nil {
+		return nil, err
 	}
-	for _, pk := range pkCols {
-		if !headers[pk] {
-			return nil, &model.APIError{
-				Status: 400,
-				Code:   model.CodeInvalidArgument,
-				Msg:    fmt.Sprintf("CSVに主キーカラム '%s' が含まれていません", pk),
-			}
-		}
+	// Rows with invalid values or a key repeated within the file become nil and
+	// are only counted as errors.
+	if req.Rows, previewErrors, columnErrors, err = mapCSVRows(req.Rows, *mapping, cols); err != nil {
+		return nil, err
 	}
+	previewErrors = append(previewErrors, markDuplicateCSVKeys(req.Rows, pkCols, cols)...)
 
-	inserts, updates, skips := 0, 0, 0
+	inserts, updates, skips, deletes := 0, 0, 0, 0
 	var sampleDiffs []model.CSVDiffRow
-	var previewErrors []model.CSVPreviewError
+	var sampleDeletes []map[string]interface{}
 
-	// Limit rows for preview
-	previewRows := req.Rows
-	if len(previewRows) > 1000 {
-		previewRows = previewRows[:1000]
-	}
+	// Every row is validated. Existing rows are fetched one batch at a time, so
+	// a large file costs len(rows)/csvPreviewBatchRows lookups, not one per row.
+	for batchStart := 0; batchStart < len(req.Rows); batchStart += csvPreviewBatchRows {
+		batch := req.Rows[batchStart:min(batchStart+csvPreviewBatchRows, len(req.Rows))]
 
-	// Build a stable PK key from a CSV row for indexing.
-	makePKKey := func(csvRow map[string]interface{}) string {
-		parts := make([]string, len(pkCols))
-		for i, pk := range pkCols {
-			parts[i] = fmt.Sprintf("%v", csvRow[pk])
-		}
-		return strings.Join(parts, "\x00")
-	}
-
-	// Batch-fetch existing DB rows using IN clause to eliminate N+1 queries.
-	// For single PK: WHERE pk IN (v1, v2, ...)
-	// For composite PK: WHERE (pk1, pk2) IN ((v1a, v1b), ...)
-	dbIndex := make(map[string]map[string]interface{}, len(previewRows))
-
-	// Collect valid PK combos (skip rows with missing PKs)
-	type pkCombo struct {
-		rowIdx int
-		key    string
-		vals   []interface{}
-	}
-	var validCombos []pkCombo
-	for i, csvRow := range previewRows {
-		vals := make([]interface{}, len(pkCols))
-		valid := true
-		for j, pk := range pkCols {
-			v, ok := csvRow[pk]
-			if !ok {
+		// Collect valid PK combos (skip rows with missing PKs)
+		validRows := make([]int, 0, len(batch))
+		for i, csvRow := range batch {
+			if csvRow == nil {
+				continue
+			}
+			missingPK := ""
+			for _, pk := range pkCols {
+				if _, ok := csvRow[pk]; !ok {
+					missingPK = pk
+					break
+				}
+			}
+			if missingPK != "" {
 				previewErrors = append(previewErrors, model.CSVPreviewError{
-					RowIndex: i,
-					Message:  fmt.Sprintf("主キー '%s' が見つかりません", pk),
+					RowIndex: batchStart + i,
+					Message:  fmt.Sprintf("主キー '%s' が見つかりません", missingPK),
 				})
-				valid = false
-				break
+				continue
 			}
-			vals[j] = v
+			validRows = append(validRows, i)
 		}
-		if valid {
-			validCombos = append(validCombos, pkCombo{rowIdx: i, key: makePKKey(csvRow), vals: vals})
+		keyRows := make([]map[string]interface{}, len(validRows))
+		for i, idx := range validRows {
+			keyRows[i] = batch[idx]
+		}
+		dbIndex, err := matchCSVRows(ctx, conn, req.Table, pkCols, keyRows)
+		if err != nil {
+			return nil, err
 		}
-	}
-
-	if len(validCombos) > 0 {
-		// Build batch WHERE clause
-		var batchQuery string
-		var batchArgs []interface{}
 
-		if len(pkCols) == 1 {
-			// Single PK: WHERE pk IN (?, ?, ...)
-			placeholders := make([]string, len(validCombos))
-			for i, combo := range validCombos {
-				placeholders[i] = "?"
-				batchArgs = append(batchArgs, combo.vals[0])
+		// Compare each CSV row against the DB index
+		for i, idx := range validRows {
+			csvRow := batch[idx]
+			rowIndex := batchStart + idx
+			dbRow, exists := dbIndex[i]
+			if exists && mode == model.CSVModeInsertOnly {
+				previewErrors = append(previewErrors, model.CSVPreviewError{RowIndex: rowIndex, Message: "主キーが既に存在します（insert_only）"})
+				continue
 			}
-			batchQuery = fmt.Sprintf("SELECT * FROM `%s` WHERE `%s` IN (%s)",
-				req.Table, pkCols[0], strings.Join(placeholders, ", "))
-		} else {
-			// Composite PK: WHERE (pk1, pk2) IN ((?,?), (?,?), ...)
-			pkColsQuoted := make([]string, len(pkCols))
-			for i, pk := range pkCols {
-				pkColsQuoted[i] = fmt.Sprintf("`%s`", pk)
+			if !exists && mode == model.CSVModeUpdateOnly {
+				previewErrors = append(previewErrors, model.CSVPreviewError{RowIndex: rowIndex, Message: "主キーが存在しません（update_only）"})
+				continue
 			}
-			rowPlaceholders := make([]string, len(validCombos))
-			for i, combo := range validCombos {
-				colPhs := make([]string, len(pkCols))
-				for j := range pkCols {
-					colPhs[j] = "?"
-					batchArgs = append(batchArgs, combo.vals[j])
+			if !exists {
+				// Row not in DB → insert
+				inserts++
+				if len(sampleDiffs) < 5 {
+					sampleDiffs = append(sampleDiffs, model.CSVDiffRow{
+						Action: "insert",
+						Row:    csvRow,
+					})
 				}
-				rowPlaceholders[i] = "(" + strings.Join(colPhs, ", ") + ")"
+				continue
 			}
-			batchQuery = fmt.Sprintf("SELECT * FROM `%s` WHERE (%s) IN (%s)",
-				req.Table, strings.Join(pkColsQuoted, ", "), strings.Join(rowPlaceholders, ", "))
-		}
-
-		batchRows, err := conn.QueryContext(ctx, batchQuery, batchArgs...)
-		if err == nil {
-			dbCols, _ := batchRows.Columns()
-			for batchRows.Next() {
-				vals := make([]interface{}, len(dbCols))
-				ptrs := make([]interface{}, len(dbCols))
-				for j := range vals {
-					ptrs[j] = &vals[j]
-				}
-				if err := batchRows.Scan(ptrs...); err != nil {
+			// Check if anything changed. The key columns matched in the database,
+			// so "007" against 7 is not a change.
+			changed := false
+			for k, csvVal := range csvRow {
+				if isPK[k] {
 					continue
 				}
-				dbRow := make(map[string]interface{}, len(dbCols))
-				for j, col := range dbCols {
-					v := vals[j]
-					if b, ok := v.([]byte); ok {
-						dbRow[col] = string(b)
-					} else {
-						dbRow[col] = v
-					}
-				}
-				// Build PK key from DB row
-				keyParts := make([]string, len(pkCols))
-				for j, pk := range pkCols {
-					keyParts[j] = fmt.Sprintf("%v", dbRow[pk])
+				if fmt.Sprintf("%v", csvVal) != fmt.Sprintf("%v", dbRow[k]) {
+					changed = true
+					break
 				}
-				dbIndex[strings.Join(keyParts, "\x00")] = dbRow
 			}
-			batchRows.Close()
-		}
-		// If batch query fails (e.g. row constructor unsupported), dbIndex stays empty →
-		// all rows treated as inserts. This is a safe degradation for preview purposes.
-	}
-
-	// Compare each CSV row against the DB index
-	for _, csvRow := range previewRows {
-		key := makePKKey(csvRow)
-		// Skip rows that had missing PK errors (already recorded above)
-		hasMissingPK := false
-		for _, pk := range pkCols {
-			if _, ok := csvRow[pk]; !ok {
-				hasMissingPK = true
-				break
+			if !changed {
+				skips++
+				continue
 			}
-		}
-		if hasMissingPK {
-			continue
-		}
-
-		dbRow, exists := dbIndex[key]
-		if !exists {
-			// Row not in DB → insert
-			inserts++
+			updates++
 			if len(sampleDiffs) < 5 {
 				sampleDiffs = append(sampleDiffs, model.CSVDiffRow{
-					Action: "insert",
+					Action: "update",
 					Row:    csvRow,
+					OldRow: dbRow,
 				})
 			}
-		} else {
-			// Check if anything changed
-			changed := false
-			for k, csvVal := range csvRow {
-				dbVal := dbRow[k]
-				if fmt.Sprintf("%v", csvVal) != fmt.Sprintf("%v", dbVal) {
-					changed = true
-					break
-				}
-			}
-			if changed {
-				updates++
-				if len(sampleDiffs) < 5 {
-					sampleDiffs = append(sampleDiffs, model.CSVDiffRow{
-						Action: "update",
-						Row:    csvRow,
-						OldRow: dbRow,
-					})
-				}
-			} else {
-				skips++
+		}
+	}
+
+	if mode == model.CSVModeReplace {
+		deletes, err = scanCSVRowsMissingFromFile(ctx, conn, req.Table, cols, req.ReplaceFilter, req.Rows, func(page []map[string]interface{}) error {
+			if room := 5 - len(sampleDeletes); room > 0 {
+				sampleDeletes = append(sampleDeletes, page[:min(room, len(page))]...)
 			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
 		}
 	}
 
-	return &model.CSVPreviewResponse{
+	resp := &model.CSVPreviewResponse{
 		Inserts:       inserts,
 		Updates:       updates,
 		Skips:         skips,
+		Deletes:       deletes,
 		Errors:        len(previewErrors),
 		SampleDiffs:   sampleDiffs,
-		PreviewErrors: previewErrors,
-	}, nil
+		SampleDeletes: sampleDeletes,
+		ColumnErrors:  columnErrors,
+	}
+	if len(previewErrors) > 0 {
+		sort.SliceStable(previewErrors, func(i, j int) bool { return previewErrors[i].RowIndex < previewErrors[j].RowIndex })
+		if resp.PreviewID, err = s.storeCSVPreviewErrors(req.UploadID, fileRows, previewErrors); err != nil {
+			return nil, err
+		}
+		resp.PreviewErrors = previewErrors[:min(len(previewErrors), csvPreviewErrorPageSize)]
+	}
+	return resp, nil
+}
+
+var csvOpIndexPattern = regexp.MustCompile(`^ops\[(\d+)\]: `)
+
+// csvOpError rewrites the "ops[i]: " position that the commit batch executor
+// puts on its errors into the 1-based file row that produced op i.
+func csvOpError(err error, opRows []int) error {
+	m := csvOpIndexPattern.FindStringSubmatch(err.Error())
+	if m == nil {
+		return err
+	}
+	i, convErr := strconv.Atoi(m[1])
+	if convErr != nil || i >= len(opRows) {
+		return err
+	}
+	row := opRows[i] + 1
+	var apiErr *model.APIError
+	if errors.As(err, &apiErr) {
+		attributed := *apiErr
+		attributed.Msg = fmt.Sprintf("行 %d: %s", row, strings.TrimPrefix(apiErr.Msg, m[0]))
+		return &attributed
+	}
+	if inner := errors.Unwrap(err); inner != nil {
+		return fmt.Errorf("行 %d: %w", row, inner)
+	}
+	return err
+}
+
+// matchCSVRows joins the primary keys of rows against the table, a batch at a
+// time, and returns the matching table rows keyed by index into rows. The join
+// leaves key comparison to the database, so "007" finds the INT key 7 and "1.5"
+// the DECIMAL key 1.50, exactly as the row writes of CSVApply will.
+func matchCSVRows(ctx context.Context, conn *sql.Conn, table string, pkCols []string, rows []map[string]interface{}) (map[int]map[string]interface{}, error) {
+	dbIndex := make(map[int]map[string]interface{}, len(rows))
+	on := make([]string, len(pkCols))
+	for i, pk := range pkCols {
+		on[i] = fmt.Sprintf("t.`%s` = f.k%d", pk, i)
+	}
+	for start := 0; start < len(rows); start += csvPreviewBatchRows {
+		batch := rows[start:min(start+csvPreviewBatchRows, len(rows))]
+		// The file keys become a derived table: SELECT ? AS csv_row, ? AS k0 UNION ALL SELECT ?, ? ...
+		selects := make([]string, 0, len(batch))
+		args := make([]interface{}, 0, len(batch)*(len(pkCols)+1))
+		for i, row := range batch {
+			if row == nil {
+				continue // a row with invalid values; see mapCSVRows
+			}
+			cols := make([]string, len(pkCols)+1)
+			cols[0] = "?"
+			if len(selects) == 0 {
+				cols[0] = "? AS csv_row"
+			}
+			args = append(args, int64(start+i))
+			for j, pk := range pkCols {
+				cols[j+1] = "?"
+				if len(selects) == 0 {
+					cols[j+1] = fmt.Sprintf("? AS k%d", j)
+				}
+				args = append(args, sqlArgFromJSON(row[pk]))
+			}
+			selects = append(selects, "SELECT "+strings.Join(cols, ", "))
+		}
+		if len(selects) == 0 {
+			continue
+		}
+		query := fmt.Sprintf("SELECT f.csv_row, t.* FROM `%s` AS t JOIN (%s) AS f ON %s",
+			table, strings.Join(selects, " UNION ALL "), strings.Join(on, " AND "))
+		if err := scanCSVMatches(ctx, conn, query, args, dbIndex); err != nil {
+			return nil, err
+		}
+	}
+	return dbIndex, nil
+}
+
+func scanCSVMatches(ctx context.Context, conn *sql.Conn, query string, args []interface{}, dbIndex map[int]map[string]interface{}) error {
+	rows, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to look up CSV rows: %w", err)
+	}
+	defer rows.Close()
+	dbCols, err := rows.Columns()
+	if err != nil {
+		return fmt.Errorf("failed to read columns: %w", err)
+	}
+	for rows.Next() {
+		var rowIndex int
+		vals := make([]interface{}, len(dbCols)-1)
+		ptrs := make([]interface{}, len(dbCols))
+		ptrs[0] = &rowIndex
+		for j := range vals {
+			ptrs[j+1] = &vals[j]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return fmt.Errorf("failed to scan CSV row: %w", err)
+		}
+		dbRow := make(map[string]interface{}, len(vals))
+		for j, col := range dbCols[1:] {
+			if b, ok := vals[j].([]byte); ok {
+				dbRow[col] = string(b)
+			} else {
+				dbRow[col] = vals[j]
+			}
+		}
+		dbIndex[rowIndex] = dbRow
+	}
+	return rows.Err()
 }
 
 // CSVApply inserts or updates rows from CSV data, then commits.
@@ -243,6 +308,15 @@ func (s *Service) CSVApply(ctx context.Context, req model.CSVApplyRequest) (*mod
 	if err := validation.ValidateIdentifier("table", req.Table); err != nil {
 		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "無効なテーブル名"}
 	}
+	rows, err := s.csvRequestRows(req.UploadID, req.Rows)
+	if err != nil {
+		return nil, err
+	}
+	req.Rows = rows
+	mode, err := normalizeCSVMode(req.Mode, req.ReplaceFilter)
+	if err != nil {
+		return nil, err
+	}
 	if len(req.Rows) == 0 {
 		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "CSVデータが空です"}
 	}
@@ -267,6 +341,25 @@ func (s *Service) CSVApply(ctx context.Context, req model.CSVApplyRequest) (*mod
 	if len(pkCols) == 0 {
 		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "テーブルに主キーがありません"}
 	}
+	mapping, err := s.csvRequestMapping(ctx, req.TargetID, req.DBName, req.Table, req.Profile, req.Mapping)
+	if err != nil {
+		return nil, err
+	}
+	mapped, rowErrors, _, err := mapCSVRows(req.Rows, *mapping, cols)
+	if err != nil {
+		return nil, err
+	}
+	rowErrors = append(rowErrors, markDuplicateCSVKeys(mapped, pkCols, cols)...)
+	if len(rowErrors) > 0 {
+		sort.SliceStable(rowErrors, func(i, j int) bool { return rowErrors[i].RowIndex < rowErrors[j].RowIndex })
+		return nil, &model.APIError{
+			Status:  400,
+			Code:    model.CodeInvalidArgument,
+			Msg:     fmt.Sprintf("行 %d: %s", rowErrors[0].RowIndex+1, rowErrors[0].Message),
+			Details: map[string]int{"invalid_rows": len(rowErrors)},
+		}
+	}
+	req.Rows = mapped
 
 	// START TRANSACTION
 	if _, err := conn.ExecContext(ctx, "START TRANSACTION"); err != nil {
@@ -289,83 +382,80 @@ func (s *Service) CSVApply(ctx context.Context, req model.CSVApplyRequest) (*mod
 		}
 	}
 
-	for i, csvRow := range req.Rows {
-		if i >= 1000 {
-			break
-		}
-
-		// Build PKs map
-		pkMap := make(map[string]interface{}, len(pkCols))
-		for _, pk := range pkCols {
-			pkMap[pk] = csvRow[pk]
+	// Replace mode deletes first so a re-keyed row cannot collide with its old version.
+	inserts, updates, deletes := 0, 0, 0
+	if mode == model.CSVModeReplace {
+		deletes, err = scanCSVRowsMissingFromFile(ctx, conn, req.Table, cols, req.ReplaceFilter, req.Rows, func(page []map[string]interface{}) error {
+			return deleteCSVRows(ctx, conn, req.Table, pkCols, page)
+		})
+		if err != nil {
+			safeRollback(conn)
+			return nil, err
 		}
+	}
 
-		// Check existence
-		whereParts := make([]string, 0, len(pkCols))
-		whereArgs := make([]interface{}, 0, len(pkCols))
-		for _, pk := range pkCols {
-			whereParts = append(whereParts, fmt.Sprintf("`%s` = ?", pk))
-			whereArgs = append(whereArgs, pkMap[pk])
+	// Look the file keys up in batches, then write the rows through the same
+	// batched executor as Commit. opRows maps each op back to its file row.
+	existing, err := matchCSVRows(ctx, conn, req.Table, pkCols, req.Rows)
+	if err != nil {
+		safeRollback(conn)
+		return nil, err
+	}
+	pkSet := make(map[string]bool, len(pkCols))
+	for _, pk := range pkCols {
+		pkSet[pk] = true
+	}
+	ops := make([]model.CommitOp, 0, len(req.Rows))
+	opRows := make([]int, 0, len(req.Rows))
+	for i, csvRow := range req.Rows {
+		dbRow, exists := existing[i]
+		if exists && mode == model.CSVModeInsertOnly {
+			safeRollback(conn)
+			return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: fmt.Sprintf("行 %d: 主キーが既に存在します（insert_only）", i+1)}
 		}
-
-		var existCount int
-		checkQuery := fmt.Sprintf("SELECT COUNT(*) FROM `%s` WHERE %s", req.Table, strings.Join(whereParts, " AND "))
-		if err := conn.QueryRowContext(ctx, checkQuery, whereArgs...).Scan(&existCount); err != nil {
+		if !exists && mode == model.CSVModeUpdateOnly {
 			safeRollback(conn)
-			return nil, fmt.Errorf("failed to check row existence: %w", err)
+			return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: fmt.Sprintf("行 %d: 主キーが存在しません（update_only）", i+1)}
 		}
 
-		if existCount == 0 {
-			// INSERT
-			csvCols := make([]string, 0, len(csvRow))
-			placeholders := make([]string, 0, len(csvRow))
-			args := make([]interface{}, 0, len(csvRow))
-			for col, val := range csvRow {
-				if err := validation.ValidateIdentifier("column", col); err != nil {
-					continue
-				}
-				csvCols = append(csvCols, fmt.Sprintf("`%s`", col))
-				placeholders = append(placeholders, "?")
-				args = append(args, val)
-			}
-			insertSQL := fmt.Sprintf("INSERT INTO `%s` (%s) VALUES (%s)",
-				req.Table, strings.Join(csvCols, ", "), strings.Join(placeholders, ", "))
-			if _, err := conn.ExecContext(ctx, insertSQL, args...); err != nil {
-				safeRollback(conn)
-				return nil, fmt.Errorf("failed to insert row %d: %w", i, err)
-			}
-		} else {
-			// UPDATE non-PK columns
-			setParts := make([]string, 0)
-			setArgs := make([]interface{}, 0)
-			pkSet := make(map[string]bool, len(pkCols))
-			for _, pk := range pkCols {
-				pkSet[pk] = true
-			}
-			for col, val := range csvRow {
-				if pkSet[col] {
-					continue
-				}
-				if err := validation.ValidateIdentifier("column", col); err != nil {
-					continue
-				}
-				setParts = append(setParts, fmt.Sprintf("`%s` = ?", col))
-				setArgs = append(setArgs, val)
-			}
-			if len(setParts) == 0 {
-				continue
-			}
-			updateSQL := fmt.Sprintf("UPDATE `%s` SET %s WHERE %s",
-				req.Table, strings.Join(setParts, ", "), strings.Join(whereParts, " AND "))
-			// NEW-1: avoid append backing-array corruption by allocating a new slice.
-			allArgs := make([]interface{}, 0, len(setArgs)+len(whereArgs))
-			allArgs = append(allArgs, setArgs...)
-			allArgs = append(allArgs, whereArgs...)
-			if _, err := conn.ExecContext(ctx, updateSQL, allArgs...); err != nil {
-				safeRollback(conn)
-				return nil, fmt.Errorf("failed to update row %d: %w", i, err)
+		if !exists {
+			ops = append(ops, model.CommitOp{Type: "insert", Table: req.Table, Values: csvRow})
+			opRows = append(opRows, i)
+			inserts++
+			continue
+		}
+		// UPDATE non-PK columns, addressed by the stored key so that "007"
+		// updates the INT row 7 it matched.
+		values := make(map[string]interface{}, len(csvRow))
+		for col, val := range csvRow {
+			if !pkSet[col] {
+				values[col] = val
 			}
 		}
+		if len(values) == 0 {
+			continue
+		}
+		pk := make(map[string]interface{}, len(pkCols))
+		for _, col := range pkCols {
+			pk[col] = dbRow[col]
+		}
+		ops = append(ops, model.CommitOp{Type: "update", Table: req.Table, Values: values, PK: pk})
+		opRows = append(opRows, i)
+		updates++
+	}
+	batches, err := planCommitBatches(ops)
+	if err != nil {
+		safeRollback(conn)
+		return nil, csvOpError(err, opRows)
+	}
+	executor := newCommitBatchExecutor(conn)
+	executor.keysMatched = true
+	defer executor.close()
+	for _, batch := range batches {
+		if err := executor.apply(ctx, ops, batch); err != nil {
+			safeRollback(conn)
+			return nil, csvOpError(err, opRows)
+		}
 	}
 
 	// NEW-2: DOLT_VERIFY_CONSTRAINTS — ensure FK/CHECK constraints are not violated.
@@ -397,13 +487,20 @@ func (s *Service) CSVApply(ctx context.Context, req model.CSVApplyRequest) (*mod
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
+	if req.UploadID != "" {
+		s.uploads.remove(req.UploadID)
+	}
+
 	var newHead string
 	if err := conn.QueryRowContext(ctx, "SELECT DOLT_HASHOF('HEAD')").Scan(&newHead); err != nil {
 		return nil, fmt.Errorf("failed to get new HEAD: %w", err)
 	}
 
 	return &model.CSVApplyResponse{
-		Hash: newHead,
+		Hash:    newHead,
+		Inserts: inserts,
+		Updates: updates,
+		Deletes: deletes,
 		OperationResultFields: model.OperationResultFields{
 			Outcome: model.OperationOutcomeCompleted,
 			Message: "CSV を適用しました",
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+)
+
+const (
+	// csvPreviewBatchRows is how many file rows CSVPreview looks up per query.
+	csvPreviewBatchRows = 1000
+	// csvPreviewErrorPageSize is the page size of CSVPreviewErrors; the first
+	// page is returned inline by CSVPreview.
+	csvPreviewErrorPageSize = 100
+)
+
+// storeCSVPreviewErrors keeps the errors of a preview, with the original file
+// row of each, for CSVPreviewErrors and ExportCSVPreviewErrors.
+func (s *Service) storeCSVPreviewErrors(uploadID string, fileRows []map[string]interface{}, errs []model.CSVPreviewError) (string, error) {
+	var headers []string
+	if stored, ok := s.uploads.get(uploadID); ok {
+		headers = stored.headers
+	} else {
+		seen := make(map[string]bool)
+		for _, row := range fileRows {
+			for h := range row {
+				if !seen[h] {
+					seen[h] = true
+					headers = append(headers, h)
+				}
+			}
+		}
+		sort.Strings(headers)
+	}
+
+	rows := make([][]string, len(errs))
+	for i, e := range errs {
+		record := make([]string, len(headers))
+		for j, h := range headers {
+			if v := fileRows[e.RowIndex][h]; v != nil {
+				record[j] = fmt.Sprintf("%v", v)
+			}
+		}
+		rows[i] = record
+	}
+	return s.uploads.putPreview(&storedPreview{
+		headers:   headers,
+		errors:    errs,
+		rows:      rows,
+		expiresAt: time.Now().Add(time.Duration(s.cfg.Server.Upload.TTLMinutes) * time.Minute),
+	})
+}
+
+func (s *Service) csvPreview(previewID string) (*storedPreview, error) {
+	stored, ok := s.uploads.getPreview(previewID)
+	if !ok {
+		return nil, &model.APIError{
+			Status: 404,
+			Code:   model.CodeNotFound,
+			Msg:    "プレビュー結果が見つからないか期限切れです。もう一度プレビューしてください",
+		}
+	}
+	return stored, nil
+}
+
+// CSVPreviewErrors returns one page (1-based) of the errors of a preview.
+func (s *Service) CSVPreviewErrors(previewID string, page int) (*model.CSVPreviewErrorsResponse, error) {
+	if page < 1 {
+		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "page must be 1 or greater"}
+	}
+	stored, err := s.csvPreview(previewID)
+	if err != nil {
+		return nil, err
+	}
+	start := min((page-1)*csvPreviewErrorPageSize, len(stored.errors))
+	end := min(start+csvPreviewErrorPageSize, len(stored.errors))
+	return &model.CSVPreviewErrorsResponse{
+		PreviewID: previewID,
+		Page:      page,
+		PageSize:  csvPreviewErrorPageSize,
+		Total:     len(stored.errors),
+		Errors:    stored.errors[start:end],
+	}, nil
+}
+
+// ExportCSVPreviewErrors writes the error report of a preview: the 1-based
+// data row number, the original file columns and the error message. It has a
+// UTF-8 BOM so that Excel opens it correctly.
+func (s *Service) ExportCSVPreviewErrors(previewID string) ([]byte, string, error) {
+	stored, err := s.csvPreview(previewID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("\ufeff")
+	cw := csv.NewWriter(&buf)
+	header := append(append([]string{"行"}, stored.headers...), "エラー")
+	if err := cw.Write(header); err != nil {
+		return nil, "", err
+	}
+	for i, e := range stored.errors {
+		record := append(append([]string{strconv.Itoa(e.RowIndex + 1)}, stored.rows[i]...), e.Message)
+		if err := cw.Write(record); err != nil {
+			return nil, "", err
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), fmt.Sprintf("csv-errors-%s.csv", previewID[:8]), nil
+}
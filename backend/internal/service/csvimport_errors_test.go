@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+)
+
+func TestCSVPreview_ValidatesEveryRowInBatches(t *testing.T) {
+	lookups := 0
+	repo := newCrossCopyTestRepo(t,
+		func(dbName, refName, query string, args []driver.NamedValue) (testQueryResult, error) {
+			switch {
+			case query == "SHOW COLUMNS FROM `users`":
+				return showColumnsResult("varchar(255)"), nil
+			case strings.HasPrefix(query, "SELECT f.csv_row, t.* FROM `users` AS t JOIN ("):
+				lookups++
+				// Even ids exist.
+				result := testQueryResult{columns: []string{"csv_row", "id", "name"}}
+				for i := 0; i < len(args); i += 2 {
+					id := args[i+1].Value.(string)
+					if id[len(id)-1]%2 == 0 {
+						result.rows = append(result.rows, []driver.Value{args[i].Value, id, "n" + id})
+					}
+				}
+				return result, nil
+			}
+			return testQueryResult{}, fmt.Errorf("unexpected revision query on %s: %s", refName, query)
+		}, nil)
+	cfg := testServiceConfig()
+	cfg.Server.Upload.TTLMinutes = 30
+	svc := newWithDeps(repo, cfg)
+
+	rows := make([]map[string]interface{}, 2500)
+	for i := range rows {
+		id := fmt.Sprint(i + 1)
+		rows[i] = map[string]interface{}{"id": id, "name": "n" + id}
+	}
+	resp, err := svc.CSVPreview(context.Background(), model.CSVPreviewRequest{
+		TargetID: "local", DBName: "test_db", BranchName: "wi/import", Table: "users",
+		Mode: model.CSVModeUpdateOnly, Rows: rows,
+	})
+	if err != nil {
+		t.Fatalf("CSVPreview: %v", err)
+	}
+	if lookups != 3 {
+		t.Fatalf("expected 3 batch lookups, got %d", lookups)
+	}
+	if resp.Skips != 1250 || resp.Errors != 1250 || len(resp.PreviewErrors) != csvPreviewErrorPageSize || len(resp.SampleDiffs) != 0 {
+		t.Fatalf("unexpected preview: skips=%d errors=%d page=%d", resp.Skips, resp.Errors, len(resp.PreviewErrors))
+	}
+	if resp.PreviewID == "" || resp.PreviewErrors[0].RowIndex != 0 {
+		t.Fatalf("unexpected first error page: id=%q first=%+v", resp.PreviewID, resp.PreviewErrors[0])
+	}
+
+	last, err := svc.CSVPreviewErrors(resp.PreviewID, 13)
+	if err != nil {
+		t.Fatalf("CSVPreviewErrors: %v", err)
+	}
+	if last.Total != 1250 || len(last.Errors) != 50 || last.Errors[49].RowIndex != 2498 {
+		t.Fatalf("unexpected last page: total=%d len=%d", last.Total, len(last.Errors))
+	}
+
+	data, name, err := svc.ExportCSVPreviewErrors(resp.PreviewID)
+	if err != nil {
+		t.Fatalf("ExportCSVPreviewErrors: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if !strings.HasSuffix(name, ".csv") || len(lines) != 1251 {
+		t.Fatalf("unexpected report %s with %d lines", name, len(lines))
+	}
+	if lines[0] != "\ufeff行,id,name,エラー" || lines[1] != "1,1,n1,主キーが存在しません（update_only）" {
+		t.Fatalf("unexpected report head:\n%s\n%s", lines[0], lines[1])
+	}
+
+	if _, err := svc.CSVPreviewErrors("missing", 1); err == nil {
+		t.Fatal("expected unknown preview_id to fail")
+	}
+}
+
+func TestCSVPreview_FailsWhenRowLookupFails(t *testing.T) {
+	repo := newCrossCopyTestRepo(t,
+		func(dbName, refName, query string, args []driver.NamedValue) (testQueryResult, error) {
+			if query == "SHOW COLUMNS FROM `users`" {
+				return showColumnsResult("varchar(255)"), nil
+			}
+			return testQueryResult{}, fmt.Errorf("lookup timed out")
+		}, nil)
+	svc := newWithDeps(repo, testServiceConfig())
+
+	_, err := svc.CSVPreview(context.Background(), model.CSVPreviewRequest{
+		TargetID: "local", DBName: "test_db", BranchName: "wi/import", Table: "users",
+		Rows: []map[string]interface{}{{"id": "1", "name": "Alice"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "lookup timed out") {
+		t.Fatalf("expected the lookup error, got %v", err)
+	}
+}
+
+func TestCSVPreview_ChecksTypesAndDuplicateKeysWithoutMapping(t *testing.T) {
+	repo := newCrossCopyTestRepo(t,
+		func(dbName, refName, query string, args []driver.NamedValue) (testQueryResult, error) {
+			switch {
+			case query == "SHOW COLUMNS FROM `users`":
+				return testQueryResult{
+					columns: []string{"Field", "Type", "Null", "Key", "Default", "Extra"},
+					rows:    [][]driver.Value{{"id", "int", "NO", "PRI", nil, ""}, {"name", "varchar(5)", "NO", "", nil, ""}},
+				}, nil
+			case strings.HasPrefix(query, csvJoinPrefix):
+				return testQueryResult{columns: []string{"csv_row", "id", "name"}}, nil
+			}
+			return testQueryResult{}, fmt.Errorf("unexpected revision query on %s: %s", refName, query)
+		}, nil)
+	svc := newWithDeps(repo, testServiceConfig())
+
+	resp, err := svc.CSVPreview(context.Background(), model.CSVPreviewRequest{
+		TargetID: "local", DBName: "test_db", BranchName: "wi/import", Table: "users", Mode: model.CSVModeInsertOnly,
+		Rows: []map[string]interface{}{
+			{"id": "abc", "name": "a"},
+			{"id": "1", "name": "toolong"},
+			{"id": "", "name": "x"},
+			{"id": "7", "name": "b"},
+			{"id": "007", "name": "c"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CSVPreview: %v", err)
+	}
+	if resp.Inserts != 1 || resp.Errors != 4 {
+		t.Fatalf("unexpected preview: %+v", resp)
+	}
+	want := []int{0, 1, 2, 4}
+	for i, e := range resp.PreviewErrors {
+		if e.RowIndex != want[i] {
+			t.Fatalf("unexpected errors: %+v", resp.PreviewErrors)
+		}
+	}
+	if msg := resp.PreviewErrors[3].Message; msg != "主キーが 4 行目と重複しています" {
+		t.Fatalf("unexpected duplicate message: %s", msg)
+	}
+}
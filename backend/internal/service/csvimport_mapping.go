@@ -0,0 +1,400 @@
+package service
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+)
+
+var (
+	csvIntegerRe  = regexp.MustCompile(`^[+-]?[0-9]+$`)
+	csvDecimalRe  = regexp.MustCompile(`^[+-]?([0-9]*)(?:\.([0-9]+))?$`)
+	csvTypeArgsRe = regexp.MustCompile(`\(([^)]*)\)`)
+	// Dates like 2026-10-16, 2026/10/16, 2026.1.6 or 2026年10月16日, with an optional time.
+	csvDateRe        = regexp.MustCompile(`^([0-9]{4})[-/.年]([0-9]{1,2})[-/.月]([0-9]{1,2})日?(?:[ T]+([0-9]{1,2}):([0-9]{2})(?::([0-9]{2}))?)?$`)
+	csvCompactDateRe = regexp.MustCompile(`^([0-9]{4})([0-9]{2})([0-9]{2})$`)
+	csvTimeRe        = regexp.MustCompile(`^([0-9]{1,3}):([0-9]{2})(?::([0-9]{2}))?$`)
+)
+
+// csvIntegerBits is the storage width of each integer type.
+var csvIntegerBits = map[string]int{
+	"tinyint":   8,
+	"bool":      8,
+	"boolean":   8,
+	"smallint":  16,
+	"mediumint": 24,
+	"int":       32,
+	"integer":   32,
+	"bigint":    64,
+}
+
+// toHalfWidth converts full-width ASCII variants (U+FF01-U+FF5E) and the
+// ideographic space to their half-width forms. Katakana is left as is.
+func toHalfWidth(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '　':
+			return ' '
+		case r >= '！' && r <= '～':
+			return r - 0xFEE0
+		}
+		return r
+	}, s)
+}
+
+// csvMapper applies a CSVMapping to file rows.
+type csvMapper struct {
+	mapping model.CSVMapping
+	cols    map[string]model.ColumnSchema
+	targets map[string]string // file header -> table column; "" drops the header
+}
+
+// newCSVMapper checks the mapping against the schema and the file headers.
+func newCSVMapper(mapping model.CSVMapping, cols []model.ColumnSchema, headers []string) (*csvMapper, error) {
+	invalid := func(format string, args ...interface{}) error {
+		return &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: fmt.Sprintf(format, args...)}
+	}
+	m := &csvMapper{mapping: mapping, cols: make(map[string]model.ColumnSchema, len(cols)), targets: make(map[string]string)}
+	for _, c := range cols {
+		m.cols[c.Name] = c
+	}
+	ignored := make(map[string]bool, len(mapping.Ignore))
+	for _, h := range mapping.Ignore {
+		ignored[h] = true
+	}
+
+	provided := make(map[string]bool)
+	unknown := make([]string, 0)
+	for _, h := range headers {
+		col, mapped := mapping.Columns[h]
+		switch {
+		case ignored[h] || (mapped && col == ""):
+			m.targets[h] = ""
+			continue
+		case !mapped:
+			col = h
+		}
+		if _, ok := m.cols[col]; !ok {
+			unknown = append(unknown, h)
+			continue
+		}
+		if provided[col] {
+			return nil, invalid("複数のヘッダーがカラム '%s' に割り当てられています", col)
+		}
+		m.targets[h] = col
+		provided[col] = true
+	}
+	if len(unknown) > 0 {
+		return nil, invalid("テーブルにないヘッダーがあります（mapping または ignore で指定してください）: %s", strings.Join(unknown, ", "))
+	}
+	for _, values := range []map[string]string{mapping.Constants, mapping.Defaults} {
+		for col := range values {
+			if _, ok := m.cols[col]; !ok {
+				return nil, invalid("カラム '%s' はテーブルにありません", col)
+			}
+			provided[col] = true
+		}
+	}
+	for _, c := range cols {
+		if c.PrimaryKey && !provided[c.Name] {
+			return nil, invalid("CSVに主キーカラム '%s' が含まれていません", c.Name)
+		}
+	}
+	return m, nil
+}
+
+// mapRow maps one file row to table columns. Invalid values are reported per
+// column and leave the row unusable.
+func (m *csvMapper) mapRow(row map[string]interface{}) (map[string]interface{}, map[string]string) {
+	raw := make(map[string]string, len(row)+len(m.mapping.Constants))
+	for h, v := range row {
+		col, ok := m.targets[h]
+		if !ok || col == "" {
+			continue
+		}
+		if v != nil {
+			raw[col] = fmt.Sprintf("%v", v)
+		} else {
+			raw[col] = ""
+		}
+	}
+	for col, v := range m.mapping.Constants {
+		raw[col] = v
+	}
+	for col, v := range m.mapping.Defaults {
+		if _, ok := raw[col]; !ok {
+			raw[col] = v
+		}
+	}
+
+	out := make(map[string]interface{}, len(raw))
+	errs := make(map[string]string)
+	for col, v := range raw {
+		if m.mapping.Trim {
+			v = strings.TrimSpace(v)
+		}
+		if m.mapping.HalfWidth {
+			v = toHalfWidth(v)
+		}
+		if v == "" {
+			if d, ok := m.mapping.Defaults[col]; ok {
+				v = d
+			}
+		}
+		value, err := coerceCSVValue(m.cols[col], v)
+		if err != nil {
+			errs[col] = err.Error()
+			continue
+		}
+		out[col] = value
+	}
+	return out, errs
+}
+
+// mapCSVRows maps every row. The returned slice keeps the row positions; rows
+// with invalid values are nil and described in rowErrors.
+func mapCSVRows(rows []map[string]interface{}, mapping model.CSVMapping, cols []model.ColumnSchema) ([]map[string]interface{}, []model.CSVPreviewError, map[string]int, error) {
+	if len(rows) == 0 {
+		return rows, nil, nil, nil
+	}
+	headerSet := make(map[string]bool)
+	for _, row := range rows {
+		for h := range row {
+			headerSet[h] = true
+		}
+	}
+	headers := make([]string, 0, len(headerSet))
+	for h := range headerSet {
+		headers = append(headers, h)
+	}
+	sort.Strings(headers)
+	mapper, err := newCSVMapper(mapping, cols, headers)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	mapped := make([]map[string]interface{}, len(rows))
+	var rowErrors []model.CSVPreviewError
+	columnErrors := make(map[string]int)
+	for i, row := range rows {
+		out, errs := mapper.mapRow(row)
+		if len(errs) == 0 {
+			mapped[i] = out
+			continue
+		}
+		msgs := make([]string, 0, len(errs))
+		for _, col := range sortedStringKeys(errs) {
+			columnErrors[col]++
+			msgs = append(msgs, fmt.Sprintf("%s: %s", col, errs[col]))
+		}
+		rowErrors = append(rowErrors, model.CSVPreviewError{RowIndex: i, Message: strings.Join(msgs, "; ")})
+	}
+	return mapped, rowErrors, columnErrors, nil
+}
+
+// markDuplicateCSVKeys reports every row whose primary key repeats an earlier
+// row of the file and clears it, like a row with invalid values. Keys are
+// compared after numeric normalization, so "007" repeats 7 in an INT key.
+func markDuplicateCSVKeys(rows []map[string]interface{}, pkCols []string, cols []model.ColumnSchema) []model.CSVPreviewError {
+	kinds := columnKindsFromSchema(cols)
+	seen := make(map[string]int, len(rows))
+	var rowErrors []model.CSVPreviewError
+	for i, row := range rows {
+		if row == nil {
+			continue
+		}
+		parts := make([]string, len(pkCols))
+		complete := true
+		for j, pk := range pkCols {
+			v, ok := row[pk]
+			if !ok || v == nil {
+				complete = false
+				break
+			}
+			parts[j] = csvKeyPart(kinds[pk], fmt.Sprintf("%v", v))
+		}
+		if !complete {
+			continue // reported as a missing key by the caller
+		}
+		key := strings.Join(parts, "\x00")
+		if first, ok := seen[key]; ok {
+			rowErrors = append(rowErrors, model.CSVPreviewError{RowIndex: i, Message: fmt.Sprintf("主キーが %d 行目と重複しています", first+1)})
+			rows[i] = nil
+			continue
+		}
+		seen[key] = i
+	}
+	return rowErrors
+}
+
+// csvKeyPart normalizes one key value the way the column type compares it.
+func csvKeyPart(kind columnKind, v string) string {
+	switch kind {
+	case columnKindInteger, columnKindBit, columnKindDecimal, columnKindFloat:
+		if r, ok := new(big.Rat).SetString(v); ok {
+			return r.RatString()
+		}
+	}
+	return v
+}
+
+func sortedStringKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// coerceCSVValue validates a text value against a SHOW COLUMNS type and returns
+// the value to bind: nil for an empty nullable cell, otherwise normalized text.
+func coerceCSVValue(col model.ColumnSchema, v string) (interface{}, error) {
+	kind := columnKindOf(col.Type)
+	if v == "" {
+		switch {
+		case col.Nullable:
+			return nil, nil
+		case kind == columnKindOther:
+			return "", nil
+		}
+		return nil, fmt.Errorf("値が必要です")
+	}
+
+	t := strings.ToLower(strings.TrimSpace(col.Type))
+	base := t
+	if i := strings.IndexAny(base, "( "); i >= 0 {
+		base = base[:i]
+	}
+	var args []string
+	if m := csvTypeArgsRe.FindStringSubmatch(t); m != nil {
+		args = strings.Split(m[1], ",")
+	}
+
+	switch kind {
+	case columnKindInteger, columnKindBit:
+		n := strings.ReplaceAll(v, ",", "")
+		if !csvIntegerRe.MatchString(n) {
+			return nil, fmt.Errorf("整数ではありません (%s)", v)
+		}
+		if bits, ok := csvIntegerBits[base]; ok && kind == columnKindInteger {
+			if err := checkCSVIntegerRange(n, bits, strings.Contains(t, "unsigned")); err != nil {
+				return nil, err
+			}
+		}
+		return strings.TrimPrefix(n, "+"), nil
+	case columnKindDecimal:
+		n := strings.ReplaceAll(v, ",", "")
+		m := csvDecimalRe.FindStringSubmatch(n)
+		if m == nil || (m[1] == "" && m[2] == "") {
+			return nil, fmt.Errorf("数値ではありません (%s)", v)
+		}
+		if len(args) == 2 {
+			precision, _ := strconv.Atoi(strings.TrimSpace(args[0]))
+			scale, _ := strconv.Atoi(strings.TrimSpace(args[1]))
+			if len(strings.TrimLeft(m[1], "0")) > precision-scale || len(m[2]) > scale {
+				return nil, fmt.Errorf("decimal(%d,%d) の桁数を超えています (%s)", precision, scale, v)
+			}
+		}
+		return strings.TrimPrefix(n, "+"), nil
+	case columnKindFloat:
+		n := strings.ReplaceAll(v, ",", "")
+		if _, err := strconv.ParseFloat(n, 64); err != nil {
+			return nil, fmt.Errorf("数値ではありません (%s)", v)
+		}
+		return n, nil
+	case columnKindTemporal:
+		return normalizeCSVTemporal(base, v)
+	}
+
+	switch base {
+	case "char", "varchar":
+		if len(args) == 1 {
+			if max, err := strconv.Atoi(strings.TrimSpace(args[0])); err == nil && utf8.RuneCountInString(v) > max {
+				return nil, fmt.Errorf("%d 文字を超えています", max)
+			}
+		}
+	case "enum":
+		m := csvTypeArgsRe.FindStringSubmatch(col.Type)
+		if m == nil {
+			break
+		}
+		allowed := make([]string, 0)
+		for _, opt := range strings.Split(m[1], ",") {
+			opt = strings.Trim(strings.TrimSpace(opt), "'")
+			if opt == v {
+				return v, nil
+			}
+			allowed = append(allowed, opt)
+		}
+		return nil, fmt.Errorf("%s のいずれかではありません (%s)", strings.Join(allowed, "/"), v)
+	}
+	return v, nil
+}
+
+func checkCSVIntegerRange(n string, bits int, unsigned bool) error {
+	value, ok := new(big.Int).SetString(n, 10)
+	if !ok {
+		return fmt.Errorf("整数ではありません (%s)", n)
+	}
+	lo, hi := new(big.Int), new(big.Int)
+	if unsigned {
+		hi.Lsh(big.NewInt(1), uint(bits)).Sub(hi, big.NewInt(1))
+	} else {
+		hi.Lsh(big.NewInt(1), uint(bits-1)).Sub(hi, big.NewInt(1))
+		lo.Neg(new(big.Int).Lsh(big.NewInt(1), uint(bits-1)))
+	}
+	if value.Cmp(lo) < 0 || value.Cmp(hi) > 0 {
+		return fmt.Errorf("範囲外です (%s..%s)", lo, hi)
+	}
+	return nil
+}
+
+// normalizeCSVTemporal converts common date spellings to the MySQL literal form.
+func normalizeCSVTemporal(base, v string) (interface{}, error) {
+	switch base {
+	case "year":
+		if y, err := strconv.Atoi(v); err != nil || y < 1901 || y > 2155 {
+			return nil, fmt.Errorf("年ではありません (%s)", v)
+		}
+		return v, nil
+	case "time":
+		if !csvTimeRe.MatchString(v) {
+			return nil, fmt.Errorf("時刻ではありません (%s)", v)
+		}
+		return v, nil
+	}
+
+	var parts []string
+	if m := csvDateRe.FindStringSubmatch(v); m != nil {
+		parts = m[1:]
+	} else if m := csvCompactDateRe.FindStringSubmatch(v); m != nil {
+		parts = append(m[1:], "", "", "")
+	} else {
+		return nil, fmt.Errorf("日付ではありません (%s)", v)
+	}
+	num := func(s string) int {
+		n, _ := strconv.Atoi(s)
+		return n
+	}
+	y, mo, d := num(parts[0]), num(parts[1]), num(parts[2])
+	h, mi, sec := num(parts[3]), num(parts[4]), num(parts[5])
+	t := time.Date(y, time.Month(mo), d, h, mi, sec, 0, time.UTC)
+	if t.Year() != y || int(t.Month()) != mo || t.Day() != d || h > 23 || mi > 59 || sec > 59 {
+		return nil, fmt.Errorf("存在しない日付です (%s)", v)
+	}
+	if base == "date" {
+		if parts[3] != "" && (h != 0 || mi != 0 || sec != 0) {
+			return nil, fmt.Errorf("日付カラムに時刻があります (%s)", v)
+		}
+		return t.Format("2006-01-02"), nil
+	}
+	return t.Format("2006-01-02 15:04:05"), nil
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+)
+
+var csvMappingSchema = testQueryResult{
+	columns: []string{"Field", "Type", "Null", "Key", "Default", "Extra"},
+	rows: [][]driver.Value{
+		{"id", "int unsigned", "NO", "PRI", nil, ""},
+		{"code", "varchar(4)", "NO", "", nil, ""},
+		{"joined", "date", "YES", "", nil, ""},
+		{"status", "enum('active','retired')", "NO", "", nil, ""},
+	},
+}
+
+func TestMapCSVRows_NormalizesAndCountsColumnErrors(t *testing.T) {
+	cols := []model.ColumnSchema{
+		{Name: "id", Type: "int unsigned", PrimaryKey: true},
+		{Name: "code", Type: "varchar(4)"},
+		{Name: "joined", Type: "date", Nullable: true},
+		{Name: "status", Type: "enum('active','retired')"},
+	}
+	mapping := model.CSVMapping{
+		Columns:   map[string]string{"社員番号": "id", "コード": "code", "入社日": "joined"},
+		Ignore:    []string{"備考"},
+		Defaults:  map[string]string{"status": "active"},
+		Trim:      true,
+		HalfWidth: true,
+	}
+	rows := []map[string]interface{}{
+		{"社員番号": "１２", "コード": " ＡＢ１ ", "入社日": "2026/10/16", "備考": "x"},
+		{"社員番号": "-1", "コード": "ABCDE", "入社日": "2026年2月30日", "備考": ""},
+		{"社員番号": "13", "コード": "C", "入社日": "", "備考": ""},
+	}
+
+	mapped, rowErrors, columnErrors, err := mapCSVRows(rows, mapping, cols)
+	if err != nil {
+		t.Fatalf("mapCSVRows: %v", err)
+	}
+	want := map[string]interface{}{"id": "12", "code": "AB1", "joined": "2026-10-16", "status": "active"}
+	if fmt.Sprint(mapped[0]) != fmt.Sprint(want) {
+		t.Fatalf("row 0 = %v, want %v", mapped[0], want)
+	}
+	if mapped[1] != nil || len(rowErrors) != 1 || rowErrors[0].RowIndex != 1 {
+		t.Fatalf("expected row 1 to be rejected, got %v / %+v", mapped[1], rowErrors)
+	}
+	if columnErrors["id"] != 1 || columnErrors["code"] != 1 || columnErrors["joined"] != 1 {
+		t.Fatalf("unexpected column errors: %v", columnErrors)
+	}
+	if mapped[2]["joined"] != nil {
+		t.Fatalf("empty nullable date should be NULL, got %v", mapped[2]["joined"])
+	}
+
+	_, _, _, err = mapCSVRows([]map[string]interface{}{{"コード": "A"}}, mapping, cols)
+	if apiErr, ok := err.(*model.APIError); !ok || !strings.Contains(apiErr.Msg, "'id'") {
+		t.Fatalf("expected missing primary key error, got %v", err)
+	}
+	_, _, _, err = mapCSVRows([]map[string]interface{}{{"社員番号": "1", "extra": "A"}}, mapping, cols)
+	if apiErr, ok := err.(*model.APIError); !ok || !strings.Contains(apiErr.Msg, "extra") {
+		t.Fatalf("expected unknown header error, got %v", err)
+	}
+}
+
+func TestCSVPreview_UsesSavedProfile(t *testing.T) {
+	profile := `{"schema":"dolt-webui/csv-profile@1","table":"users","name":"legacy","columns":{"No":"id","Code":"code"},"constants":{"status":"retired"},"trim":true}`
+	repo := newCrossCopyTestRepo(t,
+		func(dbName, refName, query string, args []driver.NamedValue) (testQueryResult, error) {
+			switch {
+			case query == "SELECT message FROM dolt_tags WHERE tag_name = ?" && refName == "main":
+				if args[0].Value != "csvprofile/users/6c6567616379" {
+					return testQueryResult{}, fmt.Errorf("unexpected profile tag: %v", args)
+				}
+				return testQueryResult{columns: []string{"message"}, rows: [][]driver.Value{{profile}}}, nil
+			case query == "SHOW COLUMNS FROM `users`":
+				return csvMappingSchema, nil
+			case query == "SELECT f.csv_row, t.* FROM `users` AS t JOIN (SELECT ? AS csv_row, ? AS k0) AS f ON t.`id` = f.k0":
+				return testQueryResult{
+					columns: []string{"csv_row", "id", "code", "joined", "status"},
+					rows:    [][]driver.Value{{args[0].Value, int64(1), "A", nil, "active"}},
+				}, nil
+			}
+			return testQueryResult{}, fmt.Errorf("unexpected revision query on %s: %s", refName, query)
+		}, nil)
+	svc := newWithDeps(repo, testServiceConfig())
+
+	resp, err := svc.CSVPreview(context.Background(), model.CSVPreviewRequest{
+		TargetID: "local", DBName: "test_db", BranchName: "wi/import", Table: "users", Profile: "legacy",
+		Rows: []map[string]interface{}{
+			{"No": " 1 ", "Code": "A"},
+			{"No": "x", "Code": "TOOLONG"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CSVPreview: %v", err)
+	}
+	if resp.Updates != 1 || resp.Errors != 1 || resp.ColumnErrors["id"] != 1 || resp.ColumnErrors["code"] != 1 {
+		t.Fatalf("unexpected preview: %+v", resp)
+	}
+	if resp.SampleDiffs[0].Row["status"] != "retired" {
+		t.Fatalf("constant not applied: %+v", resp.SampleDiffs[0])
+	}
+}
+
+func TestSaveCSVMappingProfile_StoresTagWithoutMovingMain(t *testing.T) {
+	var executed []string
+	var message string
+	repo := newCrossCopyTestRepo(t, nil, nil)
+	repo.protectedHandler = func(dbName, branchName, query string, args []driver.NamedValue) (testQueryResult, error) {
+		if branchName != "main" {
+			return testQueryResult{}, fmt.Errorf("unexpected protected branch %s", branchName)
+		}
+		executed = append(executed, query)
+		switch {
+		case strings.HasPrefix(query, "SELECT COUNT(*) FROM dolt_tags"):
+			return testQueryResult{columns: []string{"count"}, rows: [][]driver.Value{{int64(0)}}}, nil
+		case strings.HasPrefix(query, "CALL DOLT_TAG('-m'"):
+			if args[1].Value != "csvprofile/users/6c6567616379" {
+				return testQueryResult{}, fmt.Errorf("unexpected tag name %v", args[1].Value)
+			}
+			message = args[0].Value.(string)
+		}
+		return testQueryResult{}, nil
+	}
+	svc := newWithDeps(repo, testServiceConfig())
+
+	saved, err := svc.SaveCSVMappingProfile(context.Background(), model.SaveCSVMappingProfileRequest{
+		TargetID: "local", DBName: "test_db",
+		CSVMappingProfile: model.CSVMappingProfile{Table: "users", Name: "legacy", CSVMapping: model.CSVMapping{Trim: true}},
+	})
+	if err != nil {
+		t.Fatalf("SaveCSVMappingProfile: %v", err)
+	}
+	if !saved.Trim || saved.UpdatedAt == "" {
+		t.Fatalf("unexpected profile: %+v", saved)
+	}
+	if stored, err := parseCSVProfile("csvprofile/users/6c6567616379", message); err != nil || stored.Name != "legacy" || !stored.Trim {
+		t.Fatalf("unexpected tag message %q: %v", message, err)
+	}
+	joined := strings.Join(executed, "\n")
+	for _, unwanted := range []string{"CREATE TABLE", "INSERT", "DOLT_ADD", "DOLT_COMMIT"} {
+		if strings.Contains(joined, unwanted) {
+			t.Fatalf("saving a profile must not write to main, got %q in:\n%s", unwanted, joined)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+)
+
+// csvDeleteBatchRows caps the primary keys folded into one DELETE in replace mode.
+const csvDeleteBatchRows = 500
+
+// normalizeCSVMode defaults the mode to upsert and rejects a replace filter on
+// any other mode.
+func normalizeCSVMode(mode string, replaceFilter []model.FilterCondition) (string, error) {
+	switch mode {
+	case "":
+		mode = model.CSVModeUpsert
+	case model.CSVModeUpsert, model.CSVModeInsertOnly, model.CSVModeUpdateOnly, model.CSVModeReplace:
+	default:
+		return "", &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: fmt.Sprintf("unknown CSV mode: %s", mode)}
+	}
+	if len(replaceFilter) > 0 && mode != model.CSVModeReplace {
+		return "", &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "replace_filter is only valid with mode=replace"}
+	}
+	return mode, nil
+}
+
+// dbPKKey builds a key from the primary key values of a row read from the
+// table. Only compare keys that both came from the database: file values are
+// text and "007" is not "7" here even when the column is an INT.
+func dbPKKey(row map[string]interface{}, pkCols []string) string {
+	parts := make([]string, len(pkCols))
+	for i, pk := range pkCols {
+		parts[i] = fmt.Sprintf("%v", row[pk])
+	}
+	return strings.Join(parts, "\x00")
+}
+
+// csvMissingPageRows is the keyset page size used to walk the table in
+// replace mode.
+var csvMissingPageRows = 1000
+
+// scanCSVRowsMissingFromFile walks the table rows inside the replace filter in
+// primary key order, a page at a time, and passes fn the rows of each page
+// whose primary key is not in the file. These are the rows replace mode
+// deletes. The file keys are first resolved to the table rows they match (see
+// matchCSVRows), so both sides of the comparison are database values; the
+// table itself is never held in memory. Rows deleted by fn are behind the
+// keyset cursor and do not disturb paging. It returns the number of rows found.
+func scanCSVRowsMissingFromFile(ctx context.Context, conn *sql.Conn, table string, cols []model.ColumnSchema, filters []model.FilterCondition, fileRows []map[string]interface{}, fn func(page []map[string]interface{}) error) (int, error) {
+	pkCols := getPKColumns(cols)
+	allowedCols := make(map[string]bool, len(cols))
+	for _, c := range cols {
+		allowedCols[c.Name] = true
+	}
+	var filterParts []string
+	var filterArgs []interface{}
+	for _, f := range filters {
+		part, args, apiErr := buildFilterSQL(f, allowedCols)
+		if apiErr != nil {
+			return 0, apiErr
+		}
+		filterParts = append(filterParts, part)
+		filterArgs = append(filterArgs, args...)
+	}
+
+	matched, err := matchCSVRows(ctx, conn, table, pkCols, fileRows)
+	if err != nil {
+		return 0, err
+	}
+	inFile := make(map[string]bool, len(matched))
+	for _, dbRow := range matched {
+		inFile[dbPKKey(dbRow, pkCols)] = true
+	}
+
+	keyCols := quoteIdentifiers(pkCols)
+	total := 0
+	var lastKey []interface{}
+	for {
+		whereParts := append([]string{}, filterParts...)
+		args := append([]interface{}{}, filterArgs...)
+		if lastKey != nil {
+			// (k1 > ?) OR (k1 = ? AND k2 > ?) OR ...
+			ors := make([]string, len(keyCols))
+			for i := range keyCols {
+				ands := make([]string, 0, i+1)
+				for j := 0; j < i; j++ {
+					ands = append(ands, keyCols[j]+" = ?")
+					args = append(args, lastKey[j])
+				}
+				ands = append(ands, keyCols[i]+" > ?")
+				args = append(args, lastKey[i])
+				ors[i] = "(" + strings.Join(ands, " AND ") + ")"
+			}
+			whereParts = append(whereParts, "("+strings.Join(ors, " OR ")+")")
+		}
+		query := fmt.Sprintf("SELECT * FROM `%s`", table)
+		if len(whereParts) > 0 {
+			query += " WHERE " + strings.Join(whereParts, " AND ")
+		}
+		query += fmt.Sprintf(" ORDER BY %s LIMIT %d", strings.Join(keyCols, ", "), csvMissingPageRows)
+
+		page, err := queryCSVTableRows(ctx, conn, query, args)
+		if err != nil {
+			return total, err
+		}
+		missing := make([]map[string]interface{}, 0)
+		for _, dbRow := range page {
+			if !inFile[dbPKKey(dbRow, pkCols)] {
+				missing = append(missing, dbRow)
+			}
+		}
+		if len(missing) > 0 {
+			if err := fn(missing); err != nil {
+				return total, err
+			}
+			total += len(missing)
+		}
+		if len(page) < csvMissingPageRows {
+			return total, nil
+		}
+		last := page[len(page)-1]
+		lastKey = make([]interface{}, len(pkCols))
+		for i, pk := range pkCols {
+			lastKey[i] = last[pk]
+		}
+	}
+}
+
+func queryCSVTableRows(ctx context.Context, conn *sql.Conn, query string, args []interface{}) ([]map[string]interface{}, error) {
+	rows, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rows to replace: %w", err)
+	}
+	defer rows.Close()
+	dbCols, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns: %w", err)
+	}
+	out := make([]map[string]interface{}, 0)
+	for rows.Next() {
+		vals := make([]interface{}, len(dbCols))
+		ptrs := make([]interface{}, len(dbCols))
+		for i := range vals {
+			ptrs[i] = &vals[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		dbRow := make(map[string]interface{}, len(dbCols))
+		for i, col := range dbCols {
+			if b, ok := vals[i].([]byte); ok {
+				dbRow[col] = string(b)
+			} else {
+				dbRow[col] = vals[i]
+			}
+		}
+		out = append(out, dbRow)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows to replace: %w", err)
+	}
+	return out, nil
+}
+
+// deleteCSVRows deletes rows by primary key in batches.
+func deleteCSVRows(ctx context.Context, conn *sql.Conn, table string, pkCols []string, rows []map[string]interface{}) error {
+	quoted := make([]string, len(pkCols))
+	for i, pk := range pkCols {
+		quoted[i] = fmt.Sprintf("`%s`", pk)
+	}
+	target := quoted[0]
+	rowPlaceholder := "?"
+	if len(pkCols) > 1 {
+		target = "(" + strings.Join(quoted, ", ") + ")"
+		rowPlaceholder = "(" + strings.TrimSuffix(strings.Repeat("?, ", len(pkCols)), ", ") + ")"
+	}
+
+	for start := 0; start < len(rows); start += csvDeleteBatchRows {
+		end := start + csvDeleteBatchRows
+		if end > len(rows) {
+			end = len(rows)
+		}
+		placeholders := make([]string, 0, end-start)
+		args := make([]interface{}, 0, (end-start)*len(pkCols))
+		for _, row := range rows[start:end] {
+			placeholders = append(placeholders, rowPlaceholder)
+			for _, pk := range pkCols {
+				args = append(args, row[pk])
+			}
+		}
+		query := fmt.Sprintf("DELETE FROM `%s` WHERE %s IN (%s)", table, target, strings.Join(placeholders, ", "))
+		if _, err := conn.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("failed to delete rows missing from the CSV: %w", err)
+		}
+	}
+	return nil
+}
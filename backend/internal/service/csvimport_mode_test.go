@@ -0,0 +1,258 @@
+package service
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+)
+
+var csvModeTableRows = testQueryResult{
+	columns: []string{"id", "name"},
+	rows: [][]driver.Value{
+		{int64(1), "Alice"},
+		{int64(2), "Bob"},
+		{int64(3), "Carol"},
+	},
+}
+
+const csvJoinPrefix = "SELECT f.csv_row, t.* FROM `users` AS t JOIN ("
+
+// csvJoinResult answers the matchCSVRows join for a table whose first column is
+// a numeric primary key, comparing keys as numbers the way the database does.
+func csvJoinResult(table testQueryResult, args []driver.NamedValue) testQueryResult {
+	result := testQueryResult{columns: append([]string{"csv_row"}, table.columns...)}
+	for i := 0; i+1 < len(args); i += 2 {
+		fileKey, err := strconv.ParseFloat(fmt.Sprint(args[i+1].Value), 64)
+		if err != nil {
+			continue
+		}
+		for _, row := range table.rows {
+			if dbKey, _ := strconv.ParseFloat(fmt.Sprint(row[0]), 64); dbKey == fileKey {
+				result.rows = append(result.rows, append([]driver.Value{args[i].Value}, row...))
+			}
+		}
+	}
+	return result
+}
+
+func TestCSVPreview_ReplaceReportsDeletesWithinFilter(t *testing.T) {
+	var scopeArgs []driver.NamedValue
+	repo := newCrossCopyTestRepo(t,
+		func(dbName, refName, query string, args []driver.NamedValue) (testQueryResult, error) {
+			switch {
+			case query == "SHOW COLUMNS FROM `users`":
+				return showColumnsResult("varchar(255)"), nil
+			case strings.HasPrefix(query, csvJoinPrefix):
+				return csvJoinResult(csvModeTableRows, args), nil
+			case query == "SELECT * FROM `users` WHERE `name` != ? ORDER BY `id` LIMIT 1000":
+				scopeArgs = args
+				return testQueryResult{columns: csvModeTableRows.columns, rows: csvModeTableRows.rows[:2]}, nil
+			}
+			return testQueryResult{}, fmt.Errorf("unexpected revision query on %s: %s", refName, query)
+		}, nil)
+	svc := newWithDeps(repo, testServiceConfig())
+
+	resp, err := svc.CSVPreview(context.Background(), model.CSVPreviewRequest{
+		TargetID: "local", DBName: "test_db", BranchName: "wi/import", Table: "users",
+		Mode:          model.CSVModeReplace,
+		ReplaceFilter: []model.FilterCondition{{Column: "name", Op: "neq", Value: "Carol"}},
+		Rows: []map[string]interface{}{
+			{"id": "1", "name": "Alice"},
+			{"id": "4", "name": "Dave"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CSVPreview: %v", err)
+	}
+	if len(scopeArgs) != 1 || scopeArgs[0].Value != "Carol" {
+		t.Fatalf("unexpected scope args: %v", scopeArgs)
+	}
+	if resp.Inserts != 1 || resp.Skips != 1 || resp.Deletes != 1 || len(resp.SampleDeletes) != 1 || resp.SampleDeletes[0]["name"] != "Bob" {
+		t.Fatalf("unexpected preview: %+v", resp)
+	}
+}
+
+func TestCSVPreview_InsertOnlyAndUpdateOnlyReportRowErrors(t *testing.T) {
+	repo := newCrossCopyTestRepo(t,
+		func(dbName, refName, query string, args []driver.NamedValue) (testQueryResult, error) {
+			switch {
+			case query == "SHOW COLUMNS FROM `users`":
+				return showColumnsResult("varchar(255)"), nil
+			case strings.HasPrefix(query, csvJoinPrefix):
+				return csvJoinResult(csvModeTableRows, args), nil
+			}
+			return testQueryResult{}, fmt.Errorf("unexpected revision query on %s: %s", refName, query)
+		}, nil)
+	svc := newWithDeps(repo, testServiceConfig())
+	rows := []map[string]interface{}{
+		{"id": "1", "name": "Alicia"},
+		{"id": "9", "name": "Zed"},
+	}
+
+	for mode, wantRow := range map[string]int{model.CSVModeInsertOnly: 0, model.CSVModeUpdateOnly: 1} {
+		resp, err := svc.CSVPreview(context.Background(), model.CSVPreviewRequest{
+			TargetID: "local", DBName: "test_db", BranchName: "wi/import", Table: "users", Mode: mode, Rows: rows,
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", mode, err)
+		}
+		if resp.Errors != 1 || resp.PreviewErrors[0].RowIndex != wantRow || resp.Inserts+resp.Updates != 1 {
+			t.Fatalf("%s: unexpected preview: %+v", mode, resp)
+		}
+	}
+
+	_, err := svc.CSVPreview(context.Background(), model.CSVPreviewRequest{
+		TargetID: "local", DBName: "test_db", BranchName: "wi/import", Table: "users", Rows: rows,
+		ReplaceFilter: []model.FilterCondition{{Column: "name", Op: "eq", Value: "x"}},
+	})
+	if apiErr, ok := err.(*model.APIError); !ok || apiErr.Code != model.CodeInvalidArgument {
+		t.Fatalf("expected INVALID_ARGUMENT for replace_filter without replace, got %v", err)
+	}
+}
+
+func TestCSVApply_ReplaceDeletesMissingRowsBeforeUpsert(t *testing.T) {
+	defer func(n int) { csvMissingPageRows = n }(csvMissingPageRows)
+	csvMissingPageRows = 2
+
+	var executed []string
+	repo := newCrossCopyTestRepo(t, nil,
+		func(dbName, branchName, query string, args []driver.NamedValue) (testQueryResult, error) {
+			switch {
+			case query == "SHOW COLUMNS FROM `users`":
+				return showColumnsResult("varchar(255)"), nil
+			case query == "SELECT COUNT(*) FROM dolt_tags WHERE tag_name = ?":
+				return testQueryResult{columns: []string{"count"}, rows: [][]driver.Value{{int64(0)}}}, nil
+			case query == "SELECT DOLT_HASHOF('HEAD')":
+				return testQueryResult{columns: []string{"hash"}, rows: [][]driver.Value{{"head-1"}}}, nil
+			case query == "SELECT * FROM `users` ORDER BY `id` LIMIT 2":
+				return testQueryResult{columns: csvModeTableRows.columns, rows: csvModeTableRows.rows[:2]}, nil
+			case query == "SELECT * FROM `users` WHERE ((`id` > ?)) ORDER BY `id` LIMIT 2":
+				if args[0].Value != int64(2) {
+					return testQueryResult{}, fmt.Errorf("unexpected keyset cursor: %v", args[0].Value)
+				}
+				return testQueryResult{columns: csvModeTableRows.columns, rows: csvModeTableRows.rows[2:]}, nil
+			case strings.HasPrefix(query, csvJoinPrefix):
+				return csvJoinResult(csvModeTableRows, args), nil
+			case query == "SELECT COUNT(*) FROM dolt_constraint_violations":
+				return testQueryResult{columns: []string{"count"}, rows: [][]driver.Value{{int64(0)}}}, nil
+			case strings.HasPrefix(query, "SELECT COUNT(*) FROM `users`"):
+				return testQueryResult{}, fmt.Errorf("unexpected per-row existence check: %s", query)
+			case strings.HasPrefix(query, "DELETE"), strings.HasPrefix(query, "INSERT"), strings.HasPrefix(query, "UPDATE"):
+				values := make([]string, len(args))
+				for i, arg := range args {
+					values[i] = fmt.Sprintf("%v", arg.Value)
+				}
+				executed = append(executed, query+" "+strings.Join(values, "|"))
+			}
+			return testQueryResult{}, nil
+		})
+	svc := newWithDeps(repo, testServiceConfig())
+
+	resp, err := svc.CSVApply(context.Background(), model.CSVApplyRequest{
+		TargetID: "local", DBName: "test_db", BranchName: "wi/import", Table: "users",
+		ExpectedHead: "head-1", Mode: model.CSVModeReplace,
+		Rows: []map[string]interface{}{
+			{"id": "1", "name": "Alicia"},
+			{"id": "4", "name": "Dave"},
+			{"id": "5", "name": "Eve"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CSVApply: %v", err)
+	}
+	want := []string{
+		"DELETE FROM `users` WHERE `id` IN (?) 2",
+		"DELETE FROM `users` WHERE `id` IN (?) 3",
+		"UPDATE `users` SET `name` = ? WHERE `id` = ? Alicia|1",
+		"INSERT INTO `users` (`id`, `name`) VALUES (?, ?), (?, ?) 4|Dave|5|Eve",
+	}
+	if got := strings.Join(executed, "\n"); got != strings.Join(want, "\n") {
+		t.Fatalf("executed:\n%s\nwant:\n%s", got, strings.Join(want, "\n"))
+	}
+	if resp.Deletes != 2 || resp.Updates != 1 || resp.Inserts != 2 {
+		t.Fatalf("unexpected counts: %+v", resp)
+	}
+}
+
+func TestCSVPreview_ReplaceLetsTheDatabaseCompareKeys(t *testing.T) {
+	// A DECIMAL key reads back as "1.50"; the file spells the same keys "1.5"
+	// and "002" (leading zero). Neither may be reported as missing.
+	table := testQueryResult{
+		columns: []string{"id", "name"},
+		rows: [][]driver.Value{
+			{"1.50", "Alice"},
+			{"2.00", "Bob"},
+			{"3.00", "Carol"},
+		},
+	}
+	repo := newCrossCopyTestRepo(t,
+		func(dbName, refName, query string, args []driver.NamedValue) (testQueryResult, error) {
+			switch {
+			case query == "SHOW COLUMNS FROM `users`":
+				return testQueryResult{
+					columns: []string{"Field", "Type", "Null", "Key", "Default", "Extra"},
+					rows:    [][]driver.Value{{"id", "decimal(5,2)", "NO", "PRI", nil, ""}, {"name", "varchar(255)", "YES", "", nil, ""}},
+				}, nil
+			case strings.HasPrefix(query, csvJoinPrefix):
+				return csvJoinResult(table, args), nil
+			case query == "SELECT * FROM `users` ORDER BY `id` LIMIT 1000":
+				return table, nil
+			}
+			return testQueryResult{}, fmt.Errorf("unexpected revision query on %s: %s", refName, query)
+		}, nil)
+	svc := newWithDeps(repo, testServiceConfig())
+
+	resp, err := svc.CSVPreview(context.Background(), model.CSVPreviewRequest{
+		TargetID: "local", DBName: "test_db", BranchName: "wi/import", Table: "users", Mode: model.CSVModeReplace,
+		Rows: []map[string]interface{}{
+			{"id": "1.5", "name": "Alice"},
+			{"id": "002", "name": "Robert"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CSVPreview: %v", err)
+	}
+	if resp.Inserts != 0 || resp.Updates != 1 || resp.Skips != 1 || resp.Deletes != 1 || resp.SampleDeletes[0]["name"] != "Carol" {
+		t.Fatalf("unexpected preview: %+v", resp)
+	}
+}
+
+func TestCSVApply_WriteErrorNamesTheFileRow(t *testing.T) {
+	repo := newCrossCopyTestRepo(t, nil,
+		func(dbName, branchName, query string, args []driver.NamedValue) (testQueryResult, error) {
+			switch {
+			case query == "SHOW COLUMNS FROM `users`":
+				return showColumnsResult("varchar(255)"), nil
+			case query == "SELECT COUNT(*) FROM dolt_tags WHERE tag_name = ?":
+				return testQueryResult{columns: []string{"count"}, rows: [][]driver.Value{{int64(0)}}}, nil
+			case query == "SELECT DOLT_HASHOF('HEAD')":
+				return testQueryResult{columns: []string{"hash"}, rows: [][]driver.Value{{"head-1"}}}, nil
+			case strings.HasPrefix(query, csvJoinPrefix):
+				return csvJoinResult(csvModeTableRows, args), nil
+			case strings.HasPrefix(query, "INSERT INTO `users` (`id`, `name`) VALUES (?, ?), "):
+				return testQueryResult{}, fmt.Errorf("check constraint failed")
+			case strings.HasPrefix(query, "INSERT INTO `users`") && fmt.Sprint(args[0].Value) == "5":
+				return testQueryResult{}, fmt.Errorf("check constraint failed")
+			}
+			return testQueryResult{}, nil
+		})
+	svc := newWithDeps(repo, testServiceConfig())
+
+	_, err := svc.CSVApply(context.Background(), model.CSVApplyRequest{
+		TargetID: "local", DBName: "test_db", BranchName: "wi/import", Table: "users",
+		ExpectedHead: "head-1", Mode: model.CSVModeInsertOnly,
+		Rows: []map[string]interface{}{
+			{"id": "4", "name": "Dave"},
+			{"id": "5", "name": "Eve"},
+			{"id": "6", "name": "Frank"},
+		},
+	})
+	if err == nil || !strings.HasPrefix(err.Error(), "行 2: ") || !strings.Contains(err.Error(), "check constraint failed") {
+		t.Fatalf("expected the failure on file row 2, got %v", err)
+	}
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/validation"
+)
+
+// csvProfileTagPrefix marks saved CSV mapping profiles. The tag
+// csvprofile/<table>/<hex(name)> carries csvProfileRecord as JSON, so saving a
+// profile never commits to main. The name is hex-encoded because profile names
+// are free text and tag names are refs.
+const csvProfileTagPrefix = "csvprofile/"
+
+const csvProfileMaxName = 255
+
+type csvProfileRecord struct {
+	Schema string `json:"schema"`
+	model.CSVMappingProfile
+}
+
+func csvProfileTag(table, name string) string {
+	return csvProfileTagPrefix + table + "/" + hex.EncodeToString([]byte(name))
+}
+
+func parseCSVProfile(tagName, message string) (model.CSVMappingProfile, error) {
+	var record csvProfileRecord
+	if err := json.Unmarshal([]byte(message), &record); err != nil {
+		return model.CSVMappingProfile{}, fmt.Errorf("invalid CSV profile tag %s: %w", tagName, err)
+	}
+	return record.CSVMappingProfile, nil
+}
+
+func validateCSVProfileKey(table, name string) error {
+	if err := validation.ValidateIdentifier("table", table); err != nil {
+		return &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "無効なテーブル名"}
+	}
+	if strings.TrimSpace(name) == "" || len(name) > csvProfileMaxName || strings.ContainsAny(name, "\r\n") {
+		return &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: fmt.Sprintf("name must be a single line of 1-%d bytes", csvProfileMaxName)}
+	}
+	return nil
+}
+
+// ListCSVMappingProfiles returns the profiles saved for a table, by name.
+func (s *Service) ListCSVMappingProfiles(ctx context.Context, targetID, dbName, table string) ([]model.CSVMappingProfile, error) {
+	if err := validation.ValidateIdentifier("table", table); err != nil {
+		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "無効なテーブル名"}
+	}
+	conn, err := s.connMetadataRevision(ctx, targetID, dbName)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// "_" is a LIKE wildcard and valid in table names, so the exact prefix is
+	// checked again below.
+	prefix := csvProfileTagPrefix + table + "/"
+	rows, err := conn.QueryContext(ctx, "SELECT tag_name, message FROM dolt_tags WHERE tag_name LIKE ?", prefix+"%")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query CSV profiles: %w", err)
+	}
+	defer rows.Close()
+	profiles := make([]model.CSVMappingProfile, 0)
+	for rows.Next() {
+		var tagName, message string
+		if err := rows.Scan(&tagName, &message); err != nil {
+			return nil, fmt.Errorf("failed to scan CSV profile: %w", err)
+		}
+		if !strings.HasPrefix(tagName, prefix) {
+			continue
+		}
+		profile, err := parseCSVProfile(tagName, message)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, profile)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+	return profiles, nil
+}
+
+// getCSVMappingProfile reads one profile.
+func (s *Service) getCSVMappingProfile(ctx context.Context, targetID, dbName, table, name string) (*model.CSVMappingProfile, error) {
+	conn, err := s.connMetadataRevision(ctx, targetID, dbName)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	tagName := csvProfileTag(table, name)
+	var message string
+	err = conn.QueryRowContext(ctx, "SELECT message FROM dolt_tags WHERE tag_name = ?", tagName).Scan(&message)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &model.APIError{Status: 404, Code: model.CodeNotFound, Msg: fmt.Sprintf("CSV profile %s not found for table %s", name, table)}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query CSV profile: %w", err)
+	}
+	profile, err := parseCSVProfile(tagName, message)
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// csvRequestMapping resolves the mapping of a preview/apply request: the inline
+// mapping, else the named profile, else the identity mapping (file headers are
+// table columns), so every cell is checked against its column type either way.
+func (s *Service) csvRequestMapping(ctx context.Context, targetID, dbName, table, profile string, inline *model.CSVMapping) (*model.CSVMapping, error) {
+	if inline != nil {
+		return inline, nil
+	}
+	if profile == "" {
+		return &model.CSVMapping{}, nil
+	}
+	saved, err := s.getCSVMappingProfile(ctx, targetID, dbName, table, profile)
+	if err != nil {
+		return nil, err
+	}
+	return &saved.CSVMapping, nil
+}
+
+// SaveCSVMappingProfile creates or replaces a profile.
+func (s *Service) SaveCSVMappingProfile(ctx context.Context, req model.SaveCSVMappingProfileRequest) (*model.CSVMappingProfile, error) {
+	if err := validateCSVProfileKey(req.Table, req.Name); err != nil {
+		return nil, err
+	}
+	if _, err := s.configuredDatabase(req.TargetID, req.DBName); err != nil {
+		return nil, err
+	}
+	profile := req.CSVMappingProfile
+	profile.UpdatedAt = time.Now().UTC().Format("2006-01-02T15:04:05")
+	message, err := json.Marshal(csvProfileRecord{Schema: "dolt-webui/csv-profile@1", CSVMappingProfile: profile})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal mapping: %w", err)
+	}
+
+	conn, err := s.repo.ConnProtectedMaintenance(ctx, req.TargetID, req.DBName, "main")
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close()
+
+	if err := replaceMessageTag(ctx, conn, csvProfileTag(req.Table, req.Name), string(message)); err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// DeleteCSVMappingProfile removes a profile.
+func (s *Service) DeleteCSVMappingProfile(ctx context.Context, req model.DeleteCSVMappingProfileRequest) error {
+	if err := validateCSVProfileKey(req.Table, req.Name); err != nil {
+		return err
+	}
+	if _, err := s.configuredDatabase(req.TargetID, req.DBName); err != nil {
+		return err
+	}
+
+	conn, err := s.repo.ConnProtectedMaintenance(ctx, req.TargetID, req.DBName, "main")
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close()
+
+	deleted, err := deleteTag(ctx, conn, csvProfileTag(req.Table, req.Name))
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return &model.APIError{Status: 404, Code: model.CodeNotFound, Msg: fmt.Sprintf("CSV profile %s not found for table %s", req.Name, req.Table)}
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+)
+
+// uploadSampleRows is how many parsed rows are echoed back by CSVUpload.
+const uploadSampleRows = 5
+
+// uploadStore keeps parsed uploads, and the errors of previews, in process
+// memory until they expire. Both are lost on restart; the client uploads the
+// file again.
+type uploadStore struct {
+	mu       sync.Mutex
+	uploads  map[string]*storedUpload
+	previews map[string]*storedPreview
+}
+
+type storedUpload struct {
+	headers   []string
+	rows      [][]string
+	expiresAt time.Time
+}
+
+// storedPreview is the full error list of one CSVPreview with the original
+// file row of each error, for paging and the error report.
+type storedPreview struct {
+	headers   []string
+	errors    []model.CSVPreviewError
+	rows      [][]string // original values of errors[i]
+	expiresAt time.Time
+}
+
+func newUploadStore() *uploadStore {
+	return &uploadStore{uploads: make(map[string]*storedUpload), previews: make(map[string]*storedPreview)}
+}
+
+func newUploadID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate upload id: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// pruneLocked drops expired entries. u.mu must be held.
+func (u *uploadStore) pruneLocked(now time.Time) {
+	for key, stored := range u.uploads {
+		if now.After(stored.expiresAt) {
+			delete(u.uploads, key)
+		}
+	}
+	for key, stored := range u.previews {
+		if now.After(stored.expiresAt) {
+			delete(u.previews, key)
+		}
+	}
+}
+
+func (u *uploadStore) put(upload *storedUpload) (string, error) {
+	id, err := newUploadID()
+	if err != nil {
+		return "", err
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.pruneLocked(time.Now())
+	u.uploads[id] = upload
+	return id, nil
+}
+
+func (u *uploadStore) putPreview(preview *storedPreview) (string, error) {
+	id, err := newUploadID()
+	if err != nil {
+		return "", err
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.pruneLocked(time.Now())
+	u.previews[id] = preview
+	return id, nil
+}
+
+func (u *uploadStore) getPreview(id string) (*storedPreview, bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	stored, ok := u.previews[id]
+	if !ok || time.Now().After(stored.expiresAt) {
+		delete(u.previews, id)
+		return nil, false
+	}
+	return stored, true
+}
+
+func (u *uploadStore) get(id string) (*storedUpload, bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	stored, ok := u.uploads[id]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(stored.expiresAt) {
+		delete(u.uploads, id)
+		return nil, false
+	}
+	return stored, true
+}
+
+func (u *uploadStore) remove(id string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	delete(u.uploads, id)
+}
+
+// CSVUpload parses an uploaded CSV, TSV or XLSX file and keeps its rows under
+// an upload ID for CSVPreview and CSVApply. Text files are decoded from the
+// detected encoding (UTF-8 with or without BOM, Shift_JIS, EUC-JP) and split on
+// the detected delimiter unless opts overrides them.
+func (s *Service) CSVUpload(file io.Reader, opts model.CSVUploadOptions) (*model.CSVUploadResponse, error) {
+	invalid := func(err error) error {
+		return &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: fmt.Sprintf("%s: %v", opts.FileName, err)}
+	}
+
+	format := "csv"
+	switch strings.ToLower(filepath.Ext(opts.FileName)) {
+	case ".xlsx":
+		format = "xlsx"
+	case ".tsv":
+		format = "tsv"
+	case ".xls":
+		return nil, invalid(fmt.Errorf("旧形式の .xls には対応していません。.xlsx または CSV で保存してください"))
+	}
+
+	table := parsedTable{}
+	var records [][]string
+	if format == "xlsx" {
+		// XLSX is a zip archive and needs random access, so it is spooled to disk.
+		tmp, err := os.CreateTemp("", "dolt-web-ui-upload-*.xlsx")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create temp file: %w", err)
+		}
+		defer os.Remove(tmp.Name())
+		defer tmp.Close()
+		size, err := io.Copy(tmp, file)
+		if err != nil {
+			return nil, invalid(err)
+		}
+		if records, table.sheet, err = readXLSX(tmp, size, opts.Sheet, s.uploadLimitBytes()); err != nil {
+			return nil, invalid(err)
+		}
+	} else {
+		var err error
+		if records, table.encoding, table.delimiter, err = parseTextUpload(file, format == "tsv", opts.Encoding, opts.Delimiter); err != nil {
+			return nil, invalid(err)
+		}
+	}
+
+	var err error
+	if table.headerRow, table.headers, table.rows, err = splitUploadHeader(records, opts.HeaderRow); err != nil {
+		return nil, invalid(err)
+	}
+	if len(table.rows) == 0 {
+		return nil, invalid(fmt.Errorf("データ行がありません"))
+	}
+
+	expiresAt := time.Now().Add(time.Duration(s.cfg.Server.Upload.TTLMinutes) * time.Minute)
+	id, err := s.uploads.put(&storedUpload{headers: table.headers, rows: table.rows, expiresAt: expiresAt})
+	if err != nil {
+		return nil, err
+	}
+
+	samples := make([]map[string]string, 0, uploadSampleRows)
+	for _, row := range table.rows {
+		if len(samples) == uploadSampleRows {
+			break
+		}
+		sample := make(map[string]string, len(table.headers))
+		for i, h := range table.headers {
+			sample[h] = row[i]
+		}
+		samples = append(samples, sample)
+	}
+	resp := &model.CSVUploadResponse{
+		UploadID:   id,
+		FileName:   opts.FileName,
+		Format:     format,
+		Encoding:   table.encoding,
+		Sheet:      table.sheet,
+		HeaderRow:  table.headerRow,
+		Headers:    table.headers,
+		RowCount:   len(table.rows),
+		SampleRows: samples,
+		ExpiresAt:  expiresAt.UTC().Format(time.RFC3339),
+	}
+	if format != "xlsx" {
+		resp.Delimiter = string(table.delimiter)
+	}
+	return resp, nil
+}
+
+// uploadLimitBytes is server.upload.limit_mb in bytes. It also bounds how far
+// each part of an XLSX upload may decompress.
+func (s *Service) uploadLimitBytes() int64 {
+	limitMB := s.cfg.Server.Upload.LimitMB
+	if limitMB <= 0 {
+		limitMB = 100
+	}
+	return int64(limitMB) << 20
+}
+
+// csvRequestRows returns the inline rows, or the rows of uploadID when set.
+func (s *Service) csvRequestRows(uploadID string, rows []map[string]interface{}) ([]map[string]interface{}, error) {
+	if uploadID == "" {
+		return rows, nil
+	}
+	stored, ok := s.uploads.get(uploadID)
+	if !ok {
+		return nil, &model.APIError{
+			Status: 404,
+			Code:   model.CodeNotFound,
+			Msg:    "アップロードが見つからないか期限切れです。ファイルを再アップロードしてください",
+		}
+	}
+	out := make([]map[string]interface{}, len(stored.rows))
+	for i, row := range stored.rows {
+		m := make(map[string]interface{}, len(stored.headers))
+		for j, h := range stored.headers {
+			m[h] = row[j]
+		}
+		out[i] = m
+	}
+	return out, nil
+}
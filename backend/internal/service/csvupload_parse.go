@@ -0,0 +1,324 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/transform"
+)
+
+// uploadSampleBytes is how much of a text upload is inspected to detect the
+// encoding and delimiter.
+const uploadSampleBytes = 64 * 1024
+
+// uploadHeaderScanRows is how many leading rows may hold titles above the header.
+const uploadHeaderScanRows = 20
+
+// uploadMaxCells caps rows × columns of one upload. A short file can describe a
+// very wide or tall grid (sparse XLSX cells, empty CSV lines under a wide
+// header), and every cell is held in memory until the upload expires.
+const uploadMaxCells = 5_000_000
+
+var errUploadTooManyCells = fmt.Errorf("ファイルのセル数が上限 (%d) を超えています", uploadMaxCells)
+
+// Encoding names reported in CSVUploadResponse.
+const (
+	uploadEncodingUTF8    = "utf-8"
+	uploadEncodingUTF8BOM = "utf-8-bom"
+	uploadEncodingSJIS    = "shift_jis"
+	uploadEncodingEUCJP   = "euc-jp"
+)
+
+var uploadDelimiters = []rune{',', '\t', ';', '|'}
+
+// parsedTable is an upload after parsing: the header row and the data rows below it.
+type parsedTable struct {
+	encoding  string
+	delimiter rune
+	sheet     string
+	headerRow int // 1-based
+	headers   []string
+	rows      [][]string
+}
+
+// uploadEncodingByName resolves an explicit encoding option.
+func uploadEncodingByName(name string) (string, encoding.Encoding, error) {
+	switch strings.ToLower(strings.ReplaceAll(name, "_", "-")) {
+	case "utf-8", "utf8":
+		return uploadEncodingUTF8, nil, nil
+	case "shift-jis", "sjis", "cp932", "windows-31j":
+		return uploadEncodingSJIS, japanese.ShiftJIS, nil
+	case "euc-jp", "eucjp":
+		return uploadEncodingEUCJP, japanese.EUCJP, nil
+	}
+	return "", nil, fmt.Errorf("unsupported encoding: %s", name)
+}
+
+// detectUploadEncoding guesses the encoding of a text sample. A BOM or valid
+// UTF-8 wins; otherwise the Japanese legacy encoding with fewer invalid byte
+// sequences is chosen. Text that is valid EUC-JP is EUC-JP, because Shift_JIS
+// text with hiragana or kanji almost always has lead bytes 0x81-0x9F, which
+// EUC-JP never uses.
+func detectUploadEncoding(sample []byte, atEOF bool) (string, encoding.Encoding) {
+	if bytes.HasPrefix(sample, []byte{0xEF, 0xBB, 0xBF}) {
+		return uploadEncodingUTF8BOM, nil
+	}
+	if !atEOF {
+		// Drop a multi-byte sequence cut off by the sample boundary.
+		for i := 0; i < utf8.UTFMax && len(sample) > 0; i++ {
+			if r, _ := utf8.DecodeLastRune(sample); r != utf8.RuneError {
+				break
+			}
+			sample = sample[:len(sample)-1]
+		}
+	}
+	if utf8.Valid(sample) {
+		return uploadEncodingUTF8, nil
+	}
+	eucErrors := eucJPInvalidSequences(sample, atEOF)
+	if eucErrors == 0 || eucErrors < shiftJISInvalidSequences(sample, atEOF) {
+		return uploadEncodingEUCJP, japanese.EUCJP
+	}
+	return uploadEncodingSJIS, japanese.ShiftJIS
+}
+
+func eucJPInvalidSequences(b []byte, atEOF bool) int {
+	invalid := 0
+	inRange := func(c byte) bool { return c >= 0xA1 && c <= 0xFE }
+	for i := 0; i < len(b); i++ {
+		c := b[i]
+		need := 0
+		switch {
+		case c < 0x80:
+			continue
+		case c == 0x8E, inRange(c):
+			need = 1
+		case c == 0x8F:
+			need = 2
+		default:
+			invalid++
+			continue
+		}
+		if i+need >= len(b) {
+			if atEOF {
+				invalid++
+			}
+			break
+		}
+		for j := 1; j <= need; j++ {
+			if !inRange(b[i+j]) {
+				invalid++
+				break
+			}
+		}
+		i += need
+	}
+	return invalid
+}
+
+func shiftJISInvalidSequences(b []byte, atEOF bool) int {
+	invalid := 0
+	for i := 0; i < len(b); i++ {
+		c := b[i]
+		switch {
+		case c < 0x80, c >= 0xA1 && c <= 0xDF: // ASCII, half-width katakana
+			continue
+		case (c >= 0x81 && c <= 0x9F) || (c >= 0xE0 && c <= 0xFC):
+			if i+1 >= len(b) {
+				if atEOF {
+					invalid++
+				}
+				continue
+			}
+			if t := b[i+1]; t < 0x40 || t == 0x7F || t > 0xFC {
+				invalid++
+			}
+			i++
+		default:
+			invalid++
+		}
+	}
+	return invalid
+}
+
+// detectUploadDelimiter picks the candidate that splits the most sample lines
+// into the same number (>1) of fields.
+func detectUploadDelimiter(sample string) rune {
+	if i := strings.LastIndexAny(sample, "\r\n"); i > 0 {
+		sample = sample[:i]
+	}
+	best, bestScore := ',', 0
+	for _, delim := range uploadDelimiters {
+		reader := csv.NewReader(strings.NewReader(sample))
+		reader.Comma = delim
+		reader.FieldsPerRecord = -1
+		reader.LazyQuotes = true
+		counts := make(map[int]int)
+		for {
+			record, err := reader.Read()
+			if err != nil {
+				break
+			}
+			if len(record) > 1 {
+				counts[len(record)]++
+			}
+		}
+		for _, n := range counts {
+			if n > bestScore {
+				best, bestScore = delim, n
+			}
+		}
+	}
+	return best
+}
+
+// parseTextUpload streams a CSV/TSV upload through the detected (or requested)
+// encoding and delimiter.
+func parseTextUpload(r io.Reader, tsv bool, encodingName, delimiter string) ([][]string, string, rune, error) {
+	br := bufio.NewReaderSize(r, uploadSampleBytes)
+	sample, err := br.Peek(uploadSampleBytes)
+	atEOF := errors.Is(err, io.EOF)
+	if err != nil && !atEOF && !errors.Is(err, bufio.ErrBufferFull) {
+		return nil, "", 0, err
+	}
+
+	var name string
+	var enc encoding.Encoding
+	if encodingName != "" {
+		if name, enc, err = uploadEncodingByName(encodingName); err != nil {
+			return nil, "", 0, err
+		}
+	} else {
+		name, enc = detectUploadEncoding(sample, atEOF)
+	}
+	if bytes.HasPrefix(sample, []byte{0xEF, 0xBB, 0xBF}) && enc == nil {
+		br.Discard(3) //nolint:errcheck // the bytes were just peeked
+		sample = sample[3:]
+	}
+
+	var text io.Reader = br
+	decodedSample := string(sample)
+	if enc != nil {
+		text = transform.NewReader(br, enc.NewDecoder())
+		decodedSample, _, _ = transform.String(enc.NewDecoder(), string(sample))
+	}
+
+	comma := '\t'
+	switch {
+	case delimiter == `\t` || delimiter == "\t" || delimiter == "tab":
+	case delimiter != "":
+		if utf8.RuneCountInString(delimiter) != 1 {
+			return nil, "", 0, fmt.Errorf("delimiter must be one character")
+		}
+		comma, _ = utf8.DecodeRuneInString(delimiter)
+	case !tsv:
+		comma = detectUploadDelimiter(decodedSample)
+	}
+
+	reader := csv.NewReader(text)
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	records := make([][]string, 0)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, "", 0, err
+		}
+		records = append(records, record)
+	}
+	return records, name, comma, nil
+}
+
+// splitUploadHeader finds the header row and returns the rows below it. With
+// headerRow == 0 the header is the first of the leading rows whose every cell
+// is filled and which is as wide as the widest row; title lines above it are
+// skipped. Blank data rows are dropped.
+func splitUploadHeader(records [][]string, headerRow int) (int, []string, [][]string, error) {
+	width := func(record []string) int {
+		n := len(record)
+		for n > 0 && strings.TrimSpace(record[n-1]) == "" {
+			n--
+		}
+		return n
+	}
+
+	idx := headerRow - 1
+	if headerRow <= 0 {
+		maxWidth := 0
+		for i := 0; i < len(records) && i < uploadHeaderScanRows; i++ {
+			if w := width(records[i]); w > maxWidth {
+				maxWidth = w
+			}
+		}
+		idx = -1
+		for i := 0; i < len(records) && i < uploadHeaderScanRows && idx < 0; i++ {
+			if width(records[i]) != maxWidth || maxWidth == 0 {
+				continue
+			}
+			filled := true
+			for _, cell := range records[i][:maxWidth] {
+				if strings.TrimSpace(cell) == "" {
+					filled = false
+					break
+				}
+			}
+			if filled {
+				idx = i
+			}
+		}
+		if idx < 0 {
+			return 0, nil, nil, fmt.Errorf("ヘッダー行が見つかりません")
+		}
+	}
+	if idx < 0 || idx >= len(records) {
+		return 0, nil, nil, fmt.Errorf("header_row %d is outside the file", headerRow)
+	}
+
+	raw := records[idx][:width(records[idx])]
+	headers := make([]string, len(raw))
+	seen := make(map[string]bool, len(raw))
+	for i, h := range raw {
+		h = strings.TrimSpace(h)
+		if i == 0 {
+			h = strings.TrimPrefix(h, "\ufeff")
+		}
+		if h == "" {
+			return 0, nil, nil, fmt.Errorf("ヘッダーの %d 列目が空です", i+1)
+		}
+		if seen[h] {
+			return 0, nil, nil, fmt.Errorf("ヘッダー '%s' が重複しています", h)
+		}
+		seen[h] = true
+		headers[i] = h
+	}
+
+	if (len(records)-idx-1)*len(headers) > uploadMaxCells {
+		return 0, nil, nil, errUploadTooManyCells
+	}
+	rows := make([][]string, 0, len(records)-idx-1)
+	for i, record := range records[idx+1:] {
+		w := width(record)
+		if w == 0 {
+			continue
+		}
+		if w > len(headers) {
+			return 0, nil, nil, fmt.Errorf("%d 行目の列数 (%d) がヘッダー (%d) を超えています", idx+i+2, w, len(headers))
+		}
+		row := make([]string, len(headers))
+		copy(row, record)
+		rows = append(rows, row)
+	}
+	return idx + 1, headers, rows, nil
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+)
+
+func uploadTestService(t *testing.T) *Service {
+	t.Helper()
+	cfg := testServiceConfig()
+	cfg.Server.Upload.TTLMinutes = 30
+	return newWithDeps(newRecordingSessionRepo(t, nil), cfg)
+}
+
+func encodeUpload(t *testing.T, enc encoding.Encoding, text string) []byte {
+	t.Helper()
+	out, err := enc.NewEncoder().String(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []byte(out)
+}
+
+func TestCSVUpload_DetectsEncodingDelimiterAndHeader(t *testing.T) {
+	cases := []struct {
+		name, file    string
+		data          []byte
+		wantEncoding  string
+		wantDelimiter string
+		wantHeaderRow int
+	}{
+		{
+			name:          "shift_jis with title line",
+			file:          "商品.csv",
+			data:          encodeUpload(t, japanese.ShiftJIS, "商品マスタ 2026年10月\r\nid,name,price\r\n1,りんご,120\r\n2,ﾊﾞﾅﾅ,98\r\n"),
+			wantEncoding:  uploadEncodingSJIS,
+			wantDelimiter: ",",
+			wantHeaderRow: 2,
+		},
+		{
+			name:          "euc-jp with semicolons",
+			file:          "items.txt",
+			data:          encodeUpload(t, japanese.EUCJP, "id;name;price\n1;りんご;120\n2;バナナ;98\n"),
+			wantEncoding:  uploadEncodingEUCJP,
+			wantDelimiter: ";",
+			wantHeaderRow: 1,
+		},
+		{
+			name:          "utf-8 bom tsv",
+			file:          "items.tsv",
+			data:          []byte("\ufeffid\tname\tprice\n1\tりんご\t120\n2\tバナナ\t98\n"),
+			wantEncoding:  uploadEncodingUTF8BOM,
+			wantDelimiter: "\t",
+			wantHeaderRow: 1,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := uploadTestService(t)
+			resp, err := svc.CSVUpload(bytes.NewReader(tc.data), model.CSVUploadOptions{FileName: tc.file})
+			if err != nil {
+				t.Fatalf("CSVUpload: %v", err)
+			}
+			if resp.Encoding != tc.wantEncoding || resp.Delimiter != tc.wantDelimiter || resp.HeaderRow != tc.wantHeaderRow {
+				t.Fatalf("detected encoding=%s delimiter=%q header_row=%d", resp.Encoding, resp.Delimiter, resp.HeaderRow)
+			}
+			if strings.Join(resp.Headers, ",") != "id,name,price" || resp.RowCount != 2 || resp.SampleRows[0]["name"] != "りんご" {
+				t.Fatalf("unexpected parse: %+v", resp)
+			}
+
+			rows, err := svc.csvRequestRows(resp.UploadID, nil)
+			if err != nil {
+				t.Fatalf("csvRequestRows: %v", err)
+			}
+			if len(rows) != 2 || rows[1]["price"] != "98" {
+				t.Fatalf("unexpected stored rows: %+v", rows)
+			}
+		})
+	}
+}
+
+// xlsxTestFile zips parts into an XLSX file.
+func xlsxTestFile(t *testing.T, parts map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range parts {
+		f, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(content)) //nolint:errcheck
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestCSVUpload_ReadsXLSX(t *testing.T) {
+	parts := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Notes" sheetId="1" r:id="rId1"/><sheet name="Prices" sheetId="2" r:id="rId2"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Target="worksheets/sheet1.xml"/><Relationship Id="rId2" Target="/xl/worksheets/sheet2.xml"/></Relationships>`,
+		"xl/sharedStrings.xml":     `<sst><si><t>id</t></si><si><t>name</t></si><si><t>from</t></si><si><r><t>りん</t></r><r><t>ご</t></r></si></sst>`,
+		"xl/styles.xml":            `<styleSheet><numFmts><numFmt numFmtId="164" formatCode="yyyy/mm/dd"/></numFmts><cellXfs><xf numFmtId="0"/><xf numFmtId="164"/></cellXfs></styleSheet>`,
+		"xl/worksheets/sheet1.xml": `<worksheet><sheetData><row r="1"><c r="A1" t="inlineStr"><is><t>memo</t></is></c></row></sheetData></worksheet>`,
+		"xl/worksheets/sheet2.xml": `<worksheet><sheetData>` +
+			`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="s"><v>2</v></c></row>` +
+			`<row r="2"><c r="A2"><v>1</v></c><c r="B2" t="s"><v>3</v></c><c r="C2" s="1"><v>46311</v></c></row>` +
+			`<row r="4"><c r="A4"><v>2</v></c><c r="C4" s="1"><v>46311.5</v></c></row>` +
+			`</sheetData></worksheet>`,
+	}
+
+	svc := uploadTestService(t)
+	resp, err := svc.CSVUpload(bytes.NewReader(xlsxTestFile(t, parts)), model.CSVUploadOptions{FileName: "prices.xlsx", Sheet: "Prices"})
+	if err != nil {
+		t.Fatalf("CSVUpload: %v", err)
+	}
+	if resp.Format != "xlsx" || resp.Sheet != "Prices" || resp.RowCount != 2 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	first, second := resp.SampleRows[0], resp.SampleRows[1]
+	if first["id"] != "1" || first["name"] != "りんご" || first["from"] != "2026-10-16" {
+		t.Fatalf("unexpected first row: %v", first)
+	}
+	if second["name"] != "" || second["from"] != "2026-10-16 12:00:00" {
+		t.Fatalf("unexpected second row: %v", second)
+	}
+}
+
+func TestCSVUpload_RejectsHostileXLSX(t *testing.T) {
+	xlsxWithSheet := func(sheet string) []byte {
+		return xlsxTestFile(t, map[string]string{
+			"xl/workbook.xml": `<workbook xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+				`<sheets><sheet name="S" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+			"xl/_rels/workbook.xml.rels": `<Relationships><Relationship Id="rId1" Target="worksheets/sheet1.xml"/></Relationships>`,
+			"xl/worksheets/sheet1.xml":   `<worksheet><sheetData>` + sheet + `</sheetData></worksheet>`,
+		})
+	}
+	wideRows := strings.Repeat(`<row><c r="XFD1"><v>1</v></c></row>`, uploadMaxCells/xlsxMaxColumns+1)
+	cases := map[string][]byte{
+		"column past XFD":    xlsxWithSheet(`<row><c r="ZZZZZZ1"><v>1</v></c></row>`),
+		"overflowing column": xlsxWithSheet(`<row><c r="` + strings.Repeat("Z", 40) + `1"><v>1</v></c></row>`),
+		"too many cells":     xlsxWithSheet(wideRows),
+		"decompression bomb": xlsxWithSheet(strings.Repeat(" ", 2<<20)),
+	}
+	for name, data := range cases {
+		t.Run(name, func(t *testing.T) {
+			svc := uploadTestService(t)
+			svc.cfg.Server.Upload.LimitMB = 1
+			_, err := svc.CSVUpload(bytes.NewReader(data), model.CSVUploadOptions{FileName: "bad.xlsx"})
+			if apiErr, ok := err.(*model.APIError); !ok || apiErr.Code != model.CodeInvalidArgument {
+				t.Fatalf("expected INVALID_ARGUMENT, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCSVUpload_RejectsUnknownUploadID(t *testing.T) {
+	svc := uploadTestService(t)
+	_, err := svc.CSVPreview(context.Background(), model.CSVPreviewRequest{
+		TargetID: "local", DBName: "test_db", BranchName: "wi/import", Table: "items", UploadID: "missing",
+	})
+	if apiErr, ok := err.(*model.APIError); !ok || apiErr.Code != model.CodeNotFound {
+		t.Fatalf("expected NOT_FOUND, got %v", err)
+	}
+
+	_, err = svc.CSVUpload(strings.NewReader("title only\n"), model.CSVUploadOptions{FileName: "empty.csv"})
+	if apiErr, ok := err.(*model.APIError); !ok || apiErr.Code != model.CodeInvalidArgument {
+		t.Fatalf("expected INVALID_ARGUMENT for a file without data rows, got %v", err)
+	}
+}
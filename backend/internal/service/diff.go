@@ -1,12 +1,8 @@
 package service
 
 import (
-	"archive/zip"
-	"bytes"
 	"context"
 	"database/sql"
-	"encoding/csv"
-	"encoding/json"
 	"fmt"
 	"log"
 	"sort"
@@ -96,15 +92,15 @@ func buildDiffFilterSQL(f model.FilterCondition, allowedCols map[string]bool) (s
 	colExpr := fmt.Sprintf("COALESCE(`to_%s`, `from_%s`)", f.Column, f.Column)
 	switch f.Op {
 	case "eq":
-		return fmt.Sprintf("%s = ?", colExpr), []interface{}{f.Value}, nil
+		return fmt.Sprintf("%s = ?", colExpr), []interface{}{sqlArgFromJSON(f.Value)}, nil
 	case "neq":
-		return fmt.Sprintf("%s != ?", colExpr), []interface{}{f.Value}, nil
+		return fmt.Sprintf("%s != ?", colExpr), []interface{}{sqlArgFromJSON(f.Value)}, nil
 	case "contains":
-		return fmt.Sprintf("%s LIKE CONCAT('%%', ?, '%%')", colExpr), []interface{}{f.Value}, nil
+		return fmt.Sprintf("%s LIKE CONCAT('%%', ?, '%%')", colExpr), []interface{}{sqlArgFromJSON(f.Value)}, nil
 	case "startsWith":
-		return fmt.Sprintf("%s LIKE CONCAT(?, '%%')", colExpr), []interface{}{f.Value}, nil
+		return fmt.Sprintf("%s LIKE CONCAT(?, '%%')", colExpr), []interface{}{sqlArgFromJSON(f.Value)}, nil
 	case "endsWith":
-		return fmt.Sprintf("%s LIKE CONCAT('%%', ?)", colExpr), []interface{}{f.Value}, nil
+		return fmt.Sprintf("%s LIKE CONCAT('%%', ?)", colExpr), []interface{}{sqlArgFromJSON(f.Value)}, nil
 	case "blank":
 		return fmt.Sprintf("%s IS NULL", colExpr), nil, nil
 	case "notBlank":
@@ -118,7 +114,7 @@ func buildDiffFilterSQL(f model.FilterCondition, allowedCols map[string]bool) (s
 		args := make([]interface{}, len(vals))
 		for i, v := range vals {
 			placeholders[i] = "?"
-			args[i] = v
+			args[i] = sqlArgFromJSON(v)
 		}
 		return fmt.Sprintf("%s IN (%s)", colExpr, strings.Join(placeholders, ",")), args, nil
 	default:
@@ -181,7 +177,7 @@ func (s *Service) DiffTable(ctx context.Context, targetID, dbName, branchName, t
 	// Column filter (JSON-encoded conditions from frontend AG Grid)
 	if filterJSON != "" {
 		var filters []model.FilterCondition
-		if err := json.Unmarshal([]byte(filterJSON), &filters); err != nil {
+		if err := unmarshalJSONNumbers([]byte(filterJSON), &filters); err != nil {
 			return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "invalid filter JSON"}
 		}
 		// Probe DOLT_DIFF columns to build allowed user-facing column set
@@ -458,139 +454,3 @@ func (s *Service) DiffSummaryLight(ctx context.Context, targetID, dbName, branch
 	})
 	return entries, nil
 }
-
-// ExportDiffZip generates a ZIP archive containing per-table, per-diff-type CSV files.
-// Files are named {table}_insert.csv / {table}_update.csv / {table}_delete.csv.
-// Update rows contain new values only (no old_ columns).
-func (s *Service) ExportDiffZip(ctx context.Context, targetID, dbName, branchName, fromRef, toRef, mode string) ([]byte, string, string, error) {
-	if err := validateRef("from", fromRef); err != nil {
-		return nil, "", "", &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: err.Error()}
-	}
-	if err := validateRef("to", toRef); err != nil {
-		return nil, "", "", &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: err.Error()}
-	}
-	if err := s.ensureHistoryRef(ctx, targetID, dbName, fromRef); err != nil {
-		return nil, "", "", err
-	}
-	if err := s.ensureHistoryRef(ctx, targetID, dbName, toRef); err != nil {
-		return nil, "", "", err
-	}
-
-	// Get diff summary to know which tables have changes
-	entries, err := s.DiffSummary(ctx, targetID, dbName, branchName, fromRef, toRef, mode)
-	if err != nil {
-		return nil, "", "", fmt.Errorf("failed to get diff summary: %w", err)
-	}
-
-	var buf bytes.Buffer
-	zw := zip.NewWriter(&buf)
-	truncatedTables := make([]string, 0)
-	truncatedTableSet := make(map[string]bool)
-
-	for _, entry := range entries {
-		diffTypes := []struct {
-			diffType string
-			suffix   string
-		}{
-			{"added", "insert"},
-			{"modified", "update"},
-			{"removed", "delete"},
-		}
-
-		for _, dt := range diffTypes {
-			count := 0
-			switch dt.diffType {
-			case "added":
-				count = entry.Added
-			case "modified":
-				count = entry.Modified
-			case "removed":
-				count = entry.Removed
-			}
-			if count == 0 {
-				continue
-			}
-
-			// BUG-K: cap rows to prevent OOM on large tables.
-			rowLimit := count + 100
-			if rowLimit > 10000 {
-				rowLimit = 10000
-				if !truncatedTableSet[entry.Table] {
-					truncatedTableSet[entry.Table] = true
-					truncatedTables = append(truncatedTables, entry.Table)
-				}
-			}
-			// Fetch all rows for this table/diffType (no pagination)
-			resp, err := s.DiffTable(ctx, targetID, dbName, branchName, entry.Table, fromRef, toRef, mode, false, dt.diffType, "", 1, rowLimit)
-			if err != nil || len(resp.Rows) == 0 {
-				continue
-			}
-
-			// Collect column names from first row's To map (or From for removed)
-			var sample map[string]interface{}
-			if dt.diffType == "removed" {
-				sample = resp.Rows[0].From
-			} else {
-				sample = resp.Rows[0].To
-			}
-			cols := make([]string, 0, len(sample))
-			for k := range sample {
-				cols = append(cols, k)
-			}
-			// Sort for deterministic order
-			for i := 0; i < len(cols)-1; i++ {
-				for j := i + 1; j < len(cols); j++ {
-					if cols[i] > cols[j] {
-						cols[i], cols[j] = cols[j], cols[i]
-					}
-				}
-			}
-
-			fname := fmt.Sprintf("%s_%s.csv", entry.Table, dt.suffix)
-			fw, err := zw.Create(fname)
-			if err != nil {
-				continue
-			}
-			cw := csv.NewWriter(fw)
-
-			// Write header
-			if err := cw.Write(cols); err != nil {
-				continue
-			}
-
-			// Write data rows
-			for _, row := range resp.Rows {
-				var src map[string]interface{}
-				if dt.diffType == "removed" {
-					src = row.From
-				} else {
-					src = row.To
-				}
-				record := make([]string, len(cols))
-				for i, col := range cols {
-					v := src[col]
-					if v == nil {
-						record[i] = ""
-					} else {
-						record[i] = fmt.Sprintf("%v", v)
-					}
-				}
-				if err := cw.Write(record); err != nil {
-					break
-				}
-			}
-			cw.Flush()
-		}
-	}
-
-	if err := zw.Close(); err != nil {
-		return nil, "", "", fmt.Errorf("failed to close zip: %w", err)
-	}
-
-	zipName := fmt.Sprintf("diff-%s-%s.zip", fromRef, toRef)
-	warning := ""
-	if len(truncatedTables) > 0 {
-		warning = fmt.Sprintf("一部のCSVは1テーブルあたり10000行で打ち切られています: %s", strings.Join(truncatedTables, ", "))
-	}
-	return buf.Bytes(), zipName, warning, nil
-}
@@ -0,0 +1,626 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/validation"
+)
+
+// diffExportPageSize is the keyset page size used when reading DOLT_DIFF.
+var diffExportPageSize = 1000
+
+var diffExportTypes = []struct {
+	diffType string
+	suffix   string
+}{
+	{"removed", "delete"},
+	{"modified", "update"},
+	{"added", "insert"},
+}
+
+// diffExportTable describes one changed table: its user columns in diff
+// order, which side of the diff carries each, and the key used for paging.
+type diffExportTable struct {
+	entry    model.DiffSummaryEntry
+	cols     []string
+	fromCols map[string]bool
+	toCols   map[string]bool
+	pk       []string
+}
+
+type diffExportRow struct {
+	diffType string
+	from     map[string]interface{}
+	to       map[string]interface{}
+}
+
+// diffExportSink records the first write error so that a broken response
+// aborts the export, while query errors are only reported in the manifest.
+type diffExportSink struct {
+	w   io.Writer
+	err error
+}
+
+func (s *diffExportSink) Write(p []byte) (int, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	n, err := s.w.Write(p)
+	if err != nil {
+		s.err = err
+	}
+	return n, err
+}
+
+func diffExportCount(entry model.DiffSummaryEntry, diffType string) int {
+	switch diffType {
+	case "added":
+		return entry.Added
+	case "modified":
+		return entry.Modified
+	default:
+		return entry.Removed
+	}
+}
+
+// diffExportValue turns a scanned value into its text form.
+func diffExportValue(v interface{}) interface{} {
+	if b, ok := v.([]byte); ok {
+		return string(b)
+	}
+	return v
+}
+
+func diffExportText(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// loadDiffExportTable reads the diff columns of a table and its primary key,
+// taken from the to side or, for a dropped table, the from side.
+func loadDiffExportTable(ctx context.Context, conn, toConn, fromConn *sql.Conn, fromRef, toRef string, entry model.DiffSummaryEntry) (*diffExportTable, error) {
+	rows, err := conn.QueryContext(ctx, fmt.Sprintf("SELECT * FROM DOLT_DIFF('%s', '%s', '%s') LIMIT 0", fromRef, toRef, entry.Table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to probe diff columns: %w", err)
+	}
+	colNames, err := rows.Columns()
+	rows.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get diff columns: %w", err)
+	}
+
+	t := &diffExportTable{entry: entry, fromCols: map[string]bool{}, toCols: map[string]bool{}}
+	seen := map[string]bool{}
+	for _, col := range colNames {
+		var name string
+		switch {
+		case strings.HasPrefix(col, "to_"):
+			name = strings.TrimPrefix(col, "to_")
+			t.toCols[name] = true
+		case strings.HasPrefix(col, "from_"):
+			name = strings.TrimPrefix(col, "from_")
+			t.fromCols[name] = true
+		default:
+			continue
+		}
+		if name == "commit" || name == "commit_date" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		t.cols = append(t.cols, name)
+	}
+
+	schema, err := getSchemaColumns(ctx, toConn, entry.Table)
+	if apiErr, ok := err.(*model.APIError); ok && apiErr.Code == model.CodeNotFound {
+		schema, err = getSchemaColumns(ctx, fromConn, entry.Table)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, pk := range getPKColumns(schema) {
+		if !seen[pk] {
+			// The key is not visible on both sides; read without paging.
+			t.pk = nil
+			break
+		}
+		t.pk = append(t.pk, pk)
+	}
+	return t, nil
+}
+
+// scanDiffExportRows reads rows of one diff type in primary key order, a
+// page at a time, resuming after the last key seen. Keyless tables are read
+// in a single query. It returns the number of rows passed to fn.
+func scanDiffExportRows(ctx context.Context, conn *sql.Conn, fromRef, toRef string, t *diffExportTable, diffType string, fn func(diffExportRow) error) (int, error) {
+	prefix := "to_"
+	if diffType == "removed" {
+		prefix = "from_"
+	}
+	keyCols := make([]string, len(t.pk))
+	for i, pk := range t.pk {
+		keyCols[i] = fmt.Sprintf("`%s%s`", prefix, pk)
+	}
+	base := fmt.Sprintf("SELECT * FROM DOLT_DIFF('%s', '%s', '%s') WHERE diff_type = ?", fromRef, toRef, t.entry.Table)
+
+	total := 0
+	var lastKey []interface{}
+	for {
+		query := base
+		args := []interface{}{diffType}
+		if len(keyCols) > 0 {
+			if lastKey != nil {
+				// (k1 > ?) OR (k1 = ? AND k2 > ?) OR ...
+				var ors []string
+				for i := range keyCols {
+					var ands []string
+					for j := 0; j < i; j++ {
+						ands = append(ands, keyCols[j]+" = ?")
+						args = append(args, lastKey[j])
+					}
+					ands = append(ands, keyCols[i]+" > ?")
+					args = append(args, lastKey[i])
+					ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+				}
+				query += " AND (" + strings.Join(ors, " OR ") + ")"
+			}
+			query += fmt.Sprintf(" ORDER BY %s LIMIT %d", strings.Join(keyCols, ", "), diffExportPageSize)
+		}
+
+		rows, err := conn.QueryContext(ctx, query, args...)
+		if err != nil {
+			return total, fmt.Errorf("failed to query diff: %w", err)
+		}
+		colNames, err := rows.Columns()
+		if err != nil {
+			rows.Close()
+			return total, fmt.Errorf("failed to get columns: %w", err)
+		}
+		keyIndex := make([]int, len(t.pk))
+		for i, pk := range t.pk {
+			keyIndex[i] = -1
+			for j, col := range colNames {
+				if col == prefix+pk {
+					keyIndex[i] = j
+				}
+			}
+		}
+
+		fetched := 0
+		for rows.Next() {
+			values := make([]interface{}, len(colNames))
+			ptrs := make([]interface{}, len(colNames))
+			for i := range values {
+				ptrs[i] = &values[i]
+			}
+			if err := rows.Scan(ptrs...); err != nil {
+				rows.Close()
+				return total, fmt.Errorf("failed to scan diff row: %w", err)
+			}
+			row := diffExportRow{diffType: diffType}
+			if diffType != "added" {
+				row.from = map[string]interface{}{}
+			}
+			if diffType != "removed" {
+				row.to = map[string]interface{}{}
+			}
+			for i, col := range colNames {
+				switch {
+				case strings.HasSuffix(col, "_commit") || strings.HasSuffix(col, "_commit_date"):
+				case strings.HasPrefix(col, "to_") && row.to != nil:
+					row.to[strings.TrimPrefix(col, "to_")] = diffExportValue(values[i])
+				case strings.HasPrefix(col, "from_") && row.from != nil:
+					row.from[strings.TrimPrefix(col, "from_")] = diffExportValue(values[i])
+				}
+			}
+			lastKey = make([]interface{}, len(keyIndex))
+			for i, idx := range keyIndex {
+				if idx >= 0 {
+					lastKey[i] = values[idx]
+				}
+			}
+			fetched++
+			if err := fn(row); err != nil {
+				rows.Close()
+				return total, err
+			}
+			total++
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return total, fmt.Errorf("failed to read diff rows: %w", err)
+		}
+		if len(keyCols) == 0 || fetched < diffExportPageSize {
+			return total, nil
+		}
+	}
+}
+
+// sqlLiteral renders v as a MySQL literal. Non-UTF-8 bytes are written in
+// hex so the patch file stays valid text.
+func sqlLiteral(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		if val {
+			return "1"
+		}
+		return "0"
+	case int64, int32, int, uint64, float64, float32:
+		return fmt.Sprintf("%v", val)
+	case time.Time:
+		return "'" + val.Format("2006-01-02 15:04:05.999999") + "'"
+	case []byte:
+		if !utf8.Valid(val) {
+			return "0x" + hex.EncodeToString(val)
+		}
+		return sqlLiteral(string(val))
+	case string:
+		if !utf8.ValidString(val) {
+			return "0x" + hex.EncodeToString([]byte(val))
+		}
+		return "'" + strings.NewReplacer(
+			`\`, `\\`, `'`, `\'`, "\x00", `\0`, "\n", `\n`, "\r", `\r`, "\x1a", `\Z`,
+		).Replace(val) + "'"
+	default:
+		return sqlLiteral(fmt.Sprintf("%v", val))
+	}
+}
+
+// sqlRowMatch builds the WHERE clause locating a from-side row: by primary
+// key, or for keyless tables by every column with LIMIT 1.
+func sqlRowMatch(t *diffExportTable, from map[string]interface{}) string {
+	if len(t.pk) > 0 {
+		parts := make([]string, len(t.pk))
+		for i, pk := range t.pk {
+			parts[i] = fmt.Sprintf("`%s` = %s", pk, sqlLiteral(from[pk]))
+		}
+		return " WHERE " + strings.Join(parts, " AND ")
+	}
+	var parts []string
+	for _, col := range t.cols {
+		if t.fromCols[col] {
+			parts = append(parts, fmt.Sprintf("`%s` <=> %s", col, sqlLiteral(from[col])))
+		}
+	}
+	return " WHERE " + strings.Join(parts, " AND ") + " LIMIT 1"
+}
+
+// sqlPatchStatement returns the statement applying one diff row, or "" for a
+// modified row whose exported columns did not change.
+func sqlPatchStatement(t *diffExportTable, row diffExportRow) string {
+	table := fmt.Sprintf("`%s`", t.entry.Table)
+	switch row.diffType {
+	case "removed":
+		return "DELETE FROM " + table + sqlRowMatch(t, row.from) + ";\n"
+	case "added":
+		var cols, values []string
+		for _, col := range t.cols {
+			if t.toCols[col] {
+				cols = append(cols, fmt.Sprintf("`%s`", col))
+				values = append(values, sqlLiteral(row.to[col]))
+			}
+		}
+		return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s);\n", table, strings.Join(cols, ", "), strings.Join(values, ", "))
+	default:
+		var sets []string
+		for _, col := range t.cols {
+			if !t.toCols[col] {
+				continue
+			}
+			if t.fromCols[col] && fmt.Sprintf("%#v", row.from[col]) == fmt.Sprintf("%#v", row.to[col]) {
+				continue
+			}
+			sets = append(sets, fmt.Sprintf("`%s` = %s", col, sqlLiteral(row.to[col])))
+		}
+		if len(sets) == 0 {
+			return ""
+		}
+		return "UPDATE " + table + " SET " + strings.Join(sets, ", ") + sqlRowMatch(t, row.from) + ";\n"
+	}
+}
+
+// ExportDiffZip streams a ZIP archive of the diff between fromRef and toRef.
+// Every table is read from DOLT_DIFF with keyset paging, so there is no row
+// cap. Formats:
+//   - csv: {table}_insert.csv / _update.csv / _delete.csv, update rows with new values
+//   - csv_pairs: as csv, but update rows carry old_<col> and new_<col> pairs
+//   - xlsx: diff.xlsx with one sheet per table
+//   - jsonl: {table}.jsonl with one {"diff_type","from","to"} object per line
+//   - sql: patch.sql turning fromRef into toRef
+//
+// A query failure does not stop the export: it is recorded against the file
+// in manifest.json, written last. open is called once all up-front checks
+// pass, so an error returned before then can still become an error response.
+func (s *Service) ExportDiffZip(ctx context.Context, targetID, dbName, branchName, fromRef, toRef, mode, format string, open func(zipName string) io.Writer) error {
+	if format == "" {
+		format = model.DiffExportCSV
+	}
+	switch format {
+	case model.DiffExportCSV, model.DiffExportCSVPairs, model.DiffExportXLSX, model.DiffExportJSONL, model.DiffExportSQL:
+	default:
+		return &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: fmt.Sprintf("unsupported export format: %s", format)}
+	}
+
+	// DiffSummary validates both refs and lists the changed tables.
+	entries, err := s.DiffSummary(ctx, targetID, dbName, branchName, fromRef, toRef, mode)
+	if err != nil {
+		return err
+	}
+
+	conn, err := s.connHistoryRevision(ctx, targetID, dbName, branchName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	resolvedFrom, resolvedTo, err := resolveDiffRefs(ctx, conn, fromRef, toRef, mode)
+	if err != nil {
+		return err
+	}
+	toConn, err := s.connHistoryRevision(ctx, targetID, dbName, resolvedTo)
+	if err != nil {
+		return err
+	}
+	defer toConn.Close()
+	fromConn, err := s.connHistoryRevision(ctx, targetID, dbName, resolvedFrom)
+	if err != nil {
+		return err
+	}
+	defer fromConn.Close()
+
+	sink := &diffExportSink{w: open(strings.ReplaceAll(fmt.Sprintf("diff-%s-%s.zip", fromRef, toRef), "/", "_"))}
+	zw := zip.NewWriter(sink)
+	manifest := model.DiffExportManifest{
+		FromRef:  fromRef,
+		ToRef:    toRef,
+		Mode:     mode,
+		Format:   format,
+		Files:    make([]model.DiffExportFile, 0),
+		Complete: true,
+	}
+	record := func(file model.DiffExportFile, err error) {
+		if err != nil {
+			file.Error = err.Error()
+			manifest.Complete = false
+		}
+		manifest.Files = append(manifest.Files, file)
+	}
+
+	tables := make([]*diffExportTable, 0, len(entries))
+	for _, entry := range entries {
+		var t *diffExportTable
+		err := validation.ValidateIdentifier("table", entry.Table)
+		if err == nil {
+			t, err = loadDiffExportTable(ctx, conn, toConn, fromConn, resolvedFrom, resolvedTo, entry)
+		}
+		if err != nil {
+			record(model.DiffExportFile{Table: entry.Table}, err)
+			continue
+		}
+		tables = append(tables, t)
+	}
+
+	switch format {
+	case model.DiffExportCSV, model.DiffExportCSVPairs:
+		err = writeDiffExportCSV(ctx, zw, conn, resolvedFrom, resolvedTo, tables, format == model.DiffExportCSVPairs, record)
+	case model.DiffExportJSONL:
+		err = writeDiffExportJSONL(ctx, zw, conn, resolvedFrom, resolvedTo, tables, record)
+	case model.DiffExportSQL:
+		err = writeDiffExportSQL(ctx, zw, conn, fromRef, toRef, resolvedFrom, resolvedTo, tables, record)
+	case model.DiffExportXLSX:
+		err = writeDiffExportXLSX(ctx, zw, conn, resolvedFrom, resolvedTo, tables, record)
+	}
+	if sink.err != nil {
+		return sink.err
+	}
+	if err != nil {
+		return err
+	}
+
+	mw, err := zw.Create("manifest.json")
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(mw)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(manifest); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// diffExportRecorder adds one file to the manifest, with err if it failed.
+type diffExportRecorder func(file model.DiffExportFile, err error)
+
+func writeDiffExportCSV(ctx context.Context, zw *zip.Writer, conn *sql.Conn, fromRef, toRef string, tables []*diffExportTable, pairs bool, record diffExportRecorder) error {
+	for _, t := range tables {
+		for _, dt := range diffExportTypes {
+			if diffExportCount(t.entry, dt.diffType) == 0 {
+				continue
+			}
+			name := fmt.Sprintf("%s_%s.csv", t.entry.Table, dt.suffix)
+			fw, err := zw.Create(name)
+			if err != nil {
+				return err
+			}
+			cw := csv.NewWriter(fw)
+
+			var header []string
+			for _, col := range t.cols {
+				switch {
+				case dt.diffType == "modified" && pairs:
+					header = append(header, "old_"+col, "new_"+col)
+				case dt.diffType == "removed" && t.fromCols[col], dt.diffType != "removed" && t.toCols[col]:
+					header = append(header, col)
+				}
+			}
+			if err := cw.Write(header); err != nil {
+				return err
+			}
+
+			n, err := scanDiffExportRows(ctx, conn, fromRef, toRef, t, dt.diffType, func(row diffExportRow) error {
+				values := make([]string, 0, len(header))
+				for _, col := range t.cols {
+					switch {
+					case dt.diffType == "modified" && pairs:
+						values = append(values, diffExportText(row.from[col]), diffExportText(row.to[col]))
+					case dt.diffType == "removed" && t.fromCols[col]:
+						values = append(values, diffExportText(row.from[col]))
+					case dt.diffType != "removed" && t.toCols[col]:
+						values = append(values, diffExportText(row.to[col]))
+					}
+				}
+				return cw.Write(values)
+			})
+			cw.Flush()
+			if err == nil {
+				err = cw.Error()
+			}
+			record(model.DiffExportFile{Name: name, Table: t.entry.Table, DiffType: dt.diffType, Rows: n}, err)
+		}
+	}
+	return nil
+}
+
+func writeDiffExportJSONL(ctx context.Context, zw *zip.Writer, conn *sql.Conn, fromRef, toRef string, tables []*diffExportTable, record diffExportRecorder) error {
+	for _, t := range tables {
+		name := t.entry.Table + ".jsonl"
+		fw, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(fw)
+		total := 0
+		for _, dt := range diffExportTypes {
+			if diffExportCount(t.entry, dt.diffType) == 0 {
+				continue
+			}
+			var n int
+			n, err = scanDiffExportRows(ctx, conn, fromRef, toRef, t, dt.diffType, func(row diffExportRow) error {
+				return enc.Encode(struct {
+					DiffType string                 `json:"diff_type"`
+					From     map[string]interface{} `json:"from"`
+					To       map[string]interface{} `json:"to"`
+				}{row.diffType, row.from, row.to})
+			})
+			total += n
+			if err != nil {
+				break
+			}
+		}
+		record(model.DiffExportFile{Name: name, Table: t.entry.Table, Rows: total}, err)
+	}
+	return nil
+}
+
+func writeDiffExportSQL(ctx context.Context, zw *zip.Writer, conn *sql.Conn, fromRef, toRef, resolvedFrom, resolvedTo string, tables []*diffExportTable, record diffExportRecorder) error {
+	const name = "patch.sql"
+	fw, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(fw, "-- Diff patch: %s -> %s (resolved %s -> %s)\n-- Applies the row changes of the diff; schema changes are not included.\nSET FOREIGN_KEY_CHECKS = 0;\nSTART TRANSACTION;\n",
+		fromRef, toRef, resolvedFrom, resolvedTo); err != nil {
+		return err
+	}
+	for _, t := range tables {
+		if _, err := fmt.Fprintf(fw, "\n-- %s\n", t.entry.Table); err != nil {
+			return err
+		}
+		total := 0
+		var scanErr error
+		for _, dt := range diffExportTypes {
+			if diffExportCount(t.entry, dt.diffType) == 0 {
+				continue
+			}
+			var n int
+			n, scanErr = scanDiffExportRows(ctx, conn, resolvedFrom, resolvedTo, t, dt.diffType, func(row diffExportRow) error {
+				_, err := io.WriteString(fw, sqlPatchStatement(t, row))
+				return err
+			})
+			total += n
+			if scanErr != nil {
+				break
+			}
+		}
+		if scanErr != nil {
+			if _, err := fmt.Fprintf(fw, "-- ERROR: %s\n", strings.ReplaceAll(scanErr.Error(), "\n", " ")); err != nil {
+				return err
+			}
+		}
+		record(model.DiffExportFile{Name: name, Table: t.entry.Table, Rows: total}, scanErr)
+	}
+	_, err = io.WriteString(fw, "\nCOMMIT;\nSET FOREIGN_KEY_CHECKS = 1;\n")
+	return err
+}
+
+func writeDiffExportXLSX(ctx context.Context, zw *zip.Writer, conn *sql.Conn, fromRef, toRef string, tables []*diffExportTable, record diffExportRecorder) error {
+	const name = "diff.xlsx"
+	// The workbook is itself a ZIP; store it uncompressed in the outer one.
+	fw, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store, Modified: time.Now()})
+	if err != nil {
+		return err
+	}
+	xw := newXLSXWriter(fw)
+	for _, t := range tables {
+		if err := xw.AddSheet(t.entry.Table); err != nil {
+			return err
+		}
+		header := []*string{strPtr("diff_type")}
+		for _, col := range t.cols {
+			header = append(header, strPtr("old_"+col), strPtr("new_"+col))
+		}
+		if err := xw.WriteRow(header); err != nil {
+			return err
+		}
+		total := 0
+		var scanErr error
+		for _, dt := range diffExportTypes {
+			if diffExportCount(t.entry, dt.diffType) == 0 {
+				continue
+			}
+			var n int
+			n, scanErr = scanDiffExportRows(ctx, conn, fromRef, toRef, t, dt.diffType, func(row diffExportRow) error {
+				cells := []*string{strPtr(row.diffType)}
+				for _, col := range t.cols {
+					cells = append(cells, diffExportCell(row.from, col), diffExportCell(row.to, col))
+				}
+				return xw.WriteRow(cells)
+			})
+			total += n
+			if scanErr != nil {
+				break
+			}
+		}
+		record(model.DiffExportFile{Name: name, Table: t.entry.Table, Rows: total}, scanErr)
+	}
+	return xw.Close()
+}
+
+// diffExportCell returns the text of side[col], or nil for a missing side or
+// a NULL value so the cell is left empty.
+func diffExportCell(side map[string]interface{}, col string) *string {
+	if side == nil || side[col] == nil {
+		return nil
+	}
+	text := diffExportText(side[col])
+	return &text
+}
+
+func strPtr(s string) *string {
+	return &s
+}
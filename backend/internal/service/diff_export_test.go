@@ -0,0 +1,171 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+)
+
+// diffExportTestRepo serves a users table with three inserts, one update and
+// one delete, plus a table whose DOLT_DIFF fails.
+func diffExportTestRepo(t *testing.T, keyset *[]driver.Value) *crossCopyTestRepo {
+	diffCols := []string{"to_id", "to_name", "to_commit", "to_commit_date", "from_id", "from_name", "from_commit", "from_commit_date", "diff_type"}
+	diffRows := [][]driver.Value{
+		{int64(1), "Ann", "c", nil, nil, nil, "c", nil, "added"},
+		{int64(2), "Bob", "c", nil, nil, nil, "c", nil, "added"},
+		{int64(3), "O'Neil", "c", nil, nil, nil, "c", nil, "added"},
+		{int64(5), "Eva", "c", nil, int64(5), "Eve", "c", nil, "modified"},
+		{nil, nil, "c", nil, int64(9), "Zed", "c", nil, "removed"},
+	}
+	return newCrossCopyTestRepo(t,
+		func(dbName, refName, query string, args []driver.NamedValue) (testQueryResult, error) {
+			switch {
+			case strings.HasPrefix(query, "SELECT table_name, rows_added, rows_modified, rows_deleted FROM DOLT_DIFF_STAT('main', 'audit')"):
+				return testQueryResult{
+					columns: []string{"table_name", "rows_added", "rows_modified", "rows_deleted"},
+					rows:    [][]driver.Value{{"broken", int64(1), int64(0), int64(0)}, {"users", int64(3), int64(1), int64(1)}},
+				}, nil
+			case strings.Contains(query, "'broken'"):
+				return testQueryResult{}, errors.New("table broken: diff unavailable")
+			case query == "SELECT * FROM DOLT_DIFF('main', 'audit', 'users') LIMIT 0":
+				return testQueryResult{columns: diffCols}, nil
+			case query == "SHOW COLUMNS FROM `users`" && refName == "audit":
+				return schemaResult([]model.ColumnSchema{{Name: "id", Type: "int", PrimaryKey: true}, {Name: "name", Type: "varchar(50)"}}), nil
+			case strings.HasPrefix(query, "SELECT * FROM DOLT_DIFF('main', 'audit', 'users') WHERE diff_type = ?"):
+				diffType := args[0].Value.(string)
+				keyCol := 0
+				if diffType == "removed" {
+					keyCol = 4
+				}
+				var after int64 = -1
+				if len(args) > 1 {
+					after = args[1].Value.(int64)
+					*keyset = append(*keyset, args[1].Value)
+				}
+				result := testQueryResult{columns: diffCols}
+				for _, row := range diffRows {
+					if row[8] == diffType && row[keyCol].(int64) > after && len(result.rows) < diffExportPageSize {
+						result.rows = append(result.rows, row)
+					}
+				}
+				return result, nil
+			}
+			return testQueryResult{}, fmt.Errorf("unexpected revision query on %s/%s: %s", dbName, refName, query)
+		},
+		nil,
+	)
+}
+
+// exportDiffZipFiles runs ExportDiffZip and returns the archive entries.
+func exportDiffZipFiles(t *testing.T, svc *Service, format string) map[string]string {
+	t.Helper()
+	var buf bytes.Buffer
+	var zipName string
+	err := svc.ExportDiffZip(context.Background(), "local", "test_db", "audit", "main", "audit", "two_dot", format, func(name string) io.Writer {
+		zipName = name
+		return &buf
+	})
+	if err != nil {
+		t.Fatalf("ExportDiffZip(%s): %v", format, err)
+	}
+	if zipName != "diff-main-audit.zip" {
+		t.Fatalf("zip name = %q", zipName)
+	}
+	files := readZipEntries(t, buf.Bytes())
+	var manifest model.DiffExportManifest
+	if err := json.Unmarshal([]byte(files["manifest.json"]), &manifest); err != nil {
+		t.Fatalf("manifest: %v", err)
+	}
+	if manifest.Complete || manifest.Files[0].Table != "broken" || !strings.Contains(manifest.Files[0].Error, "diff unavailable") {
+		t.Fatalf("expected the broken table in the manifest, got %+v", manifest)
+	}
+	return files
+}
+
+func readZipEntries(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("zip: %v", err)
+	}
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		body, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(body)
+	}
+	return files
+}
+
+func TestExportDiffZip_PagesByKeyAndWritesEachFormat(t *testing.T) {
+	defer func(size int) { diffExportPageSize = size }(diffExportPageSize)
+	diffExportPageSize = 2
+
+	var keyset []driver.Value
+	svc := newWithDeps(diffExportTestRepo(t, &keyset), testServiceConfig())
+
+	files := exportDiffZipFiles(t, svc, model.DiffExportCSVPairs)
+	if got := files["users_insert.csv"]; got != "id,name\n1,Ann\n2,Bob\n3,O'Neil\n" {
+		t.Fatalf("users_insert.csv = %q", got)
+	}
+	if got := files["users_update.csv"]; got != "old_id,new_id,old_name,new_name\n5,5,Eve,Eva\n" {
+		t.Fatalf("users_update.csv = %q", got)
+	}
+	if got := files["users_delete.csv"]; got != "id,name\n9,Zed\n" {
+		t.Fatalf("users_delete.csv = %q", got)
+	}
+	// Only the insert file filled a page, so only it resumes after id 2.
+	if len(keyset) != 1 || keyset[0] != int64(2) {
+		t.Fatalf("keyset args = %v, want [2]", keyset)
+	}
+
+	files = exportDiffZipFiles(t, svc, model.DiffExportSQL)
+	patch := files["patch.sql"]
+	for _, want := range []string{
+		"DELETE FROM `users` WHERE `id` = 9;",
+		"UPDATE `users` SET `name` = 'Eva' WHERE `id` = 5;",
+		"INSERT INTO `users` (`id`, `name`) VALUES (3, 'O\\'Neil');",
+	} {
+		if !strings.Contains(patch, want) {
+			t.Fatalf("patch.sql missing %q:\n%s", want, patch)
+		}
+	}
+
+	files = exportDiffZipFiles(t, svc, model.DiffExportXLSX)
+	book := readZipEntries(t, []byte(files["diff.xlsx"]))
+	if !strings.Contains(book["xl/workbook.xml"], `<sheet name="users"`) ||
+		!strings.Contains(book["xl/worksheets/sheet1.xml"], `<c r="E3" t="inlineStr"><is><t xml:space="preserve">Eva</t>`) {
+		t.Fatalf("unexpected workbook:\n%s\n%s", book["xl/workbook.xml"], book["xl/worksheets/sheet1.xml"])
+	}
+
+	files = exportDiffZipFiles(t, svc, model.DiffExportJSONL)
+	if lines := strings.Split(strings.TrimSpace(files["users.jsonl"]), "\n"); len(lines) != 5 ||
+		lines[0] != `{"diff_type":"removed","from":{"id":9,"name":"Zed"},"to":null}` {
+		t.Fatalf("users.jsonl = %q", files["users.jsonl"])
+	}
+}
+
+func TestExportDiffZip_RejectsUnknownFormatBeforeStreaming(t *testing.T) {
+	svc := newWithDeps(newCrossCopyTestRepo(t, nil, nil), testServiceConfig())
+	err := svc.ExportDiffZip(context.Background(), "local", "test_db", "audit", "main", "audit", "two_dot", "pdf", func(string) io.Writer {
+		t.Fatal("open must not be called for an invalid request")
+		return nil
+	})
+	var apiErr *model.APIError
+	if !errors.As(err, &apiErr) || apiErr.Status != 400 {
+		t.Fatalf("expected 400, got %v", err)
+	}
+}
@@ -3,9 +3,8 @@ package service
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 	"fmt"
-	"strconv"
+	"log"
 	"strings"
 
 	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
@@ -32,7 +31,7 @@ func historyIntegrityError(commitHash string, err error) *model.APIError {
 // Supports both single and composite primary keys.
 func parsePK(pkJSON string) (map[string]interface{}, error) {
 	var pkMap map[string]interface{}
-	if err := json.Unmarshal([]byte(pkJSON), &pkMap); err != nil {
+	if err := unmarshalJSONNumbers([]byte(pkJSON), &pkMap); err != nil {
 		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "invalid pk JSON"}
 	}
 	if len(pkMap) < 1 {
@@ -103,44 +102,18 @@ func (s *Service) HistoryCommits(ctx context.Context, targetID, dbName, branchNa
 	// --- Step 3: classify each commit and build the history list ---
 	commits := make([]model.HistoryCommit, 0)
 	for _, rc := range rawCommits {
-		footer, parseErr := parseApprovalFooter(rc.message)
-
-		switch {
-		case parseErr != nil:
-			// Footer marker present but invalid — integrity error, must not silent-skip.
-			return nil, historyIntegrityError(rc.hash, parseErr)
-
-		case footer != nil:
-			// Post-cutover: footer is the primary truth.
-			c := model.HistoryCommit{
-				Hash:        rc.hash,
-				Author:      rc.committer,
-				Message:     humanSubjectFromApprovalMessage(rc.message),
-				Timestamp:   rc.date,
-				MergeBranch: footer.WorkBranch,
-			}
-			commits = append(commits, c)
-
-		default:
-			// No footer — check merged/* legacy adapter (pre-cutover).
-			legacyWorkItem, hasLegacy := mergedTagIndex[rc.hash]
-			if !hasLegacy {
-				// Normal (non-approval) commit — skip.
-				continue
-			}
-			c := model.HistoryCommit{
-				Hash:        rc.hash,
-				Author:      rc.committer,
-				Message:     rc.message,
-				Timestamp:   rc.date,
-				MergeBranch: "wi/" + legacyWorkItem,
-			}
-			commits = append(commits, c)
+		c, err := classifyApprovalCommit(rc.hash, rc.committer, rc.date, rc.message, mergedTagIndex)
+		if err != nil {
+			return nil, err
+		}
+		if c != nil {
+			commits = append(commits, *c)
 		}
 	}
 
 	// --- Step 4: apply keyword / date filters in Go ---
 	commits = filterHistoryCommits(commits, keyword, fromDate, toDate, searchField)
+	s.attachWorkItemMetas(ctx, targetID, dbName, commits)
 
 	// When filtering by record, fetch a larger batch (paginate after filtering).
 	if filterTable == "" || filterPk == "" {
@@ -181,11 +154,8 @@ func (s *Service) HistoryCommits(ctx context.Context, targetID, dbName, branchNa
 		if err := validation.ValidateIdentifier("pk column", k); err != nil {
 			return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "invalid pk column name"}
 		}
-		if fv, ok := v.(float64); ok && fv == float64(int64(fv)) {
-			v = strconv.FormatInt(int64(fv), 10)
-		}
 		whereParts = append(whereParts, fmt.Sprintf("`%s` = ?", k))
-		pkArgs = append(pkArgs, v)
+		pkArgs = append(pkArgs, sqlArgFromJSON(v))
 	}
 
 	// --- Query 1: Get first-parent hash for each merge commit ---
@@ -382,12 +352,8 @@ func (s *Service) HistoryRow(ctx context.Context, targetID, dbName, branchName,
 		if err := validation.ValidateIdentifier("pk column", k); err != nil {
 			return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "invalid pk column name"}
 		}
-		// Convert JSON number (float64) to integer string for query
-		if fv, ok := v.(float64); ok && fv == float64(int64(fv)) {
-			v = strconv.FormatInt(int64(fv), 10)
-		}
 		whereParts = append(whereParts, fmt.Sprintf("`%s` = ?", k))
-		whereArgs = append(whereArgs, v)
+		whereArgs = append(whereArgs, sqlArgFromJSON(v))
 	}
 
 	conn, err := s.connHistoryRevision(ctx, targetID, dbName, branchName)
@@ -397,6 +363,11 @@ func (s *Service) HistoryRow(ctx context.Context, targetID, dbName, branchName,
 	defer conn.Close()
 
 	histTable := fmt.Sprintf("dolt_history_%s", table)
+	histCols, err := getSchemaColumns(ctx, conn, histTable)
+	if err != nil {
+		return nil, err
+	}
+	kinds := columnKindsFromSchema(histCols)
 	query := fmt.Sprintf(
 		"SELECT * FROM `%s` WHERE %s ORDER BY commit_date DESC LIMIT ?",
 		histTable, strings.Join(whereParts, " AND "),
@@ -427,10 +398,7 @@ func (s *Service) HistoryRow(ctx context.Context, targetID, dbName, branchName,
 
 		snap := model.HistoryRowSnapshot{Row: make(map[string]interface{})}
 		for i, col := range colNames {
-			val := values[i]
-			if b, ok := val.([]byte); ok {
-				val = string(b)
-			}
+			val := formatColumnValue(kinds[col], values[i])
 			switch col {
 			case "commit_hash":
 				if sv, ok := val.(string); ok {
@@ -462,6 +430,42 @@ func (s *Service) HistoryRow(ctx context.Context, targetID, dbName, branchName,
 
 // --- B-PR2 helpers ---
 
+// classifyApprovalCommit returns the history entry for an approval merge, or nil
+// for a normal (non-approval) commit. The footer is the primary truth; the
+// merged/* index covers pre-cutover approvals without one.
+func classifyApprovalCommit(hash, committer, date, message string, mergedTagIndex map[string]string) (*model.HistoryCommit, error) {
+	footer, parseErr := parseApprovalFooter(message)
+
+	switch {
+	case parseErr != nil:
+		// Footer marker present but invalid — integrity error, must not silent-skip.
+		return nil, historyIntegrityError(hash, parseErr)
+
+	case footer != nil:
+		return &model.HistoryCommit{
+			Hash:        hash,
+			Author:      committer,
+			Message:     humanSubjectFromApprovalMessage(message),
+			Timestamp:   date,
+			MergeBranch: footer.WorkBranch,
+			TicketID:    footer.TicketID,
+		}, nil
+
+	default:
+		legacyWorkItem, hasLegacy := mergedTagIndex[hash]
+		if !hasLegacy {
+			return nil, nil
+		}
+		return &model.HistoryCommit{
+			Hash:        hash,
+			Author:      committer,
+			Message:     message,
+			Timestamp:   date,
+			MergeBranch: "wi/" + legacyWorkItem,
+		}, nil
+	}
+}
+
 // buildMergedTagIndex queries all merged/* tags and returns a map from
 // tag_hash (approval merge commit hash) to workItem name.
 // This is used as a legacy adapter for pre-cutover approvals that do not
@@ -559,3 +563,22 @@ func paginateCommits(commits []model.HistoryCommit, page, pageSize int) []model.
 	}
 	return commits[start:end]
 }
+
+// attachWorkItemMetas decorates approval records with the current work item
+// registry entry from main. Failures leave the records undecorated.
+func (s *Service) attachWorkItemMetas(ctx context.Context, targetID, dbName string, commits []model.HistoryCommit) {
+	if len(commits) == 0 {
+		return
+	}
+	conn, err := s.connMetadataRevision(ctx, targetID, dbName)
+	if err != nil {
+		log.Printf("WARN: work item metadata unavailable: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	metas := loadWorkItemMetasSoft(ctx, conn)
+	for i := range commits {
+		commits[i].WorkItemMeta = workItemMetaForBranch(metas, commits[i].MergeBranch)
+	}
+}
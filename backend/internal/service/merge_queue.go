@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+)
+
+// mergeQueueHistoryLimit caps how many finished entries a queue keeps for display.
+const mergeQueueHistoryLimit = 50
+
+// mergeQueueMergeFn merges one queued request. Production: re-checks conflicts
+// against current main, then runs ApproveRequest. Tests: can be overridden.
+type mergeQueueMergeFn func(ctx context.Context, req model.ApproveRequest) (*model.ApproveResponse, error)
+
+// mergeQueueRegistry holds one queue per database. Queues live in process memory:
+// after a restart, queued requests are still pending req/* tags and can be re-enqueued.
+type mergeQueueRegistry struct {
+	mu     sync.Mutex
+	nextID int64
+	queues map[string]*mergeQueue
+}
+
+type mergeQueue struct {
+	// mainLock serializes every commit onto main: approval merges, queued or
+	// direct, and admin cross-copy schema preparation.
+	mainLock sync.Mutex
+
+	mu      sync.Mutex
+	running bool
+	entries []*model.MergeQueueEntry
+}
+
+func newMergeQueueRegistry() *mergeQueueRegistry {
+	return &mergeQueueRegistry{queues: make(map[string]*mergeQueue)}
+}
+
+func (r *mergeQueueRegistry) queue(targetID, dbName string) *mergeQueue {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := targetID + "/" + dbName
+	q, ok := r.queues[key]
+	if !ok {
+		q = &mergeQueue{}
+		r.queues[key] = q
+	}
+	return q
+}
+
+func (r *mergeQueueRegistry) newID() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	return r.nextID
+}
+
+// snapshot copies the entries and numbers queued ones by position. Callers hold q.mu.
+func (q *mergeQueue) snapshot() []model.MergeQueueEntry {
+	entries := make([]model.MergeQueueEntry, 0, len(q.entries))
+	position := 0
+	for _, entry := range q.entries {
+		copied := *entry
+		if copied.Status == model.MergeQueueQueued {
+			position++
+			copied.Position = position
+		}
+		entries = append(entries, copied)
+	}
+	return entries
+}
+
+// trimHistory drops the oldest finished entries beyond mergeQueueHistoryLimit. Callers hold q.mu.
+func (q *mergeQueue) trimHistory() {
+	finished := 0
+	for _, entry := range q.entries {
+		if entry.Status == model.MergeQueueMerged || entry.Status == model.MergeQueueFailed {
+			finished++
+		}
+	}
+	kept := q.entries[:0]
+	for _, entry := range q.entries {
+		if finished > mergeQueueHistoryLimit && (entry.Status == model.MergeQueueMerged || entry.Status == model.MergeQueueFailed) {
+			finished--
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	q.entries = kept
+}
+
+// EnqueueMerge adds an approved request to the database's merge queue and starts
+// the worker if it is idle. The merge itself happens asynchronously; its outcome
+// is reported by GetMergeQueue instead of the approver's HTTP call.
+func (s *Service) EnqueueMerge(ctx context.Context, req model.EnqueueMergeRequest) (*model.MergeQueueEntry, error) {
+	if !isRequestTagName(req.RequestID) {
+		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "invalid request_id format"}
+	}
+
+	conn, err := s.connMetadataRevision(ctx, req.TargetID, req.DBName)
+	if err != nil {
+		return nil, err
+	}
+	var count int
+	err = conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM dolt_tags WHERE tag_name = ?", req.RequestID).Scan(&count)
+	conn.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check request: %w", err)
+	}
+	if count == 0 {
+		return nil, &model.APIError{Status: 404, Code: model.CodeNotFound, Msg: "request not found"}
+	}
+
+	q := s.mergeQueues.queue(req.TargetID, req.DBName)
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, entry := range q.entries {
+		if entry.RequestID == req.RequestID && (entry.Status == model.MergeQueueQueued || entry.Status == model.MergeQueueMerging) {
+			return nil, &model.APIError{
+				Status:  412,
+				Code:    model.CodePreconditionFailed,
+				Msg:     "request is already in the merge queue",
+				Details: map[string]interface{}{"reason": "already_queued", "entry_id": entry.ID},
+			}
+		}
+	}
+
+	entry := &model.MergeQueueEntry{
+		ID:             s.mergeQueues.newID(),
+		RequestID:      req.RequestID,
+		MergeMessageJa: req.MergeMessageJa,
+		Status:         model.MergeQueueQueued,
+		EnqueuedAt:     time.Now().UTC().Format(time.RFC3339),
+	}
+	q.entries = append(q.entries, entry)
+	if !q.running {
+		q.running = true
+		go s.runMergeQueue(req.TargetID, req.DBName, q)
+	}
+
+	for _, queued := range q.snapshot() {
+		if queued.ID == entry.ID {
+			return &queued, nil
+		}
+	}
+	return nil, fmt.Errorf("merge queue entry %d disappeared", entry.ID)
+}
+
+// GetMergeQueue returns the queued, running and recently finished entries.
+func (s *Service) GetMergeQueue(ctx context.Context, targetID, dbName string) (*model.MergeQueueResponse, error) {
+	if _, err := s.configuredDatabase(targetID, dbName); err != nil {
+		return nil, err
+	}
+	q := s.mergeQueues.queue(targetID, dbName)
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return &model.MergeQueueResponse{Running: q.running, Entries: q.snapshot()}, nil
+}
+
+// runMergeQueue merges queued entries one at a time, in order, until none are left.
+func (s *Service) runMergeQueue(targetID, dbName string, q *mergeQueue) {
+	for {
+		q.mu.Lock()
+		var entry *model.MergeQueueEntry
+		for _, candidate := range q.entries {
+			if candidate.Status == model.MergeQueueQueued {
+				entry = candidate
+				break
+			}
+		}
+		if entry == nil {
+			q.running = false
+			q.mu.Unlock()
+			return
+		}
+		entry.Status = model.MergeQueueMerging
+		entry.StartedAt = time.Now().UTC().Format(time.RFC3339)
+		req := model.ApproveRequest{
+			TargetID:       targetID,
+			DBName:         dbName,
+			RequestID:      entry.RequestID,
+			MergeMessageJa: entry.MergeMessageJa,
+		}
+		q.mu.Unlock()
+
+		ctx, cancel := context.WithTimeout(context.Background(), s.mergeQueueTimeBudget())
+		result, err := s.mergeQueueMergeHook(ctx, req)
+		cancel()
+
+		q.mu.Lock()
+		entry.FinishedAt = time.Now().UTC().Format(time.RFC3339)
+		if err != nil {
+			entry.Status = model.MergeQueueFailed
+			entry.Reason, entry.Message = mergeQueueFailure(err)
+			log.Printf("WARN: merge queue entry %d (%s) failed: %v", entry.ID, entry.RequestID, err)
+		} else {
+			entry.Status = model.MergeQueueMerged
+			entry.Result = result
+			entry.Message = result.Message
+		}
+		q.trimHistory()
+		q.mu.Unlock()
+	}
+}
+
+// mergeQueueTimeBudget bounds one queued merge the way the HTTP write timeout
+// bounds a direct approve, so a stuck merge cannot hold the queue forever.
+func (s *Service) mergeQueueTimeBudget() time.Duration {
+	if sec := s.cfg.Server.Timeouts.WriteSec; sec > 0 {
+		return time.Duration(sec) * time.Second
+	}
+	return 5 * time.Minute
+}
+
+func mergeQueueFailure(err error) (string, string) {
+	var apiErr *model.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Code, apiErr.Msg
+	}
+	return model.CodeInternal, err.Error()
+}
+
+// mergeQueuedRequest re-checks the request against the main that earlier queue
+// entries produced, then approves it exactly like POST /request/approve.
+func (s *Service) mergeQueuedRequest(ctx context.Context, req model.ApproveRequest) (*model.ApproveResponse, error) {
+	conn, err := s.connMetadataRevision(ctx, req.TargetID, req.DBName)
+	if err != nil {
+		return nil, err
+	}
+	var mainHash string
+	if err := conn.QueryRowContext(ctx, "SELECT DOLT_HASHOF('main')").Scan(&mainHash); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to get main hash: %w", err)
+	}
+	check, err := checkRequestMergeability(ctx, conn, req.RequestID, mainHash)
+	conn.Close()
+	if err != nil {
+		return nil, err
+	}
+	if len(check.SchemaConflictTables) > 0 {
+		return nil, &model.APIError{
+			Status:  409,
+			Code:    model.CodeSchemaConflictsPresent,
+			Msg:     "schema conflicts detected",
+			Details: map[string]interface{}{"tables": check.SchemaConflictTables},
+		}
+	}
+	if len(check.ConflictTables) > 0 {
+		return nil, &model.APIError{
+			Status:  409,
+			Code:    model.CodeMergeConflictsPresent,
+			Msg:     "merge conflicts detected - same cell edited in multiple branches",
+			Details: map[string]interface{}{"tables": check.ConflictTables},
+		}
+	}
+	return s.ApproveRequest(ctx, req)
+}
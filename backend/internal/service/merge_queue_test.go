@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+)
+
+func waitMergeQueueIdle(t *testing.T, svc *Service) *model.MergeQueueResponse {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		state, err := svc.GetMergeQueue(context.Background(), "local", "test_db")
+		if err != nil {
+			t.Fatalf("GetMergeQueue: %v", err)
+		}
+		if !state.Running {
+			return state
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("merge queue did not drain: %+v", state.Entries)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestMergeQueue_MergesInOrderAndRecordsFailures(t *testing.T) {
+	repo := newRecordingSessionRepo(t, func(refName, query string, args []driver.NamedValue) (testQueryResult, error) {
+		if query == "SELECT COUNT(*) FROM dolt_tags WHERE tag_name = ?" {
+			return testQueryResult{columns: []string{"count"}, rows: [][]driver.Value{{int64(1)}}}, nil
+		}
+		return testQueryResult{}, fmt.Errorf("unexpected query: %s", query)
+	})
+	svc := newWithDeps(repo, testServiceConfig())
+
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var merged []string
+	unbounded := false
+	svc.mergeQueueMergeHook = func(ctx context.Context, req model.ApproveRequest) (*model.ApproveResponse, error) {
+		<-release
+		mu.Lock()
+		defer mu.Unlock()
+		merged = append(merged, req.RequestID)
+		if _, ok := ctx.Deadline(); !ok {
+			unbounded = true
+		}
+		if req.RequestID == "req/conflict" {
+			return nil, &model.APIError{Status: 409, Code: model.CodeMergeConflictsPresent, Msg: "merge conflicts detected"}
+		}
+		return &model.ApproveResponse{
+			Hash:       "hash-" + req.RequestID,
+			ArchiveTag: "merged/" + strings.TrimPrefix(req.RequestID, "req/") + "/01",
+			OperationResultFields: model.OperationResultFields{
+				Outcome: model.OperationOutcomeCompleted,
+				Message: "main へのマージが完了しました",
+			},
+		}, nil
+	}
+
+	for _, requestID := range []string{"req/first", "req/conflict", "req/last"} {
+		entry, err := svc.EnqueueMerge(context.Background(), model.EnqueueMergeRequest{
+			TargetID: "local", DBName: "test_db", RequestID: requestID, MergeMessageJa: "承認",
+		})
+		if err != nil {
+			t.Fatalf("EnqueueMerge(%s): %v", requestID, err)
+		}
+		if entry.Status != model.MergeQueueQueued {
+			t.Fatalf("unexpected entry: %+v", entry)
+		}
+	}
+
+	_, err := svc.EnqueueMerge(context.Background(), model.EnqueueMergeRequest{
+		TargetID: "local", DBName: "test_db", RequestID: "req/last",
+	})
+	if apiErr, ok := err.(*model.APIError); !ok || apiErr.Code != model.CodePreconditionFailed {
+		t.Fatalf("expected duplicate enqueue to fail, got %v", err)
+	}
+
+	close(release)
+	state := waitMergeQueueIdle(t, svc)
+
+	if strings.Join(merged, ",") != "req/first,req/conflict,req/last" {
+		t.Fatalf("unexpected merge order: %v", merged)
+	}
+	if unbounded {
+		t.Fatal("queued merges must run with a deadline")
+	}
+	byID := make(map[string]model.MergeQueueEntry)
+	for _, entry := range state.Entries {
+		byID[entry.RequestID] = entry
+	}
+	if entry := byID["req/conflict"]; entry.Status != model.MergeQueueFailed || entry.Reason != model.CodeMergeConflictsPresent {
+		t.Fatalf("unexpected failed entry: %+v", entry)
+	}
+	if entry := byID["req/last"]; entry.Status != model.MergeQueueMerged || entry.Result == nil || entry.Result.ArchiveTag != "merged/last/01" {
+		t.Fatalf("unexpected merged entry: %+v", entry)
+	}
+}
+
+func TestMergeQueue_UnknownRequestIsNotFound(t *testing.T) {
+	repo := newRecordingSessionRepo(t, func(refName, query string, args []driver.NamedValue) (testQueryResult, error) {
+		return testQueryResult{columns: []string{"count"}, rows: [][]driver.Value{{int64(0)}}}, nil
+	})
+	svc := newWithDeps(repo, testServiceConfig())
+
+	_, err := svc.EnqueueMerge(context.Background(), model.EnqueueMergeRequest{TargetID: "local", DBName: "test_db", RequestID: "req/missing"})
+	if apiErr, ok := err.(*model.APIError); !ok || apiErr.Code != model.CodeNotFound {
+		t.Fatalf("expected NOT_FOUND, got %v", err)
+	}
+}
+
+func TestMergeQueueTrimHistory_KeepsQueuedEntries(t *testing.T) {
+	q := &mergeQueue{}
+	for i := 0; i < mergeQueueHistoryLimit+5; i++ {
+		q.entries = append(q.entries, &model.MergeQueueEntry{ID: int64(i), Status: model.MergeQueueMerged})
+	}
+	q.entries = append(q.entries, &model.MergeQueueEntry{ID: 999, Status: model.MergeQueueQueued})
+	q.trimHistory()
+
+	if len(q.entries) != mergeQueueHistoryLimit+1 || q.entries[0].ID != 5 || q.entries[len(q.entries)-1].ID != 999 {
+		t.Fatalf("unexpected entries after trim: first=%d len=%d", q.entries[0].ID, len(q.entries))
+	}
+}
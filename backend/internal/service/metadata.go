@@ -2,7 +2,9 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"log"
 
 	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
 	"github.com/Makeinu1/dolt-web-ui/backend/internal/validation"
@@ -53,6 +55,19 @@ func (s *Service) ListBranches(ctx context.Context, targetID, dbName string) ([]
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
+
+	bases, err := listBranchBases(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	metas := loadWorkItemMetasSoft(ctx, conn)
+	for i := range branches {
+		if base, ok := bases[branches[i].Name]; ok {
+			branches[i].BaseRef = base.FromRef
+			branches[i].BaseHash = base.FromHash
+		}
+		branches[i].WorkItemMeta = workItemMetaForBranch(metas, branches[i].Name)
+	}
 	return s.filterAllowedBranches(targetID, dbName, branches)
 }
 
@@ -61,17 +76,47 @@ func (s *Service) CreateBranch(ctx context.Context, req model.CreateBranchReques
 		return err
 	}
 
+	if req.FromRef != "" {
+		if err := s.ensureHistoryRef(ctx, req.TargetID, req.DBName, req.FromRef); err != nil {
+			return err
+		}
+	}
+
 	conn, err := s.repo.ConnProtectedMaintenance(ctx, req.TargetID, req.DBName, "main")
 	if err != nil {
 		return fmt.Errorf("failed to connect: %w", err)
 	}
 	defer conn.Close()
 
-	// The protected maintenance session is checked out on main, so single-arg
-	// DOLT_BRANCH creates from main HEAD.
-	_, err = conn.ExecContext(ctx, "CALL DOLT_BRANCH(?)", req.BranchName)
-	if err != nil {
-		return classifyBranchCreateError(ctx, conn, req.BranchName, err)
+	if req.FromRef == "" {
+		// The protected maintenance session is checked out on main, so single-arg
+		// DOLT_BRANCH creates from main HEAD.
+		_, err = conn.ExecContext(ctx, "CALL DOLT_BRANCH(?)", req.BranchName)
+		if err != nil {
+			return classifyBranchCreateError(ctx, conn, req.BranchName, err)
+		}
+		// Record the creation time: a fresh branch off an old main commit
+		// must not look idle to the stale branch policy.
+		var mainHash string
+		if err := conn.QueryRowContext(ctx, "SELECT DOLT_HASHOF('main')").Scan(&mainHash); err != nil {
+			log.Printf("WARN: failed to resolve main for the branch base of %s: %v", req.BranchName, err)
+		} else if err := recordBranchBase(ctx, conn, req.BranchName, branchBaseMainRef, mainHash); err != nil {
+			log.Printf("WARN: failed to record branch base for %s: %v", req.BranchName, err)
+		}
+	} else {
+		// Resolve the ref first so the recorded base is a stable commit hash even
+		// when from_ref is a tag or an expression such as <hash>~1.
+		var fromHash string
+		if err := conn.QueryRowContext(ctx, "SELECT DOLT_HASHOF(?)", req.FromRef).Scan(&fromHash); err != nil {
+			return &model.APIError{Status: 404, Code: model.CodeNotFound, Msg: fmt.Sprintf("ref %s not found", req.FromRef)}
+		}
+		_, err = conn.ExecContext(ctx, "CALL DOLT_BRANCH(?, ?)", req.BranchName, fromHash)
+		if err != nil {
+			return classifyBranchCreateError(ctx, conn, req.BranchName, err)
+		}
+		if err := recordBranchBase(ctx, conn, req.BranchName, req.FromRef, fromHash); err != nil {
+			log.Printf("WARN: failed to record branch base for %s: %v", req.BranchName, err)
+		}
 	}
 
 	// Verify the branch is queryable on a fresh connection (USE db/branch).
@@ -153,19 +198,29 @@ func (s *Service) DeleteBranch(ctx context.Context, req model.DeleteBranchReques
 		return apiErr
 	}
 
+	return s.deleteWorkBranch(ctx, conn, req.TargetID, req.BranchName)
+}
+
+// deleteWorkBranch force-deletes a work branch on a protected maintenance session.
+// Callers are responsible for the lock check.
+func (s *Service) deleteWorkBranch(ctx context.Context, conn *sql.Conn, targetID, branchName string) error {
 	// Force delete (-D) is required because Dolt's safe delete (-d) rejects
 	// branches with unique commits that are not fully merged into HEAD.
 	// PurgeIdleConns below prevents stale pooled connections from causing
 	// cascading "branch not found" errors after deletion.
-	_, err = conn.ExecContext(ctx, "CALL DOLT_BRANCH('-D', ?)", req.BranchName)
+	_, err := conn.ExecContext(ctx, "CALL DOLT_BRANCH('-D', ?)", branchName)
 	if err != nil {
-		return classifyBranchDeleteError(req.BranchName, err)
+		return classifyBranchDeleteError(branchName, err)
 	}
 
 	// Purge idle connections to prevent stale branch/database contexts from
 	// lingering in the pool. Without this, pooled connections that were
 	// previously set to USE `db/deletedBranch` would fail on reuse.
-	s.repo.PurgeIdleConns(req.TargetID)
+	s.repo.PurgeIdleConns(targetID)
+
+	if err := dropBranchBase(ctx, conn, branchName); err != nil {
+		log.Printf("WARN: failed to delete branch base for %s: %v", branchName, err)
+	}
 
 	return nil
 }
@@ -0,0 +1,326 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/validation"
+)
+
+// overlapRowLimit caps how many diff rows are read per branch and table.
+const overlapRowLimit = 10000
+
+// overlapSubmitTimeout bounds the overlap check that SubmitRequest runs. The
+// full check stays available on demand through ListOverlaps.
+const overlapSubmitTimeout = 5 * time.Second
+
+// rowChange is one row of a three-dot diff against main.
+type rowChange struct {
+	pk    map[string]interface{}
+	cells map[string]bool
+}
+
+// branchChanges maps table -> PK key -> change.
+type branchChanges map[string]map[string]rowChange
+
+// ListOverlaps reports rows that branchName changed since its merge base with
+// main and that other allowed work branches changed as well. Branches with a
+// pending request carry its request ID.
+func (s *Service) ListOverlaps(ctx context.Context, targetID, dbName, branchName string) (*model.OverlapsResponse, error) {
+	return s.findOverlaps(ctx, targetID, dbName, branchName, false)
+}
+
+// findOverlaps implements ListOverlaps. With pendingOnly, only branches that
+// have a pending request are compared.
+func (s *Service) findOverlaps(ctx context.Context, targetID, dbName, branchName string, pendingOnly bool) (*model.OverlapsResponse, error) {
+	if err := s.ensureAllowedWorkBranchWrite(targetID, dbName, branchName); err != nil {
+		return nil, err
+	}
+	db, err := s.configuredDatabase(targetID, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := s.connMetadataRevision(ctx, targetID, dbName)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var exists int
+	if err := conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM dolt_branches WHERE name = ?", branchName).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to check branch: %w", err)
+	}
+	if exists == 0 {
+		return nil, newBranchNotFoundError(branchName)
+	}
+
+	others, err := otherWorkBranches(ctx, conn, branchName, db.AllowedBranches)
+	if err != nil {
+		return nil, err
+	}
+	locks, err := pendingRequestTags(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	if pendingOnly {
+		pending := others[:0]
+		for _, other := range others {
+			if requestID, ok := requestIDFromWorkBranch(other); ok && locks[requestID] {
+				pending = append(pending, other)
+			}
+		}
+		others = pending
+	}
+
+	resp := &model.OverlapsResponse{BranchName: branchName, Overlaps: make([]model.BranchOverlap, 0)}
+	if len(others) == 0 {
+		return resp, nil
+	}
+	pkCols := make(map[string][]string)
+	mine, truncated, err := readBranchChanges(ctx, conn, branchName, nil, pkCols)
+	if err != nil {
+		return nil, err
+	}
+	resp.Truncated = truncated
+	if len(mine) == 0 {
+		return resp, nil
+	}
+
+	for _, other := range others {
+		theirs, truncated, err := readBranchChanges(ctx, conn, other, mine, pkCols)
+		if err != nil {
+			return nil, err
+		}
+		resp.Truncated = resp.Truncated || truncated
+		rows := intersectBranchChanges(mine, theirs)
+		if len(rows) == 0 {
+			continue
+		}
+		overlap := model.BranchOverlap{Branch: other, Rows: rows}
+		if requestID, ok := requestIDFromWorkBranch(other); ok && locks[requestID] {
+			overlap.RequestID = requestID
+		}
+		resp.Overlaps = append(resp.Overlaps, overlap)
+	}
+	return resp, nil
+}
+
+// overlapWarning summarizes overlaps for SubmitRequest. Only branches with a
+// pending request are compared, within overlapSubmitTimeout. Failures only log:
+// overlap detection must never block (or noticeably slow) a submit.
+func (s *Service) overlapWarning(ctx context.Context, targetID, dbName, branchName string) ([]model.BranchOverlap, string) {
+	ctx, cancel := context.WithTimeout(ctx, overlapSubmitTimeout)
+	defer cancel()
+	resp, err := s.findOverlaps(ctx, targetID, dbName, branchName, true)
+	if err != nil {
+		log.Printf("WARN: overlap detection failed for %s: %v", branchName, err)
+		return nil, ""
+	}
+	if len(resp.Overlaps) == 0 {
+		return nil, ""
+	}
+	rowCount := 0
+	branches := make([]string, 0, len(resp.Overlaps))
+	for _, overlap := range resp.Overlaps {
+		rowCount += len(overlap.Rows)
+		branches = append(branches, overlap.Branch)
+	}
+	return resp.Overlaps, fmt.Sprintf("他の作業ブランチと %d 行の変更が重複しています (%s)。承認順によってはコンフリクトになります。", rowCount, strings.Join(branches, ", "))
+}
+
+func otherWorkBranches(ctx context.Context, conn *sql.Conn, branchName string, allowed []string) ([]string, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT name FROM dolt_branches WHERE name LIKE 'wi/%' ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query branches: %w", err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan branch: %w", err)
+		}
+		if name == branchName || !isWorkBranchName(name) || !validation.IsAllowedBranch(name, allowed) {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// readBranchChanges reads the three-dot diff main...branchName row by row.
+// When only is non-nil, tables outside it are skipped. pkCols caches main's
+// primary keys per table; tables missing on main cannot overlap and are skipped.
+func readBranchChanges(ctx context.Context, conn *sql.Conn, branchName string, only branchChanges, pkCols map[string][]string) (branchChanges, bool, error) {
+	// Per v6f spec 1.4: DOLT_DIFF literal constraint - embed validated tokens
+	refSpec := "main..." + branchName
+	rows, err := conn.QueryContext(ctx, fmt.Sprintf("SELECT to_table_name FROM DOLT_DIFF_SUMMARY('%s') WHERE data_change = 1", refSpec))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to summarize diff for %s: %w", branchName, err)
+	}
+	var tables []string
+	for rows.Next() {
+		var table sql.NullString
+		if err := rows.Scan(&table); err != nil {
+			rows.Close()
+			return nil, false, fmt.Errorf("failed to scan diff summary: %w", err)
+		}
+		if !table.Valid || isHiddenTableName(table.String) {
+			continue
+		}
+		if only != nil {
+			if _, ok := only[table.String]; !ok {
+				continue
+			}
+		}
+		tables = append(tables, table.String)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
+
+	changes := make(branchChanges)
+	truncated := false
+	for _, table := range tables {
+		if validation.ValidateIdentifier("table", table) != nil {
+			continue
+		}
+		pks, ok := pkCols[table]
+		if !ok {
+			cols, err := getSchemaColumns(ctx, conn, table)
+			if err != nil {
+				if apiErr, isAPI := err.(*model.APIError); isAPI && apiErr.Code == model.CodeNotFound {
+					pkCols[table] = nil
+					continue
+				}
+				return nil, false, err
+			}
+			pks = getPKColumns(cols)
+			pkCols[table] = pks
+		}
+		if len(pks) == 0 {
+			continue
+		}
+		tableChanges, tableTruncated, err := readTableChanges(ctx, conn, refSpec, table, pks)
+		if err != nil {
+			return nil, false, err
+		}
+		truncated = truncated || tableTruncated
+		if len(tableChanges) > 0 {
+			changes[table] = tableChanges
+		}
+	}
+	return changes, truncated, nil
+}
+
+func readTableChanges(ctx context.Context, conn *sql.Conn, refSpec, table string, pkCols []string) (map[string]rowChange, bool, error) {
+	query := fmt.Sprintf("SELECT * FROM DOLT_DIFF('%s', '%s') LIMIT %d", refSpec, table, overlapRowLimit+1)
+	rows, err := conn.QueryContext(ctx, query)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to diff %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	colNames, err := rows.Columns()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get columns: %w", err)
+	}
+
+	changes := make(map[string]rowChange)
+	count := 0
+	for rows.Next() {
+		count++
+		if count > overlapRowLimit {
+			return changes, true, nil
+		}
+		values := make([]interface{}, len(colNames))
+		ptrs := make([]interface{}, len(colNames))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, false, fmt.Errorf("failed to scan diff row: %w", err)
+		}
+
+		fromRow := make(map[string]interface{})
+		toRow := make(map[string]interface{})
+		for i, col := range colNames {
+			val := values[i]
+			if b, ok := val.([]byte); ok {
+				val = string(b)
+			}
+			switch {
+			case col == "to_commit" || col == "from_commit" || col == "to_commit_date" || col == "from_commit_date":
+			case strings.HasPrefix(col, "from_"):
+				fromRow[strings.TrimPrefix(col, "from_")] = val
+			case strings.HasPrefix(col, "to_"):
+				toRow[strings.TrimPrefix(col, "to_")] = val
+			}
+		}
+
+		pk := make(map[string]interface{}, len(pkCols))
+		key := pkValueKey(pkCols, func(col string) (string, bool) {
+			v := toRow[col]
+			if v == nil {
+				v = fromRow[col]
+			}
+			if v == nil {
+				return "", false
+			}
+			pk[col] = v
+			return fmt.Sprint(v), true
+		})
+		if key == "" {
+			continue
+		}
+
+		cells := make(map[string]bool)
+		for col, to := range toRow {
+			if _, isPK := pk[col]; !isPK && fmt.Sprint(fromRow[col]) != fmt.Sprint(to) {
+				cells[col] = true
+			}
+		}
+		changes[key] = rowChange{pk: pk, cells: cells}
+	}
+	return changes, false, rows.Err()
+}
+
+func intersectBranchChanges(mine, theirs branchChanges) []model.OverlapRow {
+	tables := make([]string, 0, len(theirs))
+	for table := range theirs {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	var rows []model.OverlapRow
+	for _, table := range tables {
+		keys := make([]string, 0)
+		for key := range theirs[table] {
+			if _, ok := mine[table][key]; ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			ours := mine[table][key]
+			cells := make([]string, 0)
+			for col := range theirs[table][key].cells {
+				if ours.cells[col] {
+					cells = append(cells, col)
+				}
+			}
+			sort.Strings(cells)
+			rows = append(rows, model.OverlapRow{Table: table, PK: ours.pk, Cells: cells})
+		}
+	}
+	return rows
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// overlapTestQueries serves wi/mine and wi/other editing the same items rows,
+// wi/quiet without changes, and a pending request for wi/other.
+func overlapTestQueries(refName, query string, args []driver.NamedValue) (testQueryResult, error) {
+	diffColumns := []string{"to_id", "to_name", "to_price", "to_commit", "from_id", "from_name", "from_price", "from_commit", "diff_type"}
+	switch {
+	case query == "SELECT COUNT(*) FROM dolt_branches WHERE name = ?":
+		return testQueryResult{columns: []string{"count"}, rows: [][]driver.Value{{int64(1)}}}, nil
+	case query == "SELECT name FROM dolt_branches WHERE name LIKE 'wi/%' ORDER BY name":
+		return testQueryResult{columns: []string{"name"}, rows: [][]driver.Value{{"wi/mine"}, {"wi/other"}, {"wi/quiet"}}}, nil
+	case query == "SELECT tag_name FROM dolt_tags WHERE tag_name LIKE 'req/%'":
+		return testQueryResult{columns: []string{"tag_name"}, rows: [][]driver.Value{{"req/other"}}}, nil
+	case strings.HasPrefix(query, "SELECT to_table_name FROM DOLT_DIFF_SUMMARY('main...wi/quiet')"):
+		return testQueryResult{columns: []string{"to_table_name"}}, nil
+	case strings.HasPrefix(query, "SELECT to_table_name FROM DOLT_DIFF_SUMMARY("):
+		return testQueryResult{columns: []string{"to_table_name"}, rows: [][]driver.Value{{"items"}}}, nil
+	case query == "SHOW COLUMNS FROM `items`":
+		return testQueryResult{
+			columns: []string{"Field", "Type", "Null", "Key", "Default", "Extra"},
+			rows: [][]driver.Value{
+				{"id", "int", "NO", "PRI", nil, ""},
+				{"name", "varchar(50)", "YES", "", nil, ""},
+				{"price", "int", "YES", "", nil, ""},
+			},
+		}, nil
+	case strings.HasPrefix(query, "SELECT * FROM DOLT_DIFF('main...wi/mine', 'items')"):
+		return testQueryResult{columns: diffColumns, rows: [][]driver.Value{
+			{int64(1), "a", int64(200), "c1", int64(1), "a", int64(100), "c0", "modified"},
+			{int64(2), "b2", int64(10), "c1", int64(2), "b", int64(10), "c0", "modified"},
+			{int64(3), "c", int64(5), "c1", nil, nil, nil, "c0", "added"},
+		}}, nil
+	case strings.HasPrefix(query, "SELECT * FROM DOLT_DIFF('main...wi/other', 'items')"):
+		return testQueryResult{columns: diffColumns, rows: [][]driver.Value{
+			{int64(1), "a", int64(300), "c2", int64(1), "a", int64(100), "c0", "modified"},
+			{int64(2), "b", int64(11), "c2", int64(2), "b", int64(10), "c0", "modified"},
+			{int64(9), "z", int64(1), "c2", int64(9), "y", int64(1), "c0", "modified"},
+		}}, nil
+	}
+	return testQueryResult{}, fmt.Errorf("unexpected query on %s: %s", refName, query)
+}
+
+func TestListOverlaps_ReportsSharedRowsAndCells(t *testing.T) {
+	svc := newWithDeps(newRecordingSessionRepo(t, overlapTestQueries), testServiceConfig())
+
+	resp, err := svc.ListOverlaps(context.Background(), "local", "test_db", "wi/mine")
+	if err != nil {
+		t.Fatalf("ListOverlaps: %v", err)
+	}
+	if len(resp.Overlaps) != 1 {
+		t.Fatalf("expected one overlapping branch, got %+v", resp.Overlaps)
+	}
+	overlap := resp.Overlaps[0]
+	if overlap.Branch != "wi/other" || overlap.RequestID != "req/other" || len(overlap.Rows) != 2 {
+		t.Fatalf("unexpected overlap: %+v", overlap)
+	}
+	cells := make(map[string]string)
+	for _, row := range overlap.Rows {
+		cells[fmt.Sprint(row.PK["id"])] = strings.Join(row.Cells, ",")
+	}
+	if cells["1"] != "price" || cells["2"] != "" {
+		t.Fatalf("unexpected overlapping cells: %v", cells)
+	}
+}
+
+func TestListOverlaps_RejectsNonWorkBranch(t *testing.T) {
+	repo := newRecordingSessionRepo(t, func(refName, query string, args []driver.NamedValue) (testQueryResult, error) {
+		return testQueryResult{}, fmt.Errorf("unexpected query: %s", query)
+	})
+	svc := newWithDeps(repo, testServiceConfig())
+
+	if _, err := svc.ListOverlaps(context.Background(), "local", "test_db", "main"); err == nil {
+		t.Fatal("expected main to be rejected")
+	}
+}
+
+func TestOverlapWarning_OnlyComparesBranchesWithPendingRequests(t *testing.T) {
+	var diffed []string
+	repo := newRecordingSessionRepo(t, func(refName, query string, args []driver.NamedValue) (testQueryResult, error) {
+		if strings.HasPrefix(query, "SELECT to_table_name FROM DOLT_DIFF_SUMMARY(") {
+			diffed = append(diffed, query)
+		}
+		return overlapTestQueries(refName, query, args)
+	})
+	svc := newWithDeps(repo, testServiceConfig())
+
+	overlaps, warning := svc.overlapWarning(context.Background(), "local", "test_db", "wi/mine")
+	if len(overlaps) != 1 || overlaps[0].Branch != "wi/other" || !strings.Contains(warning, "wi/other") {
+		t.Fatalf("unexpected overlaps: %+v / %q", overlaps, warning)
+	}
+	for _, query := range diffed {
+		if strings.Contains(query, "wi/quiet") {
+			t.Fatalf("a branch without a pending request must not be diffed on submit: %s", query)
+		}
+	}
+}
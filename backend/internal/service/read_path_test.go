@@ -32,8 +32,10 @@ type testConn struct {
 	handler testQueryHandler
 }
 
+// Prepare returns a statement that routes through the same handler as direct
+// queries, so prepared and unprepared paths observe identical SQL text.
 func (c *testConn) Prepare(query string) (driver.Stmt, error) {
-	return nil, fmt.Errorf("Prepare not supported in tests: %s", query)
+	return &testStmt{conn: c, query: query}, nil
 }
 
 func (c *testConn) Close() error {
@@ -60,6 +62,35 @@ func (c *testConn) ExecContext(ctx context.Context, query string, args []driver.
 	return driver.ResultNoRows, err
 }
 
+type testStmt struct {
+	conn  *testConn
+	query string
+}
+
+func (s *testStmt) Close() error {
+	return nil
+}
+
+func (s *testStmt) NumInput() int {
+	return -1
+}
+
+func (s *testStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, fmt.Errorf("Exec without context not supported in tests: %s", s.query)
+}
+
+func (s *testStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return nil, fmt.Errorf("Query without context not supported in tests: %s", s.query)
+}
+
+func (s *testStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return s.conn.ExecContext(ctx, s.query, args)
+}
+
+func (s *testStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.conn.QueryContext(ctx, s.query, args)
+}
+
 type testRows struct {
 	columns []string
 	rows    [][]driver.Value
@@ -172,6 +203,9 @@ func TestMetadataRead_UsesConnRevision_NotProtectedMaintenance(t *testing.T) {
 		if refName != "main" {
 			return testQueryResult{}, fmt.Errorf("unexpected ref: %s", refName)
 		}
+		if query == "SELECT tag_name, message FROM dolt_tags WHERE tag_name LIKE ?" {
+			return testQueryResult{columns: []string{"tag_name", "message"}}, nil
+		}
 		if query != "SELECT name, hash FROM dolt_branches" {
 			return testQueryResult{}, fmt.Errorf("unexpected query: %s", query)
 		}
@@ -268,6 +302,9 @@ func TestHistoryRead_UsesConnRevision_ForAllowedHistoryRef(t *testing.T) {
 				},
 			}, nil
 
+		case refName == "main" && query == "SELECT tag_name, message FROM dolt_tags WHERE tag_name LIKE ?":
+			return testQueryResult{columns: []string{"tag_name", "message"}}, nil
+
 		// B-PR2: Step 2 — dolt_log walk.
 		case refName == tagRef && strings.HasPrefix(query, "SELECT commit_hash, committer, date, message FROM dolt_log"):
 			return testQueryResult{
@@ -300,9 +337,11 @@ func TestHistoryRead_UsesConnRevision_ForAllowedHistoryRef(t *testing.T) {
 		t.Errorf("expected MergeBranch=wi/demo, got %s", commitsResp.Commits[0].MergeBranch)
 	}
 
+	// The last main session reads the work item registry to decorate the records.
 	wantCalls := []repoCall{
 		{method: "ConnRevision", ref: "main"},
 		{method: "ConnRevision", ref: tagRef},
+		{method: "ConnRevision", ref: "main"},
 	}
 	if fmt.Sprint(repo.calls) != fmt.Sprint(wantCalls) {
 		t.Fatalf("unexpected session calls: %v", repo.calls)
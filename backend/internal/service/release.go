@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+)
+
+const releaseTagPrefix = "release/"
+
+var releaseNameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// ListReleases returns release/* tags, newest first, each with the approvals it
+// added over the release created before it.
+func (s *Service) ListReleases(ctx context.Context, targetID, dbName string) ([]model.Release, error) {
+	conn, err := s.connMetadataRevision(ctx, targetID, dbName)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	rows, err := conn.QueryContext(ctx,
+		"SELECT tag_name, tag_hash, tagger, date, message FROM dolt_tags WHERE tag_name LIKE 'release/%' ORDER BY date DESC, tag_name DESC")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list releases: %w", err)
+	}
+	releases := make([]model.Release, 0)
+	for rows.Next() {
+		var r model.Release
+		if err := rows.Scan(&r.Tag, &r.Hash, &r.CreatedBy, &r.CreatedAt, &r.Notes); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan release: %w", err)
+		}
+		r.Name = strings.TrimPrefix(r.Tag, releaseTagPrefix)
+		releases = append(releases, r)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return nil, err
+	}
+	rows.Close()
+	if len(releases) == 0 {
+		return releases, nil
+	}
+
+	mergedTagIndex, err := buildMergedTagIndex(ctx, conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build merged/* tag index: %w", err)
+	}
+	for i := range releases {
+		previousTag := ""
+		if i+1 < len(releases) {
+			previousTag = releases[i+1].Tag
+			releases[i].PreviousRelease = releases[i+1].Name
+		}
+		approvals, err := releaseApprovals(ctx, conn, releases[i].Tag, previousTag, mergedTagIndex)
+		if err != nil {
+			return nil, err
+		}
+		releases[i].Approvals = approvals
+	}
+	return releases, nil
+}
+
+// releaseApprovals lists approval merges reachable from tag but not from previousTag.
+func releaseApprovals(ctx context.Context, conn *sql.Conn, tag, previousTag string, mergedTagIndex map[string]string) ([]model.HistoryCommit, error) {
+	query := "SELECT commit_hash, committer, date, message FROM dolt_log(?) ORDER BY date DESC"
+	args := []interface{}{tag}
+	if previousTag != "" {
+		query = "SELECT commit_hash, committer, date, message FROM dolt_log(?, '--not', ?) ORDER BY date DESC"
+		args = append(args, previousTag)
+	}
+	rows, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query release log for %s: %w", tag, err)
+	}
+	defer rows.Close()
+
+	approvals := make([]model.HistoryCommit, 0)
+	for rows.Next() {
+		var hash, committer, date, message string
+		if err := rows.Scan(&hash, &committer, &date, &message); err != nil {
+			return nil, fmt.Errorf("failed to scan release log: %w", err)
+		}
+		c, err := classifyApprovalCommit(hash, committer, date, message, mergedTagIndex)
+		if err != nil {
+			return nil, err
+		}
+		if c != nil {
+			approvals = append(approvals, *c)
+		}
+	}
+	return approvals, rows.Err()
+}
+
+// CreateRelease tags a commit on main as release/<name> with the notes as the
+// tag message. The commit defaults to the current main HEAD.
+func (s *Service) CreateRelease(ctx context.Context, req model.CreateReleaseRequest) (*model.Release, error) {
+	if !releaseNameRe.MatchString(req.Name) {
+		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument,
+			Msg: fmt.Sprintf("invalid release name: %q (use letters, digits, '.', '_' or '-')", req.Name)}
+	}
+	if req.CommitHash != "" && !commitHashRefRe.MatchString(req.CommitHash) {
+		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "invalid commit_hash"}
+	}
+	if _, err := s.configuredDatabase(req.TargetID, req.DBName); err != nil {
+		return nil, err
+	}
+
+	conn, err := s.repo.ConnProtectedMaintenance(ctx, req.TargetID, req.DBName, "main")
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close()
+
+	tagName := releaseTagPrefix + req.Name
+	var existing int
+	if err := conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM dolt_tags WHERE tag_name = ?", tagName).Scan(&existing); err != nil {
+		return nil, fmt.Errorf("failed to check release tag: %w", err)
+	}
+	if existing > 0 {
+		return nil, &model.APIError{
+			Status:  412,
+			Code:    model.CodePreconditionFailed,
+			Msg:     fmt.Sprintf("release %s already exists", req.Name),
+			Details: map[string]string{"reason": "release_exists"},
+		}
+	}
+
+	hash := req.CommitHash
+	if hash == "" {
+		if err := conn.QueryRowContext(ctx, "SELECT DOLT_HASHOF('main')").Scan(&hash); err != nil {
+			return nil, fmt.Errorf("failed to resolve main HEAD: %w", err)
+		}
+	} else {
+		var onMain int
+		if err := conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM dolt_log('main') WHERE commit_hash = ?", hash).Scan(&onMain); err != nil {
+			return nil, fmt.Errorf("failed to check commit on main: %w", err)
+		}
+		if onMain == 0 {
+			return nil, &model.APIError{Status: 404, Code: model.CodeNotFound, Msg: fmt.Sprintf("commit %s is not on main", hash)}
+		}
+	}
+
+	if _, err := conn.ExecContext(ctx, "CALL DOLT_TAG('-m', ?, ?, ?)", req.Notes, tagName, hash); err != nil {
+		return nil, fmt.Errorf("failed to create release tag: %w", err)
+	}
+
+	var release model.Release
+	if err := conn.QueryRowContext(ctx,
+		"SELECT tag_name, tag_hash, tagger, date, message FROM dolt_tags WHERE tag_name = ?", tagName,
+	).Scan(&release.Tag, &release.Hash, &release.CreatedBy, &release.CreatedAt, &release.Notes); err != nil {
+		return nil, fmt.Errorf("failed to read release tag: %w", err)
+	}
+	release.Name = req.Name
+	release.Approvals = make([]model.HistoryCommit, 0)
+	return &release, nil
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+)
+
+const releaseTestFooter = "承認マージ: 価格改定\n\nDolt-Approval-Schema: v1\nRequest-Id: req/price\nWork-Item: price\nWork-Branch: wi/price\nSubmitted-Work-Hash: abcdefghijklmnopqrstuvwxyz012345\nTicket-Id: JIRA-7"
+
+func TestListReleases_GroupsApprovalsSincePreviousRelease(t *testing.T) {
+	var logArgs [][]driver.NamedValue
+	repo := newRecordingSessionRepo(t, func(refName, query string, args []driver.NamedValue) (testQueryResult, error) {
+		logColumns := []string{"commit_hash", "committer", "date", "message"}
+		switch query {
+		case "SELECT tag_name, tag_hash, tagger, date, message FROM dolt_tags WHERE tag_name LIKE 'release/%' ORDER BY date DESC, tag_name DESC":
+			return testQueryResult{
+				columns: []string{"tag_name", "tag_hash", "tagger", "date", "message"},
+				rows: [][]driver.Value{
+					{"release/2026.04", "hash-r2", "alice", "2026-04-01 09:00:00", "April prices"},
+					{"release/2026.03", "hash-r1", "alice", "2026-03-01 09:00:00", "March prices"},
+				},
+			}, nil
+		case "SELECT tag_name, tag_hash FROM dolt_tags WHERE tag_name LIKE 'merged/%'":
+			return testQueryResult{
+				columns: []string{"tag_name", "tag_hash"},
+				rows:    [][]driver.Value{{"merged/legacy/01", "hash-legacy"}},
+			}, nil
+		case "SELECT commit_hash, committer, date, message FROM dolt_log(?, '--not', ?) ORDER BY date DESC":
+			logArgs = append(logArgs, args)
+			return testQueryResult{columns: logColumns, rows: [][]driver.Value{
+				{"hash-approval", "bob", "2026-03-20 10:00:00", releaseTestFooter},
+				{"hash-work", "carol", "2026-03-19 10:00:00", "edit prices"},
+			}}, nil
+		case "SELECT commit_hash, committer, date, message FROM dolt_log(?) ORDER BY date DESC":
+			logArgs = append(logArgs, args)
+			return testQueryResult{columns: logColumns, rows: [][]driver.Value{
+				{"hash-legacy", "bob", "2026-02-01 10:00:00", "Merge wi/legacy"},
+				{"hash-init", "root", "2026-01-01 10:00:00", "init"},
+			}}, nil
+		}
+		return testQueryResult{}, fmt.Errorf("unexpected query: %s", query)
+	})
+	svc := newWithDeps(repo, testServiceConfig())
+
+	releases, err := svc.ListReleases(context.Background(), "local", "test_db")
+	if err != nil {
+		t.Fatalf("ListReleases: %v", err)
+	}
+	if len(releases) != 2 {
+		t.Fatalf("expected 2 releases, got %+v", releases)
+	}
+	latest, first := releases[0], releases[1]
+	if latest.Name != "2026.04" || latest.PreviousRelease != "2026.03" || latest.Notes != "April prices" {
+		t.Fatalf("unexpected latest release: %+v", latest)
+	}
+	if len(latest.Approvals) != 1 || latest.Approvals[0].MergeBranch != "wi/price" ||
+		latest.Approvals[0].TicketID != "JIRA-7" || latest.Approvals[0].Message != "承認マージ: 価格改定" {
+		t.Fatalf("unexpected latest approvals: %+v", latest.Approvals)
+	}
+	if first.PreviousRelease != "" || len(first.Approvals) != 1 || first.Approvals[0].MergeBranch != "wi/legacy" {
+		t.Fatalf("unexpected first release: %+v", first)
+	}
+	if len(logArgs) != 2 || logArgs[0][0].Value != "release/2026.04" || logArgs[0][1].Value != "release/2026.03" {
+		t.Fatalf("unexpected dolt_log args: %v", logArgs)
+	}
+}
+
+func TestCreateRelease_TagsMainHead(t *testing.T) {
+	var tagArgs []driver.NamedValue
+	repo := newCrossCopyTestRepo(t, nil, nil)
+	repo.protectedHandler = func(dbName, branchName, query string, args []driver.NamedValue) (testQueryResult, error) {
+		switch query {
+		case "SELECT COUNT(*) FROM dolt_tags WHERE tag_name = ?":
+			return testQueryResult{columns: []string{"count"}, rows: [][]driver.Value{{int64(0)}}}, nil
+		case "SELECT DOLT_HASHOF('main')":
+			return testQueryResult{columns: []string{"hash"}, rows: [][]driver.Value{{"hash-main"}}}, nil
+		case "CALL DOLT_TAG('-m', ?, ?, ?)":
+			tagArgs = args
+			return testQueryResult{}, nil
+		case "SELECT tag_name, tag_hash, tagger, date, message FROM dolt_tags WHERE tag_name = ?":
+			return testQueryResult{
+				columns: []string{"tag_name", "tag_hash", "tagger", "date", "message"},
+				rows:    [][]driver.Value{{"release/v1", "hash-main", "alice", "2026-04-01 09:00:00", "first"}},
+			}, nil
+		}
+		return testQueryResult{}, fmt.Errorf("unexpected query: %s", query)
+	}
+	svc := newWithDeps(repo, testServiceConfig())
+
+	release, err := svc.CreateRelease(context.Background(), model.CreateReleaseRequest{
+		TargetID: "local", DBName: "test_db", Name: "v1", Notes: "first",
+	})
+	if err != nil {
+		t.Fatalf("CreateRelease: %v", err)
+	}
+	if release.Tag != "release/v1" || release.Hash != "hash-main" || release.Name != "v1" {
+		t.Fatalf("unexpected release: %+v", release)
+	}
+	if len(tagArgs) != 3 || tagArgs[0].Value != "first" || tagArgs[1].Value != "release/v1" || tagArgs[2].Value != "hash-main" {
+		t.Fatalf("unexpected DOLT_TAG args: %v", tagArgs)
+	}
+}
+
+func TestCreateRelease_RejectsDuplicateAndInvalidName(t *testing.T) {
+	repo := newCrossCopyTestRepo(t, nil, nil)
+	repo.protectedHandler = func(dbName, branchName, query string, args []driver.NamedValue) (testQueryResult, error) {
+		if query == "SELECT COUNT(*) FROM dolt_tags WHERE tag_name = ?" {
+			return testQueryResult{columns: []string{"count"}, rows: [][]driver.Value{{int64(1)}}}, nil
+		}
+		return testQueryResult{}, fmt.Errorf("unexpected query: %s", query)
+	}
+	svc := newWithDeps(repo, testServiceConfig())
+
+	_, err := svc.CreateRelease(context.Background(), model.CreateReleaseRequest{TargetID: "local", DBName: "test_db", Name: "v1"})
+	if apiErr, ok := err.(*model.APIError); !ok || apiErr.Code != model.CodePreconditionFailed {
+		t.Fatalf("expected PRECONDITION_FAILED for duplicate, got %v", err)
+	}
+
+	_, err = svc.CreateRelease(context.Background(), model.CreateReleaseRequest{TargetID: "local", DBName: "test_db", Name: "../v1"})
+	if apiErr, ok := err.(*model.APIError); !ok || apiErr.Code != model.CodeInvalidArgument {
+		t.Fatalf("expected INVALID_ARGUMENT for bad name, got %v", err)
+	}
+}
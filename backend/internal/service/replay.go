@@ -0,0 +1,666 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/footer"
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/validation"
+)
+
+// replayMaxRows caps the changed rows of one replayed approval.
+const replayMaxRows = 5000
+
+// replayBranchPrefix names the destination work branch of a replay.
+const replayBranchPrefix = "wi/replay-"
+
+// replayTableChanges is the row-level diff of one table in the approval.
+type replayTableChanges struct {
+	table   string
+	columns []string // user columns at the approval, in diff order
+	rows    []model.DiffRow
+}
+
+// replaySource is an approval merge commit and its row-level diff.
+type replaySource struct {
+	footer *approvalFooter
+	from   string // "<db>@<merge hash>" for the Replayed-From trailer
+	tables []replayTableChanges
+}
+
+// replayOp is one guarded write on the destination.
+type replayOp struct {
+	kind   string                 // "insert", "update" or "delete"
+	pk     map[string]interface{} // destination key
+	values map[string]interface{} // insert: every shared column; update: the changed columns
+}
+
+// replayTablePlan is the outcome of matching one table's changes against
+// the destination rows.
+type replayTablePlan struct {
+	result  model.ReplayTableResult
+	skipped []model.ReplaySkippedRow
+	ops     []replayOp
+}
+
+func validateReplayApprovalRequest(req model.ReplayApprovalRequest) error {
+	if err := validation.ValidateDBName(req.SourceDB); err != nil {
+		return &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "無効なソースDB名"}
+	}
+	if err := validation.ValidateDBName(req.DestDB); err != nil {
+		return &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "無効な宛先DB名"}
+	}
+	if !commitHashRefRe.MatchString(req.MergeHash) {
+		return &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "invalid merge_hash"}
+	}
+	if crossCopySourceTarget(req.TargetID, req.SourceTargetID) == req.TargetID && req.SourceDB == req.DestDB {
+		return &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "ソースDBと宛先DBが同じです"}
+	}
+	return nil
+}
+
+// loadReplaySource reads the approval footer of mergeHash on source main and
+// the row changes the merge brought in, i.e. the diff from its first parent.
+func (s *Service) loadReplaySource(ctx context.Context, sourceTarget, sourceLabel, sourceDB, mergeHash string) (*replaySource, error) {
+	conn, err := s.connMetadataRevision(ctx, sourceTarget, sourceDB)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var message string
+	err = conn.QueryRowContext(ctx, "SELECT message FROM dolt_log WHERE commit_hash = ? LIMIT 1", mergeHash).Scan(&message)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &model.APIError{Status: 404, Code: model.CodeNotFound, Msg: fmt.Sprintf("commit %s is not on main", mergeHash)}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit %s: %w", mergeHash, err)
+	}
+	f, err := parseApprovalFooter(message)
+	if err != nil {
+		return nil, fmt.Errorf("invalid approval footer on %s: %w", mergeHash, err)
+	}
+	if f == nil {
+		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: fmt.Sprintf("コミット %s は承認マージではありません", mergeHash)}
+	}
+
+	// Per v6f spec 1.4: DOLT_DIFF literal constraint - embed validated tokens
+	rows, err := conn.QueryContext(ctx, fmt.Sprintf(
+		"SELECT table_name, rows_added, rows_modified, rows_deleted FROM DOLT_DIFF_STAT('%s^', '%s')", mergeHash, mergeHash))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get approval diff: %w", err)
+	}
+	var tables []string
+	total := 0
+	for rows.Next() {
+		var table string
+		var added, modified, removed int
+		if err := rows.Scan(&table, &added, &modified, &removed); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan approval diff: %w", err)
+		}
+		if isHiddenTableName(table) {
+			continue
+		}
+		tables = append(tables, table)
+		total += added + modified + removed
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read approval diff: %w", err)
+	}
+	if total > replayMaxRows {
+		return nil, &model.APIError{
+			Status: 400,
+			Code:   model.CodeInvalidArgument,
+			Msg:    fmt.Sprintf("承認の変更行数 %d が再適用の上限 %d 件を超えています", total, replayMaxRows),
+		}
+	}
+	sort.Strings(tables)
+
+	src := &replaySource{footer: f, from: sourceLabel + "@" + mergeHash}
+	for _, table := range tables {
+		changes, err := readReplayTableChanges(ctx, conn, mergeHash, table)
+		if err != nil {
+			return nil, err
+		}
+		src.tables = append(src.tables, changes)
+	}
+	return src, nil
+}
+
+// readReplayTableChanges reads the diff rows of table between the merge and
+// its first parent. The commit metadata columns of DOLT_DIFF are dropped.
+func readReplayTableChanges(ctx context.Context, conn *sql.Conn, mergeHash, table string) (replayTableChanges, error) {
+	changes := replayTableChanges{table: table}
+	rows, err := conn.QueryContext(ctx, fmt.Sprintf("SELECT * FROM DOLT_DIFF('%s^..%s', '%s')", mergeHash, mergeHash, table))
+	if err != nil {
+		return changes, fmt.Errorf("failed to query approval diff of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	colNames, err := rows.Columns()
+	if err != nil {
+		return changes, fmt.Errorf("failed to read diff columns of %s: %w", table, err)
+	}
+	for _, c := range colNames {
+		if name, ok := strings.CutPrefix(c, "to_"); ok && name != "commit" && name != "commit_date" {
+			changes.columns = append(changes.columns, name)
+		}
+	}
+	for rows.Next() {
+		values := make([]interface{}, len(colNames))
+		ptrs := make([]interface{}, len(colNames))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return changes, fmt.Errorf("failed to scan diff row of %s: %w", table, err)
+		}
+		row := model.DiffRow{From: make(map[string]interface{}), To: make(map[string]interface{})}
+		for i, col := range colNames {
+			val := values[i]
+			if b, ok := val.([]byte); ok {
+				val = string(b)
+			}
+			switch {
+			case col == "diff_type":
+				row.DiffType, _ = val.(string)
+			case col == "from_commit" || col == "from_commit_date" || col == "to_commit" || col == "to_commit_date":
+			case strings.HasPrefix(col, "from_"):
+				row.From[strings.TrimPrefix(col, "from_")] = val
+			case strings.HasPrefix(col, "to_"):
+				row.To[strings.TrimPrefix(col, "to_")] = val
+			}
+		}
+		changes.rows = append(changes.rows, row)
+	}
+	if err := rows.Err(); err != nil {
+		return changes, fmt.Errorf("failed to read diff rows of %s: %w", table, err)
+	}
+	return changes, nil
+}
+
+// replayValueEqual compares a source diff value with a destination value.
+// Both sides are compared in their text form: the diff and the destination
+// lookup may be read over different MySQL protocols.
+func replayValueEqual(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+// replayDiffering returns the columns whose destination value differs from want.
+func replayDiffering(cols []string, dest, want map[string]interface{}) []string {
+	var differing []string
+	for _, c := range cols {
+		if !replayValueEqual(want[c], dest[c]) {
+			differing = append(differing, c)
+		}
+	}
+	return differing
+}
+
+// planReplayTable matches the changes of one table against the destination
+// rows read through conn. Only shared columns are compared and written:
+//   - added: inserted unless the key exists; an identical row is unchanged
+//   - modified: the changed columns are updated when the destination still
+//     holds the source value before the approval (or already the new value)
+//   - removed: deleted when the destination row still equals the source row
+//     before the approval; an absent row is unchanged
+func planReplayTable(ctx context.Context, conn *sql.Conn, changes replayTableChanges, dstCols []model.ColumnSchema) (*replayTablePlan, error) {
+	dstByName := make(map[string]bool, len(dstCols))
+	for _, c := range dstCols {
+		dstByName[c.Name] = true
+	}
+	var shared []string
+	for _, c := range changes.columns {
+		if dstByName[c] {
+			shared = append(shared, c)
+		}
+	}
+	pkCols := getPKColumns(dstCols)
+
+	plan := &replayTablePlan{result: model.ReplayTableResult{Table: changes.table}}
+	keyRows := make([]map[string]interface{}, len(changes.rows))
+	pkJSONs := make([]string, len(changes.rows))
+	for i, row := range changes.rows {
+		keyRows[i] = row.To
+		if row.DiffType == "removed" {
+			keyRows[i] = row.From
+		}
+		pkJSON, ok := destPKJSON(keyRows[i], pkCols)
+		if !ok {
+			return nil, &model.APIError{
+				Status: 400,
+				Code:   model.CodeInvalidArgument,
+				Msg:    fmt.Sprintf("テーブル %s: 宛先の主キー (%s) がソースにありません", changes.table, strings.Join(pkCols, ", ")),
+			}
+		}
+		pkJSONs[i] = pkJSON
+	}
+	existing, err := fetchRowsByPKs(ctx, conn, changes.table, pkCols, pkJSONs, shared)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, row := range changes.rows {
+		pk := make(map[string]interface{}, len(pkCols))
+		for _, c := range pkCols {
+			pk[c] = keyRows[i][c]
+		}
+		skip := func(reason string, cols []string) {
+			plan.result.Skipped++
+			plan.skipped = append(plan.skipped, model.ReplaySkippedRow{
+				Table: changes.table, DiffType: row.DiffType, PK: pk, Reason: reason, Columns: cols,
+			})
+		}
+		dest, found := existing[pkJSONs[i]]
+
+		switch row.DiffType {
+		case "added":
+			if found {
+				if differing := replayDiffering(shared, dest, row.To); len(differing) > 0 {
+					skip(model.ReplaySkipPKExists, differing)
+				} else {
+					plan.result.Unchanged++
+				}
+				continue
+			}
+			values := make(map[string]interface{}, len(shared))
+			for _, c := range shared {
+				values[c] = row.To[c]
+			}
+			plan.ops = append(plan.ops, replayOp{kind: "insert", pk: pk, values: values})
+			plan.result.Inserted++
+
+		case "removed":
+			if !found {
+				plan.result.Unchanged++
+				continue
+			}
+			if differing := replayDiffering(shared, dest, row.From); len(differing) > 0 {
+				skip(model.ReplaySkipBaseChanged, differing)
+				continue
+			}
+			plan.ops = append(plan.ops, replayOp{kind: "delete", pk: pk})
+			plan.result.Deleted++
+
+		default:
+			var changed []string
+			for _, c := range shared {
+				if !replayValueEqual(row.From[c], row.To[c]) {
+					changed = append(changed, c)
+				}
+			}
+			if len(changed) == 0 {
+				plan.result.Unchanged++
+				continue
+			}
+			if !found {
+				skip(model.ReplaySkipMissingPK, nil)
+				continue
+			}
+			var conflicts []string
+			values := make(map[string]interface{})
+			for _, c := range changed {
+				switch {
+				case replayValueEqual(dest[c], row.To[c]):
+				case replayValueEqual(dest[c], row.From[c]):
+					values[c] = row.To[c]
+				default:
+					conflicts = append(conflicts, c)
+				}
+			}
+			if len(conflicts) > 0 {
+				skip(model.ReplaySkipBaseChanged, conflicts)
+				continue
+			}
+			if len(values) == 0 {
+				plan.result.Unchanged++
+				continue
+			}
+			plan.ops = append(plan.ops, replayOp{kind: "update", pk: pk, values: values})
+			plan.result.Updated++
+		}
+	}
+	return plan, nil
+}
+
+// execReplayOp applies one planned write.
+func execReplayOp(ctx context.Context, conn *sql.Conn, table string, op replayOp) error {
+	pkCols := sortedMapKeys(op.pk)
+	where := make([]string, len(pkCols))
+	whereArgs := make([]interface{}, len(pkCols))
+	for i, c := range pkCols {
+		where[i] = fmt.Sprintf("`%s` = ?", c)
+		whereArgs[i] = op.pk[c]
+	}
+	cols := sortedMapKeys(op.values)
+
+	var query string
+	var args []interface{}
+	switch op.kind {
+	case "insert":
+		quoted := make([]string, len(cols))
+		for i, c := range cols {
+			quoted[i] = fmt.Sprintf("`%s`", c)
+			args = append(args, op.values[c])
+		}
+		query = fmt.Sprintf("INSERT INTO `%s` (%s) VALUES (%s)",
+			table, strings.Join(quoted, ", "), strings.TrimSuffix(strings.Repeat("?, ", len(cols)), ", "))
+	case "update":
+		sets := make([]string, len(cols))
+		for i, c := range cols {
+			sets[i] = fmt.Sprintf("`%s` = ?", c)
+			args = append(args, op.values[c])
+		}
+		query = fmt.Sprintf("UPDATE `%s` SET %s WHERE %s", table, strings.Join(sets, ", "), strings.Join(where, " AND "))
+		args = append(args, whereArgs...)
+	default:
+		query = fmt.Sprintf("DELETE FROM `%s` WHERE %s", table, strings.Join(where, " AND "))
+		args = whereArgs
+	}
+	_, err := conn.ExecContext(ctx, query, args...)
+	return err
+}
+
+// planReplay matches every changed table against the destination read
+// through conn and returns the plans in destination foreign key order.
+// Tables missing from the destination come last and are reported as warnings.
+func planReplay(ctx context.Context, conn *sql.Conn, src *replaySource) ([]string, map[string]*replayTablePlan, []string, error) {
+	plans := make(map[string]*replayTablePlan, len(src.tables))
+	warnings := make([]string, 0)
+	var tables, missing []string
+	for _, changes := range src.tables {
+		dstCols, err := getSchemaColumns(ctx, conn, changes.table)
+		var apiErr *model.APIError
+		if errors.As(err, &apiErr) && apiErr.Code == model.CodeNotFound {
+			warnings = append(warnings, fmt.Sprintf("テーブル %s は宛先にないため %d 行をスキップしました", changes.table, len(changes.rows)))
+			plans[changes.table] = &replayTablePlan{result: model.ReplayTableResult{Table: changes.table, Skipped: len(changes.rows)}}
+			missing = append(missing, changes.table)
+			continue
+		}
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		plan, err := planReplayTable(ctx, conn, changes, dstCols)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		plans[changes.table] = plan
+		tables = append(tables, changes.table)
+	}
+
+	refs, err := loadForeignKeys(ctx, conn)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	order, err := orderTablesByForeignKeys(tables, refs)
+	if err != nil {
+		// A cycle only affects statement order; constraints are still
+		// verified before the commit.
+		order = tables
+	}
+	return append(order, missing...), plans, warnings, nil
+}
+
+func replayResults(order []string, plans map[string]*replayTablePlan) ([]model.ReplayTableResult, []model.ReplaySkippedRow, int) {
+	results := make([]model.ReplayTableResult, 0, len(order))
+	skipped := make([]model.ReplaySkippedRow, 0)
+	applied := 0
+	for _, table := range order {
+		plan := plans[table]
+		results = append(results, plan.result)
+		skipped = append(skipped, plan.skipped...)
+		applied += len(plan.ops)
+	}
+	return results, skipped, applied
+}
+
+// replayedWarning notes a previous replay of the same approval on destination main.
+func replayedWarning(ctx context.Context, conn *sql.Conn, from string) string {
+	rows, err := conn.QueryContext(ctx, "SELECT commit_hash, message FROM dolt_log WHERE message LIKE ? LIMIT 20", "%Replayed-From: "+from+"%")
+	if err != nil {
+		log.Printf("WARN: replay history check failed: from=%s error=%v", from, err)
+		return ""
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var hash, message string
+		if err := rows.Scan(&hash, &message); err != nil {
+			return ""
+		}
+		if f, err := footer.ParseReplayFooter(message); err == nil && f != nil && f.ReplayedFrom == from {
+			return fmt.Sprintf("この承認は既に再適用されています（%s）", hash)
+		}
+	}
+	return ""
+}
+
+func (s *Service) prepareReplay(ctx context.Context, req model.ReplayApprovalRequest) (*replaySource, string, error) {
+	if err := validateReplayApprovalRequest(req); err != nil {
+		return nil, "", err
+	}
+	sourceTarget := crossCopySourceTarget(req.TargetID, req.SourceTargetID)
+	src, err := s.loadReplaySource(ctx, sourceTarget, crossCopySourceLabel(req.TargetID, req.SourceTargetID, req.SourceDB), req.SourceDB, req.MergeHash)
+	if err != nil {
+		return nil, "", err
+	}
+	branchName := replayBranchPrefix + src.footer.WorkItem
+	if !workBranchNameRe.MatchString(branchName) {
+		return nil, "", &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: fmt.Sprintf("invalid replay branch name: %s", branchName)}
+	}
+	return src, branchName, nil
+}
+
+// ReplayApprovalPreview reports what replaying an approval would apply to
+// the current destination main, without creating the branch.
+func (s *Service) ReplayApprovalPreview(ctx context.Context, req model.ReplayApprovalRequest) (*model.ReplayApprovalPreviewResponse, error) {
+	src, branchName, err := s.prepareReplay(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := s.connMetadataRevision(ctx, req.TargetID, req.DestDB)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	order, plans, warnings, err := planReplay(ctx, conn, src)
+	if err != nil {
+		return nil, err
+	}
+	if w := replayedWarning(ctx, conn, src.from); w != "" {
+		warnings = append(warnings, w)
+	}
+	tables, skipped, _ := replayResults(order, plans)
+	return &model.ReplayApprovalPreviewResponse{
+		BranchName: branchName,
+		RequestID:  src.footer.RequestID,
+		WorkItem:   src.footer.WorkItem,
+		Tables:     tables,
+		Skipped:    skipped,
+		Warnings:   warnings,
+	}, nil
+}
+
+func replayFailureResponse(branchName string, src *replaySource, cleanupErr error) *model.ReplayApprovalResponse {
+	single := crossCopyTableFailureResponse(branchName, nil, nil, nil, cleanupErr)
+	return &model.ReplayApprovalResponse{
+		BranchName:            single.BranchName,
+		RequestID:             src.footer.RequestID,
+		WorkItem:              src.footer.WorkItem,
+		OperationResultFields: single.OperationResultFields,
+	}
+}
+
+// ReplayApproval applies the row changes of an approval merge commit of the
+// source DB to a new wi/replay-<WorkItem> branch of the destination DB in one
+// commit. Rows whose destination state no longer matches the source before
+// the approval are skipped and reported. The commit records the source as a
+// Replayed-From trailer.
+func (s *Service) ReplayApproval(ctx context.Context, req model.ReplayApprovalRequest) (*model.ReplayApprovalResponse, error) {
+	src, branchName, err := s.prepareReplay(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.createImportBranch(ctx, req.TargetID, req.DestDB, branchName); err != nil {
+		return nil, err
+	}
+
+	cleanupIfNeeded := func() error {
+		cleanupErr := s.cleanupCrossCopyBranch(context.Background(), req.TargetID, req.DestDB, branchName)
+		if cleanupErr != nil {
+			log.Printf("WARN: %v", cleanupErr)
+		}
+		return cleanupErr
+	}
+
+	readiness := s.branchReadiness(ctx, req.TargetID, req.DestDB, branchName)
+	if !readiness.Ready {
+		logBranchQueryabilityFailure("replay_branch_not_ready", req.TargetID, req.DestDB, branchName, readiness)
+		return replayFailureResponse(branchName, src, cleanupIfNeeded()), nil
+	}
+
+	dstConn, err := s.repo.ConnWorkBranchWrite(ctx, req.TargetID, req.DestDB, branchName)
+	if err != nil {
+		return replayFailureResponse(branchName, src, cleanupIfNeeded()), nil
+	}
+	defer dstConn.Close()
+
+	if _, err := dstConn.ExecContext(ctx, "START TRANSACTION"); err != nil {
+		return replayFailureResponse(branchName, src, cleanupIfNeeded()), nil
+	}
+	order, plans, warnings, err := planReplay(ctx, dstConn, src)
+	if err != nil {
+		safeRollback(dstConn)
+		if cleanupErr := cleanupIfNeeded(); cleanupErr != nil {
+			return replayFailureResponse(branchName, src, cleanupErr), nil
+		}
+		return nil, err
+	}
+	if w := replayedWarning(ctx, dstConn, src.from); w != "" {
+		warnings = append(warnings, w)
+	}
+
+	// failWrite rolls back a failed write. A classified copy error is
+	// returned as such once the branch is gone.
+	failWrite := func(table string, err error) (*model.ReplayApprovalResponse, error) {
+		safeRollback(dstConn)
+		cleanupErr := cleanupIfNeeded()
+		if apiErr := parseCopyError(err); apiErr != nil && cleanupErr == nil {
+			apiErr.Msg = fmt.Sprintf("テーブル %s: %s", table, apiErr.Msg)
+			return nil, apiErr
+		}
+		return replayFailureResponse(branchName, src, cleanupErr), nil
+	}
+	// Deletes run children first, inserts and updates parents first.
+	for i := len(order) - 1; i >= 0; i-- {
+		for _, op := range plans[order[i]].ops {
+			if op.kind != "delete" {
+				continue
+			}
+			if err := execReplayOp(ctx, dstConn, order[i], op); err != nil {
+				return failWrite(order[i], err)
+			}
+		}
+	}
+	for _, table := range order {
+		for _, op := range plans[table].ops {
+			if op.kind == "delete" {
+				continue
+			}
+			if err := execReplayOp(ctx, dstConn, table, op); err != nil {
+				return failWrite(table, err)
+			}
+		}
+	}
+
+	if _, err := dstConn.ExecContext(ctx, "CALL DOLT_VERIFY_CONSTRAINTS()"); err != nil {
+		safeRollback(dstConn)
+		return replayFailureResponse(branchName, src, cleanupIfNeeded()), nil
+	}
+	var violationCount int
+	if err := dstConn.QueryRowContext(ctx, "SELECT COUNT(*) FROM dolt_constraint_violations").Scan(&violationCount); err == nil && violationCount > 0 {
+		safeRollback(dstConn)
+		cleanupErr := cleanupIfNeeded()
+		if cleanupErr != nil {
+			return replayFailureResponse(branchName, src, cleanupErr), nil
+		}
+		return nil, &model.APIError{Status: 400, Code: model.CodeCopyFKError, Msg: "制約違反が検出されました"}
+	}
+
+	tables, skipped, applied := replayResults(order, plans)
+	if _, err := dstConn.ExecContext(ctx, "CALL DOLT_ADD('.')"); err != nil {
+		safeRollback(dstConn)
+		return replayFailureResponse(branchName, src, cleanupIfNeeded()), nil
+	}
+	commitMsg := footer.BuildReplayFooter(
+		fmt.Sprintf("[replay] %sの承認 %s を再適用（%d行、スキップ%d行）",
+			crossCopySourceLabel(req.TargetID, req.SourceTargetID, req.SourceDB), src.footer.WorkItem, applied, len(skipped)),
+		footer.ReplayFooter{
+			Schema:       "v1",
+			ReplayedFrom: src.from,
+			WorkItem:     src.footer.WorkItem,
+			Applied:      applied,
+			Skipped:      len(skipped),
+		},
+	)
+	if _, err := dstConn.ExecContext(ctx, "CALL DOLT_COMMIT('--allow-empty', '-m', ?)", commitMsg); err != nil {
+		safeRollback(dstConn)
+		return replayFailureResponse(branchName, src, cleanupIfNeeded()), nil
+	}
+	if _, err := dstConn.ExecContext(ctx, "COMMIT"); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	resp := &model.ReplayApprovalResponse{
+		BranchName: branchName,
+		RequestID:  src.footer.RequestID,
+		WorkItem:   src.footer.WorkItem,
+		Tables:     tables,
+		Skipped:    skipped,
+	}
+	retry := func(message, retryReason, label string) *model.ReplayApprovalResponse {
+		single := crossCopyTableRetryResponse(branchName, resp.Hash, applied, nil, nil, nil, message, retryReason,
+			[]model.RetryAction{{Action: "open_import_branch", Label: label}})
+		resp.OperationResultFields = single.OperationResultFields
+		resp.Warnings = warnings
+		return resp
+	}
+	if err := dstConn.QueryRowContext(ctx, "SELECT DOLT_HASHOF('HEAD')").Scan(&resp.Hash); err != nil {
+		return retry("再適用コミットは作成された可能性がありますが、確認に失敗しました。再適用ブランチを確認して再試行してください。",
+			"destination_commit_uncertain", "再適用ブランチを確認する"), nil
+	}
+
+	readiness = s.branchReadiness(ctx, req.TargetID, req.DestDB, branchName)
+	if !readiness.Ready {
+		logBranchQueryabilityFailure("replay_committed_branch_not_ready", req.TargetID, req.DestDB, branchName, readiness)
+		return retry("再適用コミットは作成されましたが、再適用ブランチの接続反映を確認できませんでした。時間をおいて開き直してください。",
+			"destination_branch_not_ready", "再適用ブランチを開き直す"), nil
+	}
+
+	message := "承認を他DBへ再適用しました"
+	if len(skipped) > 0 {
+		message = fmt.Sprintf("承認を他DBへ再適用しました（%d行は適用できませんでした）", len(skipped))
+	}
+	resp.OperationResultFields = model.OperationResultFields{
+		Outcome:  model.OperationOutcomeCompleted,
+		Message:  message,
+		Warnings: warnings,
+		Completion: map[string]bool{
+			"destination_committed":    true,
+			"destination_branch_ready": true,
+			"protected_refs_clean":     true,
+		},
+	}
+	return resp, nil
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/footer"
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+)
+
+func TestReplayApproval_AppliesSharedColumnsAndReportsConflicts(t *testing.T) {
+	var writes [][]interface{}
+	var commitMsg string
+	approvalMsg := buildApprovalFooter("承認: ProjectA", validFooter())
+	repo := newCrossCopyTestRepo(t,
+		func(dbName, refName, query string, args []driver.NamedValue) (testQueryResult, error) {
+			switch {
+			case dbName == "master_db" && query == "SELECT message FROM dolt_log WHERE commit_hash = ? LIMIT 1":
+				if args[0].Value == validHash2 {
+					return testQueryResult{columns: []string{"message"}, rows: [][]driver.Value{{"normal commit"}}}, nil
+				}
+				return testQueryResult{columns: []string{"message"}, rows: [][]driver.Value{{approvalMsg}}}, nil
+			case dbName == "master_db" && strings.HasPrefix(query, "SELECT table_name, rows_added, rows_modified, rows_deleted FROM DOLT_DIFF_STAT"):
+				return testQueryResult{
+					columns: []string{"table_name", "rows_added", "rows_modified", "rows_deleted"},
+					rows:    [][]driver.Value{{"users", int64(1), int64(3), int64(1)}, {"_memo_users", int64(1), int64(0), int64(0)}},
+				}, nil
+			case dbName == "master_db" && query == fmt.Sprintf("SELECT * FROM DOLT_DIFF('%s^..%s', 'users')", validHash, validHash):
+				return testQueryResult{
+					columns: []string{"to_id", "to_name", "to_commit", "to_commit_date", "from_id", "from_name", "from_commit", "from_commit_date", "diff_type"},
+					rows: [][]driver.Value{
+						{int64(10), "New", "c", nil, nil, nil, "p", nil, "added"},
+						{int64(1), "Alicia", "c", nil, int64(1), "Alice", "p", nil, "modified"},
+						{int64(2), "Robert", "c", nil, int64(2), "Bob", "p", nil, "modified"},
+						{int64(3), "Kat", "c", nil, int64(3), "Cat", "p", nil, "modified"},
+						{nil, nil, "c", nil, int64(4), "Dan", "p", nil, "removed"},
+					},
+				}, nil
+			case dbName == "test_db" && query == "SELECT COUNT(*) FROM dolt_branches WHERE name = ?":
+				if args[0].Value != "wi/replay-ProjectA" {
+					return testQueryResult{}, fmt.Errorf("unexpected branch %v", args[0].Value)
+				}
+				return testQueryResult{columns: []string{"count(*)"}, rows: [][]driver.Value{{int64(0)}}}, nil
+			case dbName == "test_db" && query == "CALL DOLT_BRANCH(?)":
+				return testQueryResult{}, nil
+			}
+			return testQueryResult{}, fmt.Errorf("unexpected revision query on %s/%s: %s", dbName, refName, query)
+		},
+		func(dbName, branchName, query string, args []driver.NamedValue) (testQueryResult, error) {
+			switch {
+			case query == "SHOW COLUMNS FROM `users`":
+				return schemaResult([]model.ColumnSchema{
+					{Name: "id", Type: "int", PrimaryKey: true},
+					{Name: "name", Type: "varchar(50)", Nullable: true},
+					{Name: "memo", Type: "text", Nullable: true},
+				}), nil
+			case query == "SELECT `id`, `name` FROM `users` WHERE `id` = ? LIMIT 1":
+				dest := map[string]string{"1": "Alice", "2": "Bobby", "4": "Dan"}
+				id := fmt.Sprint(args[0].Value)
+				if name, ok := dest[id]; ok {
+					return testQueryResult{columns: []string{"id", "name"}, rows: [][]driver.Value{{id, name}}}, nil
+				}
+				return testQueryResult{columns: []string{"id", "name"}}, nil
+			case query == "SELECT COUNT(*) FROM dolt_constraint_violations":
+				return testQueryResult{columns: []string{"count"}, rows: [][]driver.Value{{int64(0)}}}, nil
+			case query == "SELECT DOLT_HASHOF('HEAD')":
+				return testQueryResult{columns: []string{"hash"}, rows: [][]driver.Value{{"head"}}}, nil
+			case strings.HasPrefix(query, "CALL DOLT_COMMIT"):
+				commitMsg = fmt.Sprint(args[0].Value)
+			case strings.HasPrefix(query, "INSERT INTO "), strings.HasPrefix(query, "UPDATE "), strings.HasPrefix(query, "DELETE FROM "):
+				write := []interface{}{query}
+				for _, a := range args {
+					write = append(write, fmt.Sprint(a.Value))
+				}
+				writes = append(writes, write)
+			}
+			return testQueryResult{}, nil
+		},
+	)
+	svc := newWithDeps(repo, testTwoTargetConfig())
+	svc.branchReadinessProbe = func(ctx context.Context, targetID, dbName, branch string) branchQueryabilityResult {
+		return branchQueryabilityResult{Ready: true, Attempts: 1}
+	}
+	req := model.ReplayApprovalRequest{
+		TargetID:       "local",
+		SourceTargetID: "staging",
+		SourceDB:       "master_db",
+		MergeHash:      validHash,
+		DestDB:         "test_db",
+	}
+
+	resp, err := svc.ReplayApproval(context.Background(), req)
+	if err != nil {
+		t.Fatalf("ReplayApproval: %v", err)
+	}
+	if resp.Outcome != model.OperationOutcomeCompleted || resp.BranchName != "wi/replay-ProjectA" || resp.RequestID != "req/ProjectA" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	want := []model.ReplayTableResult{{Table: "users", Inserted: 1, Updated: 1, Deleted: 1, Skipped: 2}}
+	if !reflect.DeepEqual(resp.Tables, want) {
+		t.Fatalf("tables = %+v, want %+v", resp.Tables, want)
+	}
+	if len(resp.Skipped) != 2 ||
+		resp.Skipped[0].Reason != model.ReplaySkipBaseChanged || !reflect.DeepEqual(resp.Skipped[0].Columns, []string{"name"}) ||
+		resp.Skipped[1].Reason != model.ReplaySkipMissingPK || fmt.Sprint(resp.Skipped[1].PK["id"]) != "3" {
+		t.Fatalf("unexpected skipped rows: %+v", resp.Skipped)
+	}
+
+	wantWrites := [][]interface{}{
+		{"DELETE FROM `users` WHERE `id` = ?", "4"},
+		{"INSERT INTO `users` (`id`, `name`) VALUES (?, ?)", "10", "New"},
+		{"UPDATE `users` SET `name` = ? WHERE `id` = ?", "Alicia", "1"},
+	}
+	if !reflect.DeepEqual(writes, wantWrites) {
+		t.Fatalf("writes = %v, want %v", writes, wantWrites)
+	}
+	f, err := footer.ParseReplayFooter(commitMsg)
+	if err != nil || f == nil {
+		t.Fatalf("expected a replay footer in %q: %v", commitMsg, err)
+	}
+	if f.ReplayedFrom != "staging:master_db@"+validHash || f.WorkItem != "ProjectA" || f.Applied != 3 || f.Skipped != 2 {
+		t.Fatalf("unexpected replay footer: %+v", f)
+	}
+
+	req.MergeHash = validHash2
+	_, err = svc.ReplayApproval(context.Background(), req)
+	if apiErr, ok := err.(*model.APIError); !ok || apiErr.Code != model.CodeInvalidArgument {
+		t.Fatalf("expected a commit without approval footer to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,464 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/config"
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/validation"
+)
+
+// replicationHistoryLimit caps how many runs are kept per remote for display.
+const replicationHistoryLimit = 20
+
+// Replication actions.
+const (
+	replicationActionSync  = "sync"
+	replicationActionPush  = "push"
+	replicationActionFetch = "fetch"
+)
+
+// replicationTagPrefix marks persisted run history. The tag repl/<remote> on
+// main carries replicationRecord as JSON, so the history and last success of a
+// remote survive a restart; StartReplicationScheduler loads them back.
+const replicationTagPrefix = "repl/"
+
+const replicationRecordSchema = "dolt-webui/replication@1"
+
+type replicationRecord struct {
+	Schema        string                 `json:"schema"`
+	LastSuccessAt string                 `json:"last_success_at,omitempty"`
+	History       []model.ReplicationRun `json:"history"`
+}
+
+func replicationTag(remote string) string {
+	return replicationTagPrefix + remote
+}
+
+// replicationRegistry caches run history per remote and serializes runs per
+// database. Every recorded run is also written to the remote's tag.
+type replicationRegistry struct {
+	mu     sync.Mutex
+	states map[string]*replicationState
+	locks  map[string]*sync.Mutex
+}
+
+type replicationState struct {
+	lastSuccessAt string
+	history       []model.ReplicationRun // newest first
+}
+
+func newReplicationRegistry() *replicationRegistry {
+	return &replicationRegistry{
+		states: make(map[string]*replicationState),
+		locks:  make(map[string]*sync.Mutex),
+	}
+}
+
+func (r *replicationRegistry) dbLock(targetID, dbName string) *sync.Mutex {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := targetID + "/" + dbName
+	lock, ok := r.locks[key]
+	if !ok {
+		lock = &sync.Mutex{}
+		r.locks[key] = lock
+	}
+	return lock
+}
+
+// record adds run to the remote's history and returns the record to persist.
+func (r *replicationRegistry) record(targetID, dbName string, run model.ReplicationRun) replicationRecord {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := targetID + "/" + dbName + "/" + run.Remote
+	state, ok := r.states[key]
+	if !ok {
+		state = &replicationState{}
+		r.states[key] = state
+	}
+	if run.Status == model.ReplicationSucceeded {
+		state.lastSuccessAt = run.FinishedAt
+	}
+	state.history = append([]model.ReplicationRun{run}, state.history...)
+	if len(state.history) > replicationHistoryLimit {
+		state.history = state.history[:replicationHistoryLimit]
+	}
+	return replicationRecord{
+		Schema:        replicationRecordSchema,
+		LastSuccessAt: state.lastSuccessAt,
+		History:       append([]model.ReplicationRun(nil), state.history...),
+	}
+}
+
+// restore seeds a remote's state from its persisted record unless a run was
+// already recorded in this process.
+func (r *replicationRegistry) restore(targetID, dbName, remote string, record replicationRecord) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := targetID + "/" + dbName + "/" + remote
+	if _, ok := r.states[key]; ok {
+		return
+	}
+	history := record.History
+	if len(history) > replicationHistoryLimit {
+		history = history[:replicationHistoryLimit]
+	}
+	r.states[key] = &replicationState{
+		lastSuccessAt: record.LastSuccessAt,
+		history:       append([]model.ReplicationRun(nil), history...),
+	}
+}
+
+func (r *replicationRegistry) state(targetID, dbName, remote string) (string, []model.ReplicationRun) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	state, ok := r.states[targetID+"/"+dbName+"/"+remote]
+	if !ok {
+		return "", make([]model.ReplicationRun, 0)
+	}
+	return state.lastSuccessAt, append([]model.ReplicationRun(nil), state.history...)
+}
+
+func (s *Service) replicationDatabase(targetID, dbName string) (*config.Database, error) {
+	db, err := s.configuredDatabase(targetID, dbName)
+	if err != nil {
+		return nil, err
+	}
+	if len(db.Replication.Remotes) == 0 {
+		return nil, &model.APIError{
+			Status:  412,
+			Code:    model.CodePreconditionFailed,
+			Msg:     "replication is not configured for this database",
+			Details: map[string]string{"reason": "replication_disabled"},
+		}
+	}
+	return db, nil
+}
+
+// GetReplicationStatus lists the configured remotes with their recent runs.
+func (s *Service) GetReplicationStatus(targetID, dbName string) (*model.ReplicationStatusResponse, error) {
+	db, err := s.replicationDatabase(targetID, dbName)
+	if err != nil {
+		return nil, err
+	}
+	resp := &model.ReplicationStatusResponse{
+		IntervalMinutes: db.Replication.IntervalMinutes,
+		Remotes:         make([]model.ReplicationRemoteStatus, 0, len(db.Replication.Remotes)),
+	}
+	for _, remote := range db.Replication.Remotes {
+		lastSuccessAt, history := s.replication.state(targetID, dbName, remote.Name)
+		resp.Remotes = append(resp.Remotes, model.ReplicationRemoteStatus{
+			Name:          remote.Name,
+			URL:           remote.URL,
+			Kind:          remote.Kind,
+			LastSuccessAt: lastSuccessAt,
+			History:       history,
+		})
+	}
+	return resp, nil
+}
+
+// ReplicationHealth returns the last state of every configured remote.
+func (s *Service) ReplicationHealth() []model.ReplicationHealth {
+	var health []model.ReplicationHealth
+	for _, db := range s.cfg.Databases {
+		for _, remote := range db.Replication.Remotes {
+			lastSuccessAt, history := s.replication.state(db.TargetID, db.Name, remote.Name)
+			entry := model.ReplicationHealth{
+				TargetID:      db.TargetID,
+				DBName:        db.Name,
+				Remote:        remote.Name,
+				LastSuccessAt: lastSuccessAt,
+			}
+			if len(history) > 0 {
+				entry.LastStatus = history[0].Status
+			}
+			health = append(health, entry)
+		}
+	}
+	return health
+}
+
+// RunReplication syncs the requested remotes now. Runs of one database are
+// serialized; a failed remote does not stop the others.
+func (s *Service) RunReplication(ctx context.Context, req model.RunReplicationRequest) (*model.RunReplicationResponse, error) {
+	db, err := s.replicationDatabase(req.TargetID, req.DBName)
+	if err != nil {
+		return nil, err
+	}
+	remotes := db.Replication.Remotes
+	if req.Remote != "" {
+		remote, ok := db.Replication.FindRemote(req.Remote)
+		if !ok {
+			return nil, &model.APIError{Status: 404, Code: model.CodeNotFound, Msg: fmt.Sprintf("remote %s is not configured", req.Remote)}
+		}
+		remotes = []config.ReplicationRemote{*remote}
+	}
+	for _, remote := range remotes {
+		if _, err := replicationAction(remote, req.Action); err != nil {
+			return nil, err
+		}
+	}
+
+	lock := s.replication.dbLock(req.TargetID, req.DBName)
+	lock.Lock()
+	defer lock.Unlock()
+
+	conn, err := s.repo.ConnProtectedMaintenance(ctx, req.TargetID, req.DBName, "main")
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close()
+
+	resp := &model.RunReplicationResponse{Runs: make([]model.ReplicationRun, 0, len(remotes))}
+	failed := 0
+	saved := true
+	for _, remote := range remotes {
+		action, _ := replicationAction(remote, req.Action)
+		run := model.ReplicationRun{
+			Remote:    remote.Name,
+			Action:    action,
+			StartedAt: time.Now().UTC().Format(time.RFC3339),
+		}
+		if err := runReplicationAction(ctx, conn, remote, action); err != nil {
+			run.Status = model.ReplicationFailed
+			run.Error = err.Error()
+			failed++
+		} else {
+			run.Status = model.ReplicationSucceeded
+		}
+		run.FinishedAt = time.Now().UTC().Format(time.RFC3339)
+		record := s.replication.record(req.TargetID, req.DBName, run)
+		if err := saveReplicationRecord(ctx, conn, remote.Name, record); err != nil {
+			log.Printf("WARN: failed to save replication history for %s/%s %s: %v", req.TargetID, req.DBName, remote.Name, err)
+			saved = false
+		}
+		resp.Runs = append(resp.Runs, run)
+	}
+
+	resp.Outcome = model.OperationOutcomeCompleted
+	resp.Message = "レプリケーションが完了しました"
+	if failed > 0 {
+		resp.Outcome = model.OperationOutcomeFailed
+		resp.Message = fmt.Sprintf("%d 件のリモートでレプリケーションに失敗しました", failed)
+	}
+	resp.Completion = map[string]bool{"replicated": failed == 0, "history_saved": saved}
+	return resp, nil
+}
+
+func saveReplicationRecord(ctx context.Context, conn *sql.Conn, remote string, record replicationRecord) error {
+	message, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode replication history: %w", err)
+	}
+	return replaceMessageTag(ctx, conn, replicationTag(remote), string(message))
+}
+
+// loadReplicationHistory restores the persisted history of every configured
+// remote of one database.
+func (s *Service) loadReplicationHistory(ctx context.Context, db config.Database) error {
+	conn, err := s.connMetadataRevision(ctx, db.TargetID, db.Name)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	rows, err := conn.QueryContext(ctx, "SELECT tag_name, message FROM dolt_tags WHERE tag_name LIKE ?", replicationTagPrefix+"%")
+	if err != nil {
+		return fmt.Errorf("failed to query replication history: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var tagName, message string
+		if err := rows.Scan(&tagName, &message); err != nil {
+			return fmt.Errorf("failed to scan replication history: %w", err)
+		}
+		remote := strings.TrimPrefix(tagName, replicationTagPrefix)
+		if _, ok := db.Replication.FindRemote(remote); !ok {
+			continue
+		}
+		var record replicationRecord
+		if err := json.Unmarshal([]byte(message), &record); err != nil {
+			log.Printf("WARN: failed to parse replication history tag %s: %v", tagName, err)
+			continue
+		}
+		s.replication.restore(db.TargetID, db.Name, remote, record)
+	}
+	return rows.Err()
+}
+
+// replicationAction resolves the action for a remote: backups only sync,
+// remotes push (default) or fetch.
+func replicationAction(remote config.ReplicationRemote, requested string) (string, error) {
+	if remote.Kind == config.ReplicationKindBackup {
+		if requested == "" || requested == replicationActionSync {
+			return replicationActionSync, nil
+		}
+	} else {
+		switch requested {
+		case "", replicationActionPush:
+			return replicationActionPush, nil
+		case replicationActionFetch:
+			return replicationActionFetch, nil
+		}
+	}
+	return "", &model.APIError{
+		Status: 400,
+		Code:   model.CodeInvalidArgument,
+		Msg:    fmt.Sprintf("action %q is not supported for %s %s", requested, remote.Kind, remote.Name),
+	}
+}
+
+func runReplicationAction(ctx context.Context, conn *sql.Conn, remote config.ReplicationRemote, action string) error {
+	switch action {
+	case replicationActionSync:
+		if _, err := conn.ExecContext(ctx, "CALL DOLT_BACKUP('sync-url', ?)", remote.URL); err != nil {
+			return fmt.Errorf("backup sync failed: %w", err)
+		}
+	case replicationActionPush:
+		if err := ensureDoltRemote(ctx, conn, remote); err != nil {
+			return err
+		}
+		if _, err := conn.ExecContext(ctx, "CALL DOLT_PUSH(?, 'main')", remote.Name); err != nil {
+			return fmt.Errorf("push failed: %w", err)
+		}
+	case replicationActionFetch:
+		if err := ensureDoltRemote(ctx, conn, remote); err != nil {
+			return err
+		}
+		if _, err := conn.ExecContext(ctx, "CALL DOLT_FETCH(?)", remote.Name); err != nil {
+			return fmt.Errorf("fetch failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// ensureDoltRemote registers the remote, replacing it when the configured URL changed.
+func ensureDoltRemote(ctx context.Context, conn *sql.Conn, remote config.ReplicationRemote) error {
+	var url string
+	err := conn.QueryRowContext(ctx, "SELECT url FROM dolt_remotes WHERE name = ?", remote.Name).Scan(&url)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return fmt.Errorf("failed to read remotes: %w", err)
+	case url == remote.URL:
+		return nil
+	default:
+		if _, err := conn.ExecContext(ctx, "CALL DOLT_REMOTE('remove', ?)", remote.Name); err != nil {
+			return fmt.Errorf("failed to replace remote: %w", err)
+		}
+	}
+	if _, err := conn.ExecContext(ctx, "CALL DOLT_REMOTE('add', ?, ?)", remote.Name, remote.URL); err != nil {
+		return fmt.Errorf("failed to add remote: %w", err)
+	}
+	return nil
+}
+
+// RestoreReplica restores a configured remote into a new database on the same
+// server: DOLT_BACKUP restore for backups, DOLT_CLONE for remotes. The restored
+// database is served only after an operator adds it to the config.
+func (s *Service) RestoreReplica(ctx context.Context, req model.RestoreReplicaRequest) (*model.RestoreReplicaResponse, error) {
+	db, err := s.replicationDatabase(req.TargetID, req.DBName)
+	if err != nil {
+		return nil, err
+	}
+	remote, ok := db.Replication.FindRemote(req.Remote)
+	if !ok {
+		return nil, &model.APIError{Status: 404, Code: model.CodeNotFound, Msg: fmt.Sprintf("remote %s is not configured", req.Remote)}
+	}
+	if err := validation.ValidateDBName(req.NewDBName); err != nil {
+		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: err.Error()}
+	}
+
+	lock := s.replication.dbLock(req.TargetID, req.DBName)
+	lock.Lock()
+	defer lock.Unlock()
+
+	conn, err := s.repo.ConnProtectedMaintenance(ctx, req.TargetID, req.DBName, "main")
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close()
+
+	var existing int
+	if err := conn.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM information_schema.schemata WHERE schema_name = ?", req.NewDBName,
+	).Scan(&existing); err != nil {
+		return nil, fmt.Errorf("failed to check databases: %w", err)
+	}
+	if existing > 0 {
+		return nil, &model.APIError{
+			Status:  412,
+			Code:    model.CodePreconditionFailed,
+			Msg:     fmt.Sprintf("database %s already exists", req.NewDBName),
+			Details: map[string]string{"reason": "database_exists"},
+		}
+	}
+
+	query := "CALL DOLT_BACKUP('restore', ?, ?)"
+	if remote.Kind == config.ReplicationKindRemote {
+		query = "CALL DOLT_CLONE(?, ?)"
+	}
+	if _, err := conn.ExecContext(ctx, query, remote.URL, req.NewDBName); err != nil {
+		return nil, fmt.Errorf("restore from %s failed: %w", remote.Name, err)
+	}
+	log.Printf("replication: restored %s/%s remote %s into database %s", req.TargetID, req.DBName, remote.Name, req.NewDBName)
+
+	return &model.RestoreReplicaResponse{
+		NewDBName: req.NewDBName,
+		OperationResultFields: model.OperationResultFields{
+			Outcome:    model.OperationOutcomeCompleted,
+			Message:    fmt.Sprintf("%s に復元しました。利用するには設定ファイルの databases に追加してください", req.NewDBName),
+			Completion: map[string]bool{"restored": true},
+		},
+	}, nil
+}
+
+// StartReplicationScheduler loads the persisted run history of every remote,
+// then syncs every remote periodically for each database whose replication sets
+// interval_minutes. The goroutines stop when ctx is cancelled.
+func (s *Service) StartReplicationScheduler(ctx context.Context) {
+	for _, db := range s.cfg.Databases {
+		if len(db.Replication.Remotes) == 0 {
+			continue
+		}
+		if err := s.loadReplicationHistory(ctx, db); err != nil {
+			log.Printf("WARN: failed to load replication history for %s/%s: %v", db.TargetID, db.Name, err)
+		}
+		if db.Replication.IntervalMinutes <= 0 {
+			continue
+		}
+		go s.runReplicationSchedule(ctx, db.TargetID, db.Name, time.Duration(db.Replication.IntervalMinutes)*time.Minute)
+	}
+}
+
+func (s *Service) runReplicationSchedule(ctx context.Context, targetID, dbName string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			resp, err := s.RunReplication(ctx, model.RunReplicationRequest{TargetID: targetID, DBName: dbName})
+			if err != nil {
+				log.Printf("WARN: scheduled replication failed for %s/%s: %v", targetID, dbName, err)
+				continue
+			}
+			for _, run := range resp.Runs {
+				if run.Status == model.ReplicationFailed {
+					log.Printf("WARN: scheduled replication failed for %s/%s %s: %s", targetID, dbName, run.Remote, run.Error)
+				}
+			}
+		}
+	}
+}
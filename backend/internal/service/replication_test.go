@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/config"
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+)
+
+func replicationTestConfig() *config.Config {
+	cfg := testServiceConfig()
+	cfg.Databases[0].Replication = config.Replication{Remotes: []config.ReplicationRemote{
+		{Name: "nas", URL: "file:///mnt/backup/test_db", Kind: config.ReplicationKindBackup},
+		{Name: "mirror", URL: "file:///mnt/mirror/test_db", Kind: config.ReplicationKindRemote},
+	}}
+	return cfg
+}
+
+func TestRunReplication_SyncsBackupAndPushesRemote(t *testing.T) {
+	var executed []string
+	repo := newCrossCopyTestRepo(t, nil, nil)
+	repo.protectedHandler = func(dbName, branchName, query string, args []driver.NamedValue) (testQueryResult, error) {
+		switch query {
+		case "SELECT url FROM dolt_remotes WHERE name = ?":
+			// A stale URL is registered under the remote's name.
+			return testQueryResult{columns: []string{"url"}, rows: [][]driver.Value{{"file:///old/path"}}}, nil
+		case "CALL DOLT_BACKUP('sync-url', ?)":
+			executed = append(executed, fmt.Sprintf("backup %v", args[0].Value))
+			return testQueryResult{}, fmt.Errorf("disk full")
+		case "CALL DOLT_REMOTE('remove', ?)":
+			executed = append(executed, fmt.Sprintf("remove %v", args[0].Value))
+			return testQueryResult{}, nil
+		case "CALL DOLT_REMOTE('add', ?, ?)":
+			executed = append(executed, fmt.Sprintf("add %v %v", args[0].Value, args[1].Value))
+			return testQueryResult{}, nil
+		case "CALL DOLT_PUSH(?, 'main')":
+			executed = append(executed, fmt.Sprintf("push %v", args[0].Value))
+			return testQueryResult{}, nil
+		case "SELECT COUNT(*) FROM dolt_tags WHERE tag_name = ?":
+			return testQueryResult{columns: []string{"count"}, rows: [][]driver.Value{{int64(0)}}}, nil
+		case "CALL DOLT_TAG('-m', ?, ?, 'main')":
+			return testQueryResult{}, nil
+		}
+		return testQueryResult{}, fmt.Errorf("unexpected query: %s", query)
+	}
+	svc := newWithDeps(repo, replicationTestConfig())
+
+	resp, err := svc.RunReplication(context.Background(), model.RunReplicationRequest{TargetID: "local", DBName: "test_db"})
+	if err != nil {
+		t.Fatalf("RunReplication: %v", err)
+	}
+	want := "backup file:///mnt/backup/test_db,remove mirror,add mirror file:///mnt/mirror/test_db,push mirror"
+	if got := strings.Join(executed, ","); got != want {
+		t.Fatalf("executed %q, want %q", got, want)
+	}
+	if resp.Outcome != model.OperationOutcomeFailed || len(resp.Runs) != 2 ||
+		resp.Runs[0].Status != model.ReplicationFailed || resp.Runs[1].Status != model.ReplicationSucceeded {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+
+	status, err := svc.GetReplicationStatus("local", "test_db")
+	if err != nil {
+		t.Fatalf("GetReplicationStatus: %v", err)
+	}
+	if status.Remotes[0].LastSuccessAt != "" || len(status.Remotes[0].History) != 1 || !strings.Contains(status.Remotes[0].History[0].Error, "disk full") {
+		t.Fatalf("unexpected backup status: %+v", status.Remotes[0])
+	}
+	if status.Remotes[1].LastSuccessAt == "" {
+		t.Fatalf("expected last success for remote: %+v", status.Remotes[1])
+	}
+
+	health := svc.ReplicationHealth()
+	if len(health) != 2 || health[0].LastStatus != model.ReplicationFailed || health[1].LastSuccessAt == "" {
+		t.Fatalf("unexpected health: %+v", health)
+	}
+}
+
+func TestRunReplication_RejectsUnsupportedAction(t *testing.T) {
+	svc := newWithDeps(newCrossCopyTestRepo(t, nil, nil), replicationTestConfig())
+
+	_, err := svc.RunReplication(context.Background(), model.RunReplicationRequest{
+		TargetID: "local", DBName: "test_db", Remote: "nas", Action: "fetch",
+	})
+	if apiErr, ok := err.(*model.APIError); !ok || apiErr.Code != model.CodeInvalidArgument {
+		t.Fatalf("expected INVALID_ARGUMENT, got %v", err)
+	}
+
+	_, err = svc.RunReplication(context.Background(), model.RunReplicationRequest{TargetID: "local", DBName: "test_db", Remote: "missing"})
+	if apiErr, ok := err.(*model.APIError); !ok || apiErr.Code != model.CodeNotFound {
+		t.Fatalf("expected NOT_FOUND, got %v", err)
+	}
+
+	unconfigured := newWithDeps(newCrossCopyTestRepo(t, nil, nil), testServiceConfig())
+	_, err = unconfigured.GetReplicationStatus("local", "test_db")
+	if apiErr, ok := err.(*model.APIError); !ok || apiErr.Code != model.CodePreconditionFailed {
+		t.Fatalf("expected PRECONDITION_FAILED, got %v", err)
+	}
+}
+
+func TestRestoreReplica_RestoresBackupIntoNewDatabase(t *testing.T) {
+	var restoreArgs []driver.NamedValue
+	existing := int64(0)
+	repo := newCrossCopyTestRepo(t, nil, nil)
+	repo.protectedHandler = func(dbName, branchName, query string, args []driver.NamedValue) (testQueryResult, error) {
+		switch query {
+		case "SELECT COUNT(*) FROM information_schema.schemata WHERE schema_name = ?":
+			return testQueryResult{columns: []string{"count"}, rows: [][]driver.Value{{existing}}}, nil
+		case "CALL DOLT_BACKUP('restore', ?, ?)":
+			restoreArgs = args
+			return testQueryResult{}, nil
+		}
+		return testQueryResult{}, fmt.Errorf("unexpected query: %s", query)
+	}
+	svc := newWithDeps(repo, replicationTestConfig())
+	req := model.RestoreReplicaRequest{TargetID: "local", DBName: "test_db", Remote: "nas", NewDBName: "test_db_restored"}
+
+	resp, err := svc.RestoreReplica(context.Background(), req)
+	if err != nil {
+		t.Fatalf("RestoreReplica: %v", err)
+	}
+	if resp.NewDBName != "test_db_restored" || len(restoreArgs) != 2 ||
+		restoreArgs[0].Value != "file:///mnt/backup/test_db" || restoreArgs[1].Value != "test_db_restored" {
+		t.Fatalf("unexpected restore: %+v %v", resp, restoreArgs)
+	}
+
+	existing = 1
+	_, err = svc.RestoreReplica(context.Background(), req)
+	if apiErr, ok := err.(*model.APIError); !ok || apiErr.Code != model.CodePreconditionFailed {
+		t.Fatalf("expected PRECONDITION_FAILED for existing database, got %v", err)
+	}
+}
+
+func TestReplicationHistory_SurvivesRestart(t *testing.T) {
+	tags := make(map[string]string)
+	repo := newCrossCopyTestRepo(t,
+		func(dbName, refName, query string, args []driver.NamedValue) (testQueryResult, error) {
+			if query == "SELECT tag_name, message FROM dolt_tags WHERE tag_name LIKE ?" && args[0].Value == "repl/%" {
+				result := testQueryResult{columns: []string{"tag_name", "message"}}
+				for name, message := range tags {
+					result.rows = append(result.rows, []driver.Value{name, message})
+				}
+				// A remote that is no longer configured is ignored.
+				result.rows = append(result.rows, []driver.Value{"repl/retired", `{"schema":"dolt-webui/replication@1"}`})
+				return result, nil
+			}
+			return testQueryResult{}, fmt.Errorf("unexpected revision query: %s", query)
+		}, nil)
+	repo.protectedHandler = func(dbName, branchName, query string, args []driver.NamedValue) (testQueryResult, error) {
+		switch query {
+		case "CALL DOLT_BACKUP('sync-url', ?)":
+			return testQueryResult{}, nil
+		case "SELECT COUNT(*) FROM dolt_tags WHERE tag_name = ?":
+			count := int64(0)
+			if _, ok := tags[args[0].Value.(string)]; ok {
+				count = 1
+			}
+			return testQueryResult{columns: []string{"count"}, rows: [][]driver.Value{{count}}}, nil
+		case "CALL DOLT_TAG('-d', ?)":
+			delete(tags, args[0].Value.(string))
+			return testQueryResult{}, nil
+		case "CALL DOLT_TAG('-m', ?, ?, 'main')":
+			tags[args[1].Value.(string)] = args[0].Value.(string)
+			return testQueryResult{}, nil
+		}
+		return testQueryResult{}, fmt.Errorf("unexpected query: %s", query)
+	}
+	cfg := replicationTestConfig()
+	req := model.RunReplicationRequest{TargetID: "local", DBName: "test_db", Remote: "nas"}
+
+	first := newWithDeps(repo, cfg)
+	for i := 0; i < 2; i++ {
+		resp, err := first.RunReplication(context.Background(), req)
+		if err != nil {
+			t.Fatalf("RunReplication: %v", err)
+		}
+		if !resp.Completion["history_saved"] {
+			t.Fatalf("expected the run to be saved: %+v", resp)
+		}
+	}
+	if len(tags) != 1 || !strings.Contains(tags["repl/nas"], `"schema":"dolt-webui/replication@1"`) {
+		t.Fatalf("unexpected tags: %v", tags)
+	}
+
+	restarted := newWithDeps(repo, cfg)
+	restarted.StartReplicationScheduler(context.Background())
+	status, err := restarted.GetReplicationStatus("local", "test_db")
+	if err != nil {
+		t.Fatalf("GetReplicationStatus: %v", err)
+	}
+	nas := status.Remotes[0]
+	if nas.LastSuccessAt == "" || len(nas.History) != 2 || nas.History[0].Status != model.ReplicationSucceeded {
+		t.Fatalf("history was not restored: %+v", nas)
+	}
+	if len(status.Remotes[1].History) != 0 {
+		t.Fatalf("unexpected history for mirror: %+v", status.Remotes[1])
+	}
+}
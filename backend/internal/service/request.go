@@ -4,11 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
 	"time"
 
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/config"
 	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
 	"github.com/Makeinu1/dolt-web-ui/backend/internal/validation"
 )
@@ -63,6 +65,17 @@ func (s *Service) SubmitRequest(ctx context.Context, req model.SubmitRequestRequ
 		return nil, previewErr
 	}
 
+	behindMain, err := commitsBehindMain(ctx, conn)
+	if err != nil {
+		safeRollback(conn)
+		return nil, err
+	}
+	base, err := readBranchBase(ctx, conn, req.BranchName)
+	if err != nil {
+		safeRollback(conn)
+		return nil, err
+	}
+
 	var syncHash string
 	var syncFF, syncConflicts int
 	var syncMsg string
@@ -120,6 +133,11 @@ func (s *Service) SubmitRequest(ctx context.Context, req model.SubmitRequestRequ
 	if _, err := conn.ExecContext(ctx, "CALL DOLT_TAG('-m', ?, ?, ?)", string(tagMessage), requestID, submittedWorkHash); err != nil {
 		return nil, fmt.Errorf("failed to create tag: %w", err)
 	}
+	// An approval scheduled for an earlier submission does not carry over. The
+	// scheduler also checks the scheduled hash, so a failed drop is not fatal.
+	if err := dropScheduledApproval(ctx, conn, requestID); err != nil {
+		log.Printf("WARN: failed to drop scheduled approval for %s: %v", requestID, err)
+	}
 
 	// Close the SQL transaction opened for auto-sync + tag creation.
 	// Without this, the connection is returned to the pool with an open transaction,
@@ -128,14 +146,31 @@ func (s *Service) SubmitRequest(ctx context.Context, req model.SubmitRequestRequ
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
+	var warnings []string
+	var baseRef string
+	if base != nil {
+		baseRef = base.FromRef
+	}
+	if warning := behindMainWarning(behindMain, base); warning != "" {
+		warnings = append(warnings, warning)
+	}
+	overlaps, overlapWarning := s.overlapWarning(ctx, req.TargetID, req.DBName, req.BranchName)
+	if overlapWarning != "" {
+		warnings = append(warnings, overlapWarning)
+	}
+
 	return &model.SubmitRequestResponse{
 		RequestID:         requestID,
 		SubmittedMainHash: submittedMainHash,
 		SubmittedWorkHash: submittedWorkHash,
 		OverwrittenTables: overwrittenTables,
+		BehindMain:        behindMain,
+		BaseRef:           baseRef,
+		Overlaps:          overlaps,
 		OperationResultFields: model.OperationResultFields{
-			Outcome: model.OperationOutcomeCompleted,
-			Message: "承認を申請しました",
+			Outcome:  model.OperationOutcomeCompleted,
+			Message:  "承認を申請しました",
+			Warnings: warnings,
 			Completion: map[string]bool{
 				"request_recorded": true,
 				"lock_observable":  true,
@@ -146,7 +181,8 @@ func (s *Service) SubmitRequest(ctx context.Context, req model.SubmitRequestRequ
 }
 
 // ListRequests returns all pending approval requests.
-// This API is read-only and never performs cleanup.
+// This API is read-only and never performs cleanup. Merge checks are left to
+// CheckRequests so listing stays cheap.
 func (s *Service) ListRequests(ctx context.Context, targetID, dbName string) ([]model.RequestSummary, error) {
 	conn, err := s.connMetadataRevision(ctx, targetID, dbName)
 	if err != nil {
@@ -154,18 +190,28 @@ func (s *Service) ListRequests(ctx context.Context, targetID, dbName string) ([]
 	}
 	defer conn.Close()
 
+	result, _, err := readPendingRequests(ctx, conn)
+	return result, err
+}
+
+// readPendingRequests lists the req/* tags as summaries, with the tag hashes in
+// a parallel slice.
+func readPendingRequests(ctx context.Context, conn *sql.Conn) ([]model.RequestSummary, []string, error) {
+	metas := loadWorkItemMetasSoft(ctx, conn)
+
 	rows, err := conn.QueryContext(ctx,
 		"SELECT tag_name, tag_hash, message FROM dolt_tags WHERE tag_name LIKE 'req/%' ORDER BY tag_name")
 	if err != nil {
-		return nil, fmt.Errorf("failed to query requests: %w", err)
+		return nil, nil, fmt.Errorf("failed to query requests: %w", err)
 	}
 	defer rows.Close()
 
 	result := make([]model.RequestSummary, 0)
+	hashes := make([]string, 0)
 	for rows.Next() {
 		var tagName, hash, message string
 		if err := rows.Scan(&tagName, &hash, &message); err != nil {
-			return nil, fmt.Errorf("failed to scan request: %w", err)
+			return nil, nil, fmt.Errorf("failed to scan request: %w", err)
 		}
 
 		var meta map[string]string
@@ -192,9 +238,14 @@ func (s *Service) ListRequests(ctx context.Context, targetID, dbName string) ([]
 			SubmittedWorkHash: submittedWorkHash,
 			SummaryJa:         meta["summary_ja"],
 			SubmittedAt:       meta["submitted_at"],
+			WorkItemMeta:      workItemMetaForBranch(metas, workBranch),
 		})
+		hashes = append(hashes, hash)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
 	}
-	return result, rows.Err()
+	return result, hashes, nil
 }
 
 // GetRequest returns details of a specific request.
@@ -246,10 +297,37 @@ func (s *Service) GetRequest(ctx context.Context, targetID, dbName, requestID st
 // archives the approval cycle, clears the request tag, and advances the same work
 // branch to main HEAD for the next editing session.
 func (s *Service) ApproveRequest(ctx context.Context, req model.ApproveRequest) (*model.ApproveResponse, error) {
-	if _, err := s.configuredDatabase(req.TargetID, req.DBName); err != nil {
+	db, err := s.configuredDatabase(req.TargetID, req.DBName)
+	if err != nil {
 		return nil, err
 	}
 
+	if req.EffectiveAt != "" {
+		effectiveAt, err := time.Parse(time.RFC3339, req.EffectiveAt)
+		if err != nil {
+			return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "effective_at must be RFC3339"}
+		}
+		if effectiveAt.After(time.Now()) {
+			return s.scheduleApproval(ctx, db, req, effectiveAt)
+		}
+	}
+	return s.approveRequest(ctx, db, req, nil)
+}
+
+// approveRequest merges now. When the scheduler runs a scheduled approval,
+// scheduled is that schedule: the request must still point at the scheduled
+// commit (errScheduledRequestChanged otherwise), and its effective time is
+// recorded as a Scheduled-At trailer.
+func (s *Service) approveRequest(ctx context.Context, db *config.Database, req model.ApproveRequest, scheduled *model.ScheduledApproval) (*model.ApproveResponse, error) {
+	if apiErr := changeWindowError(db, time.Now()); apiErr != nil {
+		return nil, apiErr
+	}
+
+	// Direct approvals and the merge queue worker take turns on main.
+	mainLock := &s.mergeQueues.queue(req.TargetID, req.DBName).mainLock
+	mainLock.Lock()
+	defer mainLock.Unlock()
+
 	conn, err := s.repo.ConnProtectedMaintenance(ctx, req.TargetID, req.DBName, "main")
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect: %w", err)
@@ -264,6 +342,9 @@ func (s *Service) ApproveRequest(ctx context.Context, req model.ApproveRequest)
 	var submittedWorkHash, message string
 	err = conn.QueryRowContext(ctx,
 		"SELECT tag_hash, message FROM dolt_tags WHERE tag_name = ?", req.RequestID).Scan(&submittedWorkHash, &message)
+	if scheduled != nil && (errors.Is(err, sql.ErrNoRows) || (err == nil && submittedWorkHash != scheduled.RequestHash)) {
+		return nil, errScheduledRequestChanged
+	}
 	if err != nil {
 		return nil, &model.APIError{Status: 404, Code: model.CodeNotFound, Msg: "request not found"}
 	}
@@ -324,6 +405,10 @@ func (s *Service) ApproveRequest(ctx context.Context, req model.ApproveRequest)
 	if v := meta["submitted_at"]; v != "" {
 		footerPayload.RequestSubmittedAt = v
 	}
+	footerPayload.TicketID = ticketIDForWorkItem(ctx, conn, workItemForFooter)
+	if scheduled != nil {
+		footerPayload.ScheduledAt = scheduled.EffectiveAt
+	}
 	commitMessage := buildApprovalFooter(req.MergeMessageJa, footerPayload)
 
 	var mergeHash string
@@ -380,6 +465,12 @@ func (s *Service) ApproveRequest(ctx context.Context, req model.ApproveRequest)
 		auditIndexed = false
 	}
 
+	// A direct approval supersedes any schedule for this request; left in place,
+	// it would merge whatever is submitted next without an approval.
+	if err := dropScheduledApproval(bgCtx, conn, req.RequestID); err != nil {
+		log.Printf("WARN: failed to drop scheduled approval for %s: %v", req.RequestID, err)
+	}
+
 	// Postcondition 1: delete the request tag (release the lock).
 	requestCleared := true
 	if err := s.approveDeleteRequestTagHook(bgCtx, req.TargetID, req.DBName, req.RequestID); err != nil {
@@ -531,6 +622,11 @@ func (s *Service) advanceWorkBranch(ctx context.Context, conn *sql.Conn, targetI
 		return false
 	}
 
+	// The branch now starts from main again; its historical fork point no longer applies.
+	if err := dropBranchBase(ctx, conn, workBranch); err != nil {
+		log.Printf("WARN: failed to clear branch base for %s: %v", workBranch, err)
+	}
+
 	readiness := s.branchReadiness(ctx, targetID, dbName, workBranch)
 	if !readiness.Ready {
 		logBranchQueryabilityFailure("advance_work_branch_not_ready", targetID, dbName, workBranch, readiness)
@@ -591,6 +687,9 @@ func (s *Service) RejectRequest(ctx context.Context, req model.RejectRequest) (*
 	if _, err := conn.ExecContext(ctx, "CALL DOLT_TAG('-d', ?)", req.RequestID); err != nil {
 		return nil, fmt.Errorf("failed to delete tag: %w", err)
 	}
+	if err := dropScheduledApproval(ctx, conn, req.RequestID); err != nil {
+		log.Printf("WARN: failed to drop scheduled approval for %s: %v", req.RequestID, err)
+	}
 
 	return &model.RejectResponse{
 		Status: "rejected",
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+)
+
+// mergeCheckCache keeps merge checks per database for the current main hash.
+// A check depends only on (main hash, request tag hash), so entries for an
+// older main are dropped as soon as main moves, and a resubmitted request is
+// checked again.
+type mergeCheckCache struct {
+	mu     sync.Mutex
+	scopes map[string]*mergeCheckScope
+}
+
+type mergeCheckScope struct {
+	mainHash string
+	checks   map[string]model.RequestMergeCheck
+}
+
+func newMergeCheckCache() *mergeCheckCache {
+	return &mergeCheckCache{scopes: make(map[string]*mergeCheckScope)}
+}
+
+func (c *mergeCheckCache) get(scope, mainHash, requestHash string) (model.RequestMergeCheck, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.scopes[scope]
+	if !ok || entry.mainHash != mainHash {
+		return model.RequestMergeCheck{}, false
+	}
+	check, ok := entry.checks[requestHash]
+	return check, ok
+}
+
+func (c *mergeCheckCache) put(scope, mainHash, requestHash string, check model.RequestMergeCheck) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.scopes[scope]
+	if !ok || entry.mainHash != mainHash {
+		entry = &mergeCheckScope{mainHash: mainHash, checks: make(map[string]model.RequestMergeCheck)}
+		c.scopes[scope] = entry
+	}
+	entry.checks[requestHash] = check
+}
+
+// CheckRequests returns pending requests with their merge checks, ready-to-merge
+// requests first. requestID narrows the result to one request.
+func (s *Service) CheckRequests(ctx context.Context, targetID, dbName, requestID string) (*model.RequestCheckResponse, error) {
+	if requestID != "" && !isRequestTagName(requestID) {
+		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "invalid request_id format"}
+	}
+
+	conn, err := s.connMetadataRevision(ctx, targetID, dbName)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	requests, hashes, err := readPendingRequests(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &model.RequestCheckResponse{Requests: make([]model.RequestSummary, 0, len(requests))}
+	checkHashes := make([]string, 0, len(requests))
+	for i, request := range requests {
+		if requestID != "" && request.RequestID != requestID {
+			continue
+		}
+		resp.Requests = append(resp.Requests, request)
+		checkHashes = append(checkHashes, hashes[i])
+	}
+	if requestID != "" && len(resp.Requests) == 0 {
+		return nil, &model.APIError{Status: 404, Code: model.CodeNotFound, Msg: "request not found"}
+	}
+
+	s.attachMergeChecks(ctx, conn, targetID, dbName, resp.Requests, checkHashes)
+	for _, request := range resp.Requests {
+		if request.Check != nil && request.Check.CurrentMainHash != "" {
+			resp.MainHash = request.Check.CurrentMainHash
+			break
+		}
+	}
+
+	sort.SliceStable(resp.Requests, func(i, j int) bool {
+		return requestReadiness(resp.Requests[i]) < requestReadiness(resp.Requests[j])
+	})
+	return resp, nil
+}
+
+// requestReadiness ranks requests for the inbox: clean merges first, then clean
+// merges on a moved main, then conflicts, then failed checks.
+func requestReadiness(request model.RequestSummary) int {
+	check := request.Check
+	switch {
+	case check == nil || check.Error != "":
+		return 3
+	case !check.Mergeable:
+		return 2
+	case check.MainMoved:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// attachMergeChecks fills Check on every request; hashes holds the request tag
+// hashes. A failing check is reported on the request itself so one broken tag
+// never hides the rest of the inbox.
+func (s *Service) attachMergeChecks(ctx context.Context, conn *sql.Conn, targetID, dbName string, requests []model.RequestSummary, hashes []string) {
+	if len(requests) == 0 {
+		return
+	}
+	var mainHash string
+	if err := conn.QueryRowContext(ctx, "SELECT DOLT_HASHOF('main')").Scan(&mainHash); err != nil {
+		for i := range requests {
+			requests[i].Check = &model.RequestMergeCheck{Error: fmt.Sprintf("failed to get main hash: %v", err)}
+		}
+		return
+	}
+
+	scope := targetID + "/" + dbName
+	for i := range requests {
+		request := &requests[i]
+		check, ok := s.mergeChecks.get(scope, mainHash, hashes[i])
+		if !ok {
+			var err error
+			check, err = checkRequestMergeability(ctx, conn, request.RequestID, mainHash)
+			if err != nil {
+				request.Check = &model.RequestMergeCheck{CurrentMainHash: mainHash, Error: err.Error()}
+				continue
+			}
+			s.mergeChecks.put(scope, mainHash, hashes[i], check)
+		}
+		check.MainMoved = request.SubmittedMainHash != "" && request.SubmittedMainHash != mainHash
+		request.Check = &check
+	}
+}
+
+// checkRequestMergeability previews merging the request tag into main and counts
+// its changes since the merge base.
+func checkRequestMergeability(ctx context.Context, conn *sql.Conn, requestID, mainHash string) (model.RequestMergeCheck, error) {
+	check := model.RequestMergeCheck{CurrentMainHash: mainHash, Tables: make([]model.DiffSummaryEntry, 0)}
+	if err := validateRef("request", requestID); err != nil {
+		return check, err
+	}
+
+	query := fmt.Sprintf("SELECT * FROM DOLT_PREVIEW_MERGE_CONFLICTS_SUMMARY('main', '%s')", requestID)
+	rows, err := conn.QueryContext(ctx, query)
+	if err != nil {
+		return check, fmt.Errorf("failed to preview merge: %w", err)
+	}
+	for rows.Next() {
+		var tableName string
+		var dataConflicts, schemaConflicts int
+		if err := rows.Scan(&tableName, &dataConflicts, &schemaConflicts); err != nil {
+			rows.Close()
+			return check, fmt.Errorf("failed to scan preview: %w", err)
+		}
+		if schemaConflicts > 0 {
+			check.SchemaConflictTables = append(check.SchemaConflictTables, tableName)
+		}
+		if dataConflicts > 0 {
+			check.ConflictTables = append(check.ConflictTables, tableName)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return check, fmt.Errorf("failed to read preview: %w", err)
+	}
+	check.Mergeable = len(check.ConflictTables) == 0 && len(check.SchemaConflictTables) == 0
+
+	mergeBase, _, err := resolveDiffRefs(ctx, conn, "main", requestID, "three_dot")
+	if err != nil {
+		return check, err
+	}
+	statQuery := fmt.Sprintf(
+		"SELECT table_name, rows_added, rows_modified, rows_deleted FROM DOLT_DIFF_STAT('%s', '%s')",
+		mergeBase, requestID,
+	)
+	statRows, err := conn.QueryContext(ctx, statQuery)
+	if err != nil {
+		return check, fmt.Errorf("failed to get diff stat: %w", err)
+	}
+	defer statRows.Close()
+	for statRows.Next() {
+		var entry model.DiffSummaryEntry
+		if err := statRows.Scan(&entry.Table, &entry.Added, &entry.Modified, &entry.Removed); err != nil {
+			return check, fmt.Errorf("failed to scan diff stat: %w", err)
+		}
+		if entry.Added+entry.Modified+entry.Removed == 0 || isHiddenTableName(entry.Table) {
+			continue
+		}
+		check.Tables = append(check.Tables, entry)
+	}
+	if err := statRows.Err(); err != nil {
+		return check, fmt.Errorf("failed to read diff stat: %w", err)
+	}
+	sort.Slice(check.Tables, func(i, j int) bool {
+		return check.Tables[i].Table < check.Tables[j].Table
+	})
+	return check, nil
+}
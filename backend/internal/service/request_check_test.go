@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+)
+
+func TestCheckRequests_OrdersReadyFirstAndCachesByHashes(t *testing.T) {
+	previews := 0
+	repo := newRecordingSessionRepo(t, func(refName, query string, args []driver.NamedValue) (testQueryResult, error) {
+		switch {
+		case query == "SELECT tag_name, tag_hash, message FROM dolt_tags WHERE tag_name LIKE 'req/%' ORDER BY tag_name":
+			return testQueryResult{
+				columns: []string{"tag_name", "tag_hash", "message"},
+				rows: [][]driver.Value{
+					{"req/conflict", "work-conflict", `{"submitted_main_hash":"main-now","submitted_work_hash":"work-conflict"}`},
+					{"req/moved", "work-moved", `{"submitted_main_hash":"main-old","submitted_work_hash":"work-moved"}`},
+					{"req/ready", "work-ready", `{"submitted_main_hash":"main-now","submitted_work_hash":"work-ready"}`},
+				},
+			}, nil
+		case query == "SELECT DOLT_HASHOF('main')":
+			return testQueryResult{columns: []string{"hash"}, rows: [][]driver.Value{{"main-now"}}}, nil
+		case strings.HasPrefix(query, "SELECT * FROM DOLT_PREVIEW_MERGE_CONFLICTS_SUMMARY('main', "):
+			previews++
+			if strings.Contains(query, "'req/conflict'") {
+				return testQueryResult{columns: []string{"table", "num_data_conflicts", "num_schema_conflicts"}, rows: [][]driver.Value{{"items", int64(2), int64(0)}}}, nil
+			}
+			return testQueryResult{columns: []string{"table", "num_data_conflicts", "num_schema_conflicts"}}, nil
+		case strings.HasPrefix(query, "SELECT DOLT_MERGE_BASE('main', "):
+			return testQueryResult{columns: []string{"merge_base"}, rows: [][]driver.Value{{"base-hash"}}}, nil
+		case strings.HasPrefix(query, "SELECT table_name, rows_added, rows_modified, rows_deleted FROM DOLT_DIFF_STAT('base-hash', "):
+			return testQueryResult{columns: []string{"table_name", "rows_added", "rows_modified", "rows_deleted"}, rows: [][]driver.Value{{"items", int64(1), int64(2), int64(0)}}}, nil
+		case strings.HasPrefix(query, "SELECT tag_name, message FROM dolt_tags"):
+			return testQueryResult{columns: []string{"tag_name", "message"}}, nil
+		}
+		return testQueryResult{}, fmt.Errorf("unexpected query on %s: %s", refName, query)
+	})
+	svc := newWithDeps(repo, testServiceConfig())
+
+	resp, err := svc.CheckRequests(context.Background(), "local", "test_db", "")
+	if err != nil {
+		t.Fatalf("CheckRequests: %v", err)
+	}
+	order := make([]string, 0, len(resp.Requests))
+	for _, request := range resp.Requests {
+		order = append(order, request.RequestID)
+	}
+	if strings.Join(order, ",") != "req/ready,req/moved,req/conflict" {
+		t.Fatalf("unexpected order: %v", order)
+	}
+	if resp.MainHash != "main-now" {
+		t.Fatalf("unexpected main hash: %q", resp.MainHash)
+	}
+	ready, moved, conflict := resp.Requests[0].Check, resp.Requests[1].Check, resp.Requests[2].Check
+	if !ready.Mergeable || ready.MainMoved || len(ready.Tables) != 1 || ready.Tables[0].Modified != 2 {
+		t.Fatalf("unexpected ready check: %+v", ready)
+	}
+	if !moved.Mergeable || !moved.MainMoved {
+		t.Fatalf("unexpected moved check: %+v", moved)
+	}
+	if conflict.Mergeable || strings.Join(conflict.ConflictTables, ",") != "items" {
+		t.Fatalf("unexpected conflict check: %+v", conflict)
+	}
+
+	if _, err := svc.CheckRequests(context.Background(), "local", "test_db", "req/ready"); err != nil {
+		t.Fatalf("CheckRequests (cached): %v", err)
+	}
+	if previews != 3 {
+		t.Fatalf("expected cached checks to skip previews, got %d previews", previews)
+	}
+}
+
+func TestMergeCheckCache_DropsEntriesWhenMainMoves(t *testing.T) {
+	cache := newMergeCheckCache()
+	cache.put("local/test_db", "main-1", "work-1", model.RequestMergeCheck{CurrentMainHash: "main-1"})
+	if _, ok := cache.get("local/test_db", "main-1", "work-1"); !ok {
+		t.Fatal("expected cached check")
+	}
+	cache.put("local/test_db", "main-2", "work-2", model.RequestMergeCheck{CurrentMainHash: "main-2"})
+	if _, ok := cache.get("local/test_db", "main-1", "work-1"); ok {
+		t.Fatal("expected entries for the previous main to be dropped")
+	}
+}
+
+func TestListRequests_RunsNoMergeChecks(t *testing.T) {
+	var previewed []string
+	repo := newRecordingSessionRepo(t, func(refName, query string, args []driver.NamedValue) (testQueryResult, error) {
+		switch {
+		case query == "SELECT tag_name, tag_hash, message FROM dolt_tags WHERE tag_name LIKE 'req/%' ORDER BY tag_name":
+			return testQueryResult{
+				columns: []string{"tag_name", "tag_hash", "message"},
+				rows: [][]driver.Value{
+					{"req/a", "hash-a", `{"submitted_main_hash":"main-now"}`},
+					{"req/b", "hash-b", `{"submitted_main_hash":"main-now"}`},
+				},
+			}, nil
+		case strings.HasPrefix(query, "SELECT tag_name, message FROM dolt_tags"):
+			return testQueryResult{columns: []string{"tag_name", "message"}}, nil
+		case query == "SELECT DOLT_HASHOF('main')":
+			return testQueryResult{columns: []string{"hash"}, rows: [][]driver.Value{{"main-now"}}}, nil
+		case strings.HasPrefix(query, "SELECT * FROM DOLT_PREVIEW_MERGE_CONFLICTS_SUMMARY('main', "):
+			previewed = append(previewed, query)
+			return testQueryResult{columns: []string{"table", "num_data_conflicts", "num_schema_conflicts"}}, nil
+		case strings.HasPrefix(query, "SELECT DOLT_MERGE_BASE('main', "):
+			return testQueryResult{columns: []string{"merge_base"}, rows: [][]driver.Value{{"base-hash"}}}, nil
+		case strings.HasPrefix(query, "SELECT table_name, rows_added, rows_modified, rows_deleted FROM DOLT_DIFF_STAT("):
+			return testQueryResult{columns: []string{"table_name", "rows_added", "rows_modified", "rows_deleted"}}, nil
+		}
+		return testQueryResult{}, fmt.Errorf("unexpected query on %s: %s", refName, query)
+	})
+	svc := newWithDeps(repo, testServiceConfig())
+
+	requests, err := svc.ListRequests(context.Background(), "local", "test_db")
+	if err != nil {
+		t.Fatalf("ListRequests: %v", err)
+	}
+	if len(requests) != 2 || requests[0].Check != nil || len(previewed) != 0 {
+		t.Fatalf("listing must not run merge checks: %+v, previews %v", requests, previewed)
+	}
+
+	resp, err := svc.CheckRequests(context.Background(), "local", "test_db", "req/b")
+	if err != nil {
+		t.Fatalf("CheckRequests: %v", err)
+	}
+	if len(resp.Requests) != 1 || resp.Requests[0].Check == nil || len(previewed) != 1 || !strings.Contains(previewed[0], "'req/b'") {
+		t.Fatalf("expected only req/b to be checked: %+v, previews %v", resp.Requests, previewed)
+	}
+}
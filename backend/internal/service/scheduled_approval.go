@@ -0,0 +1,328 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/config"
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+)
+
+// approvalSchedulerInterval is how often due scheduled approvals are looked up.
+const approvalSchedulerInterval = 30 * time.Second
+
+// scheduledApprovalTagPrefix marks approvals waiting for their effective time.
+// The tag sched/<WorkItem> points at the submitted work hash and carries
+// scheduledApprovalMeta as JSON.
+const scheduledApprovalTagPrefix = "sched/"
+
+// errScheduledRequestChanged means req/<WorkItem> no longer points at the
+// commit that was scheduled: it was approved, rejected or resubmitted since.
+var errScheduledRequestChanged = errors.New("request changed since the approval was scheduled")
+
+type scheduledApprovalMeta struct {
+	Schema         string `json:"schema"`
+	RequestID      string `json:"request_id"`
+	RequestHash    string `json:"request_hash"`
+	EffectiveAt    string `json:"effective_at"`
+	MergeMessageJa string `json:"merge_message_ja"`
+	CreatedAt      string `json:"created_at"`
+	Status         string `json:"status"`
+	LastError      string `json:"last_error,omitempty"`
+}
+
+func scheduleTagForRequest(requestID string) (string, bool) {
+	workItem, ok := workItemFromRequestID(requestID)
+	if !ok {
+		return "", false
+	}
+	return scheduledApprovalTagPrefix + workItem, true
+}
+
+// changeWindowError rejects merges into main outside the database's change windows.
+func changeWindowError(db *config.Database, at time.Time) *model.APIError {
+	blocked, detail := db.ChangeControl.Blocked(at)
+	if !blocked {
+		return nil
+	}
+	return &model.APIError{
+		Status: 412,
+		Code:   model.CodePreconditionFailed,
+		Msg:    "変更可能な時間帯ではないため main へ反映できません: " + detail,
+		Details: map[string]string{
+			"reason": "freeze_window",
+			"detail": detail,
+			"at":     at.UTC().Format(time.RFC3339),
+		},
+	}
+}
+
+// scheduleApproval records an approval that the scheduler merges at effectiveAt.
+// The effective time itself must fall inside an allowed change window.
+func (s *Service) scheduleApproval(ctx context.Context, db *config.Database, req model.ApproveRequest, effectiveAt time.Time) (*model.ApproveResponse, error) {
+	if apiErr := changeWindowError(db, effectiveAt); apiErr != nil {
+		return nil, apiErr
+	}
+	tagName, ok := scheduleTagForRequest(req.RequestID)
+	if !ok {
+		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "invalid request_id format"}
+	}
+	workBranch, _ := workBranchFromRequestID(req.RequestID)
+
+	conn, err := s.repo.ConnProtectedMaintenance(ctx, req.TargetID, req.DBName, "main")
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close()
+
+	var submittedWorkHash string
+	err = conn.QueryRowContext(ctx, "SELECT tag_hash FROM dolt_tags WHERE tag_name = ?", req.RequestID).Scan(&submittedWorkHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &model.APIError{Status: 404, Code: model.CodeNotFound, Msg: "request not found"}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query request: %w", err)
+	}
+
+	meta := scheduledApprovalMeta{
+		Schema:         "dolt-webui/scheduled-approval@1",
+		RequestID:      req.RequestID,
+		RequestHash:    submittedWorkHash,
+		EffectiveAt:    effectiveAt.Format(time.RFC3339),
+		MergeMessageJa: req.MergeMessageJa,
+		CreatedAt:      time.Now().UTC().Format(time.RFC3339),
+		Status:         model.ScheduledApprovalPending,
+	}
+	if err := writeScheduledApproval(ctx, conn, tagName, submittedWorkHash, meta); err != nil {
+		return nil, err
+	}
+
+	return &model.ApproveResponse{
+		ActiveBranch: workBranch,
+		ScheduledFor: meta.EffectiveAt,
+		OperationResultFields: model.OperationResultFields{
+			Outcome: model.OperationOutcomeCompleted,
+			Message: fmt.Sprintf("%s に main へ反映する承認を予約しました", meta.EffectiveAt),
+			Completion: map[string]bool{
+				"approval_scheduled": true,
+				"main_merged":        false,
+			},
+		},
+	}, nil
+}
+
+func writeScheduledApproval(ctx context.Context, conn *sql.Conn, tagName, hash string, meta scheduledApprovalMeta) error {
+	message, err := json.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("failed to marshal scheduled approval: %w", err)
+	}
+	var count int
+	if err := conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM dolt_tags WHERE tag_name = ?", tagName).Scan(&count); err != nil {
+		return fmt.Errorf("failed to check scheduled approval: %w", err)
+	}
+	if count > 0 {
+		if _, err := conn.ExecContext(ctx, "CALL DOLT_TAG('-d', ?)", tagName); err != nil {
+			return fmt.Errorf("failed to replace scheduled approval: %w", err)
+		}
+	}
+	if _, err := conn.ExecContext(ctx, "CALL DOLT_TAG('-m', ?, ?, ?)", string(message), tagName, hash); err != nil {
+		return fmt.Errorf("failed to record scheduled approval: %w", err)
+	}
+	return nil
+}
+
+// dropScheduledApproval removes sched/<WorkItem> if present.
+func dropScheduledApproval(ctx context.Context, conn *sql.Conn, requestID string) error {
+	tagName, ok := scheduleTagForRequest(requestID)
+	if !ok {
+		return nil
+	}
+	var count int
+	if err := conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM dolt_tags WHERE tag_name = ?", tagName).Scan(&count); err != nil || count == 0 {
+		return err
+	}
+	if _, err := conn.ExecContext(ctx, "CALL DOLT_TAG('-d', ?)", tagName); err != nil {
+		return fmt.Errorf("failed to delete scheduled approval: %w", err)
+	}
+	return nil
+}
+
+// ListScheduledApprovals returns scheduled approvals ordered by effective time.
+func (s *Service) ListScheduledApprovals(ctx context.Context, targetID, dbName string) ([]model.ScheduledApproval, error) {
+	conn, err := s.connMetadataRevision(ctx, targetID, dbName)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	rows, err := conn.QueryContext(ctx, "SELECT tag_name, tag_hash, message FROM dolt_tags WHERE tag_name LIKE 'sched/%' ORDER BY tag_name")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scheduled approvals: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]model.ScheduledApproval, 0)
+	for rows.Next() {
+		var tagName, tagHash, message string
+		if err := rows.Scan(&tagName, &tagHash, &message); err != nil {
+			return nil, fmt.Errorf("failed to scan scheduled approval: %w", err)
+		}
+		var meta scheduledApprovalMeta
+		if err := json.Unmarshal([]byte(message), &meta); err != nil {
+			log.Printf("WARN: failed to parse scheduled approval %s: %v", tagName, err)
+			continue
+		}
+		requestID := "req/" + strings.TrimPrefix(tagName, scheduledApprovalTagPrefix)
+		workBranch, ok := workBranchFromRequestID(requestID)
+		if !ok {
+			continue
+		}
+		if meta.RequestHash == "" {
+			// Schedules written before request_hash point at the same commit.
+			meta.RequestHash = tagHash
+		}
+		result = append(result, model.ScheduledApproval{
+			RequestID:      requestID,
+			RequestHash:    meta.RequestHash,
+			WorkBranch:     workBranch,
+			EffectiveAt:    meta.EffectiveAt,
+			MergeMessageJa: meta.MergeMessageJa,
+			CreatedAt:      meta.CreatedAt,
+			Status:         meta.Status,
+			LastError:      meta.LastError,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].EffectiveAt < result[j].EffectiveAt
+	})
+	return result, nil
+}
+
+// CancelScheduledApproval drops a scheduled approval. The request stays pending.
+func (s *Service) CancelScheduledApproval(ctx context.Context, req model.CancelScheduledApprovalRequest) error {
+	if _, err := s.configuredDatabase(req.TargetID, req.DBName); err != nil {
+		return err
+	}
+	tagName, ok := scheduleTagForRequest(req.RequestID)
+	if !ok {
+		return &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "invalid request_id format"}
+	}
+
+	conn, err := s.repo.ConnProtectedMaintenance(ctx, req.TargetID, req.DBName, "main")
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close()
+
+	var count int
+	if err := conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM dolt_tags WHERE tag_name = ?", tagName).Scan(&count); err != nil {
+		return fmt.Errorf("failed to check scheduled approval: %w", err)
+	}
+	if count == 0 {
+		return &model.APIError{Status: 404, Code: model.CodeNotFound, Msg: "scheduled approval not found"}
+	}
+	if _, err := conn.ExecContext(ctx, "CALL DOLT_TAG('-d', ?)", tagName); err != nil {
+		return fmt.Errorf("failed to delete scheduled approval: %w", err)
+	}
+	return nil
+}
+
+// StartApprovalScheduler merges due scheduled approvals for every configured
+// database until ctx is cancelled.
+func (s *Service) StartApprovalScheduler(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(approvalSchedulerInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				for _, db := range s.cfg.Databases {
+					if err := s.runDueApprovals(ctx, db.TargetID, db.Name, time.Now()); err != nil {
+						log.Printf("WARN: scheduled approvals failed for %s/%s: %v", db.TargetID, db.Name, err)
+					}
+				}
+			}
+		}
+	}()
+}
+
+// runDueApprovals merges every pending scheduled approval whose effective time has
+// passed. A failed merge stays listed with status "failed" so it is not retried
+// blindly; the approver can cancel it or approve again. A schedule whose request
+// was resubmitted (or is gone) since it was made is dropped without merging:
+// nobody approved the new submission.
+func (s *Service) runDueApprovals(ctx context.Context, targetID, dbName string, now time.Time) error {
+	db, err := s.configuredDatabase(targetID, dbName)
+	if err != nil {
+		return err
+	}
+	scheduled, err := s.ListScheduledApprovals(ctx, targetID, dbName)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range scheduled {
+		effectiveAt, err := time.Parse(time.RFC3339, item.EffectiveAt)
+		if err != nil || item.Status != model.ScheduledApprovalPending || effectiveAt.After(now) {
+			continue
+		}
+		_, approveErr := s.approveRequest(ctx, db, model.ApproveRequest{
+			TargetID:       targetID,
+			DBName:         dbName,
+			RequestID:      item.RequestID,
+			MergeMessageJa: item.MergeMessageJa,
+		}, &item)
+		if errors.Is(approveErr, errScheduledRequestChanged) {
+			log.Printf("WARN: dropping scheduled approval %s: %v", item.RequestID, approveErr)
+			approveErr = nil
+		} else if approveErr != nil {
+			log.Printf("WARN: scheduled approval %s failed: %v", item.RequestID, approveErr)
+		}
+		if err := s.finishScheduledApproval(ctx, targetID, dbName, item, approveErr); err != nil {
+			log.Printf("WARN: failed to update scheduled approval %s: %v", item.RequestID, err)
+		}
+	}
+	return nil
+}
+
+// finishScheduledApproval drops the schedule after a merge (or a changed
+// request), or marks it failed.
+func (s *Service) finishScheduledApproval(ctx context.Context, targetID, dbName string, item model.ScheduledApproval, approveErr error) error {
+	conn, err := s.repo.ConnProtectedMaintenance(ctx, targetID, dbName, "main")
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close()
+
+	if approveErr == nil {
+		return dropScheduledApproval(ctx, conn, item.RequestID)
+	}
+
+	tagName, _ := scheduleTagForRequest(item.RequestID)
+	var hash string
+	if err := conn.QueryRowContext(ctx, "SELECT tag_hash FROM dolt_tags WHERE tag_name = ?", tagName).Scan(&hash); err != nil {
+		return fmt.Errorf("failed to read scheduled approval: %w", err)
+	}
+	return writeScheduledApproval(ctx, conn, tagName, hash, scheduledApprovalMeta{
+		Schema:         "dolt-webui/scheduled-approval@1",
+		RequestID:      item.RequestID,
+		RequestHash:    item.RequestHash,
+		EffectiveAt:    item.EffectiveAt,
+		MergeMessageJa: item.MergeMessageJa,
+		CreatedAt:      item.CreatedAt,
+		Status:         model.ScheduledApprovalFailed,
+		LastError:      approveErr.Error(),
+	})
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/config"
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+)
+
+func TestApproveRequest_RejectsInsideFreeze(t *testing.T) {
+	repo := newApproveTestRepo(t, nil, nil)
+	cfg := testServiceConfig()
+	cfg.Databases[0].ChangeControl = config.ChangeControl{
+		Freezes: []config.FreezePeriod{{Start: "2000-01-01T00:00:00Z", End: "2999-01-01T00:00:00Z", Reason: "audit"}},
+	}
+	svc := newWithDeps(repo, cfg)
+
+	_, err := svc.ApproveRequest(context.Background(), model.ApproveRequest{
+		TargetID: "local", DBName: "test_db", RequestID: approveTestRequestID,
+	})
+	apiErr, ok := err.(*model.APIError)
+	if !ok || apiErr.Code != model.CodePreconditionFailed {
+		t.Fatalf("expected PRECONDITION_FAILED, got %v", err)
+	}
+	if details, _ := apiErr.Details.(map[string]string); details["reason"] != "freeze_window" {
+		t.Fatalf("unexpected details: %v", apiErr.Details)
+	}
+	if len(repo.calls) != 0 {
+		t.Fatalf("expected no session before the window check, got %v", repo.calls)
+	}
+}
+
+func TestApproveRequest_FutureEffectiveAtSchedules(t *testing.T) {
+	var tagArgs []driver.NamedValue
+	repo := newApproveTestRepo(t, nil, func(refName, query string, args []driver.NamedValue) (testQueryResult, error) {
+		switch query {
+		case "SELECT tag_hash FROM dolt_tags WHERE tag_name = ?":
+			return testQueryResult{columns: []string{"tag_hash"}, rows: [][]driver.Value{{approveTestSubmittedWorkHash}}}, nil
+		case "SELECT COUNT(*) FROM dolt_tags WHERE tag_name = ?":
+			return testQueryResult{columns: []string{"count"}, rows: [][]driver.Value{{int64(0)}}}, nil
+		case "CALL DOLT_TAG('-m', ?, ?, ?)":
+			tagArgs = args
+			return testQueryResult{}, nil
+		}
+		return testQueryResult{}, fmt.Errorf("unexpected maintenance query: %s", query)
+	})
+	svc := newWithDeps(repo, testServiceConfig())
+	effectiveAt := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+
+	resp, err := svc.ApproveRequest(context.Background(), model.ApproveRequest{
+		TargetID: "local", DBName: "test_db", RequestID: approveTestRequestID, MergeMessageJa: "夜間反映", EffectiveAt: effectiveAt,
+	})
+	if err != nil {
+		t.Fatalf("ApproveRequest: %v", err)
+	}
+	if resp.ScheduledFor != effectiveAt || resp.Completion["main_merged"] || !resp.Completion["approval_scheduled"] {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if len(tagArgs) != 3 || tagArgs[1].Value != "sched/task-hook-test" || tagArgs[2].Value != approveTestSubmittedWorkHash {
+		t.Fatalf("unexpected DOLT_TAG args: %v", tagArgs)
+	}
+	if !strings.Contains(tagArgs[0].Value.(string), effectiveAt) {
+		t.Fatalf("schedule message lacks effective time: %v", tagArgs[0].Value)
+	}
+}
+
+// approveTagStore keeps dolt_tags in memory so that schedule, approve and
+// scheduler calls see each other's tag writes.
+type approveTagStore struct {
+	hashes   map[string]string
+	messages map[string]string
+	merges   []string // merge commit messages
+}
+
+func newApproveTagStore() *approveTagStore {
+	return &approveTagStore{hashes: map[string]string{}, messages: map[string]string{}}
+}
+
+// submit records req/<WorkItem> as SubmitRequest does.
+func (st *approveTagStore) submit(workHash string) {
+	st.hashes[approveTestRequestID] = workHash
+	st.messages[approveTestRequestID] = fmt.Sprintf(`{"submitted_work_hash":%q,"work_branch":%q}`, workHash, approveTestWorkBranch)
+}
+
+func (st *approveTagStore) handle(refName, query string, args []driver.NamedValue) (testQueryResult, error) {
+	switch {
+	case query == "SELECT tag_name, tag_hash, message FROM dolt_tags WHERE tag_name LIKE 'sched/%' ORDER BY tag_name":
+		result := testQueryResult{columns: []string{"tag_name", "tag_hash", "message"}}
+		for _, name := range []string{"sched/task-hook-test"} {
+			if hash, ok := st.hashes[name]; ok {
+				result.rows = append(result.rows, []driver.Value{name, hash, st.messages[name]})
+			}
+		}
+		return result, nil
+	case query == "SELECT tag_hash FROM dolt_tags WHERE tag_name = ?", query == "SELECT tag_hash, message FROM dolt_tags WHERE tag_name = ?":
+		name := args[0].Value.(string)
+		hash, ok := st.hashes[name]
+		if !ok {
+			return testQueryResult{columns: []string{"tag_hash", "message"}}, nil
+		}
+		if query == "SELECT tag_hash FROM dolt_tags WHERE tag_name = ?" {
+			return testQueryResult{columns: []string{"tag_hash"}, rows: [][]driver.Value{{hash}}}, nil
+		}
+		return testQueryResult{columns: []string{"tag_hash", "message"}, rows: [][]driver.Value{{hash, st.messages[name]}}}, nil
+	case query == "SELECT COUNT(*) FROM dolt_tags WHERE tag_name = ?":
+		_, ok := st.hashes[args[0].Value.(string)]
+		count := int64(0)
+		if ok {
+			count = 1
+		}
+		return testQueryResult{columns: []string{"count"}, rows: [][]driver.Value{{count}}}, nil
+	case query == "CALL DOLT_TAG('-d', ?)":
+		delete(st.hashes, args[0].Value.(string))
+		return testQueryResult{}, nil
+	case query == "CALL DOLT_TAG('-m', ?, ?, ?)":
+		name := args[1].Value.(string)
+		st.hashes[name], st.messages[name] = args[2].Value.(string), args[0].Value.(string)
+		return testQueryResult{}, nil
+	case query == "SELECT HASHOF(?)":
+		return testQueryResult{columns: []string{"hash"}, rows: [][]driver.Value{{st.hashes[approveTestRequestID]}}}, nil
+	case strings.HasPrefix(query, "CALL DOLT_MERGE("):
+		st.merges = append(st.merges, args[1].Value.(string))
+	}
+	return approveMaintenanceHandler("", "", approveTestNewHead)(refName, query, args)
+}
+
+func newApproveTagStoreService(t *testing.T, st *approveTagStore) *Service {
+	svc := newWithDeps(newApproveTestRepo(t, st.handle, st.handle), testServiceConfig())
+	svc.approveAdvanceWorkBranchHook = func(ctx context.Context, targetID, dbName, workBranch string, warnings *[]string) bool {
+		return true
+	}
+	return svc
+}
+
+func TestRunDueApprovals_MergesWithScheduledAtTrailer(t *testing.T) {
+	const effectiveAt = "2026-03-14T22:00:00+09:00"
+	st := newApproveTagStore()
+	st.submit(approveTestSubmittedWorkHash)
+	// A schedule written before request_hash existed: the tag hash is used.
+	st.hashes["sched/task-hook-test"] = approveTestSubmittedWorkHash
+	st.messages["sched/task-hook-test"] = fmt.Sprintf(`{"request_id":%q,"effective_at":%q,"merge_message_ja":"夜間反映","status":"scheduled"}`, approveTestRequestID, effectiveAt)
+	svc := newApproveTagStoreService(t, st)
+
+	if err := svc.runDueApprovals(context.Background(), "local", "test_db", time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("runDueApprovals: %v", err)
+	}
+	if len(st.merges) != 1 || !strings.Contains(st.merges[0], "\nScheduled-At: "+effectiveAt) {
+		t.Fatalf("merge message lacks Scheduled-At trailer: %q", st.merges)
+	}
+	if _, ok := st.hashes["sched/task-hook-test"]; ok {
+		t.Fatal("schedule should be dropped after the merge")
+	}
+	if _, ok := st.hashes[approveTestRequestID]; ok {
+		t.Fatal("request tag should be cleared after the merge")
+	}
+}
+
+func TestRunDueApprovals_DoesNotMergeResubmittedRequest(t *testing.T) {
+	const resubmittedHash = "99887766554433221100ffeeddccbbaa"
+	st := newApproveTagStore()
+	st.submit(approveTestSubmittedWorkHash)
+	svc := newApproveTagStoreService(t, st)
+	ctx := context.Background()
+
+	// 1. The reviewer schedules the approval.
+	effectiveAt := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	if _, err := svc.ApproveRequest(ctx, model.ApproveRequest{
+		TargetID: "local", DBName: "test_db", RequestID: approveTestRequestID, EffectiveAt: effectiveAt,
+	}); err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+	if !strings.Contains(st.messages["sched/task-hook-test"], `"request_hash":"`+approveTestSubmittedWorkHash+`"`) {
+		t.Fatalf("schedule does not record the request hash: %s", st.messages["sched/task-hook-test"])
+	}
+	staleSchedule := st.messages["sched/task-hook-test"]
+
+	// 2. ...then approves directly, which drops the schedule.
+	if _, err := svc.ApproveRequest(ctx, model.ApproveRequest{TargetID: "local", DBName: "test_db", RequestID: approveTestRequestID}); err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	if _, ok := st.hashes["sched/task-hook-test"]; ok {
+		t.Fatal("direct approval should drop the schedule")
+	}
+
+	// 3. The item is resubmitted. Even if a stale schedule survived, the
+	// scheduler must not merge the new submission.
+	st.submit(resubmittedHash)
+	st.hashes["sched/task-hook-test"], st.messages["sched/task-hook-test"] = approveTestSubmittedWorkHash, staleSchedule
+
+	// 4. Scheduler tick after the effective time.
+	if err := svc.runDueApprovals(ctx, "local", "test_db", time.Now().Add(2*time.Hour)); err != nil {
+		t.Fatalf("runDueApprovals: %v", err)
+	}
+	if len(st.merges) != 1 {
+		t.Fatalf("expected only the direct approval to merge, got %d merges", len(st.merges))
+	}
+	if _, ok := st.hashes["sched/task-hook-test"]; ok {
+		t.Fatal("stale schedule should be dropped")
+	}
+	if st.hashes[approveTestRequestID] != resubmittedHash {
+		t.Fatal("resubmitted request must stay pending")
+	}
+}
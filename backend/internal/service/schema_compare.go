@@ -0,0 +1,465 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+)
+
+// schemaForeignKeyQuery lists the foreign key columns of the current database.
+const schemaForeignKeyQuery = "SELECT CONSTRAINT_NAME, TABLE_NAME, COLUMN_NAME, REFERENCED_TABLE_NAME, REFERENCED_COLUMN_NAME " +
+	"FROM INFORMATION_SCHEMA.KEY_COLUMN_USAGE WHERE TABLE_SCHEMA = DATABASE() AND REFERENCED_TABLE_NAME IS NOT NULL " +
+	"ORDER BY TABLE_NAME, CONSTRAINT_NAME, ORDINAL_POSITION"
+
+// schemaTable is the schema of one user table at one (db, ref).
+type schemaTable struct {
+	columns     []model.ColumnSchema
+	indexes     map[string]model.SchemaIndex
+	foreignKeys []model.SchemaForeignKey
+}
+
+// loadIndexes reads the secondary indexes of table, with columns in index order.
+func loadIndexes(ctx context.Context, conn *sql.Conn, table string) (map[string]model.SchemaIndex, error) {
+	rows, err := conn.QueryContext(ctx, fmt.Sprintf("SHOW INDEX FROM `%s`", table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get indexes for %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get index columns: %w", err)
+	}
+	keyNameIdx, nonUniqueIdx, seqIdx, columnIdx := -1, -1, -1, -1
+	for i, col := range columns {
+		switch strings.ToLower(col) {
+		case "key_name":
+			keyNameIdx = i
+		case "non_unique":
+			nonUniqueIdx = i
+		case "seq_in_index":
+			seqIdx = i
+		case "column_name":
+			columnIdx = i
+		}
+	}
+	if keyNameIdx < 0 || nonUniqueIdx < 0 || seqIdx < 0 || columnIdx < 0 {
+		return nil, fmt.Errorf("unexpected SHOW INDEX layout for %s", table)
+	}
+
+	type indexColumn struct {
+		seq  int
+		name string
+	}
+	indexCols := make(map[string][]indexColumn)
+	indexes := make(map[string]model.SchemaIndex)
+	values := make([]sql.RawBytes, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan index: %w", err)
+		}
+		name := string(values[keyNameIdx])
+		if name == "PRIMARY" {
+			continue
+		}
+		seq, _ := strconv.Atoi(string(values[seqIdx]))
+		indexCols[name] = append(indexCols[name], indexColumn{seq: seq, name: string(values[columnIdx])})
+		indexes[name] = model.SchemaIndex{Name: name, Unique: string(values[nonUniqueIdx]) == "0"}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read indexes for %s: %w", table, err)
+	}
+	for name, cols := range indexCols {
+		sort.Slice(cols, func(i, j int) bool { return cols[i].seq < cols[j].seq })
+		index := indexes[name]
+		for _, c := range cols {
+			index.Columns = append(index.Columns, c.name)
+		}
+		indexes[name] = index
+	}
+	return indexes, nil
+}
+
+// loadSchemaForeignKeys reads every foreign key of the current database by table.
+func loadSchemaForeignKeys(ctx context.Context, conn *sql.Conn) (map[string][]model.SchemaForeignKey, error) {
+	rows, err := conn.QueryContext(ctx, schemaForeignKeyQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query foreign keys: %w", err)
+	}
+	defer rows.Close()
+
+	byTable := make(map[string][]model.SchemaForeignKey)
+	for rows.Next() {
+		var name, table, column, refTable, refColumn string
+		if err := rows.Scan(&name, &table, &column, &refTable, &refColumn); err != nil {
+			return nil, fmt.Errorf("failed to scan foreign key: %w", err)
+		}
+		fks := byTable[table]
+		if n := len(fks); n > 0 && fks[n-1].Name == name {
+			fks[n-1].Columns = append(fks[n-1].Columns, column)
+			fks[n-1].ReferencedColumns = append(fks[n-1].ReferencedColumns, refColumn)
+			continue
+		}
+		byTable[table] = append(fks, model.SchemaForeignKey{
+			Name:              name,
+			Columns:           []string{column},
+			ReferencedTable:   refTable,
+			ReferencedColumns: []string{refColumn},
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read foreign keys: %w", err)
+	}
+	return byTable, nil
+}
+
+// loadSchemaTables reads the columns, indexes and foreign keys of every user
+// table at (dbName, refName).
+func (s *Service) loadSchemaTables(ctx context.Context, targetID, dbName, refName string) (map[string]*schemaTable, error) {
+	tables, err := s.ListTables(ctx, targetID, dbName, refName)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := s.connHistoryRevision(ctx, targetID, dbName, refName)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	fks, err := loadSchemaForeignKeys(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]*schemaTable, len(tables))
+	for _, t := range tables {
+		cols, err := getSchemaColumns(ctx, conn, t.Name)
+		if err != nil {
+			return nil, err
+		}
+		indexes, err := loadIndexes(ctx, conn, t.Name)
+		if err != nil {
+			return nil, err
+		}
+		result[t.Name] = &schemaTable{columns: cols, indexes: indexes, foreignKeys: fks[t.Name]}
+	}
+	return result, nil
+}
+
+func foreignKeySignature(fk model.SchemaForeignKey) string {
+	return fmt.Sprintf("(%s) → %s(%s)", strings.Join(fk.Columns, ", "), fk.ReferencedTable, strings.Join(fk.ReferencedColumns, ", "))
+}
+
+// compareSchemaTable returns the differences of a table present on both
+// sides, or nil if its schema is identical.
+func compareSchemaTable(name string, from, to *schemaTable) *model.SchemaTableDiff {
+	diff := &model.SchemaTableDiff{
+		Table:       name,
+		Status:      model.SchemaDiffChanged,
+		Columns:     make([]model.SchemaColumnDiff, 0),
+		Indexes:     make([]model.SchemaIndexDiff, 0),
+		ForeignKeys: make([]model.SchemaForeignKeyDiff, 0),
+	}
+
+	toCols := make(map[string]model.ColumnSchema, len(to.columns))
+	for _, c := range to.columns {
+		toCols[c.Name] = c
+	}
+	fromCols := make(map[string]bool, len(from.columns))
+	for _, c := range from.columns {
+		fromCols[c.Name] = true
+		fc := c
+		tc, ok := toCols[c.Name]
+		if !ok {
+			diff.Columns = append(diff.Columns, model.SchemaColumnDiff{Column: c.Name, Status: model.SchemaDiffOnlyInFrom, From: &fc})
+			continue
+		}
+		var changes []string
+		if fc.Type != tc.Type {
+			changes = append(changes, "type")
+		}
+		if fc.Nullable != tc.Nullable {
+			changes = append(changes, "nullable")
+		}
+		if fc.PrimaryKey != tc.PrimaryKey {
+			changes = append(changes, "primary_key")
+		}
+		if len(changes) > 0 {
+			diff.Columns = append(diff.Columns, model.SchemaColumnDiff{Column: c.Name, Status: model.SchemaDiffChanged, From: &fc, To: &tc, Changes: changes})
+		}
+	}
+	for _, c := range to.columns {
+		if !fromCols[c.Name] {
+			tc := c
+			diff.Columns = append(diff.Columns, model.SchemaColumnDiff{Column: c.Name, Status: model.SchemaDiffOnlyInTo, To: &tc})
+		}
+	}
+
+	names := make(map[string]bool, len(from.indexes)+len(to.indexes))
+	for n := range from.indexes {
+		names[n] = true
+	}
+	for n := range to.indexes {
+		names[n] = true
+	}
+	indexNames := make([]string, 0, len(names))
+	for n := range names {
+		indexNames = append(indexNames, n)
+	}
+	sort.Strings(indexNames)
+	for _, n := range indexNames {
+		fi, inFrom := from.indexes[n]
+		ti, inTo := to.indexes[n]
+		switch {
+		case !inTo:
+			diff.Indexes = append(diff.Indexes, model.SchemaIndexDiff{Name: n, Status: model.SchemaDiffOnlyInFrom, From: &fi})
+		case !inFrom:
+			diff.Indexes = append(diff.Indexes, model.SchemaIndexDiff{Name: n, Status: model.SchemaDiffOnlyInTo, To: &ti})
+		case fi.Unique != ti.Unique || strings.Join(fi.Columns, "\x00") != strings.Join(ti.Columns, "\x00"):
+			diff.Indexes = append(diff.Indexes, model.SchemaIndexDiff{Name: n, Status: model.SchemaDiffChanged, From: &fi, To: &ti})
+		}
+	}
+
+	toFKs := make(map[string]bool, len(to.foreignKeys))
+	for _, fk := range to.foreignKeys {
+		toFKs[foreignKeySignature(fk)] = true
+	}
+	fromFKs := make(map[string]bool, len(from.foreignKeys))
+	for _, fk := range from.foreignKeys {
+		fromFKs[foreignKeySignature(fk)] = true
+		if !toFKs[foreignKeySignature(fk)] {
+			diff.ForeignKeys = append(diff.ForeignKeys, model.SchemaForeignKeyDiff{Status: model.SchemaDiffOnlyInFrom, ForeignKey: fk})
+		}
+	}
+	for _, fk := range to.foreignKeys {
+		if !fromFKs[foreignKeySignature(fk)] {
+			diff.ForeignKeys = append(diff.ForeignKeys, model.SchemaForeignKeyDiff{Status: model.SchemaDiffOnlyInTo, ForeignKey: fk})
+		}
+	}
+
+	shared, _, _ := computeSharedColumns(from.columns, to.columns)
+	diff.ExpandColumns = expandColumnsForShared(from.columns, to.columns, shared)
+
+	if len(diff.Columns) == 0 && len(diff.Indexes) == 0 && len(diff.ForeignKeys) == 0 && len(diff.ExpandColumns) == 0 {
+		return nil
+	}
+	return diff
+}
+
+// CompareSchemas compares the schema of every user table between two
+// (db, ref) pairs of a target: columns (type, nullability, primary key),
+// secondary indexes and foreign keys.
+func (s *Service) CompareSchemas(ctx context.Context, targetID, fromDB, fromRef, toDB, toRef string) (*model.SchemaCompareResponse, error) {
+	from, err := s.loadSchemaTables(ctx, targetID, fromDB, fromRef)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.loadSchemaTables(ctx, targetID, toDB, toRef)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(from)+len(to))
+	for n := range from {
+		names = append(names, n)
+	}
+	for n := range to {
+		if from[n] == nil {
+			names = append(names, n)
+		}
+	}
+	sort.Strings(names)
+
+	resp := &model.SchemaCompareResponse{
+		From:   model.SchemaRef{DB: fromDB, Ref: fromRef},
+		To:     model.SchemaRef{DB: toDB, Ref: toRef},
+		Tables: make([]model.SchemaTableDiff, 0),
+	}
+	for _, n := range names {
+		empty := model.SchemaTableDiff{
+			Table:         n,
+			Columns:       []model.SchemaColumnDiff{},
+			Indexes:       []model.SchemaIndexDiff{},
+			ForeignKeys:   []model.SchemaForeignKeyDiff{},
+			ExpandColumns: []model.ExpandColumn{},
+		}
+		switch {
+		case to[n] == nil:
+			empty.Status = model.SchemaDiffOnlyInFrom
+			resp.Tables = append(resp.Tables, empty)
+			resp.Summary.OnlyInFrom++
+		case from[n] == nil:
+			empty.Status = model.SchemaDiffOnlyInTo
+			resp.Tables = append(resp.Tables, empty)
+			resp.Summary.OnlyInTo++
+		default:
+			diff := compareSchemaTable(n, from[n], to[n])
+			if diff == nil {
+				resp.Summary.Identical++
+				continue
+			}
+			resp.Tables = append(resp.Tables, *diff)
+			resp.Summary.Changed++
+		}
+	}
+	return resp, nil
+}
+
+func describeColumn(c *model.ColumnSchema) string {
+	if c == nil {
+		return ""
+	}
+	desc := c.Type
+	if c.Nullable {
+		desc += " NULL"
+	} else {
+		desc += " NOT NULL"
+	}
+	if c.PrimaryKey {
+		desc += " PK"
+	}
+	return desc
+}
+
+func describeIndex(i *model.SchemaIndex) string {
+	if i == nil {
+		return ""
+	}
+	desc := "(" + strings.Join(i.Columns, ", ") + ")"
+	if i.Unique {
+		desc = "UNIQUE " + desc
+	}
+	return desc
+}
+
+// schemaCompareRecord is one line of the schema comparison export.
+type schemaCompareRecord struct {
+	table, kind, name, status, from, to, detail string
+}
+
+// schemaCompareRecords flattens a comparison for export. Widenings are listed
+// with the "expand" kind.
+func schemaCompareRecords(resp *model.SchemaCompareResponse) []schemaCompareRecord {
+	var records []schemaCompareRecord
+	for _, t := range resp.Tables {
+		if t.Status != model.SchemaDiffChanged {
+			records = append(records, schemaCompareRecord{table: t.Table, kind: "table", name: t.Table, status: t.Status})
+			continue
+		}
+		for _, c := range t.Columns {
+			records = append(records, schemaCompareRecord{
+				table: t.Table, kind: "column", name: c.Column, status: c.Status,
+				from: describeColumn(c.From), to: describeColumn(c.To), detail: strings.Join(c.Changes, ", "),
+			})
+		}
+		for _, i := range t.Indexes {
+			records = append(records, schemaCompareRecord{
+				table: t.Table, kind: "index", name: i.Name, status: i.Status,
+				from: describeIndex(i.From), to: describeIndex(i.To),
+			})
+		}
+		for _, fk := range t.ForeignKeys {
+			r := schemaCompareRecord{table: t.Table, kind: "foreign_key", name: fk.ForeignKey.Name, status: fk.Status}
+			if fk.Status == model.SchemaDiffOnlyInFrom {
+				r.from = foreignKeySignature(fk.ForeignKey)
+			} else {
+				r.to = foreignKeySignature(fk.ForeignKey)
+			}
+			records = append(records, r)
+		}
+		for _, e := range t.ExpandColumns {
+			records = append(records, schemaCompareRecord{
+				table: t.Table, kind: "expand", name: e.Name, status: model.SchemaDiffChanged,
+				from: e.SrcType, to: e.DstType, detail: "POST /cross-copy/admin/prepare-table",
+			})
+		}
+	}
+	return records
+}
+
+func markdownCell(v string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(v, "|", `\|`), "\n", " ")
+}
+
+func writeSchemaCompareMarkdown(buf *bytes.Buffer, resp *model.SchemaCompareResponse) {
+	fmt.Fprintf(buf, "# スキーマ比較: %s@%s → %s@%s\n\n", resp.From.DB, resp.From.Ref, resp.To.DB, resp.To.Ref)
+	buf.WriteString("| 一致 | 差分あり | from のみ | to のみ |\n|---|---|---|---|\n")
+	fmt.Fprintf(buf, "| %d | %d | %d | %d |\n", resp.Summary.Identical, resp.Summary.Changed, resp.Summary.OnlyInFrom, resp.Summary.OnlyInTo)
+
+	var expand []schemaCompareRecord
+	records := schemaCompareRecords(resp)
+	table := ""
+	for _, r := range records {
+		if r.kind == "expand" {
+			expand = append(expand, r)
+			continue
+		}
+		if r.table != table {
+			table = r.table
+			fmt.Fprintf(buf, "\n## %s\n\n", markdownCell(table))
+			if r.kind == "table" {
+				fmt.Fprintf(buf, "%s\n", r.status)
+				continue
+			}
+			buf.WriteString("| 種別 | 名前 | 状態 | from | to | 差分 |\n|---|---|---|---|---|---|\n")
+		}
+		fmt.Fprintf(buf, "| %s | %s | %s | %s | %s | %s |\n",
+			r.kind, markdownCell(r.name), r.status, markdownCell(r.from), markdownCell(r.to), markdownCell(r.detail))
+	}
+
+	if len(expand) > 0 {
+		buf.WriteString("\n## クロスコピー前に拡張が必要なカラム\n\n")
+		buf.WriteString("`POST /cross-copy/admin/prepare-table` で to 側を拡張してください。\n\n")
+		buf.WriteString("| テーブル | カラム | from | to |\n|---|---|---|---|\n")
+		for _, r := range expand {
+			fmt.Fprintf(buf, "| %s | %s | %s | %s |\n", markdownCell(r.table), markdownCell(r.name), markdownCell(r.from), markdownCell(r.to))
+		}
+	}
+}
+
+// ExportSchemaCompare renders CompareSchemas as a Markdown report or as a CSV
+// with one line per difference. The CSV has a UTF-8 BOM so that Excel opens it
+// correctly.
+func (s *Service) ExportSchemaCompare(ctx context.Context, targetID, fromDB, fromRef, toDB, toRef, format string) ([]byte, string, error) {
+	if format != "markdown" && format != "csv" {
+		return nil, "", &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "format must be markdown or csv"}
+	}
+	resp, err := s.CompareSchemas(ctx, targetID, fromDB, fromRef, toDB, toRef)
+	if err != nil {
+		return nil, "", err
+	}
+
+	fileName := strings.NewReplacer("/", "-", "^", "", "~", "").Replace(
+		fmt.Sprintf("schema-compare-%s-%s-%s-%s", fromDB, fromRef, toDB, toRef))
+	var buf bytes.Buffer
+	if format == "markdown" {
+		writeSchemaCompareMarkdown(&buf, resp)
+		return buf.Bytes(), fileName + ".md", nil
+	}
+
+	buf.WriteString("\ufeff")
+	cw := csv.NewWriter(&buf)
+	if err := cw.Write([]string{"table", "kind", "name", "status", "from", "to", "detail"}); err != nil {
+		return nil, "", err
+	}
+	for _, r := range schemaCompareRecords(resp) {
+		if err := cw.Write([]string{r.table, r.kind, r.name, r.status, r.from, r.to, r.detail}); err != nil {
+			return nil, "", err
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), fileName + ".csv", nil
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+)
+
+func TestCompareSchemas_ReportsTableColumnIndexAndForeignKeyDiffs(t *testing.T) {
+	type side struct {
+		tables  []string
+		columns map[string][]model.ColumnSchema
+		indexes map[string][][]driver.Value
+		fks     [][]driver.Value
+	}
+	sides := map[string]side{
+		"main": {
+			tables: []string{"legacy", "orders", "users"},
+			columns: map[string][]model.ColumnSchema{
+				"legacy": {{Name: "id", Type: "int", PrimaryKey: true}},
+				"orders": {{Name: "id", Type: "int", PrimaryKey: true}, {Name: "user_id", Type: "int"}},
+				"users": {
+					{Name: "id", Type: "int", PrimaryKey: true},
+					{Name: "name", Type: "varchar(100)"},
+					{Name: "email", Type: "varchar(255)", Nullable: true},
+				},
+			},
+			indexes: map[string][][]driver.Value{
+				"users": {{"users", "0", "PRIMARY", "1", "id"}, {"users", "0", "idx_email", "1", "email"}},
+			},
+			fks: [][]driver.Value{{"fk_orders_user", "orders", "user_id", "users", "id"}},
+		},
+		"audit": {
+			tables: []string{"orders", "staging_users", "users"},
+			columns: map[string][]model.ColumnSchema{
+				"orders":        {{Name: "id", Type: "int", PrimaryKey: true}, {Name: "user_id", Type: "int"}},
+				"staging_users": {{Name: "id", Type: "int", PrimaryKey: true}},
+				"users": {
+					{Name: "id", Type: "int", PrimaryKey: true},
+					{Name: "name", Type: "varchar(50)"},
+					{Name: "email", Type: "varchar(255)"},
+					{Name: "note", Type: "text", Nullable: true},
+				},
+			},
+			indexes: map[string][][]driver.Value{
+				"users": {{"users", "1", "idx_email", "2", "name"}, {"users", "1", "idx_email", "1", "email"}},
+			},
+		},
+	}
+	repo := newCrossCopyTestRepo(t,
+		func(dbName, refName, query string, args []driver.NamedValue) (testQueryResult, error) {
+			sd := sides[refName]
+			switch {
+			case query == "SHOW FULL TABLES WHERE Table_type = 'BASE TABLE'":
+				result := testQueryResult{columns: []string{"Tables_in_test_db", "Table_type"}}
+				for _, name := range sd.tables {
+					result.rows = append(result.rows, []driver.Value{name, "BASE TABLE"})
+				}
+				return result, nil
+			case query == schemaForeignKeyQuery:
+				return testQueryResult{
+					columns: []string{"CONSTRAINT_NAME", "TABLE_NAME", "COLUMN_NAME", "REFERENCED_TABLE_NAME", "REFERENCED_COLUMN_NAME"},
+					rows:    sd.fks,
+				}, nil
+			case strings.HasPrefix(query, "SHOW COLUMNS FROM "):
+				return schemaResult(sd.columns[strings.Trim(strings.TrimPrefix(query, "SHOW COLUMNS FROM "), "`")]), nil
+			case strings.HasPrefix(query, "SHOW INDEX FROM "):
+				return testQueryResult{
+					columns: []string{"Table", "Non_unique", "Key_name", "Seq_in_index", "Column_name"},
+					rows:    sd.indexes[strings.Trim(strings.TrimPrefix(query, "SHOW INDEX FROM "), "`")],
+				}, nil
+			}
+			return testQueryResult{}, fmt.Errorf("unexpected revision query on %s/%s: %s", dbName, refName, query)
+		},
+		nil,
+	)
+	svc := newWithDeps(repo, testServiceConfig())
+
+	resp, err := svc.CompareSchemas(context.Background(), "local", "test_db", "main", "test_db", "audit")
+	if err != nil {
+		t.Fatalf("CompareSchemas: %v", err)
+	}
+	if want := (model.SchemaCompareSummary{Changed: 2, OnlyInFrom: 1, OnlyInTo: 1}); resp.Summary != want {
+		t.Fatalf("summary = %+v, want %+v", resp.Summary, want)
+	}
+	var statuses []string
+	for _, table := range resp.Tables {
+		statuses = append(statuses, table.Table+":"+table.Status)
+	}
+	if want := []string{"legacy:only_in_from", "orders:changed", "staging_users:only_in_to", "users:changed"}; !reflect.DeepEqual(statuses, want) {
+		t.Fatalf("tables = %v, want %v", statuses, want)
+	}
+
+	orders := resp.Tables[1]
+	if len(orders.Columns) != 0 || len(orders.ForeignKeys) != 1 || orders.ForeignKeys[0].Status != model.SchemaDiffOnlyInFrom ||
+		!reflect.DeepEqual(orders.ForeignKeys[0].ForeignKey.ReferencedColumns, []string{"id"}) {
+		t.Fatalf("unexpected orders diff: %+v", orders)
+	}
+	users := resp.Tables[3]
+	var columns []string
+	for _, c := range users.Columns {
+		columns = append(columns, fmt.Sprintf("%s:%s:%v", c.Column, c.Status, c.Changes))
+	}
+	if want := []string{"name:changed:[type]", "email:changed:[nullable]", "note:only_in_to:[]"}; !reflect.DeepEqual(columns, want) {
+		t.Fatalf("users columns = %v, want %v", columns, want)
+	}
+	if len(users.Indexes) != 1 || users.Indexes[0].Status != model.SchemaDiffChanged ||
+		!reflect.DeepEqual(users.Indexes[0].To.Columns, []string{"email", "name"}) || users.Indexes[0].To.Unique {
+		t.Fatalf("unexpected users indexes: %+v", users.Indexes)
+	}
+	if len(users.ExpandColumns) != 1 || users.ExpandColumns[0].Name != "name" {
+		t.Fatalf("expected name to need widening, got %+v", users.ExpandColumns)
+	}
+
+	data, fileName, err := svc.ExportSchemaCompare(context.Background(), "local", "test_db", "main", "test_db", "audit", "csv")
+	if err != nil {
+		t.Fatalf("ExportSchemaCompare: %v", err)
+	}
+	csvText := string(data)
+	if fileName != "schema-compare-test_db-main-test_db-audit.csv" || !strings.HasPrefix(csvText, "\ufefftable,kind,") ||
+		!strings.Contains(csvText, "users,expand,name,changed,varchar(100),varchar(50),") {
+		t.Fatalf("unexpected csv export %s:\n%s", fileName, csvText)
+	}
+	data, _, err = svc.ExportSchemaCompare(context.Background(), "local", "test_db", "main", "test_db", "audit", "markdown")
+	if err != nil || !strings.Contains(string(data), "| users | name | varchar(100) | varchar(50) |") {
+		t.Fatalf("unexpected markdown export (%v):\n%s", err, data)
+	}
+}
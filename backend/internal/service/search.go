@@ -2,7 +2,6 @@ package service
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
@@ -93,7 +92,7 @@ func (s *Service) Search(ctx context.Context, targetID, dbName, branchName, keyw
 			rows.Close()
 			return nil, fmt.Errorf("failed to scan table name: %w", err)
 		}
-		if !strings.HasPrefix(name, "dolt_") && !strings.HasPrefix(name, "_memo_") && !strings.HasPrefix(name, "_cell_") {
+		if !isHiddenTableName(name) {
 			tableNames = append(tableNames, name)
 		}
 	}
@@ -318,7 +317,7 @@ func (s *Service) Search(ctx context.Context, targetID, dbName, branchName, keyw
 
 			// Build compact pk string from JSON object
 			var pkMap map[string]interface{}
-			if json.Unmarshal([]byte(pkJSON), &pkMap) == nil && len(pkMap) == 1 {
+			if unmarshalJSONNumbers([]byte(pkJSON), &pkMap) == nil && len(pkMap) == 1 {
 				// single-PK: just use the value string
 				for _, v := range pkMap {
 					pkJSON = fmt.Sprintf("%v", v)
@@ -45,12 +45,19 @@ type Service struct {
 	repo                 sessionRepository
 	cfg                  *config.Config
 	branchReadinessProbe branchReadinessProbe
+	mergeChecks          *mergeCheckCache
 
 	// Approve postcondition hooks — set to real implementations by default.
 	// Override in tests to inject failures without SQL mocking.
 	approveCreateSecondaryIndexHook approveCreateSecondaryIndexFn
 	approveDeleteRequestTagHook     approveDeleteRequestTagFn
 	approveAdvanceWorkBranchHook    approveAdvanceWorkBranchFn
+
+	mergeQueues         *mergeQueueRegistry
+	mergeQueueMergeHook mergeQueueMergeFn
+
+	replication *replicationRegistry
+	uploads     *uploadStore
 }
 
 func New(repo *repository.Repository, cfg *config.Config) *Service {
@@ -59,12 +66,17 @@ func New(repo *repository.Repository, cfg *config.Config) *Service {
 
 func newWithDeps(repo sessionRepository, cfg *config.Config) *Service {
 	svc := &Service{
-		repo: repo,
-		cfg:  cfg,
+		repo:        repo,
+		cfg:         cfg,
+		mergeChecks: newMergeCheckCache(),
+		mergeQueues: newMergeQueueRegistry(),
+		replication: newReplicationRegistry(),
+		uploads:     newUploadStore(),
 	}
 	svc.branchReadinessProbe = svc.probeBranchReadiness
 	svc.approveCreateSecondaryIndexHook = svc.createArchiveTag
 	svc.approveDeleteRequestTagHook = svc.defaultDeleteRequestTag
 	svc.approveAdvanceWorkBranchHook = svc.defaultAdvanceWorkBranchForApprove
+	svc.mergeQueueMergeHook = svc.mergeQueuedRequest
 	return svc
 }
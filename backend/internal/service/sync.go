@@ -18,6 +18,7 @@ import (
 //  4. START TRANSACTION + DOLT_MERGE('main')
 //  5. If mergeConflicts > 0: DOLT_CONFLICTS_RESOLVE('--theirs', table) for each table → DOLT_COMMIT
 //  6. Return SyncResponse with overwritten_tables list (non-empty when auto-resolution occurred)
+//     and behind_main (main commits the branch lacked before the merge)
 func (s *Service) Sync(ctx context.Context, req model.SyncRequest) (*model.SyncResponse, error) {
 	if validation.IsProtectedBranch(req.BranchName) {
 		return nil, &model.APIError{Status: 403, Code: model.CodeForbidden, Msg: "sync on protected branch is forbidden"}
@@ -61,6 +62,19 @@ func (s *Service) Sync(ctx context.Context, req model.SyncRequest) (*model.SyncR
 		}
 	}
 
+	behindMain, err := commitsBehindMain(ctx, conn)
+	if err != nil {
+		safeRollback(conn)
+		return nil, err
+	}
+	var baseRef string
+	if base, err := readBranchBase(ctx, conn, req.BranchName); err != nil {
+		safeRollback(conn)
+		return nil, err
+	} else if base != nil {
+		baseRef = base.FromRef
+	}
+
 	var mergeHash string
 	var fastForward, mergeConflicts int
 	var mergeMessage string
@@ -88,7 +102,7 @@ func (s *Service) Sync(ctx context.Context, req model.SyncRequest) (*model.SyncR
 		if err := conn.QueryRowContext(ctx, "SELECT DOLT_HASHOF('HEAD')").Scan(&newHead); err != nil {
 			return nil, fmt.Errorf("failed to get new HEAD: %w", err)
 		}
-		return &model.SyncResponse{Hash: newHead, OverwrittenTables: overwritten}, nil
+		return &model.SyncResponse{Hash: newHead, OverwrittenTables: overwritten, BehindMain: behindMain, BaseRef: baseRef}, nil
 	}
 
 	// No conflicts: merge already committed at Dolt level, close the SQL transaction
@@ -101,7 +115,7 @@ func (s *Service) Sync(ctx context.Context, req model.SyncRequest) (*model.SyncR
 	if err := conn.QueryRowContext(ctx, "SELECT DOLT_HASHOF('HEAD')").Scan(&newHead); err != nil {
 		return nil, fmt.Errorf("failed to get new HEAD: %w", err)
 	}
-	return &model.SyncResponse{Hash: newHead}, nil
+	return &model.SyncResponse{Hash: newHead, BehindMain: behindMain, BaseRef: baseRef}, nil
 }
 
 // previewMergeInfo runs DOLT_PREVIEW_MERGE_CONFLICTS_SUMMARY.
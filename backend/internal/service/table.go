@@ -6,7 +6,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"strconv"
 	"strings"
 
 	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
@@ -38,15 +37,15 @@ func buildFilterSQL(f model.FilterCondition, allowedCols map[string]bool) (strin
 	}
 	switch f.Op {
 	case "eq":
-		return fmt.Sprintf("`%s` = ?", f.Column), []interface{}{f.Value}, nil
+		return fmt.Sprintf("`%s` = ?", f.Column), []interface{}{sqlArgFromJSON(f.Value)}, nil
 	case "neq":
-		return fmt.Sprintf("`%s` != ?", f.Column), []interface{}{f.Value}, nil
+		return fmt.Sprintf("`%s` != ?", f.Column), []interface{}{sqlArgFromJSON(f.Value)}, nil
 	case "contains":
-		return fmt.Sprintf("`%s` LIKE CONCAT('%%', ?, '%%')", f.Column), []interface{}{f.Value}, nil
+		return fmt.Sprintf("`%s` LIKE CONCAT('%%', ?, '%%')", f.Column), []interface{}{sqlArgFromJSON(f.Value)}, nil
 	case "startsWith":
-		return fmt.Sprintf("`%s` LIKE CONCAT(?, '%%')", f.Column), []interface{}{f.Value}, nil
+		return fmt.Sprintf("`%s` LIKE CONCAT(?, '%%')", f.Column), []interface{}{sqlArgFromJSON(f.Value)}, nil
 	case "endsWith":
-		return fmt.Sprintf("`%s` LIKE CONCAT('%%', ?)", f.Column), []interface{}{f.Value}, nil
+		return fmt.Sprintf("`%s` LIKE CONCAT('%%', ?)", f.Column), []interface{}{sqlArgFromJSON(f.Value)}, nil
 	case "blank":
 		return fmt.Sprintf("`%s` IS NULL", f.Column), nil, nil
 	case "notBlank":
@@ -60,7 +59,7 @@ func buildFilterSQL(f model.FilterCondition, allowedCols map[string]bool) (strin
 		args := make([]interface{}, len(vals))
 		for i, v := range vals {
 			placeholders[i] = "?"
-			args[i] = v
+			args[i] = sqlArgFromJSON(v)
 		}
 		return fmt.Sprintf("`%s` IN (%s)", f.Column, strings.Join(placeholders, ",")), args, nil
 	default:
@@ -134,7 +133,7 @@ func (s *Service) ListTables(ctx context.Context, targetID, dbName, branchName s
 			return nil, fmt.Errorf("failed to scan table: %w", err)
 		}
 		// Exclude dolt_ system tables, _cell_ legacy tables, and _memo_ hidden tables
-		if strings.HasPrefix(name, "dolt_") || strings.HasPrefix(name, "_cell_") || strings.HasPrefix(name, "_memo_") {
+		if isHiddenTableName(name) {
 			continue
 		}
 		tables = append(tables, model.TableResponse{Name: name})
@@ -196,6 +195,7 @@ func (s *Service) GetTableRows(ctx context.Context, targetID, dbName, branchName
 	}
 
 	allowedCols := make(map[string]bool)
+	kinds := columnKindsFromSchema(schema.Columns)
 	var pkCols []string
 	for _, col := range schema.Columns {
 		allowedCols[col.Name] = true
@@ -219,7 +219,7 @@ func (s *Service) GetTableRows(ctx context.Context, targetID, dbName, branchName
 	var whereArgs []interface{}
 	if filterJSON != "" {
 		var filters []model.FilterCondition
-		if err := json.Unmarshal([]byte(filterJSON), &filters); err != nil {
+		if err := unmarshalJSONNumbers([]byte(filterJSON), &filters); err != nil {
 			return &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "invalid filter JSON"}
 		}
 		for _, f := range filters {
@@ -287,15 +287,7 @@ func (s *Service) GetTableRows(ctx context.Context, targetID, dbName, branchName
 		if err := rows.Scan(valuePtrs...); err != nil {
 			return fmt.Errorf("failed to scan row: %w", err)
 		}
-		row := make(map[string]interface{})
-		for i, col := range colNames {
-			val := values[i]
-			if b, ok := val.([]byte); ok {
-				row[col] = string(b)
-			} else {
-				row[col] = val
-			}
-		}
+		row := formatRowValues(colNames, values, kinds)
 
 		rowBytes, err := json.Marshal(row)
 		if err != nil {
@@ -322,7 +314,7 @@ func (s *Service) GetTableRow(ctx context.Context, targetID, dbName, branchName,
 
 	// Parse PK — supports single and composite keys
 	var pkMap map[string]interface{}
-	if err := json.Unmarshal([]byte(pkJSON), &pkMap); err != nil {
+	if err := unmarshalJSONNumbers([]byte(pkJSON), &pkMap); err != nil {
 		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "invalid pk JSON"}
 	}
 	if len(pkMap) < 1 {
@@ -336,12 +328,8 @@ func (s *Service) GetTableRow(ctx context.Context, targetID, dbName, branchName,
 		if err := validation.ValidateIdentifier("pk column", k); err != nil {
 			return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "invalid pk column name: " + k}
 		}
-		// Convert float64 (JSON number) to integer string for query
-		if fv, ok := v.(float64); ok && fv == float64(int64(fv)) {
-			v = strconv.FormatInt(int64(fv), 10)
-		}
 		whereParts = append(whereParts, fmt.Sprintf("`%s` = ?", k))
-		whereArgs = append(whereArgs, v)
+		whereArgs = append(whereArgs, sqlArgFromJSON(v))
 	}
 
 	conn, err := s.connHistoryRevision(ctx, targetID, dbName, branchName)
@@ -350,6 +338,11 @@ func (s *Service) GetTableRow(ctx context.Context, targetID, dbName, branchName,
 	}
 	defer conn.Close()
 
+	schemaCols, err := getSchemaColumns(ctx, conn, table)
+	if err != nil {
+		return nil, err
+	}
+
 	query := fmt.Sprintf("SELECT * FROM `%s` WHERE %s LIMIT 1", table, strings.Join(whereParts, " AND "))
 	rows, err := conn.QueryContext(ctx, query, whereArgs...)
 	if err != nil {
@@ -375,14 +368,5 @@ func (s *Service) GetTableRow(ctx context.Context, targetID, dbName, branchName,
 		return nil, fmt.Errorf("failed to scan row: %w", err)
 	}
 
-	row := make(map[string]interface{})
-	for i, col := range colNames {
-		val := values[i]
-		if b, ok := val.([]byte); ok {
-			row[col] = string(b)
-		} else {
-			row[col] = val
-		}
-	}
-	return row, nil
+	return formatRowValues(colNames, values, columnKindsFromSchema(schemaCols)), nil
 }
@@ -1,8 +1,11 @@
 package service
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -58,6 +61,14 @@ func archiveTagForWorkItem(workItem string, sequence int) string {
 	return fmt.Sprintf("merged/%s/%02d", workItem, sequence)
 }
 
+func baseTagForWorkItem(workItem string) string {
+	return "base/" + workItem
+}
+
+func abandonedTagForWorkItem(workItem, date string) string {
+	return "archived/" + workItem + "/" + date
+}
+
 func archiveTagPrefixForWorkItem(workItem string) string {
 	return "merged/" + workItem + "/"
 }
@@ -89,3 +100,25 @@ func importWorkItemName(sourceDB, tableName string) string {
 func importWorkBranchName(sourceDB, tableName string) string {
 	return "wi/" + importWorkItemName(sourceDB, tableName)
 }
+
+// importTablesNameMaxLen bounds the table part of a multi-table import branch name.
+const importTablesNameMaxLen = 64
+
+// importTablesBranchName names the import branch of a multi-table copy after
+// the sorted table names, e.g. wi/import-src-order_lines.orders. When the joined
+// names exceed importTablesNameMaxLen, the first table plus a short hash of the
+// full list is used instead, e.g. wi/import-src-customers.and-11-more-3f9a0c1d2b4e.
+func importTablesBranchName(sourceDB string, tables []string) string {
+	sorted := append([]string(nil), tables...)
+	sort.Strings(sorted)
+	joined := strings.Join(sorted, ".")
+	if len(joined) <= importTablesNameMaxLen {
+		return importWorkBranchName(sourceDB, joined)
+	}
+	sum := sha256.Sum256([]byte(joined))
+	first := sorted[0]
+	if len(first) > importTablesNameMaxLen/2 {
+		first = first[:importTablesNameMaxLen/2]
+	}
+	return importWorkBranchName(sourceDB, fmt.Sprintf("%s.and-%d-more-%s", first, len(sorted)-1, hex.EncodeToString(sum[:6])))
+}
@@ -0,0 +1,377 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+)
+
+// workItemMetaTagPrefix marks work item registry entries. The tag
+// wimeta/<WorkItem> carries workItemMetaRecord as JSON. Tags keep the registry
+// out of user data: saving an entry never commits to (or moves) main.
+const workItemMetaTagPrefix = "wimeta/"
+
+const (
+	workItemMetaMaxField = 255
+	workItemMetaMaxLabel = 64
+)
+
+type workItemMetaRecord struct {
+	Schema string `json:"schema"`
+	model.WorkItemMeta
+}
+
+func isHiddenTableName(name string) bool {
+	return strings.HasPrefix(name, "dolt_") || strings.HasPrefix(name, "_cell_") || strings.HasPrefix(name, "_memo_")
+}
+
+func workItemMetaTag(workItem string) string {
+	return workItemMetaTagPrefix + workItem
+}
+
+func parseWorkItemMeta(tagName, message string) (model.WorkItemMeta, bool) {
+	var record workItemMetaRecord
+	if err := json.Unmarshal([]byte(message), &record); err != nil {
+		log.Printf("WARN: failed to parse work item metadata tag %s: %v", tagName, err)
+		return model.WorkItemMeta{}, false
+	}
+	meta := record.WorkItemMeta
+	meta.WorkItem = strings.TrimPrefix(tagName, workItemMetaTagPrefix)
+	if meta.Labels == nil {
+		meta.Labels = []string{}
+	}
+	return meta, true
+}
+
+// replaceMessageTag points tagName at main with message, replacing any
+// previous tag of that name.
+func replaceMessageTag(ctx context.Context, conn *sql.Conn, tagName, message string) error {
+	if _, err := deleteTag(ctx, conn, tagName); err != nil {
+		return err
+	}
+	if _, err := conn.ExecContext(ctx, "CALL DOLT_TAG('-m', ?, ?, 'main')", message, tagName); err != nil {
+		return fmt.Errorf("failed to create tag %s: %w", tagName, err)
+	}
+	return nil
+}
+
+// deleteTag removes tagName and reports whether it existed.
+func deleteTag(ctx context.Context, conn *sql.Conn, tagName string) (bool, error) {
+	var count int
+	if err := conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM dolt_tags WHERE tag_name = ?", tagName).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to check tag %s: %w", tagName, err)
+	}
+	if count == 0 {
+		return false, nil
+	}
+	if _, err := conn.ExecContext(ctx, "CALL DOLT_TAG('-d', ?)", tagName); err != nil {
+		return false, fmt.Errorf("failed to delete tag %s: %w", tagName, err)
+	}
+	return true, nil
+}
+
+// loadWorkItemMetas returns every registry entry keyed by work item.
+func loadWorkItemMetas(ctx context.Context, conn *sql.Conn) (map[string]model.WorkItemMeta, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT tag_name, message FROM dolt_tags WHERE tag_name LIKE ?", workItemMetaTagPrefix+"%")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query work item metadata: %w", err)
+	}
+	defer rows.Close()
+
+	metas := make(map[string]model.WorkItemMeta)
+	for rows.Next() {
+		var tagName, message string
+		if err := rows.Scan(&tagName, &message); err != nil {
+			return nil, fmt.Errorf("failed to scan work item metadata: %w", err)
+		}
+		if meta, ok := parseWorkItemMeta(tagName, message); ok {
+			metas[meta.WorkItem] = meta
+		}
+	}
+	return metas, rows.Err()
+}
+
+// readWorkItemMeta returns the registry entry for one work item, or nil.
+func readWorkItemMeta(ctx context.Context, conn *sql.Conn, workItem string) (*model.WorkItemMeta, error) {
+	tagName := workItemMetaTag(workItem)
+	var message string
+	err := conn.QueryRowContext(ctx, "SELECT message FROM dolt_tags WHERE tag_name = ?", tagName).Scan(&message)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query work item metadata: %w", err)
+	}
+	meta, ok := parseWorkItemMeta(tagName, message)
+	if !ok {
+		return nil, nil
+	}
+	return &meta, nil
+}
+
+// loadWorkItemMetasSoft is loadWorkItemMetas for read paths that only decorate
+// their results: failures are logged and yield no metadata.
+func loadWorkItemMetasSoft(ctx context.Context, conn *sql.Conn) map[string]model.WorkItemMeta {
+	metas, err := loadWorkItemMetas(ctx, conn)
+	if err != nil {
+		log.Printf("WARN: work item metadata unavailable: %v", err)
+		return map[string]model.WorkItemMeta{}
+	}
+	return metas
+}
+
+// workItemMetaForBranch looks up the registry entry for a wi/<WorkItem> branch.
+func workItemMetaForBranch(metas map[string]model.WorkItemMeta, branchName string) *model.WorkItemMeta {
+	workItem, ok := workItemFromWorkBranch(branchName)
+	if !ok {
+		return nil
+	}
+	meta, ok := metas[workItem]
+	if !ok {
+		return nil
+	}
+	return &meta
+}
+
+// ticketIDForWorkItem reads the ticket ID for the approval footer. Best-effort.
+func ticketIDForWorkItem(ctx context.Context, conn *sql.Conn, workItem string) string {
+	meta, err := readWorkItemMeta(ctx, conn, workItem)
+	if err != nil {
+		log.Printf("WARN: failed to read ticket id for work item %s: %v", workItem, err)
+	}
+	if meta == nil {
+		return ""
+	}
+	return meta.TicketID
+}
+
+func validateWorkItemMeta(req model.SaveWorkItemMetaRequest) (model.SaveWorkItemMetaRequest, error) {
+	invalid := func(msg string) error {
+		return &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: msg}
+	}
+	if !workItemNameRe.MatchString(req.WorkItem) {
+		return req, invalid("work_item must match [A-Za-z0-9._-]+")
+	}
+	req.Owner = strings.TrimSpace(req.Owner)
+	req.TicketID = strings.TrimSpace(req.TicketID)
+	if len(req.WorkItem) > workItemMetaMaxField || len(req.Owner) > workItemMetaMaxField || len(req.TicketID) > workItemMetaMaxField {
+		return req, invalid(fmt.Sprintf("work_item, owner and ticket_id must be at most %d bytes", workItemMetaMaxField))
+	}
+	if strings.ContainsAny(req.TicketID, "\r\n") {
+		return req, invalid("ticket_id must be a single line")
+	}
+	if req.DueDate != "" {
+		if _, err := time.Parse("2006-01-02", req.DueDate); err != nil {
+			return req, invalid("due_date must be YYYY-MM-DD")
+		}
+	}
+	labels := make([]string, 0, len(req.Labels))
+	seen := make(map[string]bool)
+	for _, label := range req.Labels {
+		label = strings.TrimSpace(label)
+		if label == "" || seen[label] {
+			continue
+		}
+		if len(label) > workItemMetaMaxLabel {
+			return req, invalid(fmt.Sprintf("labels must be at most %d bytes each", workItemMetaMaxLabel))
+		}
+		seen[label] = true
+		labels = append(labels, label)
+	}
+	req.Labels = labels
+	return req, nil
+}
+
+// SaveWorkItemMeta creates or replaces the registry entry for a work item.
+func (s *Service) SaveWorkItemMeta(ctx context.Context, req model.SaveWorkItemMetaRequest) (*model.WorkItemMeta, error) {
+	req, err := validateWorkItemMeta(req)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.configuredDatabase(req.TargetID, req.DBName); err != nil {
+		return nil, err
+	}
+
+	meta := model.WorkItemMeta{
+		WorkItem:    req.WorkItem,
+		Owner:       req.Owner,
+		TicketID:    req.TicketID,
+		Description: req.Description,
+		Labels:      req.Labels,
+		DueDate:     req.DueDate,
+		UpdatedAt:   time.Now().UTC().Format("2006-01-02T15:04:05"),
+	}
+	message, err := json.Marshal(workItemMetaRecord{Schema: "dolt-webui/workitem-meta@1", WorkItemMeta: meta})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal work item metadata: %w", err)
+	}
+
+	conn, err := s.repo.ConnProtectedMaintenance(ctx, req.TargetID, req.DBName, "main")
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close()
+
+	if err := replaceMessageTag(ctx, conn, workItemMetaTag(req.WorkItem), string(message)); err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
+
+// DeleteWorkItemMeta removes the registry entry for a work item.
+func (s *Service) DeleteWorkItemMeta(ctx context.Context, req model.DeleteWorkItemMetaRequest) error {
+	if !workItemNameRe.MatchString(req.WorkItem) {
+		return &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "work_item must match [A-Za-z0-9._-]+"}
+	}
+	if _, err := s.configuredDatabase(req.TargetID, req.DBName); err != nil {
+		return err
+	}
+
+	conn, err := s.repo.ConnProtectedMaintenance(ctx, req.TargetID, req.DBName, "main")
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close()
+
+	deleted, err := deleteTag(ctx, conn, workItemMetaTag(req.WorkItem))
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return &model.APIError{Status: 404, Code: model.CodeNotFound, Msg: fmt.Sprintf("work item %s not found", req.WorkItem)}
+	}
+	return nil
+}
+
+// GetWorkItemMeta returns the registry entry for one work item.
+func (s *Service) GetWorkItemMeta(ctx context.Context, targetID, dbName, workItem string) (*model.WorkItemMeta, error) {
+	if !workItemNameRe.MatchString(workItem) {
+		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "work_item must match [A-Za-z0-9._-]+"}
+	}
+	conn, err := s.connMetadataRevision(ctx, targetID, dbName)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	meta, err := readWorkItemMeta(ctx, conn, workItem)
+	if err != nil {
+		return nil, err
+	}
+	if meta == nil {
+		return nil, &model.APIError{Status: 404, Code: model.CodeNotFound, Msg: fmt.Sprintf("work item %s not found", workItem)}
+	}
+	return meta, nil
+}
+
+// ListWorkItemMeta returns registry entries, filtered and sorted in Go.
+func (s *Service) ListWorkItemMeta(ctx context.Context, targetID, dbName string, filter model.WorkItemMetaFilter) ([]model.WorkItemMeta, error) {
+	less, err := workItemMetaLess(filter.Sort)
+	if err != nil {
+		return nil, err
+	}
+	if filter.Order != "" && filter.Order != "asc" && filter.Order != "desc" {
+		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "order must be asc or desc"}
+	}
+	if filter.DueBefore != "" {
+		if _, err := time.Parse("2006-01-02", filter.DueBefore); err != nil {
+			return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: "due_before must be YYYY-MM-DD"}
+		}
+	}
+
+	conn, err := s.connMetadataRevision(ctx, targetID, dbName)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	metas, err := loadWorkItemMetas(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]model.WorkItemMeta, 0, len(metas))
+	for _, meta := range metas {
+		if matchesWorkItemMetaFilter(meta, filter) {
+			result = append(result, meta)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if filter.Order == "desc" {
+			return less(result[j], result[i])
+		}
+		return less(result[i], result[j])
+	})
+	return result, nil
+}
+
+func matchesWorkItemMetaFilter(meta model.WorkItemMeta, filter model.WorkItemMetaFilter) bool {
+	if filter.Owner != "" && meta.Owner != filter.Owner {
+		return false
+	}
+	if filter.TicketID != "" && meta.TicketID != filter.TicketID {
+		return false
+	}
+	if filter.Label != "" {
+		found := false
+		for _, label := range meta.Labels {
+			if label == filter.Label {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if filter.Keyword != "" {
+		keyword := strings.ToLower(filter.Keyword)
+		if !strings.Contains(strings.ToLower(meta.WorkItem), keyword) && !strings.Contains(strings.ToLower(meta.Description), keyword) {
+			return false
+		}
+	}
+	if filter.DueBefore != "" && (meta.DueDate == "" || meta.DueDate > filter.DueBefore) {
+		return false
+	}
+	return true
+}
+
+// workItemMetaLess orders by the requested field, then by work item.
+// Entries without a due date sort after dated ones.
+func workItemMetaLess(field string) (func(a, b model.WorkItemMeta) bool, error) {
+	var key func(m model.WorkItemMeta) string
+	switch field {
+	case "", "work_item":
+		key = func(m model.WorkItemMeta) string { return "" }
+	case "owner":
+		key = func(m model.WorkItemMeta) string { return m.Owner }
+	case "ticket_id":
+		key = func(m model.WorkItemMeta) string { return m.TicketID }
+	case "due_date":
+		key = func(m model.WorkItemMeta) string {
+			if m.DueDate == "" {
+				return "9999-99-99"
+			}
+			return m.DueDate
+		}
+	case "updated_at":
+		key = func(m model.WorkItemMeta) string { return m.UpdatedAt }
+	default:
+		return nil, &model.APIError{Status: 400, Code: model.CodeInvalidArgument, Msg: fmt.Sprintf("unsupported sort field: %s", field)}
+	}
+	return func(a, b model.WorkItemMeta) bool {
+		ka, kb := key(a), key(b)
+		if ka != kb {
+			return ka < kb
+		}
+		return a.WorkItem < b.WorkItem
+	}, nil
+}
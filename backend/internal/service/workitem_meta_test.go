@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Makeinu1/dolt-web-ui/backend/internal/model"
+)
+
+// workItemMetaRows builds dolt_tags rows for the given registry entries.
+func workItemMetaRows(metas ...model.WorkItemMeta) testQueryResult {
+	result := testQueryResult{columns: []string{"tag_name", "message"}}
+	for _, meta := range metas {
+		message, _ := json.Marshal(workItemMetaRecord{Schema: "dolt-webui/workitem-meta@1", WorkItemMeta: meta})
+		result.rows = append(result.rows, []driver.Value{workItemMetaTag(meta.WorkItem), string(message)})
+	}
+	return result
+}
+
+func newWorkItemMetaTestService(t *testing.T, result testQueryResult) *Service {
+	t.Helper()
+	repo := newRecordingSessionRepo(t, func(refName, query string, args []driver.NamedValue) (testQueryResult, error) {
+		if query == "SELECT tag_name, message FROM dolt_tags WHERE tag_name LIKE ?" && args[0].Value == workItemMetaTagPrefix+"%" {
+			return result, nil
+		}
+		return testQueryResult{}, fmt.Errorf("unexpected query: %s", query)
+	})
+	return newWithDeps(repo, testServiceConfig())
+}
+
+func TestListWorkItemMeta_FiltersAndSorts(t *testing.T) {
+	svc := newWorkItemMetaTestService(t, workItemMetaRows(
+		model.WorkItemMeta{WorkItem: "task-b", Owner: "alice", TicketID: "T-2", Description: "second", Labels: []string{"urgent", "db"}, DueDate: "2026-04-01", UpdatedAt: "2026-03-01T00:00:00"},
+		model.WorkItemMeta{WorkItem: "task-a", Owner: "alice", TicketID: "T-1", Description: "first", Labels: []string{"urgent"}, DueDate: "2026-05-01", UpdatedAt: "2026-03-02T00:00:00"},
+		model.WorkItemMeta{WorkItem: "task-c", Owner: "bob", Description: "third", UpdatedAt: "2026-03-03T00:00:00"},
+	))
+
+	got, err := svc.ListWorkItemMeta(context.Background(), "local", "test_db", model.WorkItemMetaFilter{
+		Owner: "alice", Label: "urgent", Sort: "due_date", Order: "desc",
+	})
+	if err != nil {
+		t.Fatalf("ListWorkItemMeta: %v", err)
+	}
+	if len(got) != 2 || got[0].WorkItem != "task-a" || got[1].WorkItem != "task-b" {
+		t.Fatalf("unexpected result: %+v", got)
+	}
+
+	got, err = svc.ListWorkItemMeta(context.Background(), "local", "test_db", model.WorkItemMetaFilter{DueBefore: "2026-04-30"})
+	if err != nil {
+		t.Fatalf("ListWorkItemMeta: %v", err)
+	}
+	if len(got) != 1 || got[0].WorkItem != "task-b" {
+		t.Fatalf("due_before should keep only dated entries up to the bound, got %+v", got)
+	}
+}
+
+func TestListWorkItemMeta_RejectsUnknownSort(t *testing.T) {
+	svc := newWorkItemMetaTestService(t, workItemMetaRows())
+
+	_, err := svc.ListWorkItemMeta(context.Background(), "local", "test_db", model.WorkItemMetaFilter{Sort: "labels"})
+	var apiErr *model.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != model.CodeInvalidArgument {
+		t.Fatalf("expected INVALID_ARGUMENT, got %v", err)
+	}
+}
+
+func TestValidateWorkItemMeta_NormalizesLabels(t *testing.T) {
+	req, err := validateWorkItemMeta(model.SaveWorkItemMetaRequest{
+		WorkItem: "task-1",
+		Owner:    " alice ",
+		Labels:   []string{"db", " ", "db", "urgent"},
+		DueDate:  "2026-04-01",
+	})
+	if err != nil {
+		t.Fatalf("validateWorkItemMeta: %v", err)
+	}
+	if req.Owner != "alice" || strings.Join(req.Labels, ",") != "db,urgent" {
+		t.Fatalf("unexpected normalized request: %+v", req)
+	}
+
+	for _, bad := range []model.SaveWorkItemMetaRequest{
+		{WorkItem: "wi/task-1"},
+		{WorkItem: "task-1", DueDate: "04/01/2026"},
+		{WorkItem: "task-1", TicketID: "T-1\nForged-Trailer: x"},
+	} {
+		if _, err := validateWorkItemMeta(bad); err == nil {
+			t.Fatalf("expected validation error for %+v", bad)
+		}
+	}
+}
+
+func TestSaveWorkItemMeta_StoresTagWithoutMovingMain(t *testing.T) {
+	var executed []string
+	repo := newCrossCopyTestRepo(t, nil, nil)
+	repo.protectedHandler = func(dbName, branchName, query string, args []driver.NamedValue) (testQueryResult, error) {
+		if branchName != "main" {
+			return testQueryResult{}, fmt.Errorf("unexpected protected branch %s", branchName)
+		}
+		executed = append(executed, query)
+		if strings.HasPrefix(query, "SELECT COUNT(*) FROM dolt_tags") {
+			return testQueryResult{columns: []string{"count"}, rows: [][]driver.Value{{int64(1)}}}, nil
+		}
+		if strings.HasPrefix(query, "CALL DOLT_TAG('-m'") {
+			var record workItemMetaRecord
+			if err := json.Unmarshal([]byte(args[0].Value.(string)), &record); err != nil || args[1].Value != "wimeta/task-1" || record.TicketID != "T-1" {
+				return testQueryResult{}, fmt.Errorf("unexpected tag args: %v", args)
+			}
+		}
+		return testQueryResult{}, nil
+	}
+	svc := newWithDeps(repo, testServiceConfig())
+
+	saved, err := svc.SaveWorkItemMeta(context.Background(), model.SaveWorkItemMetaRequest{
+		TargetID: "local", DBName: "test_db", WorkItem: "task-1", TicketID: "T-1",
+	})
+	if err != nil {
+		t.Fatalf("SaveWorkItemMeta: %v", err)
+	}
+	if saved.TicketID != "T-1" || saved.UpdatedAt == "" {
+		t.Fatalf("unexpected entry: %+v", saved)
+	}
+	joined := strings.Join(executed, "\n")
+	if !strings.Contains(joined, "CALL DOLT_TAG('-d', ?)") || !strings.Contains(joined, "CALL DOLT_TAG('-m', ?, ?, 'main')") {
+		t.Fatalf("expected the tag to be replaced, got:\n%s", joined)
+	}
+	for _, unwanted := range []string{"CREATE TABLE", "INSERT", "DOLT_ADD", "DOLT_COMMIT"} {
+		if strings.Contains(joined, unwanted) {
+			t.Fatalf("saving metadata must not write to main, got %q in:\n%s", unwanted, joined)
+		}
+	}
+}
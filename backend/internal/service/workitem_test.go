@@ -1,6 +1,10 @@
 package service
 
-import "testing"
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
 
 func TestWorkItemDerivation(t *testing.T) {
 	workItem, ok := workItemFromWorkBranch("wi/task-123")
@@ -60,3 +64,28 @@ func TestImportWorkBranchName(t *testing.T) {
 		t.Fatalf("unexpected import work branch: got %q", got)
 	}
 }
+
+func TestImportTablesBranchName_BoundsLongTableLists(t *testing.T) {
+	if got := importTablesBranchName("test_db", []string{"orders", "customers"}); got != "wi/import-test_db-customers.orders" {
+		t.Fatalf("unexpected short branch: got %q", got)
+	}
+
+	tables := make([]string, 50)
+	for i := range tables {
+		tables[i] = fmt.Sprintf("table_with_a_long_name_%02d", i)
+	}
+	got := importTablesBranchName("test_db", tables)
+	if !strings.HasPrefix(got, "wi/import-test_db-table_with_a_long_name_00.and-49-more-") || len(got) > 100 || !isWorkBranchName(got) {
+		t.Fatalf("unexpected long branch: got %q (%d chars)", got, len(got))
+	}
+	reversed := append([]string(nil), tables...)
+	for i, j := 0, len(reversed)-1; i < j; i, j = i+1, j-1 {
+		reversed[i], reversed[j] = reversed[j], reversed[i]
+	}
+	if again := importTablesBranchName("test_db", reversed); again != got {
+		t.Fatalf("branch name depends on table order: %q vs %q", again, got)
+	}
+	if other := importTablesBranchName("test_db", tables[1:]); other == got {
+		t.Fatalf("different table lists share branch %q", got)
+	}
+}
@@ -0,0 +1,338 @@
+package service
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A minimal XLSX (Office Open XML) reader: cell values of one worksheet as text.
+// Formulas yield their cached value; dates are formatted as 2006-01-02
+// (or 2006-01-02 15:04:05 when the cell has a time part).
+//
+// Uploads are untrusted: every part is decompressed through a reader capped at
+// the upload limit, column references beyond XFD are rejected, and the cells a
+// sheet may expand to are capped at uploadMaxCells.
+
+// xlsxMaxColumns is the column count of a worksheet (A..XFD).
+const xlsxMaxColumns = 16384
+
+// errXLSXPartTooLarge is returned when a part decompresses past the upload limit.
+var errXLSXPartTooLarge = errors.New("xlsx part exceeds the upload size limit when decompressed")
+
+// xlsxPartReader reads a zip entry and fails once more than remaining bytes
+// have been decompressed.
+type xlsxPartReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (p *xlsxPartReader) Read(b []byte) (int, error) {
+	if p.remaining <= 0 {
+		return 0, errXLSXPartTooLarge
+	}
+	if int64(len(b)) > p.remaining {
+		b = b[:p.remaining]
+	}
+	n, err := p.r.Read(b)
+	p.remaining -= int64(n)
+	return n, err
+}
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.T
+	}
+	var b strings.Builder
+	for _, r := range t.Runs {
+		b.WriteString(r.T)
+	}
+	return b.String()
+}
+
+type xlsxStyles struct {
+	NumFmts []struct {
+		ID   int    `xml:"numFmtId,attr"`
+		Code string `xml:"formatCode,attr"`
+	} `xml:"numFmts>numFmt"`
+	CellXfs []struct {
+		NumFmtID int `xml:"numFmtId,attr"`
+	} `xml:"cellXfs>xf"`
+}
+
+type xlsxRow struct {
+	Cells []struct {
+		Ref    string   `xml:"r,attr"`
+		Type   string   `xml:"t,attr"`
+		Style  int      `xml:"s,attr"`
+		Value  string   `xml:"v"`
+		Inline xlsxText `xml:"is"`
+	} `xml:"c"`
+}
+
+// readXLSX returns the rows of the named sheet (the first sheet when sheet is
+// empty) and the sheet name. No part may decompress to more than partLimit bytes.
+func readXLSX(r io.ReaderAt, size int64, sheet string, partLimit int64) ([][]string, string, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, "", fmt.Errorf("not an xlsx file: %w", err)
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	var wb xlsxWorkbook
+	if err := decodeXLSXPart(files, partLimit, "xl/workbook.xml", &wb); err != nil {
+		return nil, "", err
+	}
+	var rels xlsxRelationships
+	if err := decodeXLSXPart(files, partLimit, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return nil, "", err
+	}
+	if len(wb.Sheets) == 0 {
+		return nil, "", fmt.Errorf("workbook has no sheets")
+	}
+	sheetIdx := 0
+	if sheet != "" {
+		sheetIdx = -1
+		for i, s := range wb.Sheets {
+			if s.Name == sheet {
+				sheetIdx = i
+			}
+		}
+		if sheetIdx < 0 {
+			return nil, "", fmt.Errorf("sheet %s not found", sheet)
+		}
+	}
+	sheetPath := ""
+	for _, rel := range rels.Relationships {
+		if rel.ID == wb.Sheets[sheetIdx].RID {
+			if strings.HasPrefix(rel.Target, "/") {
+				sheetPath = strings.TrimPrefix(rel.Target, "/")
+			} else {
+				sheetPath = path.Join("xl", rel.Target)
+			}
+		}
+	}
+
+	var shared []string
+	if _, ok := files["xl/sharedStrings.xml"]; ok {
+		var sst struct {
+			Items []xlsxText `xml:"si"`
+		}
+		if err := decodeXLSXPart(files, partLimit, "xl/sharedStrings.xml", &sst); err != nil {
+			return nil, "", err
+		}
+		shared = make([]string, len(sst.Items))
+		for i, item := range sst.Items {
+			shared[i] = item.String()
+		}
+	}
+	var dateStyles map[int]bool
+	if _, ok := files["xl/styles.xml"]; ok {
+		var styles xlsxStyles
+		if err := decodeXLSXPart(files, partLimit, "xl/styles.xml", &styles); err != nil {
+			return nil, "", err
+		}
+		dateStyles = xlsxDateStyles(styles)
+	}
+
+	f, ok := files[sheetPath]
+	if !ok {
+		return nil, "", fmt.Errorf("sheet %s has no data", wb.Sheets[sheetIdx].Name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, "", err
+	}
+	defer rc.Close()
+
+	rows := make([][]string, 0)
+	cells := 0
+	dec := xml.NewDecoder(&xlsxPartReader{r: rc, remaining: partLimit})
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if errors.Is(err, errXLSXPartTooLarge) {
+			return nil, "", err
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid sheet xml: %w", err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "row" {
+			continue
+		}
+		var row xlsxRow
+		if err := dec.DecodeElement(&row, &start); err != nil {
+			return nil, "", fmt.Errorf("invalid sheet xml: %w", err)
+		}
+		values := make([]string, 0, len(row.Cells))
+		for _, c := range row.Cells {
+			col := len(values)
+			if c.Ref != "" {
+				if col, err = xlsxColumnIndex(c.Ref); err != nil {
+					return nil, "", err
+				}
+			}
+			if col >= len(values) {
+				if cells += col + 1 - len(values); cells > uploadMaxCells {
+					return nil, "", errUploadTooManyCells
+				}
+				values = append(values, make([]string, col+1-len(values))...)
+			}
+			values[col] = xlsxCellValue(c.Type, c.Value, c.Inline, dateStyles[c.Style], shared)
+		}
+		if len(values) == 0 {
+			// Empty rows still occupy a slot, so they count toward the cap as well.
+			if cells++; cells > uploadMaxCells {
+				return nil, "", errUploadTooManyCells
+			}
+		}
+		rows = append(rows, values)
+	}
+	return rows, wb.Sheets[sheetIdx].Name, nil
+}
+
+func decodeXLSXPart(files map[string]*zip.File, limit int64, name string, v interface{}) error {
+	f, ok := files[name]
+	if !ok {
+		return fmt.Errorf("not an xlsx file: %s is missing", name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	if err := xml.NewDecoder(&xlsxPartReader{r: rc, remaining: limit}).Decode(v); err != nil {
+		if errors.Is(err, errXLSXPartTooLarge) {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		return fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return nil
+}
+
+// xlsxColumnIndex converts the letters of a cell reference ("AB12") to a 0-based
+// column. References past XFD, the last worksheet column, are rejected.
+func xlsxColumnIndex(ref string) (int, error) {
+	col := 0
+	for _, ch := range ref {
+		if ch < 'A' || ch > 'Z' {
+			break
+		}
+		col = col*26 + int(ch-'A'+1)
+		if col > xlsxMaxColumns {
+			return 0, fmt.Errorf("cell reference %q is beyond column XFD", ref)
+		}
+	}
+	if col == 0 {
+		return 0, fmt.Errorf("invalid cell reference %q", ref)
+	}
+	return col - 1, nil
+}
+
+func xlsxCellValue(cellType, value string, inline xlsxText, isDate bool, shared []string) string {
+	switch cellType {
+	case "s":
+		if i, err := strconv.Atoi(value); err == nil && i >= 0 && i < len(shared) {
+			return shared[i]
+		}
+		return ""
+	case "inlineStr":
+		return inline.String()
+	case "b":
+		if value == "1" {
+			return "1"
+		}
+		return "0"
+	case "str", "e":
+		return value
+	}
+	if isDate && value != "" {
+		if serial, err := strconv.ParseFloat(value, 64); err == nil {
+			return xlsxSerialTime(serial)
+		}
+	}
+	return value
+}
+
+// xlsxSerialTime formats an Excel date serial (1900 date system).
+func xlsxSerialTime(serial float64) string {
+	days := math.Floor(serial)
+	seconds := math.Round((serial - days) * 86400)
+	t := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC).AddDate(0, 0, int(days)).Add(time.Duration(seconds) * time.Second)
+	if seconds == 0 {
+		return t.Format("2006-01-02")
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
+
+// xlsxDateStyles marks the cell styles whose number format shows a date or time.
+func xlsxDateStyles(styles xlsxStyles) map[int]bool {
+	custom := make(map[int]string, len(styles.NumFmts))
+	for _, f := range styles.NumFmts {
+		custom[f.ID] = f.Code
+	}
+	dates := make(map[int]bool)
+	for i, xf := range styles.CellXfs {
+		id := xf.NumFmtID
+		if (id >= 14 && id <= 22) || (id >= 45 && id <= 47) {
+			dates[i] = true
+		} else if code, ok := custom[id]; ok && isXLSXDateFormat(code) {
+			dates[i] = true
+		}
+	}
+	return dates
+}
+
+func isXLSXDateFormat(code string) bool {
+	var b strings.Builder
+	inQuote, inBracket := false, false
+	for _, ch := range code {
+		switch {
+		case ch == '"':
+			inQuote = !inQuote
+		case inQuote:
+		case ch == '[':
+			inBracket = true
+		case ch == ']':
+			inBracket = false
+		case !inBracket:
+			b.WriteRune(ch)
+		}
+	}
+	plain := strings.ToLower(b.String())
+	return strings.ContainsAny(plain, "yd") || strings.Contains(plain, "h:") || strings.Contains(plain, ":s")
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// xlsxMaxRows is the row limit of one worksheet.
+const xlsxMaxRows = 1048576
+
+var errXLSXRowLimit = errors.New("worksheet row limit reached")
+
+// xlsxWriter streams a minimal XLSX workbook: worksheets with inline string
+// cells, written one after another. Nothing is buffered beyond the current
+// row, so a workbook of any size can be written to a response.
+type xlsxWriter struct {
+	zw     *zip.Writer
+	sheets []string
+	used   map[string]bool
+	sheet  io.Writer
+	rows   int
+}
+
+func newXLSXWriter(w io.Writer) *xlsxWriter {
+	return &xlsxWriter{zw: zip.NewWriter(w), used: make(map[string]bool)}
+}
+
+// xlsxSheetName makes name a valid, unique worksheet name: no []:*?/\
+// characters and at most 31 characters.
+func (x *xlsxWriter) xlsxSheetName(name string) string {
+	clean := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if clean == "" {
+		clean = "sheet"
+	}
+	base := clean
+	for n := 2; ; n++ {
+		if utf8.RuneCountInString(clean) > 31 {
+			clean = string([]rune(clean)[:31])
+		}
+		if !x.used[strings.ToLower(clean)] {
+			break
+		}
+		suffix := "~" + strconv.Itoa(n)
+		runes := []rune(base)
+		if len(runes)+len(suffix) > 31 {
+			runes = runes[:31-len(suffix)]
+		}
+		clean = string(runes) + suffix
+	}
+	x.used[strings.ToLower(clean)] = true
+	return clean
+}
+
+func (x *xlsxWriter) closeSheet() error {
+	if x.sheet == nil {
+		return nil
+	}
+	_, err := io.WriteString(x.sheet, `</sheetData></worksheet>`)
+	x.sheet = nil
+	return err
+}
+
+// AddSheet ends the current worksheet and starts a new one.
+func (x *xlsxWriter) AddSheet(name string) error {
+	if err := x.closeSheet(); err != nil {
+		return err
+	}
+	x.sheets = append(x.sheets, x.xlsxSheetName(name))
+	w, err := x.zw.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", len(x.sheets)))
+	if err != nil {
+		return err
+	}
+	x.sheet = w
+	x.rows = 0
+	_, err = io.WriteString(w, xml.Header+`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	return err
+}
+
+// xlsxColumnName returns the column letters of the 0-based column i.
+func xlsxColumnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+// WriteRow appends a row to the current worksheet. Nil cells are left empty.
+// It returns errXLSXRowLimit once the worksheet is full.
+func (x *xlsxWriter) WriteRow(cells []*string) error {
+	if x.rows >= xlsxMaxRows {
+		return errXLSXRowLimit
+	}
+	x.rows++
+	var b strings.Builder
+	fmt.Fprintf(&b, `<row r="%d">`, x.rows)
+	for i, cell := range cells {
+		if cell == nil {
+			continue
+		}
+		fmt.Fprintf(&b, `<c r="%s%d" t="inlineStr"><is><t xml:space="preserve">`, xlsxColumnName(i), x.rows)
+		xml.EscapeText(&b, []byte(*cell)) //nolint:errcheck // strings.Builder does not fail
+		b.WriteString(`</t></is></c>`)
+	}
+	b.WriteString(`</row>`)
+	_, err := io.WriteString(x.sheet, b.String())
+	return err
+}
+
+// Close ends the last worksheet and writes the workbook parts. A workbook
+// needs at least one worksheet; an empty one is added if none was.
+func (x *xlsxWriter) Close() error {
+	if len(x.sheets) == 0 {
+		if err := x.AddSheet("diff"); err != nil {
+			return err
+		}
+	}
+	if err := x.closeSheet(); err != nil {
+		return err
+	}
+
+	var workbook, rels, types strings.Builder
+	workbook.WriteString(xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	rels.WriteString(xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	types.WriteString(xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	for i, name := range x.sheets {
+		workbook.WriteString(`<sheet name="`)
+		xml.EscapeText(&workbook, []byte(name)) //nolint:errcheck // strings.Builder does not fail
+		fmt.Fprintf(&workbook, `" sheetId="%d" r:id="rId%d"/>`, i+1, i+1)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+		fmt.Fprintf(&types, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+	}
+	workbook.WriteString(`</sheets></workbook>`)
+	rels.WriteString(`</Relationships>`)
+	types.WriteString(`</Types>`)
+
+	parts := []struct{ name, body string }{
+		{"xl/workbook.xml", workbook.String()},
+		{"xl/_rels/workbook.xml.rels", rels.String()},
+		{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"[Content_Types].xml", types.String()},
+	}
+	for _, p := range parts {
+		w, err := x.zw.Create(p.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, p.body); err != nil {
+			return err
+		}
+	}
+	return x.zw.Close()
+}
@@ -49,6 +49,22 @@ export const getBranchReady = (targetId: string, dbName: string, branchName: str
     `/branches/ready${queryString({ target_id: targetId, db_name: dbName, branch_name: branchName })}`
   );
 
+export const getBranchStatus = (targetId: string, dbName: string, branchName = "") =>
+  request<import("../types/api").BranchStatus[]>(
+    `/branches/status${queryString({ target_id: targetId, db_name: dbName, branch_name: branchName })}`
+  );
+
+export const getStaleBranches = (targetId: string, dbName: string) =>
+  request<import("../types/api").StaleBranchesResponse>(
+    `/branches/stale${queryString({ target_id: targetId, db_name: dbName })}`
+  );
+
+export const cleanupStaleBranches = (body: import("../types/api").CleanupStaleBranchesRequest) =>
+  request<import("../types/api").CleanupStaleBranchesResponse>("/branches/admin/cleanup-stale", {
+    method: "POST",
+    body: JSON.stringify(body),
+  });
+
 export const createBranch = (body: import("../types/api").CreateBranchRequest) =>
   request<{ branch_name: string }>("/branches/create", {
     method: "POST",
@@ -93,6 +109,38 @@ export const getTableSchema = (
   });
 };
 
+export const compareSchemas = (
+  targetId: string,
+  fromDb: string,
+  fromRef: string,
+  toDb: string,
+  toRef: string
+) =>
+  request<import("../types/api").SchemaCompareResponse>(
+    `/schema/compare${queryString({ target_id: targetId, from_db: fromDb, from_ref: fromRef, to_db: toDb, to_ref: toRef })}`
+  );
+
+export const exportSchemaCompare = async (
+  targetId: string,
+  fromDb: string,
+  fromRef: string,
+  toDb: string,
+  toRef: string,
+  format: "markdown" | "csv"
+): Promise<{ blob: Blob; filename: string }> => {
+  const qs = queryString({ target_id: targetId, from_db: fromDb, from_ref: fromRef, to_db: toDb, to_ref: toRef, format });
+  const res = await fetch(`${API_BASE}/schema/compare/export${qs}`);
+  if (!res.ok) {
+    const error = await res.json().catch(() => ({ code: "INTERNAL", message: res.statusText }));
+    throw new ApiError(res.status, error);
+  }
+  const cd = res.headers.get("Content-Disposition") ?? "";
+  const match = cd.match(/filename="([^"]+)"/);
+  const filename = match ? match[1] : format === "csv" ? "schema-compare.csv" : "schema-compare.md";
+  const blob = await res.blob();
+  return { blob, filename };
+};
+
 /** Invalidate cached schemas for a DB (call after CREATE/DROP TABLE). */
 export const invalidateSchemaCache = (targetId: string, dbName: string) => {
   const prefix = `${targetId}|${dbName}|`;
@@ -155,12 +203,36 @@ export const commit = (body: import("../types/api").CommitRequest) =>
     body: JSON.stringify(body),
   });
 
+export const validateCommit = (body: import("../types/api").CommitRequest) =>
+  request<import("../types/api").CommitValidateResponse>("/commit/validate", {
+    method: "POST",
+    body: JSON.stringify(body),
+  });
+
 export const syncBranch = (body: import("../types/api").SyncRequest) =>
   request<import("../types/api").SyncResponse>("/sync", {
     method: "POST",
     body: JSON.stringify(body),
   });
 
+export const undoCommit = (body: import("../types/api").UndoCommitRequest) =>
+  request<import("../types/api").BranchRewriteResponse>("/branch/undo-commit", {
+    method: "POST",
+    body: JSON.stringify(body),
+  });
+
+export const squashBranch = (body: import("../types/api").SquashRequest) =>
+  request<import("../types/api").BranchRewriteResponse>("/branch/squash", {
+    method: "POST",
+    body: JSON.stringify(body),
+  });
+
+export const cherryPick = (body: import("../types/api").CherryPickRequest) =>
+  request<import("../types/api").BranchRewriteResponse>("/branch/cherry-pick", {
+    method: "POST",
+    body: JSON.stringify(body),
+  });
+
 // Diff & History
 export const getDiffTable = (
   targetId: string,
@@ -241,6 +313,18 @@ export const getHistoryCommits = (
     })}`
   );
 
+// Releases
+export const listReleases = (targetId: string, dbName: string) =>
+  request<import("../types/api").Release[]>(
+    `/releases${queryString({ target_id: targetId, db_name: dbName })}`
+  );
+
+export const createRelease = (body: import("../types/api").CreateReleaseRequest) =>
+  request<import("../types/api").Release>("/releases", {
+    method: "POST",
+    body: JSON.stringify(body),
+  });
+
 export const getDiffSummary = (
   targetId: string,
   dbName: string,
@@ -291,6 +375,16 @@ export const listRequests = (targetId: string, dbName: string) =>
     `/requests${queryString({ target_id: targetId, db_name: dbName })}`
   );
 
+export const checkRequests = (targetId: string, dbName: string, requestId = "") =>
+  request<import("../types/api").RequestCheckResponse>(
+    `/request/check${queryString({ target_id: targetId, db_name: dbName, request_id: requestId })}`
+  );
+
+export const getOverlaps = (targetId: string, dbName: string, branchName: string) =>
+  request<import("../types/api").OverlapsResponse>(
+    `/overlaps${queryString({ target_id: targetId, db_name: dbName, branch_name: branchName })}`
+  );
+
 export const getRequest = (targetId: string, dbName: string, requestId: string) =>
   request<import("../types/api").RequestSummary>(
     `/request${queryString({ target_id: targetId, db_name: dbName, request_id: requestId })}`
@@ -302,12 +396,71 @@ export const approveRequest = (body: import("../types/api").ApproveRequest) =>
     body: JSON.stringify(body),
   });
 
+export const enqueueMerge = (body: import("../types/api").EnqueueMergeRequest) =>
+  request<import("../types/api").MergeQueueEntry>("/merge-queue/enqueue", {
+    method: "POST",
+    body: JSON.stringify(body),
+  });
+
+export const getMergeQueue = (targetId: string, dbName: string) =>
+  request<import("../types/api").MergeQueueResponse>(
+    `/merge-queue${queryString({ target_id: targetId, db_name: dbName })}`
+  );
+
+export const listScheduledApprovals = (targetId: string, dbName: string) =>
+  request<import("../types/api").ScheduledApproval[]>(
+    `/approvals/scheduled${queryString({ target_id: targetId, db_name: dbName })}`
+  );
+
+export const cancelScheduledApproval = (body: import("../types/api").CancelScheduledApprovalRequest) =>
+  request<{ status: string }>("/approvals/scheduled/cancel", {
+    method: "POST",
+    body: JSON.stringify(body),
+  });
+
 export const rejectRequest = (body: import("../types/api").RejectRequest) =>
   request<import("../types/api").RejectResponse>("/request/reject", {
     method: "POST",
     body: JSON.stringify(body),
   });
 
+// Work Items
+export const listWorkItems = (
+  targetId: string,
+  dbName: string,
+  query: import("../types/api").WorkItemMetaQuery = {}
+) =>
+  request<import("../types/api").WorkItemMeta[]>(
+    `/work-items${queryString({
+      target_id: targetId,
+      db_name: dbName,
+      owner: query.owner ?? "",
+      ticket_id: query.ticket_id ?? "",
+      label: query.label ?? "",
+      keyword: query.keyword ?? "",
+      due_before: query.due_before ?? "",
+      sort: query.sort ?? "",
+      order: query.order ?? "",
+    })}`
+  );
+
+export const getWorkItem = (targetId: string, dbName: string, workItem: string) =>
+  request<import("../types/api").WorkItemMeta>(
+    `/work-item${queryString({ target_id: targetId, db_name: dbName, work_item: workItem })}`
+  );
+
+export const saveWorkItem = (body: import("../types/api").SaveWorkItemMetaRequest) =>
+  request<import("../types/api").WorkItemMeta>("/work-items/save", {
+    method: "POST",
+    body: JSON.stringify(body),
+  });
+
+export const deleteWorkItem = (body: { target_id: string; db_name: string; work_item: string }) =>
+  request<{ status: string }>("/work-items/delete", {
+    method: "POST",
+    body: JSON.stringify(body),
+  });
+
 // Cell Memos
 export const getMemoMap = (
   targetId: string,
@@ -356,6 +509,30 @@ export const crossCopyTable = (body: import("../types/api").CrossCopyTableReques
     body: JSON.stringify(body),
   });
 
+export const crossCopyTablesPreview = (body: import("../types/api").CrossCopyTablesRequest) =>
+  request<import("../types/api").CrossCopyTablesPreviewResponse>("/cross-copy/tables/preview", {
+    method: "POST",
+    body: JSON.stringify(body),
+  });
+
+export const crossCopyTables = (body: import("../types/api").CrossCopyTablesRequest) =>
+  request<import("../types/api").CrossCopyTablesResult>("/cross-copy/tables", {
+    method: "POST",
+    body: JSON.stringify(body),
+  });
+
+export const replayApprovalPreview = (body: import("../types/api").ReplayApprovalRequest) =>
+  request<import("../types/api").ReplayApprovalPreviewResponse>("/cross-copy/replay-approval/preview", {
+    method: "POST",
+    body: JSON.stringify(body),
+  });
+
+export const replayApproval = (body: import("../types/api").ReplayApprovalRequest) =>
+  request<import("../types/api").ReplayApprovalResult>("/cross-copy/replay-approval", {
+    method: "POST",
+    body: JSON.stringify(body),
+  });
+
 export const crossCopyAdminPrepareTable = (body: import("../types/api").CrossCopyAdminPrepareTableRequest) =>
   request<import("../types/api").CrossCopyAdminPrepareTableResult>("/cross-copy/admin/prepare-table", {
     method: "POST",
@@ -369,18 +546,71 @@ export const crossCopyAdminCleanupImport = (body: import("../types/api").CrossCo
   });
 
 // CSV Import
+// Option fields are appended before the file: the server parses the file while it streams in.
+export const csvUpload = (file: File, options: import("../types/api").CSVUploadOptions = {}) => {
+  const form = new FormData();
+  Object.entries(options).forEach(([key, value]) => {
+    if (value !== undefined && value !== "") form.append(key, String(value));
+  });
+  form.append("file", file);
+  return request<import("../types/api").CSVUploadResponse>("/csv/upload", {
+    method: "POST",
+    headers: {},
+    body: form,
+  });
+};
+
 export const csvPreview = (body: import("../types/api").CSVPreviewRequest) =>
   request<import("../types/api").CSVPreviewResponse>("/csv/preview", {
     method: "POST",
     body: JSON.stringify(body),
   });
 
+export const csvPreviewErrors = (previewId: string, page: number) =>
+  request<import("../types/api").CSVPreviewErrorsResponse>(
+    `/csv/preview/errors${queryString({ preview_id: previewId, page: String(page) })}`
+  );
+
+export const exportCSVPreviewErrors = async (previewId: string): Promise<{ blob: Blob; filename: string }> => {
+  const res = await fetch(`${API_BASE}/csv/preview/errors/export${queryString({ preview_id: previewId })}`);
+  if (!res.ok) {
+    const error = await res.json().catch(() => ({ code: "INTERNAL", message: res.statusText }));
+    throw new ApiError(res.status, error);
+  }
+  const cd = res.headers.get("Content-Disposition") ?? "";
+  const match = cd.match(/filename="([^"]+)"/);
+  const filename = match ? match[1] : "csv-errors.csv";
+  const blob = await res.blob();
+  return { blob, filename };
+};
+
 export const csvApply = (body: import("../types/api").CSVApplyRequest) =>
   request<import("../types/api").CSVApplyResponse>("/csv/apply", {
     method: "POST",
     body: JSON.stringify(body),
   });
 
+export const listCSVProfiles = (targetId: string, dbName: string, table: string) =>
+  request<import("../types/api").CSVMappingProfile[]>(
+    `/csv/profiles${queryString({ target_id: targetId, db_name: dbName, table })}`
+  );
+
+export const saveCSVProfile = (
+  targetId: string,
+  dbName: string,
+  profile: import("../types/api").CSVMappingProfile
+) =>
+  request<import("../types/api").CSVMappingProfile>("/csv/profiles/save", {
+    method: "POST",
+    body: JSON.stringify({ target_id: targetId, db_name: dbName, ...profile }),
+  });
+
+export const deleteCSVProfile = (targetId: string, dbName: string, table: string, name: string) =>
+  request<{ status: string }>("/csv/profiles/delete", {
+    method: "POST",
+    body: JSON.stringify({ target_id: targetId, db_name: dbName, table, name }),
+  });
+
 // Search
 export const search = (
   targetId: string,
@@ -410,9 +640,10 @@ export const exportDiffZip = async (
   branchName: string,
   fromRef: string,
   toRef: string,
-  mode = "three_dot"
-): Promise<{ blob: Blob; filename: string; warning?: string }> => {
-  const qs = queryString({ target_id: targetId, db_name: dbName, branch_name: branchName, from_ref: fromRef, to_ref: toRef, mode });
+  mode = "three_dot",
+  format: import("../types/api").DiffExportFormat = "csv"
+): Promise<{ blob: Blob; filename: string }> => {
+  const qs = queryString({ target_id: targetId, db_name: dbName, branch_name: branchName, from_ref: fromRef, to_ref: toRef, mode, format });
   const res = await fetch(`${API_BASE}/diff/export-zip${qs}`);
   if (!res.ok) {
     const error = await res.json().catch(() => ({ code: "INTERNAL", message: res.statusText }));
@@ -421,9 +652,8 @@ export const exportDiffZip = async (
   const cd = res.headers.get("Content-Disposition") ?? "";
   const match = cd.match(/filename="([^"]+)"/);
   const filename = match ? match[1] : "diff-export.zip";
-  const warning = res.headers.get("X-Diff-Warning") ?? undefined;
   const blob = await res.blob();
-  return { blob, filename, warning };
+  return { blob, filename };
 };
 
 // L3-2: Abort stuck merge state
@@ -432,3 +662,49 @@ export const mergeAbort = (body: { target_id: string; db_name: string; branch_na
     method: "POST",
     body: JSON.stringify(body),
   });
+
+// Replication
+export const getReplicationStatus = (targetId: string, dbName: string) =>
+  request<import("../types/api").ReplicationStatusResponse>(
+    `/replication/status${queryString({ target_id: targetId, db_name: dbName })}`
+  );
+
+export const runReplication = (body: import("../types/api").RunReplicationRequest) =>
+  request<import("../types/api").RunReplicationResult>("/replication/admin/run", {
+    method: "POST",
+    body: JSON.stringify(body),
+  });
+
+export const restoreReplica = (body: import("../types/api").RestoreReplicaRequest) =>
+  request<import("../types/api").RestoreReplicaResult>("/replication/admin/restore", {
+    method: "POST",
+    body: JSON.stringify(body),
+  });
+
+// Audit
+export const importAuditSnapshot = (body: import("../types/api").AuditImportRequest) =>
+  request<import("../types/api").AuditImportResult>("/audit/admin/import", {
+    method: "POST",
+    body: JSON.stringify(body),
+  });
+
+export const reconcileAudit = (targetId: string, dbName: string) =>
+  request<import("../types/api").AuditReconcileResponse>(
+    `/audit/reconcile${queryString({ target_id: targetId, db_name: dbName })}`
+  );
+
+export const exportAuditReconcileZip = async (
+  targetId: string,
+  dbName: string
+): Promise<{ blob: Blob; filename: string }> => {
+  const res = await fetch(`${API_BASE}/audit/reconcile/export-zip${queryString({ target_id: targetId, db_name: dbName })}`);
+  if (!res.ok) {
+    const error = await res.json().catch(() => ({ code: "INTERNAL", message: res.statusText }));
+    throw new ApiError(res.status, error);
+  }
+  const cd = res.headers.get("Content-Disposition") ?? "";
+  const match = cd.match(/filename="([^"]+)"/);
+  const filename = match ? match[1] : "reconcile-main-audit.zip";
+  const blob = await res.blob();
+  return { blob, filename };
+};
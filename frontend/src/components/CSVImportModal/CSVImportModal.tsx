@@ -1,9 +1,16 @@
-import { useRef, useState } from "react";
+import { useEffect, useRef, useState } from "react";
 import { useContextStore } from "../../store/context";
 import { useUIStore } from "../../store/ui";
 import * as api from "../../api/client";
 import { ApiError } from "../../api/errors";
-import type { CSVApplyResponse, CSVPreviewResponse } from "../../types/api";
+import type {
+  CSVApplyResponse,
+  CSVImportMode,
+  CSVMappingProfile,
+  CSVPreviewErrorsResponse,
+  CSVPreviewResponse,
+  CSVUploadResponse,
+} from "../../types/api";
 import { isCompleted, operationMessage } from "../../utils/apiResult";
 
 interface CSVImportModalProps {
@@ -15,53 +22,12 @@ interface CSVImportModalProps {
 
 type Step = "select" | "preview" | "done";
 
-/** Parse CSV text into array of row objects. Handles quoted fields. */
-function parseCSV(text: string): { headers: string[]; rows: Record<string, string>[] } {
-  const lines = text.split(/\r?\n/).filter((l) => l.trim() !== "");
-  if (lines.length < 1) return { headers: [], rows: [] };
-
-  const parseRow = (line: string): string[] => {
-    const fields: string[] = [];
-    let current = "";
-    let inQuotes = false;
-    for (let i = 0; i < line.length; i++) {
-      const ch = line[i];
-      if (inQuotes) {
-        if (ch === '"' && line[i + 1] === '"') {
-          current += '"';
-          i++;
-        } else if (ch === '"') {
-          inQuotes = false;
-        } else {
-          current += ch;
-        }
-      } else {
-        if (ch === '"') {
-          inQuotes = true;
-        } else if (ch === ",") {
-          fields.push(current);
-          current = "";
-        } else {
-          current += ch;
-        }
-      }
-    }
-    fields.push(current);
-    return fields;
-  };
-
-  const headers = parseRow(lines[0]);
-  const rows: Record<string, string>[] = [];
-  for (let i = 1; i < lines.length; i++) {
-    const vals = parseRow(lines[i]);
-    const row: Record<string, string> = {};
-    headers.forEach((h, j) => {
-      row[h] = vals[j] ?? "";
-    });
-    rows.push(row);
-  }
-  return { headers, rows };
-}
+const MODE_LABELS: Record<CSVImportMode, string> = {
+  upsert: "追加＋更新（CSVにない行は残す）",
+  insert_only: "追加のみ（既存の主キーはエラー）",
+  update_only: "更新のみ（未登録の主キーはエラー）",
+  replace: "置換（CSVにない行を削除）",
+};
 
 export function CSVImportModal({
   tableName,
@@ -75,42 +41,37 @@ export function CSVImportModal({
   const fileInputRef = useRef<HTMLInputElement>(null);
 
   const [step, setStep] = useState<Step>("select");
-  const [headers, setHeaders] = useState<string[]>([]);
-  const [csvRows, setCsvRows] = useState<Record<string, string>[]>([]);
+  const [upload, setUpload] = useState<CSVUploadResponse | null>(null);
   const [parseError, setParseError] = useState<string | null>(null);
   const [loading, setLoading] = useState(false);
   const [error, setError] = useState<string | null>(null);
   const [preview, setPreview] = useState<CSVPreviewResponse | null>(null);
   const [commitMessage, setCommitMessage] = useState("");
+  const [mode, setMode] = useState<CSVImportMode>("upsert");
+  const [profiles, setProfiles] = useState<CSVMappingProfile[]>([]);
+  const [profile, setProfile] = useState("");
+  const [errorPage, setErrorPage] = useState<CSVPreviewErrorsResponse | null>(null);
 
-  const handleFileChange = (e: React.ChangeEvent<HTMLInputElement>) => {
+  useEffect(() => {
+    // Profiles are optional; without them headers must match the column names.
+    api.listCSVProfiles(targetId, dbName, tableName).then(setProfiles).catch(() => setProfiles([]));
+  }, [targetId, dbName, tableName]);
+
+  const handleFileChange = async (e: React.ChangeEvent<HTMLInputElement>) => {
     const file = e.target.files?.[0];
     if (!file) return;
     setParseError(null);
-    const reader = new FileReader();
-    reader.onload = (ev) => {
-      const text = ev.target?.result as string;
-      const { headers: h, rows } = parseCSV(text);
-      if (h.length === 0) {
-        setParseError("CSVのヘッダー行が見つかりません");
-        return;
-      }
-      if (rows.length === 0) {
-        setParseError("CSVにデータ行がありません");
-        return;
-      }
-      if (rows.length > 1000) {
-        setParseError(`CSVが1000行を超えています（${rows.length}行）。最大1000行まで対応しています`);
-        return;
-      }
-      setHeaders(h);
-      setCsvRows(rows);
-    };
-    // BUG-G: handle file read errors explicitly.
-    reader.onerror = () => {
-      setParseError("ファイルの読み込みに失敗しました");
-    };
-    reader.readAsText(file, "UTF-8");
+    setUpload(null);
+    setLoading(true);
+    try {
+      // The server detects encoding (UTF-8 / Shift_JIS / EUC-JP), delimiter and header row.
+      setUpload(await api.csvUpload(file));
+    } catch (err) {
+      setParseError(err instanceof ApiError ? err.message : "ファイルの読み込みに失敗しました");
+    } finally {
+      setLoading(false);
+      e.target.value = "";
+    }
   };
 
   const handlePreview = async () => {
@@ -122,9 +83,12 @@ export function CSVImportModal({
         db_name: dbName,
         branch_name: branchName,
         table: tableName,
-        rows: csvRows,
+        upload_id: upload?.upload_id,
+        mode,
+        profile: profile || undefined,
       });
       setPreview(result);
+      setErrorPage(null);
       setStep("preview");
     } catch (err) {
       const msg = err instanceof ApiError ? err.message : "プレビューに失敗しました";
@@ -134,6 +98,30 @@ export function CSVImportModal({
     }
   };
 
+  const loadErrorPage = async (page: number) => {
+    if (!preview?.preview_id) return;
+    try {
+      setErrorPage(await api.csvPreviewErrors(preview.preview_id, page));
+    } catch (err) {
+      setError(err instanceof ApiError ? err.message : "エラー一覧の取得に失敗しました");
+    }
+  };
+
+  const handleDownloadErrors = async () => {
+    if (!preview?.preview_id) return;
+    try {
+      const { blob, filename } = await api.exportCSVPreviewErrors(preview.preview_id);
+      const url = URL.createObjectURL(blob);
+      const a = document.createElement("a");
+      a.href = url;
+      a.download = filename;
+      a.click();
+      URL.revokeObjectURL(url);
+    } catch (err) {
+      setError(err instanceof ApiError ? err.message : "エラーレポートのダウンロードに失敗しました");
+    }
+  };
+
   const handleApply = async () => {
     setLoading(true);
     setError(null);
@@ -145,7 +133,9 @@ export function CSVImportModal({
         table: tableName,
         expected_head: expectedHead,
         commit_message: commitMessage || `[CSV] ${tableName}: 一括更新`,
-        rows: csvRows,
+        upload_id: upload?.upload_id,
+        mode,
+        profile: profile || undefined,
       });
       if (!isCompleted(result)) {
         setError(operationMessage(result, "CSV を適用できませんでした"));
@@ -188,23 +178,50 @@ export function CSVImportModal({
         {step === "select" && (
           <div>
             <p style={{ fontSize: 13, color: "#555", marginBottom: 12 }}>
-              CSVをアップロードして既存行を一括更新します。PKが一致する行は更新、存在しない行は追加されます。CSVにない行は変更されません。
+              CSVをアップロードしてテーブルを一括更新します。主キーで既存行と照合し、取り込み方法に従って追加・更新・削除します。
             </p>
             <div
               style={{ border: "2px dashed #cbd5e1", borderRadius: 6, padding: 24, textAlign: "center", marginBottom: 12, cursor: "pointer" }}
               onClick={() => fileInputRef.current?.click()}
             >
               <div style={{ fontSize: 32, marginBottom: 4 }}>📄</div>
-              <div style={{ fontSize: 13, color: "#666" }}>クリックしてCSVを選択</div>
-              <div style={{ fontSize: 11, color: "#888", marginTop: 4 }}>UTF-8 / 最大1000行</div>
-              <input ref={fileInputRef} type="file" accept=".csv" style={{ display: "none" }} onChange={handleFileChange} />
+              <div style={{ fontSize: 13, color: "#666" }}>クリックしてCSV / TSV / XLSXを選択</div>
+              <div style={{ fontSize: 11, color: "#888", marginTop: 4 }}>UTF-8 / Shift_JIS / EUC-JP を自動判定</div>
+              <input ref={fileInputRef} type="file" accept=".csv,.tsv,.txt,.xlsx" style={{ display: "none" }} onChange={handleFileChange} />
             </div>
             {parseError && (
               <div style={{ color: "#991b1b", fontSize: 12, marginBottom: 8 }}>⚠ {parseError}</div>
             )}
-            {csvRows.length > 0 && (
+            <label style={{ display: "block", fontSize: 12, fontWeight: 600, marginBottom: 4 }}>取り込み方法</label>
+            <select
+              value={mode}
+              onChange={(e) => setMode(e.target.value as CSVImportMode)}
+              style={{ width: "100%", fontSize: 13, padding: "4px 8px", marginBottom: 12 }}
+            >
+              {(Object.keys(MODE_LABELS) as CSVImportMode[]).map((m) => (
+                <option key={m} value={m}>{MODE_LABELS[m]}</option>
+              ))}
+            </select>
+            {profiles.length > 0 && (
+              <>
+                <label style={{ display: "block", fontSize: 12, fontWeight: 600, marginBottom: 4 }}>マッピング</label>
+                <select
+                  value={profile}
+                  onChange={(e) => setProfile(e.target.value)}
+                  style={{ width: "100%", fontSize: 13, padding: "4px 8px", marginBottom: 12 }}
+                >
+                  <option value="">なし（ヘッダー＝カラム名）</option>
+                  {profiles.map((p) => (
+                    <option key={p.name} value={p.name}>{p.name}</option>
+                  ))}
+                </select>
+              </>
+            )}
+            {upload && (
               <div style={{ fontSize: 12, color: "#166534", marginBottom: 12 }}>
-                ✓ {csvRows.length}行 / {headers.length}カラム 読み込み済み
+                ✓ {upload.row_count}行 / {upload.headers.length}カラム 読み込み済み
+                {upload.encoding && ` (${upload.encoding})`}
+                {upload.header_row > 1 && ` / ヘッダー: ${upload.header_row}行目`}
               </div>
             )}
             <div style={{ display: "flex", gap: 8, justifyContent: "flex-end" }}>
@@ -212,7 +229,7 @@ export function CSVImportModal({
               <button
                 className="primary"
                 onClick={handlePreview}
-                disabled={loading || csvRows.length === 0}
+                disabled={loading || !upload}
                 style={{ padding: "6px 16px", fontSize: 13 }}
               >
                 {loading ? "確認中..." : "プレビュー →"}
@@ -233,6 +250,11 @@ export function CSVImportModal({
               <div style={{ padding: "8px 16px", background: "#f1f5f9", borderRadius: 6, fontSize: 13, color: "#475569", fontWeight: 600 }}>
                 変更なし: {preview.skips}行
               </div>
+              {mode === "replace" && (
+                <div style={{ padding: "8px 16px", background: "#fee2e2", borderRadius: 6, fontSize: 13, color: "#991b1b", fontWeight: 600 }}>
+                  削除: {preview.deletes}行
+                </div>
+              )}
               {preview.errors > 0 && (
                 <div style={{ padding: "8px 16px", background: "#fee2e2", borderRadius: 6, fontSize: 13, color: "#991b1b", fontWeight: 600 }}>
                   エラー: {preview.errors}行
@@ -240,6 +262,36 @@ export function CSVImportModal({
               )}
             </div>
 
+            {preview.column_errors && Object.keys(preview.column_errors).length > 0 && (
+              <div style={{ fontSize: 12, color: "#991b1b", marginBottom: 8 }}>
+                型エラー: {Object.entries(preview.column_errors).map(([col, n]) => `${col} ${n}件`).join(" / ")}
+                {preview.preview_errors?.[0] && ` — 例: 行${preview.preview_errors[0].row_index + 1} ${preview.preview_errors[0].message}`}
+              </div>
+            )}
+
+            {preview.preview_id && (() => {
+              const page = errorPage?.page ?? 1;
+              const errors = errorPage?.errors ?? preview.preview_errors ?? [];
+              const pageCount = Math.ceil(preview.errors / (errorPage?.page_size ?? 100));
+              return (
+                <div style={{ marginBottom: 12 }}>
+                  <div style={{ display: "flex", alignItems: "center", gap: 8, fontSize: 11, color: "#888", marginBottom: 4 }}>
+                    <span>エラー行（{page} / {pageCount} ページ）</span>
+                    <button disabled={page <= 1} onClick={() => loadErrorPage(page - 1)} style={{ fontSize: 11 }}>←</button>
+                    <button disabled={page >= pageCount} onClick={() => loadErrorPage(page + 1)} style={{ fontSize: 11 }}>→</button>
+                    <button onClick={handleDownloadErrors} style={{ fontSize: 11, marginLeft: "auto" }}>エラーレポートCSV</button>
+                  </div>
+                  <div style={{ maxHeight: 120, overflow: "auto" }}>
+                    {errors.map((e) => (
+                      <div key={e.row_index} style={{ fontSize: 11, padding: "2px 8px", color: "#991b1b", fontFamily: "monospace" }}>
+                        行{e.row_index + 1}: {e.message}
+                      </div>
+                    ))}
+                  </div>
+                </div>
+              );
+            })()}
+
             {preview.sample_diffs.length > 0 && (
               <div style={{ flex: 1, overflow: "auto", marginBottom: 12 }}>
                 <div style={{ fontSize: 11, color: "#888", marginBottom: 4 }}>サンプル差分（最初の5件）:</div>
@@ -255,6 +307,19 @@ export function CSVImportModal({
               </div>
             )}
 
+            {preview.sample_deletes && preview.sample_deletes.length > 0 && (
+              <div style={{ marginBottom: 12 }}>
+                <div style={{ fontSize: 11, color: "#888", marginBottom: 4 }}>削除される行（最初の5件）:</div>
+                {preview.sample_deletes.map((row, i) => (
+                  <div key={i} style={{ fontSize: 11, padding: "4px 8px", background: "#fef2f2", borderRadius: 4, marginBottom: 4, fontFamily: "monospace" }}>
+                    <span style={{ fontWeight: 700, color: "#991b1b" }}>-</span>
+                    {" "}
+                    {Object.entries(row).slice(0, 5).map(([k, v]) => `${k}=${v}`).join(", ")}
+                  </div>
+                ))}
+              </div>
+            )}
+
             <div style={{ marginBottom: 12 }}>
               <label style={{ display: "block", fontSize: 12, fontWeight: 600, marginBottom: 4 }}>
                 コミットメッセージ
@@ -273,10 +338,10 @@ export function CSVImportModal({
               <button
                 className="primary"
                 onClick={handleApply}
-                disabled={loading || (preview.inserts === 0 && preview.updates === 0)}
+                disabled={loading || preview.errors > 0 || (preview.inserts === 0 && preview.updates === 0 && preview.deletes === 0)}
                 style={{ padding: "6px 16px", fontSize: 13 }}
               >
-                {loading ? "適用中..." : `適用する (${preview.inserts + preview.updates}行)`}
+                {loading ? "適用中..." : `適用する (${preview.inserts + preview.updates + preview.deletes}行)`}
               </button>
             </div>
           </div>
@@ -70,7 +70,6 @@ export function MergeLog({ onClose, onPreviewCommit, filterTable, filterPk }: Pr
     const [expandedTableKey, setExpandedTableKey] = useState<string | null>(null);
     const [exportingZip, setExportingZip] = useState(false);
     const [zipError, setZipError] = useState<string | null>(null);
-    const [zipWarning, setZipWarning] = useState<string | null>(null);
 
     // 2b: cross-merge comparison — up to 2 selected hashes
     const [compareHashes, setCompareHashes] = useState<string[]>([]);
@@ -181,7 +180,6 @@ export function MergeLog({ onClose, onPreviewCommit, filterTable, filterPk }: Pr
         setLoading(true);
         setSearched(false);
         setSearchError(null);
-        setZipWarning(null);
         setLightDiffMap({});
         setHeavyDiffMap({});
         setExpandedCommitHash(null);
@@ -254,18 +252,14 @@ export function MergeLog({ onClose, onPreviewCommit, filterTable, filterPk }: Pr
     const handleExportZip = async (hash: string) => {
         setExportingZip(true);
         setZipError(null);
-        setZipWarning(null);
         try {
-            const { blob, filename, warning } = await api.exportDiffZip(targetId, dbName, "main", `${hash}^`, hash, "two_dot");
+            const { blob, filename } = await api.exportDiffZip(targetId, dbName, "main", `${hash}^`, hash, "two_dot");
             const url = URL.createObjectURL(blob);
             const a = document.createElement("a");
             a.href = url;
             a.download = filename;
             a.click();
             URL.revokeObjectURL(url);
-            if (warning) {
-                setZipWarning(warning);
-            }
         } catch {
             setZipError("ZIPのエクスポートに失敗しました");
         } finally {
@@ -335,11 +329,6 @@ export function MergeLog({ onClose, onPreviewCommit, filterTable, filterPk }: Pr
                         {zipError}
                     </div>
                 )}
-                {zipWarning && (
-                    <div style={{ padding: "4px 10px", background: "#fef3c7", color: "#92400e", fontSize: 12, borderRadius: 4, marginBottom: 8 }}>
-                        {zipWarning}
-                    </div>
-                )}
 
                 {/* 2b: compare selection bar */}
                 <div style={{
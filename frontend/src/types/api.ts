@@ -17,6 +17,93 @@ export interface Database {
 export interface Branch {
   name: string;
   hash: string;
+  base_ref?: string;
+  base_hash?: string;
+  work_item_meta?: WorkItemMeta;
+}
+
+export type BranchAction = "commit" | "sync" | "submit" | "delete" | "abort_merge";
+
+export interface WorkingTableStatus {
+  table: string;
+  staged: boolean;
+  status: string;
+}
+
+export interface BranchStatus {
+  name: string;
+  hash: string;
+  ahead: number;
+  behind: number;
+  locked: boolean;
+  request_id?: string;
+  merging: boolean;
+  working_tables: WorkingTableStatus[];
+  last_commit_author: string;
+  last_commit_at: string;
+  allowed_actions: BranchAction[];
+}
+
+export interface StaleBranch {
+  name: string;
+  hash: string;
+  last_commit_at: string;
+  idle_days: number;
+}
+
+export interface StaleBranchesResponse {
+  idle_days: number;
+  action: "report" | "delete" | "archive";
+  branches: StaleBranch[];
+}
+
+export interface CleanupStaleBranchesRequest {
+  target_id: string;
+  db_name: string;
+  action?: "delete" | "archive";
+}
+
+export interface StaleBranchCleanupResult {
+  name: string;
+  deleted: boolean;
+  archive_tag?: string;
+  error?: string;
+}
+
+export interface CleanupStaleBranchesResponse extends OperationResultFields {
+  action: string;
+  results: StaleBranchCleanupResult[];
+}
+
+export interface WorkItemMeta {
+  work_item: string;
+  owner: string;
+  ticket_id: string;
+  description: string;
+  labels: string[];
+  due_date?: string;
+  updated_at?: string;
+}
+
+export interface SaveWorkItemMetaRequest {
+  target_id: string;
+  db_name: string;
+  work_item: string;
+  owner: string;
+  ticket_id: string;
+  description: string;
+  labels: string[];
+  due_date: string;
+}
+
+export interface WorkItemMetaQuery {
+  owner?: string;
+  ticket_id?: string;
+  label?: string;
+  keyword?: string;
+  due_before?: string;
+  sort?: "work_item" | "owner" | "ticket_id" | "due_date" | "updated_at";
+  order?: "asc" | "desc";
 }
 
 export interface Head {
@@ -54,6 +141,7 @@ export interface CreateBranchRequest {
   target_id: string;
   db_name: string;
   branch_name: string;
+  from_ref?: string;
 }
 
 export interface DeleteBranchRequest {
@@ -78,6 +166,57 @@ export interface SchemaResponse {
   columns: ColumnSchema[];
 }
 
+export type SchemaDiffStatus = "only_in_from" | "only_in_to" | "changed";
+
+export interface SchemaColumnDiff {
+  column: string;
+  status: SchemaDiffStatus;
+  from?: ColumnSchema;
+  to?: ColumnSchema;
+  changes?: ("type" | "nullable" | "primary_key")[];
+}
+
+export interface SchemaIndex {
+  name: string;
+  unique: boolean;
+  columns: string[];
+}
+
+export interface SchemaIndexDiff {
+  name: string;
+  status: SchemaDiffStatus;
+  from?: SchemaIndex;
+  to?: SchemaIndex;
+}
+
+export interface SchemaForeignKey {
+  name: string;
+  columns: string[];
+  referenced_table: string;
+  referenced_columns: string[];
+}
+
+export interface SchemaForeignKeyDiff {
+  status: SchemaDiffStatus;
+  foreign_key: SchemaForeignKey;
+}
+
+export interface SchemaTableDiff {
+  table: string;
+  status: SchemaDiffStatus;
+  columns: SchemaColumnDiff[];
+  indexes: SchemaIndexDiff[];
+  foreign_keys: SchemaForeignKeyDiff[];
+  expand_columns: ExpandColumn[];
+}
+
+export interface SchemaCompareResponse {
+  from: { db: string; ref: string };
+  to: { db: string; ref: string };
+  tables: SchemaTableDiff[];
+  summary: { identical: number; changed: number; only_in_from: number; only_in_to: number };
+}
+
 export interface RowsResponse {
   rows: Record<string, unknown>[];
   page: number;
@@ -106,6 +245,11 @@ export interface CommitResponse {
   hash: string;
 }
 
+export interface CommitValidateResponse {
+  valid: boolean;
+  errors: PreviewError[];
+}
+
 export interface SyncRequest {
   target_id: string;
   db_name: string;
@@ -116,6 +260,29 @@ export interface SyncRequest {
 export interface SyncResponse {
   hash: string;
   overwritten_tables?: OverwrittenTable[];
+  behind_main: number;
+  base_ref?: string;
+}
+
+export interface UndoCommitRequest {
+  target_id: string;
+  db_name: string;
+  branch_name: string;
+  expected_head: string;
+}
+
+export interface SquashRequest extends UndoCommitRequest {
+  commit_message: string;
+}
+
+export interface CherryPickRequest extends UndoCommitRequest {
+  source_branch: string;
+  commit_hashes: string[];
+}
+
+export interface BranchRewriteResponse extends OperationResultFields {
+  hash: string;
+  previous_head: string;
 }
 
 export interface OverwrittenTable {
@@ -135,6 +302,27 @@ export interface HistoryCommit {
   message: string;
   timestamp: string;
   merge_branch?: string; // 2a: extracted work branch name for merges_only
+  ticket_id?: string;
+  work_item_meta?: WorkItemMeta;
+}
+
+export interface Release {
+  name: string;
+  tag: string;
+  hash: string;
+  notes: string;
+  created_by: string;
+  created_at: string;
+  previous_release?: string;
+  approvals: HistoryCommit[];
+}
+
+export interface CreateReleaseRequest {
+  target_id: string;
+  db_name: string;
+  name: string;
+  commit_hash?: string;
+  notes: string;
 }
 
 export interface SubmitRequestRequest {
@@ -150,9 +338,30 @@ export interface SubmitRequestResponse {
   submitted_main_hash: string;
   submitted_work_hash: string;
   overwritten_tables?: OverwrittenTable[];
+  behind_main: number;
+  base_ref?: string;
+  overlaps?: BranchOverlap[];
 }
 export interface SubmitRequestResult extends SubmitRequestResponse, OperationResultFields {}
 
+export interface OverlapRow {
+  table: string;
+  pk: Record<string, unknown>;
+  cells: string[];
+}
+
+export interface BranchOverlap {
+  branch: string;
+  request_id?: string;
+  rows: OverlapRow[];
+}
+
+export interface OverlapsResponse {
+  branch_name: string;
+  overlaps: BranchOverlap[];
+  truncated: boolean;
+}
+
 export interface RequestSummary {
   request_id: string;
   work_branch: string;
@@ -160,6 +369,23 @@ export interface RequestSummary {
   submitted_work_hash: string;
   summary_ja: string;
   submitted_at?: string;
+  work_item_meta?: WorkItemMeta;
+  check?: RequestMergeCheck;
+}
+
+export interface RequestMergeCheck {
+  mergeable: boolean;
+  main_moved: boolean;
+  current_main_hash: string;
+  conflict_tables?: string[];
+  schema_conflict_tables?: string[];
+  tables: DiffSummaryEntry[];
+  error?: string;
+}
+
+export interface RequestCheckResponse {
+  main_hash: string;
+  requests: RequestSummary[];
 }
 
 export interface ApproveRequest {
@@ -167,6 +393,7 @@ export interface ApproveRequest {
   db_name: string;
   request_id: string;
   merge_message_ja: string;
+  effective_at?: string;
 }
 
 export interface RejectRequest {
@@ -180,10 +407,56 @@ export interface ApproveResponse {
   active_branch: string;
   active_branch_advanced: boolean;
   archive_tag?: string;
+  scheduled_for?: string;
   warnings?: string[];
 }
 export interface ApproveResult extends ApproveResponse, OperationResultFields {}
 
+export interface ScheduledApproval {
+  request_id: string;
+  request_hash: string;
+  work_branch: string;
+  effective_at: string;
+  merge_message_ja: string;
+  created_at: string;
+  status: "scheduled" | "failed";
+  last_error?: string;
+}
+
+export interface CancelScheduledApprovalRequest {
+  target_id: string;
+  db_name: string;
+  request_id: string;
+}
+
+export interface EnqueueMergeRequest {
+  target_id: string;
+  db_name: string;
+  request_id: string;
+  merge_message_ja: string;
+}
+
+export type MergeQueueStatus = "queued" | "merging" | "merged" | "failed";
+
+export interface MergeQueueEntry {
+  id: number;
+  request_id: string;
+  merge_message_ja: string;
+  status: MergeQueueStatus;
+  position?: number;
+  enqueued_at: string;
+  started_at?: string;
+  finished_at?: string;
+  reason?: string;
+  message?: string;
+  result?: ApproveResult;
+}
+
+export interface MergeQueueResponse {
+  running: boolean;
+  entries: MergeQueueEntry[];
+}
+
 export interface PreviewCloneRequest {
   target_id: string;
   db_name: string;
@@ -239,6 +512,26 @@ export interface DiffSummaryResponse {
   entries: DiffSummaryEntry[];
 }
 
+export type DiffExportFormat = "csv" | "csv_pairs" | "xlsx" | "jsonl" | "sql";
+
+// manifest.json, the last entry of a diff export ZIP
+export interface DiffExportFile {
+  name: string;
+  table: string;
+  diff_type?: string;
+  rows: number;
+  error?: string;
+}
+
+export interface DiffExportManifest {
+  from_ref: string;
+  to_ref: string;
+  mode: string;
+  format: DiffExportFormat;
+  files: DiffExportFile[];
+  complete: boolean;
+}
+
 export interface DiffSummaryLightEntry {
   table: string;
   has_data_change: boolean;
@@ -258,12 +551,22 @@ export interface ExpandColumn {
   dst_type: string;
 }
 
+// Source columns that are neither mapped nor skipped are matched by name.
+export interface CrossCopyMapping {
+  columns?: Record<string, string>; // source column -> destination column
+  constants?: Record<string, string>; // destination column -> value
+  skip?: string[];
+}
+
 export interface CrossCopyPreviewRequest {
   target_id: string;
+  source_target_id?: string;
   source_db: string;
   source_branch: string;
   source_table: string;
   source_pks: string[];
+  source_filters?: FilterCondition[];
+  mapping?: CrossCopyMapping;
   dest_db: string;
   dest_branch: string;
 }
@@ -281,14 +584,18 @@ export interface CrossCopyPreviewResponse {
   warnings: string[];
   rows: CrossCopyPreviewRow[];
   expand_columns?: ExpandColumn[];
+  column_map?: Record<string, string>; // destination -> source column
 }
 
 export interface CrossCopyRowsRequest {
   target_id: string;
+  source_target_id?: string;
   source_db: string;
   source_branch: string;
   source_table: string;
   source_pks: string[];
+  source_filters?: FilterCondition[];
+  mapping?: CrossCopyMapping;
   dest_db: string;
   dest_branch: string;
 }
@@ -303,6 +610,7 @@ export interface CrossCopyRowsResult extends CrossCopyRowsResponse, OperationRes
 
 export interface CrossCopyAdminPrepareRowsRequest {
   target_id: string;
+  source_target_id?: string;
   source_db: string;
   source_branch: string;
   source_table: string;
@@ -320,6 +628,7 @@ export interface CrossCopyAdminPrepareRowsResult extends CrossCopyAdminPrepareRo
 
 export interface CrossCopyTableRequest {
   target_id: string;
+  source_target_id?: string;
   source_db: string;
   source_branch: string;
   source_table: string;
@@ -336,8 +645,94 @@ export interface CrossCopyTableResponse {
 }
 export interface CrossCopyTableResult extends CrossCopyTableResponse, OperationResultFields {}
 
+export interface CrossCopyTablesRequest {
+  target_id: string;
+  source_target_id?: string;
+  source_db: string;
+  source_branch: string;
+  source_tables: string[];
+  dest_db: string;
+}
+
+export interface CrossCopyTablePlan {
+  table: string;
+  shared_columns: string[];
+  source_only_columns: string[];
+  dest_only_columns: string[];
+  source_rows: number;
+  dest_rows: number;
+  depends_on: string[];
+  expand_columns?: ExpandColumn[];
+}
+
+export interface CrossCopyTablesPreviewResponse {
+  branch_name: string;
+  order: string[];
+  tables: CrossCopyTablePlan[];
+  warnings: string[];
+}
+
+export interface CrossCopyTableCount {
+  table: string;
+  row_count: number;
+}
+
+export interface CrossCopyTablesResponse {
+  hash: string;
+  branch_name: string;
+  order: string[];
+  tables: CrossCopyTableCount[];
+  row_count: number;
+}
+export interface CrossCopyTablesResult extends CrossCopyTablesResponse, OperationResultFields {}
+
+export interface ReplayApprovalRequest {
+  target_id: string;
+  source_target_id?: string;
+  source_db: string;
+  merge_hash: string;
+  dest_db: string;
+}
+
+export interface ReplaySkippedRow {
+  table: string;
+  diff_type: "added" | "modified" | "removed";
+  pk: Record<string, unknown>;
+  reason: "missing_pk" | "base_changed" | "pk_exists";
+  columns?: string[];
+}
+
+export interface ReplayTableResult {
+  table: string;
+  inserted: number;
+  updated: number;
+  deleted: number;
+  unchanged: number;
+  skipped: number;
+}
+
+export interface ReplayApprovalPreviewResponse {
+  branch_name: string;
+  request_id: string;
+  work_item: string;
+  tables: ReplayTableResult[];
+  skipped: ReplaySkippedRow[];
+  warnings: string[];
+}
+
+export interface ReplayApprovalResponse {
+  hash: string;
+  branch_name: string;
+  request_id: string;
+  work_item: string;
+  tables: ReplayTableResult[];
+  skipped: ReplaySkippedRow[];
+}
+export interface ReplayApprovalResult extends ReplayApprovalResponse, OperationResultFields {}
+
 export interface CrossCopyAdminPrepareTableRequest {
   target_id: string;
+  source_target_id?: string;
   source_db: string;
   source_branch: string;
   source_table: string;
@@ -381,7 +776,58 @@ export interface CSVPreviewRequest {
   db_name: string;
   branch_name: string;
   table: string;
-  rows: Record<string, unknown>[];
+  rows?: Record<string, unknown>[];
+  upload_id?: string;
+  mode?: CSVImportMode;
+  replace_filter?: FilterCondition[];
+  profile?: string;
+  mapping?: CSVMapping;
+}
+
+export type CSVImportMode = "upsert" | "insert_only" | "update_only" | "replace";
+
+export interface CSVMapping {
+  columns?: Record<string, string>;
+  ignore?: string[];
+  constants?: Record<string, string>;
+  defaults?: Record<string, string>;
+  trim?: boolean;
+  half_width?: boolean;
+}
+
+export interface CSVMappingProfile extends CSVMapping {
+  table: string;
+  name: string;
+  updated_at?: string;
+}
+
+/** Same shape as the table filter sent as JSON in GET /table/rows?filter=. */
+export interface FilterCondition {
+  column: string;
+  op: "eq" | "neq" | "contains" | "startsWith" | "endsWith" | "blank" | "notBlank" | "in" | "or_group";
+  value?: unknown;
+  or_group?: FilterCondition[];
+}
+
+export interface CSVUploadOptions {
+  encoding?: "utf-8" | "shift_jis" | "euc-jp";
+  delimiter?: string;
+  header_row?: number;
+  sheet?: string;
+}
+
+export interface CSVUploadResponse {
+  upload_id: string;
+  file_name: string;
+  format: "csv" | "tsv" | "xlsx";
+  encoding?: string;
+  delimiter?: string;
+  sheet?: string;
+  header_row: number;
+  headers: string[];
+  row_count: number;
+  sample_rows: Record<string, string>[];
+  expires_at: string;
 }
 
 export interface CSVDiffRow {
@@ -399,9 +845,21 @@ export interface CSVPreviewResponse {
   inserts: number;
   updates: number;
   skips: number;
+  deletes: number;
   errors: number;
   sample_diffs: CSVDiffRow[];
+  sample_deletes?: Record<string, unknown>[];
   preview_errors?: CSVPreviewError[];
+  column_errors?: Record<string, number>;
+  preview_id?: string;
+}
+
+export interface CSVPreviewErrorsResponse {
+  preview_id: string;
+  page: number;
+  page_size: number;
+  total: number;
+  errors: CSVPreviewError[];
 }
 
 export interface CSVApplyRequest {
@@ -411,11 +869,19 @@ export interface CSVApplyRequest {
   table: string;
   expected_head: string;
   commit_message: string;
-  rows: Record<string, unknown>[];
+  rows?: Record<string, unknown>[];
+  upload_id?: string;
+  mode?: CSVImportMode;
+  replace_filter?: FilterCondition[];
+  profile?: string;
+  mapping?: CSVMapping;
 }
 
 export interface CSVApplyResponse extends OperationResultFields {
   hash: string;
+  inserts: number;
+  updates: number;
+  deletes: number;
 }
 
 // --- Search ---
@@ -450,3 +916,93 @@ export interface MemoResponse {
   memo_text: string;
   updated_at?: string;
 }
+
+// --- Replication ---
+
+export interface ReplicationRun {
+  remote: string;
+  action: "sync" | "push" | "fetch";
+  status: "succeeded" | "failed";
+  started_at: string;
+  finished_at: string;
+  error?: string;
+}
+
+export interface ReplicationRemoteStatus {
+  name: string;
+  url: string;
+  kind: "backup" | "remote";
+  last_success_at?: string;
+  history: ReplicationRun[];
+}
+
+export interface ReplicationStatusResponse {
+  interval_minutes: number;
+  remotes: ReplicationRemoteStatus[];
+}
+
+export interface RunReplicationRequest {
+  target_id: string;
+  db_name: string;
+  remote?: string;
+  action?: "sync" | "push" | "fetch";
+}
+
+export interface RunReplicationResult extends OperationResultFields {
+  runs: ReplicationRun[];
+}
+
+export interface RestoreReplicaRequest {
+  target_id: string;
+  db_name: string;
+  remote: string;
+  new_db_name: string;
+}
+
+export interface RestoreReplicaResult extends OperationResultFields {
+  new_db_name: string;
+}
+
+// --- Audit ---
+
+export interface AuditImportRequest {
+  target_id: string;
+  db_name: string;
+  snapshot: string;
+  expected_head?: string;
+}
+
+export interface AuditImportTable {
+  table: string;
+  file: string;
+  rows: number;
+}
+
+export interface AuditImportResult extends OperationResultFields {
+  hash: string;
+  snapshot: string;
+  tables: AuditImportTable[];
+}
+
+export interface AuditImportInfo {
+  hash: string;
+  snapshot: string;
+  tables: string[];
+  rows: number;
+  imported_at: string;
+}
+
+export interface AuditReconcileTable {
+  table: string;
+  only_in_main: number;
+  only_in_audit: number;
+  differing_rows: number;
+  differing_cells: number;
+}
+
+export interface AuditReconcileResponse {
+  main_hash: string;
+  audit_hash: string;
+  last_import?: AuditImportInfo;
+  tables: AuditReconcileTable[];
+}